@@ -0,0 +1,193 @@
+// Package aider implements the Agent interface for Aider.
+//
+// Aider has no lifecycle hooks. Instead it appends every session to a Markdown
+// chat log (.aider.chat.history.md) and every submitted prompt to an input
+// history file (.aider.input.history) in the repository root. This package
+// parses those files and implements agent.FileWatcher so that `entire watch`
+// can turn their changes into lifecycle events.
+package aider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+const (
+	// ChatHistoryFileName is Aider's default Markdown chat log, relative to the repo root.
+	ChatHistoryFileName = ".aider.chat.history.md"
+
+	// InputHistoryFileName is Aider's default prompt history, relative to the repo root.
+	InputHistoryFileName = ".aider.input.history"
+
+	// configFileName is Aider's per-repository config file.
+	configFileName = ".aider.conf.yml"
+
+	// Environment variables Aider reads to relocate its history files.
+	chatHistoryEnvVar  = "AIDER_CHAT_HISTORY_FILE"
+	inputHistoryEnvVar = "AIDER_INPUT_HISTORY_FILE"
+)
+
+//nolint:gochecknoinits // Agent self-registration is the intended pattern
+func init() {
+	agent.Register(agent.AgentNameAider, NewAiderAgent)
+}
+
+// AiderAgent implements the Agent interface for Aider.
+//
+//nolint:revive // AiderAgent is clearer than Agent in this context
+type AiderAgent struct{}
+
+// NewAiderAgent creates a new Aider agent instance.
+func NewAiderAgent() agent.Agent {
+	return &AiderAgent{}
+}
+
+// Name returns the agent registry key.
+func (a *AiderAgent) Name() agent.AgentName {
+	return agent.AgentNameAider
+}
+
+// Type returns the agent type identifier.
+func (a *AiderAgent) Type() agent.AgentType {
+	return agent.AgentTypeAider
+}
+
+// Description returns a human-readable description.
+func (a *AiderAgent) Description() string {
+	return "Aider - AI pair programming in your terminal"
+}
+
+func (a *AiderAgent) IsPreview() bool { return true }
+
+// DetectPresence checks if Aider has been used or configured in the repository.
+func (a *AiderAgent) DetectPresence(ctx context.Context) (bool, error) {
+	repoRoot, err := paths.WorktreeRoot(ctx)
+	if err != nil {
+		repoRoot = "."
+	}
+
+	for _, name := range []string{ChatHistoryFileName, InputHistoryFileName, configFileName} {
+		if _, err := os.Stat(filepath.Join(repoRoot, name)); err == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ProtectedDirs returns the paths Aider uses for history and caches.
+// The history files are protected so rewind never deletes the session log itself.
+func (a *AiderAgent) ProtectedDirs() []string {
+	return []string{ChatHistoryFileName, InputHistoryFileName, ".aider.tags.cache.v3", ".aider.tags.cache.v4"}
+}
+
+// GetSessionID extracts the session ID from hook input.
+func (a *AiderAgent) GetSessionID(input *agent.HookInput) string {
+	return input.SessionID
+}
+
+// GetSessionDir returns the directory containing Aider's chat history.
+// Aider writes its history files into the repository root.
+func (a *AiderAgent) GetSessionDir(repoPath string) (string, error) {
+	return repoPath, nil
+}
+
+// ResolveSessionFile returns the path to the Aider chat history.
+// All Aider sessions share one chat log, so the session ID does not affect the path.
+func (a *AiderAgent) ResolveSessionFile(sessionDir, _ string) string {
+	return resolveHistoryPath(sessionDir, chatHistoryEnvVar, ChatHistoryFileName)
+}
+
+// ReadSession reads a session from Aider's chat history.
+func (a *AiderAgent) ReadSession(input *agent.HookInput) (*agent.AgentSession, error) {
+	if input.SessionRef == "" {
+		return nil, errors.New("session reference (chat history path) is required")
+	}
+
+	data, err := os.ReadFile(input.SessionRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chat history: %w", err)
+	}
+
+	return &agent.AgentSession{
+		SessionID:     input.SessionID,
+		AgentName:     a.Name(),
+		SessionRef:    input.SessionRef,
+		StartTime:     time.Now(),
+		NativeData:    data,
+		ModifiedFiles: ExtractModifiedFiles(data),
+	}, nil
+}
+
+// WriteSession writes a session to Aider's chat history.
+func (a *AiderAgent) WriteSession(_ context.Context, session *agent.AgentSession) error {
+	if session == nil {
+		return errors.New("session is nil")
+	}
+
+	if session.AgentName != "" && session.AgentName != a.Name() {
+		return fmt.Errorf("session belongs to agent %q, not %q", session.AgentName, a.Name())
+	}
+
+	if session.SessionRef == "" {
+		return errors.New("session reference (chat history path) is required")
+	}
+
+	if len(session.NativeData) == 0 {
+		return errors.New("session has no native data to write")
+	}
+
+	if err := os.WriteFile(session.SessionRef, session.NativeData, 0o600); err != nil {
+		return fmt.Errorf("failed to write chat history: %w", err)
+	}
+
+	return nil
+}
+
+// FormatResumeCommand returns the command to resume an Aider session.
+// Aider has no session IDs of its own; it can only replay the chat history.
+func (a *AiderAgent) FormatResumeCommand(_ string) string {
+	return "aider --restore-chat-history"
+}
+
+// ReadTranscript reads the raw Markdown chat history.
+func (a *AiderAgent) ReadTranscript(sessionRef string) ([]byte, error) {
+	data, err := os.ReadFile(sessionRef) //nolint:gosec // Path comes from the watcher or session state
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return data, nil
+}
+
+// ChunkTranscript splits the Markdown chat history at line boundaries.
+func (a *AiderAgent) ChunkTranscript(_ context.Context, content []byte, maxSize int) ([][]byte, error) {
+	chunks, err := agent.ChunkJSONL(content, maxSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to chunk chat history: %w", err)
+	}
+	return chunks, nil
+}
+
+// ReassembleTranscript concatenates chat history chunks with newlines.
+func (a *AiderAgent) ReassembleTranscript(chunks [][]byte) ([]byte, error) {
+	return agent.ReassembleJSONL(chunks), nil
+}
+
+// resolveHistoryPath returns the history file path, honoring Aider's environment
+// overrides. Relative overrides are resolved against repoRoot, as Aider does.
+func resolveHistoryPath(repoRoot, envVar, defaultName string) string {
+	name := os.Getenv(envVar)
+	if name == "" {
+		name = defaultName
+	}
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(repoRoot, name)
+}
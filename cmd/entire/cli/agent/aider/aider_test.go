@@ -0,0 +1,167 @@
+package aider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+)
+
+// Compile-time interface checks
+var (
+	_ agent.FileWatcher        = (*AiderAgent)(nil)
+	_ agent.TranscriptAnalyzer = (*AiderAgent)(nil)
+	_ agent.TokenCalculator    = (*AiderAgent)(nil)
+)
+
+// setupAiderRepo creates a git repo in a temp dir and changes into it.
+func setupAiderRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	testutil.InitRepo(t, dir)
+	t.Chdir(dir)
+	paths.ClearWorktreeRootCache()
+	t.Cleanup(paths.ClearWorktreeRootCache)
+	return dir
+}
+
+func TestAiderAgent_Identity(t *testing.T) {
+	t.Parallel()
+	ag := &AiderAgent{}
+	if ag.Name() != agent.AgentNameAider {
+		t.Errorf("Name() = %q, want %q", ag.Name(), agent.AgentNameAider)
+	}
+	if ag.Type() != agent.AgentTypeAider {
+		t.Errorf("Type() = %q, want %q", ag.Type(), agent.AgentTypeAider)
+	}
+	if ag.Description() == "" {
+		t.Error("Description() returned empty string")
+	}
+	if !ag.IsPreview() {
+		t.Error("IsPreview() = false, want true")
+	}
+}
+
+func TestAiderAgent_Registered(t *testing.T) {
+	t.Parallel()
+	ag, err := agent.GetByAgentType(agent.AgentTypeAider)
+	if err != nil {
+		t.Fatalf("GetByAgentType() error = %v", err)
+	}
+	if ag.Name() != agent.AgentNameAider {
+		t.Errorf("registered agent Name() = %q, want %q", ag.Name(), agent.AgentNameAider)
+	}
+}
+
+func TestAiderAgent_ProtectedDirsIncludeHistory(t *testing.T) {
+	t.Parallel()
+	dirs := (&AiderAgent{}).ProtectedDirs()
+	for _, want := range []string{ChatHistoryFileName, InputHistoryFileName} {
+		found := false
+		for _, d := range dirs {
+			if d == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("ProtectedDirs() = %v, missing %q", dirs, want)
+		}
+	}
+}
+
+func TestAiderAgent_ResolveSessionFile(t *testing.T) {
+	ag := &AiderAgent{}
+
+	got := ag.ResolveSessionFile("/repo", "aider-20260101-120000")
+	if want := filepath.Join("/repo", ChatHistoryFileName); got != want {
+		t.Errorf("ResolveSessionFile() = %q, want %q", got, want)
+	}
+
+	t.Setenv(chatHistoryEnvVar, "logs/chat.md")
+	if got, want := ag.ResolveSessionFile("/repo", ""), filepath.Join("/repo", "logs", "chat.md"); got != want {
+		t.Errorf("ResolveSessionFile() with relative override = %q, want %q", got, want)
+	}
+
+	t.Setenv(chatHistoryEnvVar, "/var/tmp/chat.md")
+	if got := ag.ResolveSessionFile("/repo", ""); got != "/var/tmp/chat.md" {
+		t.Errorf("ResolveSessionFile() with absolute override = %q, want /var/tmp/chat.md", got)
+	}
+}
+
+func TestAiderAgent_DetectPresence(t *testing.T) {
+	dir := setupAiderRepo(t)
+	ag := &AiderAgent{}
+
+	present, err := ag.DetectPresence(context.Background())
+	if err != nil {
+		t.Fatalf("DetectPresence() error = %v", err)
+	}
+	if present {
+		t.Error("DetectPresence() = true before any Aider files exist")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, InputHistoryFileName), []byte("\n# 2026-01-01 12:00:00.000000\n+hi\n"), 0o600); err != nil {
+		t.Fatalf("failed to write input history: %v", err)
+	}
+	present, err = ag.DetectPresence(context.Background())
+	if err != nil {
+		t.Fatalf("DetectPresence() error = %v", err)
+	}
+	if !present {
+		t.Error("DetectPresence() = false, want true with input history present")
+	}
+}
+
+func TestAiderAgent_ReadWriteSession(t *testing.T) {
+	t.Parallel()
+	ag := &AiderAgent{}
+	path := filepath.Join(t.TempDir(), ChatHistoryFileName)
+
+	if err := ag.WriteSession(context.Background(), &agent.AgentSession{
+		AgentName:  agent.AgentNameAider,
+		SessionRef: path,
+		NativeData: []byte(sampleChatHistory),
+	}); err != nil {
+		t.Fatalf("WriteSession() error = %v", err)
+	}
+
+	session, err := ag.ReadSession(&agent.HookInput{SessionID: "aider-20260101-120000", SessionRef: path})
+	if err != nil {
+		t.Fatalf("ReadSession() error = %v", err)
+	}
+	if string(session.NativeData) != sampleChatHistory {
+		t.Error("ReadSession() NativeData does not match written chat history")
+	}
+	if len(session.ModifiedFiles) != 2 {
+		t.Errorf("ReadSession() ModifiedFiles = %v, want 2 files", session.ModifiedFiles)
+	}
+
+	if err := ag.WriteSession(context.Background(), &agent.AgentSession{AgentName: agent.AgentNameCursor, SessionRef: path, NativeData: []byte("x")}); err == nil {
+		t.Error("WriteSession() should reject sessions from other agents")
+	}
+}
+
+func TestAiderAgent_ChunkRoundTrip(t *testing.T) {
+	t.Parallel()
+	ag := &AiderAgent{}
+	content := []byte(sampleChatHistory)
+
+	chunks, err := ag.ChunkTranscript(context.Background(), content, 200)
+	if err != nil {
+		t.Fatalf("ChunkTranscript() error = %v", err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("expected multiple chunks, got %d", len(chunks))
+	}
+	reassembled, err := ag.ReassembleTranscript(chunks)
+	if err != nil {
+		t.Fatalf("ReassembleTranscript() error = %v", err)
+	}
+	if string(reassembled) != string(content) {
+		t.Error("chunk/reassemble round trip changed the chat history")
+	}
+}
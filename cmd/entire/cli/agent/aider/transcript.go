@@ -0,0 +1,379 @@
+package aider
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/transcript"
+)
+
+// Message roles in a parsed chat history.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	// RoleTool marks Aider's own output ("> " lines): edits applied, commits, token reports.
+	RoleTool = "tool"
+)
+
+const (
+	sessionHeaderPrefix = "# aider chat started at "
+	userLinePrefix      = "####"
+	toolLinePrefix      = ">"
+	codeFence           = "```"
+	sessionTimeLayout   = "2006-01-02 15:04:05"
+	sessionIDTimeLayout = "20060102-150405"
+)
+
+// Message is one block of the chat history.
+type Message struct {
+	Role    string
+	Content string
+	// Line is the zero-based line number where the message starts.
+	Line int
+}
+
+// ChatSession is one "# aider chat started at ..." section of the chat history.
+// Content before the first header (or in a sliced transcript) has an empty ID.
+type ChatSession struct {
+	ID        string
+	StartedAt time.Time
+	// Line is the zero-based line number of the session header.
+	Line     int
+	Messages []Message
+}
+
+// ParseChatHistory parses Aider's Markdown chat history into sessions.
+// "####" lines are user prompts, ">" lines are Aider's own output, and everything
+// else is assistant text.
+func ParseChatHistory(data []byte) []ChatSession {
+	var sessions []ChatSession
+	var current *ChatSession
+	var block []string
+	blockRole := ""
+	blockLine := 0
+	inFence := false
+
+	flush := func() {
+		if blockRole == "" {
+			return
+		}
+		content := strings.TrimSpace(strings.Join(block, "\n"))
+		if content != "" {
+			if current == nil {
+				sessions = append(sessions, ChatSession{})
+				current = &sessions[len(sessions)-1]
+			}
+			current.Messages = append(current.Messages, Message{Role: blockRole, Content: content, Line: blockLine})
+		}
+		block = nil
+		blockRole = ""
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		// Aider ends most lines with two spaces (Markdown hard breaks)
+		line = strings.TrimRight(line, " \r")
+
+		if header, ok := strings.CutPrefix(line, sessionHeaderPrefix); ok {
+			flush()
+			inFence = false
+			sessions = append(sessions, newChatSession(header, i))
+			current = &sessions[len(sessions)-1]
+			continue
+		}
+
+		role, text := classifyLine(line)
+		if inFence {
+			// Code blocks (including SEARCH/REPLACE markers) are assistant text
+			role, text = RoleAssistant, line
+		}
+		if strings.HasPrefix(line, codeFence) && role == RoleAssistant {
+			inFence = !inFence
+		}
+		if role == "" {
+			// Blank line: ends prompt and output blocks, but is part of assistant prose
+			if blockRole == RoleAssistant {
+				block = append(block, "")
+			} else {
+				flush()
+			}
+			continue
+		}
+		if role != blockRole {
+			flush()
+			blockRole = role
+			blockLine = i
+		}
+		block = append(block, text)
+	}
+	flush()
+
+	return sessions
+}
+
+// newChatSession creates a session from the timestamp in its header line.
+func newChatSession(header string, line int) ChatSession {
+	s := ChatSession{Line: line}
+	if started, err := time.ParseInLocation(sessionTimeLayout, strings.TrimSpace(header), time.Local); err == nil {
+		s.StartedAt = started
+		s.ID = "aider-" + started.Format(sessionIDTimeLayout)
+	} else {
+		s.ID = fmt.Sprintf("aider-line-%d", line+1)
+	}
+	return s
+}
+
+// classifyLine returns the role and text of a non-header line.
+// Returns an empty role for blank lines.
+func classifyLine(line string) (role, text string) {
+	switch {
+	case strings.TrimSpace(line) == "":
+		return "", ""
+	case strings.HasPrefix(line, userLinePrefix):
+		return RoleUser, strings.TrimPrefix(strings.TrimPrefix(line, userLinePrefix), " ")
+	case strings.HasPrefix(line, toolLinePrefix):
+		return RoleTool, strings.TrimPrefix(strings.TrimPrefix(line, toolLinePrefix), " ")
+	default:
+		return RoleAssistant, line
+	}
+}
+
+// CurrentSessionID returns the ID of the last session in the chat history,
+// or an empty string if no session header has been written yet.
+func CurrentSessionID(data []byte) string {
+	sessions := ParseChatHistory(data)
+	for i := len(sessions) - 1; i >= 0; i-- {
+		if sessions[i].ID != "" {
+			return sessions[i].ID
+		}
+	}
+	return ""
+}
+
+// messagesFromLine returns all messages starting at the given line offset.
+func messagesFromLine(data []byte, startLine int) []Message {
+	var messages []Message
+	for _, s := range ParseChatHistory(transcript.SliceFromLine(data, startLine)) {
+		messages = append(messages, s.Messages...)
+	}
+	return messages
+}
+
+// ExtractAllUserPrompts extracts every user prompt from the chat history.
+func ExtractAllUserPrompts(data []byte) []string {
+	return promptsFromMessages(messagesFromLine(data, 0))
+}
+
+func promptsFromMessages(messages []Message) []string {
+	var prompts []string
+	for _, m := range messages {
+		if m.Role == RoleUser {
+			prompts = append(prompts, m.Content)
+		}
+	}
+	return prompts
+}
+
+// ExtractLastAssistantMessage returns the last assistant reply in the chat history.
+func ExtractLastAssistantMessage(data []byte) string {
+	messages := messagesFromLine(data, 0)
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == RoleAssistant {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
+// appliedEditRegex matches Aider's "Applied edit to <file>" output line.
+var appliedEditRegex = regexp.MustCompile(`^Applied edit to (.+)$`)
+
+// ExtractModifiedFiles returns the files Aider reports editing, in first-seen order.
+func ExtractModifiedFiles(data []byte) []string {
+	return modifiedFilesFromMessages(messagesFromLine(data, 0))
+}
+
+func modifiedFilesFromMessages(messages []Message) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, m := range messages {
+		for _, file := range m.EditedFiles() {
+			if !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	return files
+}
+
+// EditedFiles returns the files named in "Applied edit to" lines of a tool message.
+func (m Message) EditedFiles() []string {
+	if m.Role != RoleTool {
+		return nil
+	}
+	var files []string
+	for _, line := range strings.Split(m.Content, "\n") {
+		if match := appliedEditRegex.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			files = append(files, strings.TrimSpace(match[1]))
+		}
+	}
+	return files
+}
+
+// transcriptPosition returns the number of complete lines in the chat history,
+// excluding a trailing prompt that Aider has echoed but not yet answered.
+// Excluding the pending prompt means a TurnStart observed just after Aider wrote
+// the "####" lines still captures an offset that includes the new prompt.
+func transcriptPosition(data []byte) int {
+	lines := strings.Split(string(data), "\n")
+	// The last element is the (possibly empty) incomplete line after the final newline
+	complete := lines[:len(lines)-1]
+
+	end := len(complete)
+	for end > 0 && strings.TrimSpace(complete[end-1]) == "" {
+		end--
+	}
+	start := end
+	for start > 0 && strings.HasPrefix(complete[start-1], userLinePrefix) {
+		start--
+	}
+	if start < end {
+		return start
+	}
+	return len(complete)
+}
+
+// tokenReportRegex matches Aider's per-message token report, e.g.
+// "Tokens: 12k sent, 1.5k cache write, 3.2k cache hit, 200 received. Cost: ...".
+var tokenReportRegex = regexp.MustCompile(`^Tokens: (.+?)(?:\. Cost:.*)?\.?$`)
+
+// tokenCountRegex matches one "<count> <kind>" pair inside a token report.
+var tokenCountRegex = regexp.MustCompile(`^([\d.,]+)([kKmM]?) (sent|received|cache write|cache hit)$`)
+
+// parseTokenReport parses a token report line. Returns false if the line is not one.
+func parseTokenReport(line string) (*agent.TokenUsage, bool) {
+	match := tokenReportRegex.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return nil, false
+	}
+
+	usage := &agent.TokenUsage{}
+	found := false
+	for _, part := range strings.Split(match[1], ", ") {
+		m := tokenCountRegex.FindStringSubmatch(strings.TrimSpace(part))
+		if m == nil {
+			continue
+		}
+		n, ok := parseTokenCount(m[1], m[2])
+		if !ok {
+			continue
+		}
+		found = true
+		switch m[3] {
+		case "sent":
+			usage.InputTokens = n
+		case "received":
+			usage.OutputTokens = n
+		case "cache write":
+			usage.CacheCreationTokens = n
+		case "cache hit":
+			usage.CacheReadTokens = n
+		}
+	}
+	return usage, found
+}
+
+// parseTokenCount parses Aider's abbreviated counts ("950", "1,234", "2.3k", "1.1M").
+func parseTokenCount(number, suffix string) (int, bool) {
+	value, err := strconv.ParseFloat(strings.ReplaceAll(number, ",", ""), 64)
+	if err != nil {
+		return 0, false
+	}
+	switch suffix {
+	case "k", "K":
+		value *= 1_000
+	case "m", "M":
+		value *= 1_000_000
+	}
+	return int(value + 0.5), true
+}
+
+// TranscriptAnalyzer interface implementation
+
+// GetTranscriptPosition returns the current line count of the chat history.
+// Returns 0 if the file doesn't exist.
+func (a *AiderAgent) GetTranscriptPosition(path string) (int, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // Path comes from the watcher or session state
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read chat history: %w", err)
+	}
+	return transcriptPosition(data), nil
+}
+
+// ExtractModifiedFilesFromOffset extracts files Aider edited since the given line offset.
+func (a *AiderAgent) ExtractModifiedFilesFromOffset(path string, startOffset int) (files []string, currentPosition int, err error) {
+	data, err := os.ReadFile(path) //nolint:gosec // Path comes from the watcher or session state
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, nil
+		}
+		return nil, 0, fmt.Errorf("failed to read chat history: %w", err)
+	}
+	return modifiedFilesFromMessages(messagesFromLine(data, startOffset)), transcriptPosition(data), nil
+}
+
+// ExtractPrompts extracts user prompts from the chat history starting at the given line offset.
+func (a *AiderAgent) ExtractPrompts(sessionRef string, fromOffset int) ([]string, error) {
+	data, err := os.ReadFile(sessionRef) //nolint:gosec // Path comes from the watcher or session state
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chat history: %w", err)
+	}
+	return promptsFromMessages(messagesFromLine(data, fromOffset)), nil
+}
+
+// ExtractSummary returns the last assistant reply as a session summary.
+func (a *AiderAgent) ExtractSummary(sessionRef string) (string, error) {
+	data, err := os.ReadFile(sessionRef) //nolint:gosec // Path comes from the watcher or session state
+	if err != nil {
+		return "", fmt.Errorf("failed to read chat history: %w", err)
+	}
+	return ExtractLastAssistantMessage(data), nil
+}
+
+// CalculateTokenUsage sums Aider's "Tokens: ..." reports starting at the given line offset.
+// Each report corresponds to one model call.
+func (a *AiderAgent) CalculateTokenUsage(transcriptData []byte, fromOffset int) (*agent.TokenUsage, error) {
+	usage := &agent.TokenUsage{}
+	scanner := bufio.NewScanner(bytes.NewReader(transcript.SliceFromLine(transcriptData, fromOffset)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		text, ok := strings.CutPrefix(line, toolLinePrefix)
+		if !ok {
+			continue
+		}
+		report, ok := parseTokenReport(text)
+		if !ok {
+			continue
+		}
+		usage.APICallCount++
+		usage.InputTokens += report.InputTokens
+		usage.OutputTokens += report.OutputTokens
+		usage.CacheCreationTokens += report.CacheCreationTokens
+		usage.CacheReadTokens += report.CacheReadTokens
+	}
+	if err := scanner.Err(); err != nil {
+		return usage, fmt.Errorf("failed to scan chat history: %w", err)
+	}
+	return usage, nil
+}
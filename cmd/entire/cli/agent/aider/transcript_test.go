@@ -0,0 +1,228 @@
+package aider
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sampleChatHistory mirrors a real .aider.chat.history.md with two sessions.
+// Aider terminates most lines with two spaces (Markdown hard breaks).
+const sampleChatHistory = `
+# aider chat started at 2026-01-01 12:00:00
+
+> /usr/local/bin/aider --model sonnet
+> Aider v0.86.1
+> Added main.go to the chat.
+
+#### add a hello function
+
+I'll add the function.
+
+main.go
+` + "```go" + `
+<<<<<<< SEARCH
+=======
+func hello() {}
+>>>>>>> REPLACE
+` + "```" + `
+
+> Tokens: 2.3k sent, 120 received. Cost: $0.01 message, $0.01 session.
+> Applied edit to main.go
+> Commit 1a2b3c4 feat: add hello function
+
+# aider chat started at 2026-01-02 09:30:15
+
+> Aider v0.86.1
+
+#### rename hello to greet
+#### and update the README
+
+Renamed and documented.
+
+> Tokens: 1,234 sent, 1.5k cache write, 800 cache hit, 95 received.
+> Applied edit to main.go
+> Applied edit to README.md
+`
+
+func TestParseChatHistory(t *testing.T) {
+	t.Parallel()
+
+	sessions := ParseChatHistory([]byte(sampleChatHistory))
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+
+	first := sessions[0]
+	if first.ID != "aider-20260101-120000" {
+		t.Errorf("first session ID = %q, want aider-20260101-120000", first.ID)
+	}
+	if first.StartedAt.IsZero() {
+		t.Error("expected StartedAt to be parsed from the header")
+	}
+
+	var roles []string
+	for _, m := range first.Messages {
+		roles = append(roles, m.Role)
+	}
+	if got := strings.Join(roles, ","); got != "tool,user,assistant,tool" {
+		t.Errorf("first session roles = %s, want tool,user,assistant,tool", got)
+	}
+	if first.Messages[1].Content != "add a hello function" {
+		t.Errorf("prompt = %q, want trailing spaces trimmed", first.Messages[1].Content)
+	}
+	if !strings.Contains(first.Messages[2].Content, "func hello() {}") {
+		t.Errorf("assistant message should keep code blocks, got %q", first.Messages[2].Content)
+	}
+
+	second := sessions[1]
+	if second.ID != "aider-20260102-093015" {
+		t.Errorf("second session ID = %q, want aider-20260102-093015", second.ID)
+	}
+	if second.Messages[1].Content != "rename hello to greet\nand update the README" {
+		t.Errorf("multi-line prompt = %q", second.Messages[1].Content)
+	}
+}
+
+func TestCurrentSessionID(t *testing.T) {
+	t.Parallel()
+
+	if got := CurrentSessionID([]byte(sampleChatHistory)); got != "aider-20260102-093015" {
+		t.Errorf("CurrentSessionID() = %q, want aider-20260102-093015", got)
+	}
+	if got := CurrentSessionID([]byte("#### no header\n")); got != "" {
+		t.Errorf("CurrentSessionID() without header = %q, want empty", got)
+	}
+}
+
+func TestExtractAllUserPrompts(t *testing.T) {
+	t.Parallel()
+
+	prompts := ExtractAllUserPrompts([]byte(sampleChatHistory))
+	if len(prompts) != 2 || prompts[0] != "add a hello function" {
+		t.Errorf("ExtractAllUserPrompts() = %q", prompts)
+	}
+}
+
+func TestExtractModifiedFiles(t *testing.T) {
+	t.Parallel()
+
+	files := ExtractModifiedFiles([]byte(sampleChatHistory))
+	if strings.Join(files, ",") != "main.go,README.md" {
+		t.Errorf("ExtractModifiedFiles() = %v, want [main.go README.md]", files)
+	}
+}
+
+func TestAiderAgent_OffsetScopedExtraction(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ChatHistoryFileName)
+	if err := os.WriteFile(path, []byte(sampleChatHistory), 0o600); err != nil {
+		t.Fatalf("failed to write chat history: %v", err)
+	}
+	// Offset of the second session header
+	offset := ParseChatHistory([]byte(sampleChatHistory))[1].Line
+
+	ag := &AiderAgent{}
+	prompts, err := ag.ExtractPrompts(path, offset)
+	if err != nil {
+		t.Fatalf("ExtractPrompts() error = %v", err)
+	}
+	if len(prompts) != 1 || !strings.HasPrefix(prompts[0], "rename hello") {
+		t.Errorf("ExtractPrompts() = %q, want only the second session's prompt", prompts)
+	}
+
+	files, pos, err := ag.ExtractModifiedFilesFromOffset(path, offset)
+	if err != nil {
+		t.Fatalf("ExtractModifiedFilesFromOffset() error = %v", err)
+	}
+	if strings.Join(files, ",") != "main.go,README.md" {
+		t.Errorf("ExtractModifiedFilesFromOffset() files = %v", files)
+	}
+	if want := strings.Count(sampleChatHistory, "\n"); pos != want {
+		t.Errorf("ExtractModifiedFilesFromOffset() position = %d, want %d", pos, want)
+	}
+
+	summary, err := ag.ExtractSummary(path)
+	if err != nil {
+		t.Fatalf("ExtractSummary() error = %v", err)
+	}
+	if summary != "Renamed and documented." {
+		t.Errorf("ExtractSummary() = %q", summary)
+	}
+}
+
+func TestTranscriptPosition_ExcludesPendingPrompt(t *testing.T) {
+	t.Parallel()
+
+	base := "# aider chat started at 2026-01-01 12:00:00\n\n> Aider v0.86.1  \n\n"
+	if got := transcriptPosition([]byte(base)); got != 4 {
+		t.Errorf("transcriptPosition() = %d, want 4", got)
+	}
+
+	withPrompt := base + "#### fix the bug  \n\n"
+	if got := transcriptPosition([]byte(withPrompt)); got != 4 {
+		t.Errorf("transcriptPosition() with unanswered prompt = %d, want 4", got)
+	}
+
+	answered := withPrompt + "Fixed.\n"
+	if got := transcriptPosition([]byte(answered)); got != 7 {
+		t.Errorf("transcriptPosition() after reply = %d, want 7", got)
+	}
+
+	if got := transcriptPosition(nil); got != 0 {
+		t.Errorf("transcriptPosition(nil) = %d, want 0", got)
+	}
+}
+
+func TestAiderAgent_CalculateTokenUsage(t *testing.T) {
+	t.Parallel()
+
+	ag := &AiderAgent{}
+	usage, err := ag.CalculateTokenUsage([]byte(sampleChatHistory), 0)
+	if err != nil {
+		t.Fatalf("CalculateTokenUsage() error = %v", err)
+	}
+	if usage.APICallCount != 2 {
+		t.Errorf("APICallCount = %d, want 2", usage.APICallCount)
+	}
+	if usage.InputTokens != 2300+1234 {
+		t.Errorf("InputTokens = %d, want %d", usage.InputTokens, 2300+1234)
+	}
+	if usage.OutputTokens != 120+95 {
+		t.Errorf("OutputTokens = %d, want %d", usage.OutputTokens, 120+95)
+	}
+	if usage.CacheCreationTokens != 1500 || usage.CacheReadTokens != 800 {
+		t.Errorf("cache tokens = %d write / %d read, want 1500 / 800", usage.CacheCreationTokens, usage.CacheReadTokens)
+	}
+
+	offset := ParseChatHistory([]byte(sampleChatHistory))[1].Line
+	scoped, err := ag.CalculateTokenUsage([]byte(sampleChatHistory), offset)
+	if err != nil {
+		t.Fatalf("CalculateTokenUsage() error = %v", err)
+	}
+	if scoped.APICallCount != 1 || scoped.OutputTokens != 95 {
+		t.Errorf("scoped usage = %+v, want only the second session", scoped)
+	}
+}
+
+func TestParseTokenCount(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		number, suffix string
+		want           int
+	}{
+		{"950", "", 950},
+		{"1,234", "", 1234},
+		{"2.3", "k", 2300},
+		{"1.1", "M", 1100000},
+	}
+	for _, tt := range tests {
+		got, ok := parseTokenCount(tt.number, tt.suffix)
+		if !ok || got != tt.want {
+			t.Errorf("parseTokenCount(%q, %q) = %d, %v; want %d", tt.number, tt.suffix, got, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package aider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+// FileWatcher interface implementation
+//
+// Aider writes its files in a fixed order, which is what the mapping relies on:
+//   - On startup it appends a "# aider chat started at ..." header to the chat history.
+//   - On submit it appends the prompt to the input history, then echoes it as "####"
+//     lines in the chat history.
+//   - While answering it appends the reply and its own "> " output to the chat history.
+//
+// Changes to the input history are therefore prompt submissions, a chat history that
+// ends in a bare session header is a session start, and any other chat history change
+// is turn activity. The watcher runtime treats a turn as ended once that activity
+// goes quiet.
+
+// GetWatchPaths returns the chat history and input history paths for the current repository.
+func (a *AiderAgent) GetWatchPaths() ([]string, error) {
+	repoRoot, err := paths.WorktreeRoot(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree root: %w", err)
+	}
+	return []string{
+		resolveHistoryPath(repoRoot, chatHistoryEnvVar, ChatHistoryFileName),
+		resolveHistoryPath(repoRoot, inputHistoryEnvVar, InputHistoryFileName),
+	}, nil
+}
+
+// OnFileChange maps a change to one of the watched files to session activity.
+// Returns nil if the change has no lifecycle significance (e.g., no session has
+// started yet, or the path is not an Aider history file).
+func (a *AiderAgent) OnFileChange(path string) (*agent.SessionChange, error) {
+	watchPaths, err := a.GetWatchPaths()
+	if err != nil {
+		return nil, err
+	}
+	chatPath, inputPath := watchPaths[0], watchPaths[1]
+
+	chatData, err := os.ReadFile(chatPath) //nolint:gosec // Path is derived from the repo root
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil //nolint:nilnil // No chat history means no session to attribute the change to
+		}
+		return nil, fmt.Errorf("failed to read chat history: %w", err)
+	}
+	sessions := ParseChatHistory(chatData)
+	if len(sessions) == 0 || sessions[len(sessions)-1].ID == "" {
+		return nil, nil //nolint:nilnil // No session header yet
+	}
+	current := sessions[len(sessions)-1]
+
+	change := &agent.SessionChange{
+		SessionID:  current.ID,
+		SessionRef: chatPath,
+		Timestamp:  time.Now(),
+	}
+
+	switch filepath.Clean(path) {
+	case filepath.Clean(inputPath):
+		inputData, err := os.ReadFile(inputPath) //nolint:gosec // Path is derived from the repo root
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input history: %w", err)
+		}
+		change.EventType = agent.HookUserPromptSubmit
+		change.Prompt = LastInputPrompt(inputData)
+	case filepath.Clean(chatPath):
+		if len(current.Messages) == 0 || onlyToolMessages(current.Messages) {
+			change.EventType = agent.HookSessionStart
+		} else {
+			change.EventType = agent.HookStop
+		}
+	default:
+		return nil, nil //nolint:nilnil // Not an Aider history file
+	}
+
+	return change, nil
+}
+
+// onlyToolMessages reports whether a session contains only Aider's startup output
+// (version banner, repo map notices) and no conversation yet.
+func onlyToolMessages(messages []Message) bool {
+	for _, m := range messages {
+		if m.Role != RoleTool {
+			return false
+		}
+	}
+	return true
+}
+
+// LastInputPrompt returns the most recent prompt from Aider's input history.
+// Entries are a "# <timestamp>" line followed by one "+"-prefixed line per prompt line.
+func LastInputPrompt(data []byte) string {
+	var last, current []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "# "):
+			if len(current) > 0 {
+				last = current
+			}
+			current = nil
+		case strings.HasPrefix(line, "+"):
+			current = append(current, strings.TrimPrefix(line, "+"))
+		}
+	}
+	if len(current) > 0 {
+		last = current
+	}
+	return strings.TrimSpace(strings.Join(last, "\n"))
+}
@@ -0,0 +1,107 @@
+package aider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestLastInputPrompt(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`
+# 2026-01-01 12:00:01.123456
++add a hello function
+
+# 2026-01-01 12:05:00.000000
++rename hello to greet
++and update the README
+`)
+	if got := LastInputPrompt(data); got != "rename hello to greet\nand update the README" {
+		t.Errorf("LastInputPrompt() = %q", got)
+	}
+	if got := LastInputPrompt(nil); got != "" {
+		t.Errorf("LastInputPrompt(nil) = %q, want empty", got)
+	}
+}
+
+func TestAiderAgent_GetWatchPaths(t *testing.T) {
+	setupAiderRepo(t)
+
+	watchPaths, err := (&AiderAgent{}).GetWatchPaths()
+	if err != nil {
+		t.Fatalf("GetWatchPaths() error = %v", err)
+	}
+	if len(watchPaths) != 2 {
+		t.Fatalf("expected 2 watch paths, got %v", watchPaths)
+	}
+	if filepath.Base(watchPaths[0]) != ChatHistoryFileName || filepath.Base(watchPaths[1]) != InputHistoryFileName {
+		t.Errorf("GetWatchPaths() = %v", watchPaths)
+	}
+}
+
+func TestAiderAgent_OnFileChange(t *testing.T) {
+	setupAiderRepo(t)
+	ag := &AiderAgent{}
+
+	watchPaths, err := ag.GetWatchPaths()
+	if err != nil {
+		t.Fatalf("GetWatchPaths() error = %v", err)
+	}
+	chatPath, inputPath := watchPaths[0], watchPaths[1]
+
+	// No chat history yet: nothing to attribute the change to
+	change, err := ag.OnFileChange(chatPath)
+	if err != nil || change != nil {
+		t.Fatalf("OnFileChange() before session = %+v, %v; want nil, nil", change, err)
+	}
+
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	// Session header with only startup output: session start
+	history := "# aider chat started at 2026-01-01 12:00:00\n\n> Aider v0.86.1  \n\n"
+	writeFile(chatPath, history)
+	change, err = ag.OnFileChange(chatPath)
+	if err != nil {
+		t.Fatalf("OnFileChange() error = %v", err)
+	}
+	if change == nil || change.EventType != agent.HookSessionStart || change.SessionID != "aider-20260101-120000" {
+		t.Fatalf("expected session start for aider-20260101-120000, got %+v", change)
+	}
+	if change.SessionRef != chatPath {
+		t.Errorf("SessionRef = %q, want %q", change.SessionRef, chatPath)
+	}
+
+	// Prompt submitted: input history change carries the prompt
+	writeFile(inputPath, "\n# 2026-01-01 12:00:05.000000\n+fix the bug\n")
+	change, err = ag.OnFileChange(inputPath)
+	if err != nil {
+		t.Fatalf("OnFileChange() error = %v", err)
+	}
+	if change == nil || change.EventType != agent.HookUserPromptSubmit || change.Prompt != "fix the bug" {
+		t.Fatalf("expected prompt submit with prompt, got %+v", change)
+	}
+
+	// Aider echoes the prompt and replies: turn activity
+	writeFile(chatPath, history+"#### fix the bug  \n\nFixed.\n")
+	change, err = ag.OnFileChange(chatPath)
+	if err != nil {
+		t.Fatalf("OnFileChange() error = %v", err)
+	}
+	if change == nil || change.EventType != agent.HookStop {
+		t.Fatalf("expected stop activity, got %+v", change)
+	}
+
+	// Unrelated path
+	change, err = ag.OnFileChange(filepath.Join(filepath.Dir(chatPath), "main.go"))
+	if err != nil || change != nil {
+		t.Errorf("OnFileChange() for unrelated path = %+v, %v; want nil, nil", change, err)
+	}
+}
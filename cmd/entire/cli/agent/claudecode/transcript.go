@@ -181,8 +181,8 @@ func FindCheckpointUUID(lines []TranscriptLine, toolUseID string) (string, bool)
 // Due to streaming, multiple transcript rows may share the same message.id.
 // We deduplicate by taking the row with the highest output_tokens for each message.id.
 func CalculateTokenUsage(transcript []TranscriptLine) *agent.TokenUsage {
-	// Map from message.id to the usage with highest output_tokens
-	usageByMessageID := make(map[string]messageUsage)
+	// Map from message.id to the message with highest output_tokens
+	usageByMessageID := make(map[string]messageWithUsage)
 
 	for _, line := range transcript {
 		if line.Type != "assistant" {
@@ -200,20 +200,28 @@ func CalculateTokenUsage(transcript []TranscriptLine) *agent.TokenUsage {
 
 		// Keep the entry with highest output_tokens (final streaming state)
 		existing, exists := usageByMessageID[msg.ID]
-		if !exists || msg.Usage.OutputTokens > existing.OutputTokens {
-			usageByMessageID[msg.ID] = msg.Usage
+		if !exists || msg.Usage.OutputTokens > existing.Usage.OutputTokens {
+			usageByMessageID[msg.ID] = msg
 		}
 	}
 
-	// Sum up all unique messages
+	// Sum up all unique messages, overall and per model
 	usage := &agent.TokenUsage{
 		APICallCount: len(usageByMessageID),
 	}
-	for _, u := range usageByMessageID {
+	for _, msg := range usageByMessageID {
+		u := msg.Usage
 		usage.InputTokens += u.InputTokens
 		usage.CacheCreationTokens += u.CacheCreationInputTokens
 		usage.CacheReadTokens += u.CacheReadInputTokens
 		usage.OutputTokens += u.OutputTokens
+		usage.Models = agent.AddModelUsage(usage.Models, msg.Model, agent.ModelUsage{
+			InputTokens:         u.InputTokens,
+			CacheCreationTokens: u.CacheCreationInputTokens,
+			CacheReadTokens:     u.CacheReadInputTokens,
+			OutputTokens:        u.OutputTokens,
+			APICallCount:        1,
+		})
 	}
 
 	return usage
@@ -361,6 +369,7 @@ func (c *ClaudeCodeAgent) CalculateTotalTokenUsage(transcriptData []byte, startL
 			subagentUsage.CacheReadTokens += agentUsage.CacheReadTokens
 			subagentUsage.OutputTokens += agentUsage.OutputTokens
 			subagentUsage.APICallCount += agentUsage.APICallCount
+			subagentUsage.Models = agent.MergeModelUsage(subagentUsage.Models, agentUsage.Models)
 		}
 		if subagentUsage.APICallCount > 0 {
 			mainUsage.SubagentTokens = subagentUsage
@@ -287,6 +287,44 @@ func TestCalculateTokenUsage_BasicMessages(t *testing.T) {
 	}
 }
 
+func TestCalculateTokenUsage_PerModel(t *testing.T) {
+	usageFor := func(id, model string, input, output int) TranscriptLine {
+		return TranscriptLine{
+			Type: "assistant",
+			Message: mustMarshal(t, map[string]interface{}{
+				"id":    id,
+				"model": model,
+				"usage": map[string]int{"input_tokens": input, "output_tokens": output, "cache_read_input_tokens": 7},
+			}),
+		}
+	}
+	transcript := []TranscriptLine{
+		usageFor("msg_001", "claude-opus-4-1", 10, 1),
+		usageFor("msg_001", "claude-opus-4-1", 10, 20), // streaming update of msg_001
+		usageFor("msg_002", "claude-haiku-4-5", 5, 3),
+		usageFor("msg_003", "claude-opus-4-1", 2, 4),
+		usageFor("msg_004", "", 1, 1), // no model recorded
+	}
+
+	usage := CalculateTokenUsage(transcript)
+
+	if len(usage.Models) != 2 {
+		t.Fatalf("Models = %+v, want 2 models", usage.Models)
+	}
+	opus := usage.Models["claude-opus-4-1"]
+	if opus == nil || opus.APICallCount != 2 || opus.InputTokens != 12 || opus.OutputTokens != 24 || opus.CacheReadTokens != 14 {
+		t.Errorf("opus usage = %+v", opus)
+	}
+	haiku := usage.Models["claude-haiku-4-5"]
+	if haiku == nil || haiku.APICallCount != 1 || haiku.InputTokens != 5 || haiku.OutputTokens != 3 {
+		t.Errorf("haiku usage = %+v", haiku)
+	}
+	// The overall counts still include the call without a model
+	if usage.APICallCount != 4 || usage.InputTokens != 18 {
+		t.Errorf("usage = %+v, want 4 calls and 18 input tokens", usage)
+	}
+}
+
 func TestCalculateTokenUsage_StreamingDeduplication(t *testing.T) {
 	// Simulate streaming: multiple rows with same message ID, increasing output_tokens
 	transcript := []TranscriptLine{
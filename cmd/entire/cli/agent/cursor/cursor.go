@@ -20,6 +20,12 @@ func init() {
 	agent.Register(agent.AgentNameCursor, NewCursorAgent)
 }
 
+// Compile-time interface checks.
+var (
+	_ agent.TranscriptAnalyzer = (*CursorAgent)(nil)
+	_ agent.TokenCalculator    = (*CursorAgent)(nil)
+)
+
 // CursorAgent implements the Agent interface for Cursor.
 //
 //nolint:revive // CursorAgent is clearer than Agent in this context
@@ -103,8 +109,7 @@ func (c *CursorAgent) GetSessionDir(repoPath string) (string, error) {
 }
 
 // ReadSession reads a session from Cursor's storage (JSONL transcript file).
-// Note: ModifiedFiles is left empty because Cursor's transcript format does not
-// contain tool_use blocks. File detection relies on git status instead.
+// Note: ModifiedFiles is left empty; use ExtractModifiedFilesFromOffset instead.
 func (c *CursorAgent) ReadSession(input *agent.HookInput) (*agent.AgentSession, error) {
 	if input.SessionRef == "" {
 		return nil, errors.New("session reference (transcript path) is required")
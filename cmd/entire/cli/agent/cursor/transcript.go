@@ -0,0 +1,287 @@
+package cursor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/transcript"
+)
+
+// Cursor transcripts are role-based JSONL: one {"role": ..., "message": {...}} object
+// per line. The shared transcript parser normalizes "role" into Line.Type, so positions
+// are line counts and offsets are line numbers, exactly as for Claude Code.
+
+// Tool names Cursor uses for file-modifying tool calls.
+const (
+	ToolWrite        = "Write"
+	ToolStrReplace   = "StrReplace"
+	ToolEdit         = "Edit"
+	ToolMultiEdit    = "MultiEdit"
+	ToolDelete       = "Delete"
+	ToolEditNotebook = "EditNotebook"
+)
+
+// FileModificationTools lists tools that create, modify or delete files.
+var FileModificationTools = []string{
+	ToolWrite,
+	ToolStrReplace,
+	ToolEdit,
+	ToolMultiEdit,
+	ToolDelete,
+	ToolEditNotebook,
+}
+
+// toolInput holds the path fields found in Cursor tool calls.
+// Different tools (and Cursor versions) name the target path differently.
+type toolInput struct {
+	Path           string `json:"path,omitempty"`
+	FilePath       string `json:"file_path,omitempty"`
+	TargetFile     string `json:"target_file,omitempty"`
+	TargetNotebook string `json:"target_notebook,omitempty"`
+}
+
+// file returns the first non-empty path field.
+func (i toolInput) file() string {
+	for _, p := range []string{i.Path, i.FilePath, i.TargetFile, i.TargetNotebook} {
+		if p != "" {
+			return p
+		}
+	}
+	return ""
+}
+
+// messageUsage represents token usage reported on an assistant message.
+type messageUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+}
+
+// messageWithUsage represents an assistant message that may carry usage data.
+type messageWithUsage struct {
+	ID    string        `json:"id"`
+	Usage *messageUsage `json:"usage"`
+}
+
+// GetTranscriptPosition returns the current line count of a Cursor transcript.
+// Returns 0 if the file doesn't exist or is empty.
+func (c *CursorAgent) GetTranscriptPosition(path string) (int, error) {
+	if path == "" {
+		return 0, nil
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // Path comes from Cursor transcript location
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return countLines(data), nil
+}
+
+// ExtractModifiedFilesFromOffset extracts files modified since a given line number.
+// Returns:
+//   - files: list of file paths modified by Cursor's file tools
+//   - currentPosition: total number of lines in the file
+//   - error: any error encountered during reading
+func (c *CursorAgent) ExtractModifiedFilesFromOffset(path string, startOffset int) (files []string, currentPosition int, err error) {
+	if path == "" {
+		return nil, 0, nil
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // Path comes from Cursor transcript location
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	lines, err := transcript.ParseFromBytes(transcript.SliceFromLine(data, startOffset))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse transcript: %w", err)
+	}
+
+	return ExtractModifiedFiles(lines), countLines(data), nil
+}
+
+// ExtractPrompts extracts user prompts from the transcript starting at the given line offset.
+// Cursor's <user_query> wrapper tags are stripped, keeping the prompt text.
+func (c *CursorAgent) ExtractPrompts(sessionRef string, fromOffset int) ([]string, error) {
+	lines, err := transcript.ParseFromFileAtLine(sessionRef, fromOffset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transcript: %w", err)
+	}
+
+	var prompts []string
+	for i := range lines {
+		if lines[i].Type != transcript.TypeUser {
+			continue
+		}
+		if content := transcript.ExtractUserContent(lines[i].Message); content != "" {
+			prompts = append(prompts, content)
+		}
+	}
+	return prompts, nil
+}
+
+// ExtractSummary extracts the last assistant text as a session summary.
+func (c *CursorAgent) ExtractSummary(sessionRef string) (string, error) {
+	data, err := os.ReadFile(sessionRef) //nolint:gosec // Path comes from agent hook input
+	if err != nil {
+		return "", fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	lines, err := transcript.ParseFromBytes(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse transcript: %w", err)
+	}
+
+	return ExtractLastAssistantMessage(lines), nil
+}
+
+// CalculateTokenUsage computes token usage from the transcript starting at the given line offset.
+// Returns nil if the transcript carries no usage data, so checkpoints record
+// "unknown" rather than zero tokens.
+func (c *CursorAgent) CalculateTokenUsage(transcriptData []byte, fromOffset int) (*agent.TokenUsage, error) {
+	if len(transcriptData) == 0 {
+		return nil, nil //nolint:nilnil // nil usage = no data available
+	}
+
+	lines, err := transcript.ParseFromBytes(transcript.SliceFromLine(transcriptData, fromOffset))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transcript: %w", err)
+	}
+	return CalculateTokenUsage(lines), nil
+}
+
+// ExtractModifiedFiles extracts files modified by tool calls in Cursor transcript lines.
+// Older Cursor transcripts contain only text blocks; they yield no files and the
+// framework falls back to git status detection.
+func ExtractModifiedFiles(lines []transcript.Line) []string {
+	fileSet := make(map[string]bool)
+	var files []string
+
+	for _, line := range lines {
+		if line.Type != transcript.TypeAssistant {
+			continue
+		}
+
+		var msg transcript.AssistantMessage
+		if err := json.Unmarshal(line.Message, &msg); err != nil {
+			continue
+		}
+
+		for _, block := range msg.Content {
+			if block.Type != transcript.ContentTypeToolUse || !isFileModificationTool(block.Name) {
+				continue
+			}
+
+			var input toolInput
+			if err := json.Unmarshal(block.Input, &input); err != nil {
+				continue
+			}
+
+			if file := input.file(); file != "" && !fileSet[file] {
+				fileSet[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+
+	return files
+}
+
+// ExtractLastAssistantMessage returns the text of the last assistant message
+// that contains text, or "" if there is none.
+func ExtractLastAssistantMessage(lines []transcript.Line) string {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if lines[i].Type != transcript.TypeAssistant {
+			continue
+		}
+		var msg transcript.AssistantMessage
+		if err := json.Unmarshal(lines[i].Message, &msg); err != nil {
+			continue
+		}
+		for j := len(msg.Content) - 1; j >= 0; j-- {
+			if msg.Content[j].Type == transcript.ContentTypeText && msg.Content[j].Text != "" {
+				return msg.Content[j].Text
+			}
+		}
+	}
+	return ""
+}
+
+// CalculateTokenUsage sums the usage reported on assistant messages.
+// Lines sharing a message ID are streaming updates of the same response, so only
+// the one with the highest output_tokens is counted. Lines without an ID are
+// counted individually. Returns nil if no assistant message reports usage.
+func CalculateTokenUsage(lines []transcript.Line) *agent.TokenUsage {
+	usageByMessageID := make(map[string]messageUsage)
+	var anonymous []messageUsage
+
+	for _, line := range lines {
+		if line.Type != transcript.TypeAssistant {
+			continue
+		}
+
+		var msg messageWithUsage
+		if err := json.Unmarshal(line.Message, &msg); err != nil || msg.Usage == nil {
+			continue
+		}
+
+		if msg.ID == "" {
+			anonymous = append(anonymous, *msg.Usage)
+			continue
+		}
+		existing, exists := usageByMessageID[msg.ID]
+		if !exists || msg.Usage.OutputTokens > existing.OutputTokens {
+			usageByMessageID[msg.ID] = *msg.Usage
+		}
+	}
+
+	if len(usageByMessageID) == 0 && len(anonymous) == 0 {
+		return nil
+	}
+
+	usage := &agent.TokenUsage{
+		APICallCount: len(usageByMessageID) + len(anonymous),
+	}
+	add := func(u messageUsage) {
+		usage.InputTokens += u.InputTokens
+		usage.CacheCreationTokens += u.CacheCreationInputTokens
+		usage.CacheReadTokens += u.CacheReadInputTokens
+		usage.OutputTokens += u.OutputTokens
+	}
+	for _, u := range usageByMessageID {
+		add(u)
+	}
+	for _, u := range anonymous {
+		add(u)
+	}
+
+	return usage
+}
+
+func isFileModificationTool(name string) bool {
+	for _, tool := range FileModificationTools {
+		if name == tool {
+			return true
+		}
+	}
+	return false
+}
+
+// countLines counts JSONL lines, including a final line without a trailing newline.
+func countLines(data []byte) int {
+	if len(data) == 0 {
+		return 0
+	}
+	n := bytes.Count(data, []byte{'\n'})
+	if data[len(data)-1] != '\n' {
+		n++
+	}
+	return n
+}
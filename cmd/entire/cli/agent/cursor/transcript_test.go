@@ -0,0 +1,184 @@
+package cursor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// toolTranscriptLines returns a Cursor transcript with file tool calls and usage data.
+func toolTranscriptLines() []string {
+	return []string{
+		`{"role":"user","message":{"content":[{"type":"text","text":"<user_query>\ncreate a greeting\n</user_query>"}]}}`,
+		`{"role":"assistant","message":{"id":"m1","usage":{"input_tokens":100,"output_tokens":5},"content":[{"type":"text","text":"Creating it."},{"type":"tool_use","name":"Write","input":{"path":"/repo/hello.txt","contents":"hi"}}]}}`,
+		`{"role":"assistant","message":{"id":"m1","usage":{"input_tokens":100,"cache_read_input_tokens":40,"output_tokens":20},"content":[{"type":"tool_use","name":"StrReplace","input":{"path":"/repo/main.go"}}]}}`,
+		`{"role":"user","message":{"content":[{"type":"text","text":"<user_query>\nremove the old file\n</user_query>"}]}}`,
+		`{"role":"assistant","message":{"usage":{"input_tokens":50,"cache_creation_input_tokens":10,"output_tokens":7},"content":[{"type":"tool_use","name":"Read","input":{"path":"/repo/old.txt"}},{"type":"tool_use","name":"Delete","input":{"target_file":"/repo/old.txt"}},{"type":"tool_use","name":"Write","input":{"path":"/repo/hello.txt"}},{"type":"text","text":"Removed old.txt."}]}}`,
+	}
+}
+
+func writeTranscript(t *testing.T, lines []string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatalf("failed to write transcript: %v", err)
+	}
+	return path
+}
+
+func TestCursorAgent_GetTranscriptPosition(t *testing.T) {
+	t.Parallel()
+	ag := &CursorAgent{}
+
+	path := writeSampleTranscript(t, t.TempDir())
+	pos, err := ag.GetTranscriptPosition(path)
+	if err != nil {
+		t.Fatalf("GetTranscriptPosition() error = %v", err)
+	}
+	if pos != 4 {
+		t.Errorf("GetTranscriptPosition() = %d, want 4", pos)
+	}
+
+	// Final line without trailing newline still counts
+	noNewline := filepath.Join(t.TempDir(), "partial.jsonl")
+	if err := os.WriteFile(noNewline, []byte("{}\n{}"), 0o644); err != nil {
+		t.Fatalf("failed to write transcript: %v", err)
+	}
+	if pos, err := ag.GetTranscriptPosition(noNewline); err != nil || pos != 2 {
+		t.Errorf("GetTranscriptPosition(no trailing newline) = %d, %v; want 2, nil", pos, err)
+	}
+
+	if pos, err := ag.GetTranscriptPosition(filepath.Join(t.TempDir(), "missing.jsonl")); err != nil || pos != 0 {
+		t.Errorf("GetTranscriptPosition(missing) = %d, %v; want 0, nil", pos, err)
+	}
+}
+
+func TestCursorAgent_ExtractModifiedFilesFromOffset(t *testing.T) {
+	t.Parallel()
+	ag := &CursorAgent{}
+	path := writeTranscript(t, toolTranscriptLines())
+
+	files, pos, err := ag.ExtractModifiedFilesFromOffset(path, 0)
+	if err != nil {
+		t.Fatalf("ExtractModifiedFilesFromOffset() error = %v", err)
+	}
+	if pos != 5 {
+		t.Errorf("position = %d, want 5", pos)
+	}
+	want := "/repo/hello.txt,/repo/main.go,/repo/old.txt"
+	if got := strings.Join(files, ","); got != want {
+		t.Errorf("files = %s, want %s", got, want)
+	}
+
+	// Offset skips earlier turns; Read is not a modification tool
+	files, _, err = ag.ExtractModifiedFilesFromOffset(path, 3)
+	if err != nil {
+		t.Fatalf("ExtractModifiedFilesFromOffset() error = %v", err)
+	}
+	if got := strings.Join(files, ","); got != "/repo/old.txt,/repo/hello.txt" {
+		t.Errorf("files from offset 3 = %s, want /repo/old.txt,/repo/hello.txt", got)
+	}
+}
+
+func TestCursorAgent_ExtractModifiedFilesFromOffset_TextOnly(t *testing.T) {
+	t.Parallel()
+	ag := &CursorAgent{}
+	path := writeSampleTranscript(t, t.TempDir())
+
+	files, pos, err := ag.ExtractModifiedFilesFromOffset(path, 0)
+	if err != nil {
+		t.Fatalf("ExtractModifiedFilesFromOffset() error = %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("expected no files from a text-only transcript, got %v", files)
+	}
+	if pos != 4 {
+		t.Errorf("position = %d, want 4", pos)
+	}
+}
+
+func TestCursorAgent_ExtractPrompts(t *testing.T) {
+	t.Parallel()
+	ag := &CursorAgent{}
+	path := writeSampleTranscript(t, t.TempDir())
+
+	prompts, err := ag.ExtractPrompts(path, 0)
+	if err != nil {
+		t.Fatalf("ExtractPrompts() error = %v", err)
+	}
+	if len(prompts) != 2 || prompts[0] != "hello" || prompts[1] != "add 'one' to a file and commit" {
+		t.Errorf("ExtractPrompts() = %q, want [hello, add 'one' to a file and commit]", prompts)
+	}
+
+	prompts, err = ag.ExtractPrompts(path, 2)
+	if err != nil {
+		t.Fatalf("ExtractPrompts() error = %v", err)
+	}
+	if len(prompts) != 1 || prompts[0] != "add 'one' to a file and commit" {
+		t.Errorf("ExtractPrompts(offset 2) = %q", prompts)
+	}
+}
+
+func TestCursorAgent_ExtractSummary(t *testing.T) {
+	t.Parallel()
+	ag := &CursorAgent{}
+
+	summary, err := ag.ExtractSummary(writeSampleTranscript(t, t.TempDir()))
+	if err != nil {
+		t.Fatalf("ExtractSummary() error = %v", err)
+	}
+	if summary != "Created one.txt with one and committed." {
+		t.Errorf("ExtractSummary() = %q", summary)
+	}
+
+	summary, err = ag.ExtractSummary(writeTranscript(t, toolTranscriptLines()))
+	if err != nil {
+		t.Fatalf("ExtractSummary() error = %v", err)
+	}
+	if summary != "Removed old.txt." {
+		t.Errorf("ExtractSummary() with tool calls = %q, want %q", summary, "Removed old.txt.")
+	}
+
+	if _, err := ag.ExtractSummary(filepath.Join(t.TempDir(), "missing.jsonl")); err == nil {
+		t.Error("expected error for missing transcript")
+	}
+}
+
+func TestCursorAgent_CalculateTokenUsage(t *testing.T) {
+	t.Parallel()
+	ag := &CursorAgent{}
+	data := []byte(strings.Join(toolTranscriptLines(), "\n") + "\n")
+
+	usage, err := ag.CalculateTokenUsage(data, 0)
+	if err != nil {
+		t.Fatalf("CalculateTokenUsage() error = %v", err)
+	}
+	// m1 is deduplicated to its final streaming state; the ID-less message counts once
+	if usage.APICallCount != 2 {
+		t.Errorf("APICallCount = %d, want 2", usage.APICallCount)
+	}
+	if usage.InputTokens != 150 || usage.OutputTokens != 27 {
+		t.Errorf("input/output = %d/%d, want 150/27", usage.InputTokens, usage.OutputTokens)
+	}
+	if usage.CacheReadTokens != 40 || usage.CacheCreationTokens != 10 {
+		t.Errorf("cache read/creation = %d/%d, want 40/10", usage.CacheReadTokens, usage.CacheCreationTokens)
+	}
+
+	usage, err = ag.CalculateTokenUsage(data, 3)
+	if err != nil {
+		t.Fatalf("CalculateTokenUsage() error = %v", err)
+	}
+	if usage.APICallCount != 1 || usage.InputTokens != 50 {
+		t.Errorf("usage from offset 3 = %+v, want 1 call with 50 input tokens", usage)
+	}
+
+	// Transcripts without usage data yield nil ("no data") rather than zero usage
+	usage, err = ag.CalculateTokenUsage([]byte(strings.Join(sampleTranscriptLines(), "\n")), 0)
+	if err != nil {
+		t.Fatalf("CalculateTokenUsage() error = %v", err)
+	}
+	if usage != nil {
+		t.Errorf("expected nil usage, got %+v", usage)
+	}
+}
@@ -129,6 +129,12 @@ func (g *GeminiCLIAgent) CalculateTokenUsage(transcriptData []byte, fromOffset i
 		usage.InputTokens += msg.Tokens.Input
 		usage.OutputTokens += msg.Tokens.Output
 		usage.CacheReadTokens += msg.Tokens.Cached
+		usage.Models = agent.AddModelUsage(usage.Models, msg.Model, agent.ModelUsage{
+			InputTokens:     msg.Tokens.Input,
+			CacheReadTokens: msg.Tokens.Cached,
+			OutputTokens:    msg.Tokens.Output,
+			APICallCount:    1,
+		})
 	}
 
 	return usage, nil
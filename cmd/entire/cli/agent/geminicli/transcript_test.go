@@ -643,6 +643,34 @@ func TestCalculateTokenUsage_BasicMessages(t *testing.T) {
 	}
 }
 
+func TestCalculateTokenUsage_PerModel(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{
+  "messages": [
+    {"id": "1", "type": "user", "content": "hello"},
+    {"id": "2", "type": "gemini", "model": "gemini-2.5-pro", "content": "hi", "tokens": {"input": 10, "output": 20, "cached": 5}},
+    {"id": "3", "type": "gemini", "model": "gemini-2.5-flash", "content": "hm", "tokens": {"input": 4, "output": 2, "cached": 0}},
+    {"id": "4", "type": "gemini", "model": "gemini-2.5-pro", "content": "ok", "tokens": {"input": 1, "output": 1, "cached": 1}}
+  ]
+}`)
+
+	ag := &GeminiCLIAgent{}
+	usage, err := ag.CalculateTokenUsage(data, 0)
+	if err != nil {
+		t.Fatalf("CalculateTokenUsage error: %v", err)
+	}
+
+	pro := usage.Models["gemini-2.5-pro"]
+	if pro == nil || pro.APICallCount != 2 || pro.InputTokens != 11 || pro.OutputTokens != 21 || pro.CacheReadTokens != 6 {
+		t.Errorf("gemini-2.5-pro usage = %+v", pro)
+	}
+	flash := usage.Models["gemini-2.5-flash"]
+	if flash == nil || flash.APICallCount != 1 || flash.InputTokens != 4 {
+		t.Errorf("gemini-2.5-flash usage = %+v", flash)
+	}
+}
+
 func TestCalculateTokenUsage_StartIndex(t *testing.T) {
 	t.Parallel()
 
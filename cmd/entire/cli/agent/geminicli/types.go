@@ -98,5 +98,6 @@ type geminiMessageTokens struct {
 type geminiMessageWithTokens struct {
 	ID     string               `json:"id"`
 	Type   string               `json:"type"`
+	Model  string               `json:"model,omitempty"`
 	Tokens *geminiMessageTokens `json:"tokens,omitempty"`
 }
@@ -309,6 +309,13 @@ func (a *OpenCodeAgent) CalculateTokenUsage(transcriptData []byte, fromOffset in
 		usage.CacheReadTokens += msg.Info.Tokens.Cache.Read
 		usage.CacheCreationTokens += msg.Info.Tokens.Cache.Write
 		usage.APICallCount++
+		usage.Models = agent.AddModelUsage(usage.Models, msg.Info.ModelID, agent.ModelUsage{
+			InputTokens:         msg.Info.Tokens.Input,
+			CacheCreationTokens: msg.Info.Tokens.Cache.Write,
+			CacheReadTokens:     msg.Info.Tokens.Cache.Read,
+			OutputTokens:        msg.Info.Tokens.Output,
+			APICallCount:        1,
+		})
 	}
 
 	return usage, nil
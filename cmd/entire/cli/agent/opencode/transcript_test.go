@@ -23,7 +23,7 @@ var testExportJSON = func() string {
 			},
 			{
 				Info: MessageInfo{
-					ID: "msg-2", Role: "assistant",
+					ID: "msg-2", Role: "assistant", ModelID: "claude-sonnet-4-20250514",
 					Time:   Time{Created: 1708300001, Completed: 1708300005},
 					Tokens: &Tokens{Input: 150, Output: 80, Reasoning: 10, Cache: Cache{Read: 5, Write: 15}},
 					Cost:   0.003,
@@ -41,7 +41,7 @@ var testExportJSON = func() string {
 			},
 			{
 				Info: MessageInfo{
-					ID: "msg-4", Role: "assistant",
+					ID: "msg-4", Role: "assistant", ModelID: "gpt-5",
 					Time:   Time{Created: 1708300011, Completed: 1708300015},
 					Tokens: &Tokens{Input: 200, Output: 100, Reasoning: 5, Cache: Cache{Read: 10, Write: 20}},
 					Cost:   0.005,
@@ -289,7 +289,7 @@ var testApplyPatchExportJSON = func() string {
 			},
 			{
 				Info: MessageInfo{
-					ID: "msg-2", Role: "assistant",
+					ID: "msg-2", Role: "assistant", ModelID: "claude-sonnet-4-20250514",
 					Time:   Time{Created: 1708300001, Completed: 1708300005},
 					Tokens: &Tokens{Input: 200, Output: 100, Cache: Cache{}},
 				},
@@ -318,7 +318,7 @@ var testApplyPatchExportJSON = func() string {
 			},
 			{
 				Info: MessageInfo{
-					ID: "msg-4", Role: "assistant",
+					ID: "msg-4", Role: "assistant", ModelID: "gpt-5",
 					Time:   Time{Created: 1708300011, Completed: 1708300015},
 					Tokens: &Tokens{Input: 250, Output: 120, Cache: Cache{}},
 				},
@@ -551,6 +551,14 @@ func TestCalculateTokenUsage(t *testing.T) {
 	if usage.APICallCount != 2 {
 		t.Errorf("expected 2 API calls, got %d", usage.APICallCount)
 	}
+	sonnet := usage.Models["claude-sonnet-4-20250514"]
+	if sonnet == nil || sonnet.InputTokens != 150 || sonnet.CacheCreationTokens != 15 || sonnet.APICallCount != 1 {
+		t.Errorf("unexpected claude-sonnet-4 usage: %+v", sonnet)
+	}
+	gpt := usage.Models["gpt-5"]
+	if gpt == nil || gpt.OutputTokens != 100 || gpt.CacheReadTokens != 10 {
+		t.Errorf("unexpected gpt-5 usage: %+v", gpt)
+	}
 }
 
 func TestCalculateTokenUsage_FromOffset(t *testing.T) {
@@ -42,6 +42,7 @@ type MessageInfo struct {
 	SessionID string  `json:"sessionID,omitempty"`
 	Role      string  `json:"role"` // "user" or "assistant"
 	Time      Time    `json:"time"`
+	ModelID   string  `json:"modelID,omitempty"`
 	Tokens    *Tokens `json:"tokens,omitempty"`
 	Cost      float64 `json:"cost,omitempty"`
 }
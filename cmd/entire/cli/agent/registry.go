@@ -104,6 +104,7 @@ type AgentType string
 
 // Agent name constants (registry keys)
 const (
+	AgentNameAider      AgentName = "aider"
 	AgentNameClaudeCode AgentName = "claude-code"
 	AgentNameCursor     AgentName = "cursor"
 	AgentNameGemini     AgentName = "gemini"
@@ -112,6 +113,7 @@ const (
 
 // Agent type constants (type identifiers stored in metadata/trailers)
 const (
+	AgentTypeAider      AgentType = "Aider"
 	AgentTypeClaudeCode AgentType = "Claude Code"
 	AgentTypeCursor     AgentType = "Cursor"
 	AgentTypeGemini     AgentType = "Gemini CLI"
@@ -29,3 +29,36 @@ func CalculateTokenUsage(ag Agent, transcriptData []byte, transcriptLinesAtStart
 	}
 	return tokenUsage
 }
+
+// AddModelUsage adds one API call's usage to the per-model breakdown and returns it.
+// Calls without a model name are not recorded.
+func AddModelUsage(models map[string]*ModelUsage, model string, u ModelUsage) map[string]*ModelUsage {
+	if model == "" {
+		return models
+	}
+	if models == nil {
+		models = make(map[string]*ModelUsage)
+	}
+	existing, ok := models[model]
+	if !ok {
+		existing = &ModelUsage{}
+		models[model] = existing
+	}
+	existing.InputTokens += u.InputTokens
+	existing.CacheCreationTokens += u.CacheCreationTokens
+	existing.CacheReadTokens += u.CacheReadTokens
+	existing.OutputTokens += u.OutputTokens
+	existing.APICallCount += u.APICallCount
+	return models
+}
+
+// MergeModelUsage returns dst with every model in src added to it.
+// dst may be nil; src is never modified.
+func MergeModelUsage(dst, src map[string]*ModelUsage) map[string]*ModelUsage {
+	for model, u := range src {
+		if u != nil {
+			dst = AddModelUsage(dst, model, *u)
+		}
+	}
+	return dst
+}
@@ -41,6 +41,9 @@ type SessionChange struct {
 	SessionRef string
 	EventType  HookType
 	Timestamp  time.Time
+
+	// Prompt is the user's prompt text (populated for HookUserPromptSubmit changes)
+	Prompt string
 }
 
 // TokenUsage represents aggregated token usage for a checkpoint.
@@ -58,4 +61,16 @@ type TokenUsage struct {
 	APICallCount int `json:"api_call_count"`
 	// SubagentTokens contains token usage from spawned subagents (if any)
 	SubagentTokens *TokenUsage `json:"subagent_tokens,omitempty"`
+	// Models breaks the counts above down by the model that served each API call,
+	// keyed by model name. Empty when the agent's transcript doesn't record models.
+	Models map[string]*ModelUsage `json:"models,omitempty"`
+}
+
+// ModelUsage is the token usage of a single model.
+type ModelUsage struct {
+	InputTokens         int `json:"input_tokens"`
+	CacheCreationTokens int `json:"cache_creation_tokens"`
+	CacheReadTokens     int `json:"cache_read_tokens"`
+	OutputTokens        int `json:"output_tokens"`
+	APICallCount        int `json:"api_call_count"`
 }
@@ -0,0 +1,525 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/stringutil"
+	"github.com/entireio/cli/cmd/entire/cli/summarize"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/spf13/cobra"
+)
+
+// Line attributions reported by 'entire blame'.
+const (
+	// blameAgent is a line the agent wrote, in a commit linked to a checkpoint.
+	blameAgent = "agent"
+	// blameHumanEdited is a line in a commit linked to a checkpoint that the agent didn't write.
+	blameHumanEdited = "human-edited"
+	// blameHuman is a line from a commit without a checkpoint.
+	blameHuman = "human"
+	// blameUncommitted is a line not yet committed that the agent didn't write.
+	blameUncommitted = "uncommitted"
+)
+
+// blameLine is the attribution of one line of the blamed file.
+type blameLine struct {
+	Line         int             `json:"line"`
+	Commit       string          `json:"commit"`
+	Attribution  string          `json:"attribution"`
+	Agent        agent.AgentType `json:"agent,omitempty"`
+	CheckpointID string          `json:"checkpoint_id,omitempty"`
+	SessionID    string          `json:"session_id,omitempty"`
+	Prompt       string          `json:"prompt,omitempty"` // The prompt in response to which the agent wrote the line
+	Content      string          `json:"content"`
+}
+
+// blameReport is the output of 'entire blame'.
+type blameReport struct {
+	File  string      `json:"file"`
+	Lines []blameLine `json:"lines"`
+}
+
+// blameOrigin is where an agent-written line came from.
+type blameOrigin struct {
+	Agent     agent.AgentType
+	SessionID string
+	Prompt    string
+}
+
+func newBlameCmd() *cobra.Command {
+	var porcelainFlag bool
+	var jsonFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "blame <file>",
+		Short: "Show which lines of a file an agent wrote, and in response to which prompt",
+		Long: `Show line-level agent attribution for a file.
+
+Each line is blamed with git blame, and its commit is resolved to a checkpoint
+through the Entire-Checkpoint trailer. The line is then matched against the
+lines the agent added to the file during that checkpoint's sessions (its edits
+and writes in the transcript, and the shadow branch snapshot if it still exists).
+Each added line matches one blamed line, and blank or punctuation-only lines
+only match right after the agent's other lines:
+
+  agent         the agent wrote the line
+  human-edited  the commit has a checkpoint, but the agent didn't write the line
+  human         the commit has no checkpoint
+  uncommitted   the line is not committed yet (and not in the current shadow branch)
+
+Agent lines show the agent, the checkpoint ID and the prompt that produced them.
+
+Use --porcelain or --json for editor integrations.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkDisabledGuard(cmd.Context(), cmd.OutOrStdout()) {
+				return nil
+			}
+			return runBlame(cmd.Context(), cmd.OutOrStdout(), args[0], porcelainFlag, jsonFlag)
+		},
+	}
+
+	cmd.Flags().BoolVar(&porcelainFlag, "porcelain", false, "Machine-readable output, one record per line")
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output as JSON")
+	cmd.MarkFlagsMutuallyExclusive("porcelain", "json")
+
+	return cmd
+}
+
+func runBlame(ctx context.Context, w io.Writer, file string, porcelain, jsonOutput bool) error {
+	report, err := buildBlameReport(ctx, file)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case jsonOutput:
+		data, err := jsonutil.MarshalIndentWithNewline(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal blame: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to write blame: %w", err)
+		}
+	case porcelain:
+		fmt.Fprint(w, formatBlamePorcelain(report))
+	default:
+		fmt.Fprint(w, formatBlameText(report))
+	}
+	return nil
+}
+
+// buildBlameReport blames file and attributes each line.
+func buildBlameReport(ctx context.Context, file string) (*blameReport, error) {
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("not a git repository: %w", err)
+	}
+	repoRoot, err := paths.WorktreeRoot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository root: %w", err)
+	}
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", file, err)
+	}
+	// The worktree root has symlinks resolved (e.g., /private/var on macOS)
+	if resolved, evalErr := filepath.EvalSymlinks(absFile); evalErr == nil {
+		absFile = resolved
+	}
+	relFile, err := filepath.Rel(repoRoot, absFile)
+	if err != nil || strings.HasPrefix(relFile, "..") {
+		return nil, fmt.Errorf("%s is outside the repository", file)
+	}
+	relFile = filepath.ToSlash(relFile)
+
+	gitLines, err := gitBlame(ctx, repoRoot, relFile)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := &blameResolver{
+		repo:    repo,
+		store:   checkpoint.NewGitStore(repo),
+		file:    relFile,
+		commits: make(map[string]*blameCommitInfo),
+	}
+	if worktreeID, wtErr := paths.GetWorktreeID(repoRoot); wtErr == nil {
+		resolver.worktreeID = worktreeID
+	}
+
+	report := &blameReport{File: relFile, Lines: make([]blameLine, 0, len(gitLines))}
+	for _, gl := range gitLines {
+		report.Lines = append(report.Lines, resolver.attribute(ctx, gl))
+	}
+	return report, nil
+}
+
+// gitBlameLine is one line of git blame output.
+type gitBlameLine struct {
+	SHA     string
+	Line    int
+	Content string
+}
+
+// gitBlame runs git blame --porcelain on a repository-relative path.
+func gitBlame(ctx context.Context, repoRoot, relFile string) ([]gitBlameLine, error) {
+	cmd := exec.CommandContext(ctx, "git", "blame", "--porcelain", "--", relFile)
+	cmd.Dir = repoRoot
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git blame failed: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+	return parseGitBlamePorcelain(output)
+}
+
+// parseGitBlamePorcelain parses `git blame --porcelain` output.
+// Each line is a header "<sha> <orig-line> <final-line> [<group-size>]",
+// optional commit info lines (only the first time a commit appears),
+// and the content prefixed with a tab.
+func parseGitBlamePorcelain(output []byte) ([]gitBlameLine, error) {
+	var lines []gitBlameLine
+	var current *gitBlameLine
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		if content, ok := strings.CutPrefix(text, "\t"); ok {
+			if current == nil {
+				return nil, errors.New("failed to parse git blame output: content before header")
+			}
+			current.Content = content
+			lines = append(lines, *current)
+			current = nil
+			continue
+		}
+		fields := strings.Fields(text)
+		if current == nil && len(fields) >= 3 && isHexSHA(fields[0]) {
+			lineNum, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse git blame output: %w", err)
+			}
+			current = &gitBlameLine{SHA: fields[0], Line: lineNum}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read git blame output: %w", err)
+	}
+	return lines, nil
+}
+
+func isHexSHA(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// blameCommitInfo caches the checkpoint and agent-written lines for one blamed commit.
+type blameCommitInfo struct {
+	CheckpointID id.CheckpointID
+	// Origins maps each line the agent added to the file (trimmed) to where it
+	// came from, one entry per copy of the line. Attributing a line consumes an entry.
+	Origins map[string][]blameOrigin
+}
+
+// blameResolver attributes blamed lines, caching per-commit lookups.
+type blameResolver struct {
+	repo       *git.Repository
+	store      *checkpoint.GitStore
+	worktreeID string
+	file       string
+	commits    map[string]*blameCommitInfo
+	prev       blameLine // The previously attributed line
+}
+
+// attribute attributes one blamed line. Lines must be attributed in file order.
+func (r *blameResolver) attribute(ctx context.Context, gl gitBlameLine) blameLine {
+	line := blameLine{Line: gl.Line, Commit: gl.SHA, Content: gl.Content}
+
+	info := r.commitInfo(ctx, gl.SHA)
+	key := strings.TrimSpace(gl.Content)
+	origins := info.Origins[key]
+	agentWrote := len(origins) > 0
+	if agentWrote && isTrivialBlameLine(key) {
+		// Blank lines and lone braces say nothing about who wrote them, so they
+		// only go to the agent when they continue the agent's lines in this commit.
+		agentWrote = r.prev.Commit == gl.SHA && r.prev.Attribution == blameAgent
+	}
+	switch {
+	case agentWrote:
+		info.Origins[key] = origins[1:]
+		line.Attribution = blameAgent
+		line.Agent = origins[0].Agent
+		line.SessionID = origins[0].SessionID
+		line.Prompt = origins[0].Prompt
+	case isUncommittedSHA(gl.SHA):
+		line.Attribution = blameUncommitted
+	case info.CheckpointID.IsEmpty():
+		line.Attribution = blameHuman
+	default:
+		line.Attribution = blameHumanEdited
+	}
+	if !info.CheckpointID.IsEmpty() {
+		line.CheckpointID = info.CheckpointID.String()
+	}
+	r.prev = line
+	return line
+}
+
+// isTrivialBlameLine reports whether a trimmed line has no letters or digits,
+// e.g. a blank line or a closing brace.
+func isTrivialBlameLine(key string) bool {
+	return !strings.ContainsFunc(key, func(c rune) bool {
+		return unicode.IsLetter(c) || unicode.IsDigit(c)
+	})
+}
+
+// commitInfo resolves a blamed commit to its checkpoint and the lines the agent wrote.
+// Uncommitted lines are matched against the shadow branch of HEAD.
+func (r *blameResolver) commitInfo(ctx context.Context, sha string) *blameCommitInfo {
+	if info, ok := r.commits[sha]; ok {
+		return info
+	}
+	info := &blameCommitInfo{Origins: make(map[string][]blameOrigin)}
+	r.commits[sha] = info
+
+	if isUncommittedSHA(sha) {
+		if head, err := r.repo.Head(); err == nil {
+			r.addShadowOrigins(info, head.Hash(), blameOrigin{})
+		}
+		return info
+	}
+
+	commit, err := r.repo.CommitObject(plumbing.NewHash(sha))
+	if err != nil {
+		return info
+	}
+	cpID, found := trailers.ParseCheckpoint(commit.Message)
+	if !found {
+		return info
+	}
+	info.CheckpointID = cpID
+
+	latest := r.addTranscriptOrigins(ctx, info, cpID)
+	// The shadow branch of the commit's parent holds the agent's last snapshot
+	// of the worktree, if it hasn't been cleaned up yet.
+	if commit.NumParents() > 0 {
+		r.addShadowOrigins(info, commit.ParentHashes[0], latest)
+	}
+	return info
+}
+
+// addTranscriptOrigins records the lines the agent added to the file in each
+// session's edits and writes. Returns the origin of the checkpoint's latest session
+// for lines whose prompt isn't known.
+func (r *blameResolver) addTranscriptOrigins(ctx context.Context, info *blameCommitInfo, cpID id.CheckpointID) blameOrigin {
+	var latest blameOrigin
+	summary, err := r.store.ReadCommitted(ctx, cpID)
+	if err != nil || summary == nil {
+		return latest
+	}
+
+	for i := range max(len(summary.Sessions), 1) {
+		content, err := r.store.ReadSessionContent(ctx, cpID, i)
+		if err != nil {
+			continue
+		}
+		meta := content.Metadata
+		latest = blameOrigin{Agent: meta.Agent, SessionID: meta.SessionID}
+
+		entries, err := summarize.BuildCondensedTranscriptFromBytes(content.Transcript, meta.Agent)
+		if err != nil {
+			continue
+		}
+		for _, turn := range buildRenderTurns(entries, nil, meta.Agent) {
+			origin := blameOrigin{Agent: meta.Agent, SessionID: meta.SessionID, Prompt: turn.Prompt}
+			for _, step := range turn.Steps {
+				for _, d := range step.Diffs {
+					if !toolPathMatches(d.Path, r.file) {
+						continue
+					}
+					for _, l := range splitDiffLines(d.Body) {
+						if added, ok := strings.CutPrefix(l, "+"); ok {
+							key := strings.TrimSpace(added)
+							info.Origins[key] = append(info.Origins[key], origin)
+						}
+					}
+				}
+			}
+		}
+	}
+	return latest
+}
+
+// addShadowOrigins records the lines the shadow branch for baseCommit, if it
+// exists, added to the file relative to baseCommit. Lines the transcript
+// already accounts for aren't counted twice.
+func (r *blameResolver) addShadowOrigins(info *blameCommitInfo, baseCommit plumbing.Hash, origin blameOrigin) {
+	branch := checkpoint.ShadowBranchNameForCommit(baseCommit.String(), r.worktreeID)
+	ref, err := r.repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		return
+	}
+	commit, err := r.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return
+	}
+	if origin.SessionID == "" {
+		origin.SessionID, _ = trailers.ParseSession(commit.Message)
+	}
+	snapshot, ok := r.fileAt(commit.Hash)
+	if !ok {
+		return
+	}
+	base, _ := r.fileAt(baseCommit) // The agent may have created the file
+
+	dmp := diffmatchpatch.New()
+	text1, text2, lineArray := dmp.DiffLinesToChars(base, snapshot)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(text1, text2, false), lineArray)
+	added := make(map[string]int)
+	for _, d := range diffs {
+		if d.Type != diffmatchpatch.DiffInsert {
+			continue
+		}
+		for _, l := range splitDiffLines(d.Text) {
+			added[strings.TrimSpace(l)]++
+		}
+	}
+	for key, n := range added {
+		for len(info.Origins[key]) < n {
+			info.Origins[key] = append(info.Origins[key], origin)
+		}
+	}
+}
+
+// fileAt returns the contents of the blamed file in the given commit.
+func (r *blameResolver) fileAt(hash plumbing.Hash) (string, bool) {
+	commit, err := r.repo.CommitObject(hash)
+	if err != nil {
+		return "", false
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", false
+	}
+	f, err := tree.File(r.file)
+	if err != nil {
+		return "", false
+	}
+	contents, err := f.Contents()
+	if err != nil {
+		return "", false
+	}
+	return contents, true
+}
+
+// isUncommittedSHA reports whether sha is git blame's all-zero "Not Committed Yet" commit.
+func isUncommittedSHA(sha string) bool {
+	return strings.Trim(sha, "0") == ""
+}
+
+// toolPathMatches reports whether a path from a tool call (absolute, or relative
+// to the repository root) refers to the repository-relative file.
+func toolPathMatches(toolPath, relFile string) bool {
+	if toolPath == "" {
+		return false
+	}
+	toolPath = path.Clean(filepath.ToSlash(toolPath))
+	return toolPath == relFile || strings.HasSuffix(toolPath, "/"+relFile)
+}
+
+// formatBlameText renders a report like git blame, with prompts listed as footnotes.
+func formatBlameText(report *blameReport) string {
+	var sb strings.Builder
+
+	type promptKey struct{ checkpoint, session, prompt string }
+	promptRefs := make(map[promptKey]int)
+	var footnotes []string
+
+	width := len(strconv.Itoa(len(report.Lines)))
+	for _, l := range report.Lines {
+		ref := ""
+		if l.Attribution == blameAgent && l.Prompt != "" {
+			key := promptKey{l.CheckpointID, l.SessionID, l.Prompt}
+			n, ok := promptRefs[key]
+			if !ok {
+				n = len(footnotes) + 1
+				promptRefs[key] = n
+				label := l.CheckpointID
+				if label == "" {
+					label = "(uncommitted)"
+				}
+				if l.Agent != "" {
+					label += " " + string(l.Agent)
+				}
+				prompt := stringutil.TruncateRunes(stringutil.CollapseWhitespace(l.Prompt), 100, "...")
+				footnotes = append(footnotes, fmt.Sprintf("  [%d] %s: %s", n, label, prompt))
+			}
+			ref = fmt.Sprintf("[%d]", n)
+		}
+		fmt.Fprintf(&sb, "%s  %-12s  %-12s  %-5s %*d) %s\n",
+			shortSHA(l.Commit), l.Attribution, l.CheckpointID, ref, width, l.Line, l.Content)
+	}
+
+	if len(footnotes) > 0 {
+		sb.WriteString("\nPrompts:\n")
+		for _, f := range footnotes {
+			sb.WriteString(f + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// formatBlamePorcelain renders a report as one record per line, modeled on
+// git blame --porcelain: a "<commit> <line>" header, "key value" lines for
+// non-empty fields, and the content prefixed with a tab.
+func formatBlamePorcelain(report *blameReport) string {
+	var sb strings.Builder
+	for _, l := range report.Lines {
+		fmt.Fprintf(&sb, "%s %d\n", l.Commit, l.Line)
+		fmt.Fprintf(&sb, "attribution %s\n", l.Attribution)
+		if l.Agent != "" {
+			fmt.Fprintf(&sb, "agent %s\n", l.Agent)
+		}
+		if l.CheckpointID != "" {
+			fmt.Fprintf(&sb, "checkpoint %s\n", l.CheckpointID)
+		}
+		if l.SessionID != "" {
+			fmt.Fprintf(&sb, "session %s\n", l.SessionID)
+		}
+		if l.Prompt != "" {
+			fmt.Fprintf(&sb, "prompt %s\n", stringutil.CollapseWhitespace(l.Prompt))
+		}
+		fmt.Fprintf(&sb, "\t%s\n", l.Content)
+	}
+	return sb.String()
+}
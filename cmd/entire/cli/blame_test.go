@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// setupBlameTestRepo creates a repo where main.go was written by an agent in
+// checkpoint abc123def456, then edited by a human before committing.
+func setupBlameTestRepo(t *testing.T) string {
+	t.Helper()
+	tmpDir, repo := setupCheckpointTestRepo(t, "hello\n")
+
+	// The agent wrote main.go with an absolute path
+	writeBlameTestCheckpoint(t, repo, filepath.Join(tmpDir, "main.go"), "package main\n\nfunc greet() string {\n\treturn \"hello\"\n}\n", "Add a greet function")
+
+	// The human added a comment before committing
+	commitWithCheckpoint(t, tmpDir, "Add greet", "abc123def456", map[string]string{
+		"main.go": "package main\n\n// greet says hi\nfunc greet() string {\n\treturn \"hello\"\n}\n",
+	})
+	return tmpDir
+}
+
+// writeBlameTestCheckpoint writes checkpoint abc123def456, whose transcript has
+// the agent write content to filePath in response to prompt.
+func writeBlameTestCheckpoint(t *testing.T, repo *git.Repository, filePath, content, prompt string) {
+	t.Helper()
+	writeInput, err := json.Marshal(map[string]string{"file_path": filePath, "content": content})
+	if err != nil {
+		t.Fatalf("failed to marshal tool input: %v", err)
+	}
+	transcript := `{"type":"user","message":{"content":"` + prompt + `"}}` + "\n" +
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"toolu_1","name":"Write","input":` + string(writeInput) + `}]}}` + "\n"
+	writeTestCheckpoint(t, repo, checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("abc123def456"),
+		SessionID:    "session-blame",
+		Agent:        agent.AgentTypeClaudeCode,
+		Transcript:   []byte(transcript),
+	})
+}
+
+func TestRunBlame_JSON(t *testing.T) {
+	tmpDir := setupBlameTestRepo(t)
+	// An uncommitted line, and a line from a commit without a checkpoint
+	testutil.WriteFile(t, tmpDir, "README.md", "hello\nwip\n")
+
+	var buf bytes.Buffer
+	if err := runBlame(context.Background(), &buf, "main.go", false, true); err != nil {
+		t.Fatalf("runBlame() error = %v", err)
+	}
+	var report blameReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if report.File != "main.go" || len(report.Lines) != 6 {
+		t.Fatalf("report = %+v, want 6 lines of main.go", report)
+	}
+
+	wantAttribution := []string{blameAgent, blameAgent, blameHumanEdited, blameAgent, blameAgent, blameAgent}
+	for i, l := range report.Lines {
+		if l.Line != i+1 || l.Attribution != wantAttribution[i] {
+			t.Errorf("line %d: got line %d attribution %q, want %q", i+1, l.Line, l.Attribution, wantAttribution[i])
+		}
+		if l.CheckpointID != "abc123def456" {
+			t.Errorf("line %d: checkpoint = %q", i+1, l.CheckpointID)
+		}
+	}
+	first := report.Lines[0]
+	if first.Agent != agent.AgentTypeClaudeCode || first.SessionID != "session-blame" || first.Prompt != "Add a greet function" {
+		t.Errorf("agent line = %+v, want agent, session and prompt", first)
+	}
+	if report.Lines[2].Prompt != "" {
+		t.Errorf("human-edited line has a prompt: %+v", report.Lines[2])
+	}
+
+	buf.Reset()
+	if err := runBlame(context.Background(), &buf, "README.md", false, true); err != nil {
+		t.Fatalf("runBlame(README.md) error = %v", err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(report.Lines) != 2 || report.Lines[0].Attribution != blameHuman || report.Lines[1].Attribution != blameUncommitted {
+		t.Errorf("README.md lines = %+v, want human then uncommitted", report.Lines)
+	}
+}
+
+func TestRunBlame_TextAndPorcelain(t *testing.T) {
+	setupBlameTestRepo(t)
+
+	var buf bytes.Buffer
+	if err := runBlame(context.Background(), &buf, "main.go", false, false); err != nil {
+		t.Fatalf("runBlame() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"agent         abc123def456  [1]   1) package main",
+		"human-edited  abc123def456        3) // greet says hi",
+		"Prompts:\n  [1] abc123def456 Claude Code: Add a greet function",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := runBlame(context.Background(), &buf, "main.go", true, false); err != nil {
+		t.Fatalf("runBlame(porcelain) error = %v", err)
+	}
+	out = buf.String()
+	for _, want := range []string{
+		" 1\nattribution agent\nagent Claude Code\ncheckpoint abc123def456\nsession session-blame\nprompt Add a greet function\n\tpackage main\n",
+		" 3\nattribution human-edited\ncheckpoint abc123def456\n\t// greet says hi\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in porcelain output:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunBlame_Errors(t *testing.T) {
+	setupBlameTestRepo(t)
+
+	var buf bytes.Buffer
+	if err := runBlame(context.Background(), &buf, "missing.go", false, false); err == nil {
+		t.Error("expected error for a file git can't blame")
+	}
+	if err := runBlame(context.Background(), &buf, filepath.Join(t.TempDir(), "x.go"), false, false); err == nil {
+		t.Error("expected error for a file outside the repository")
+	}
+}
+
+func TestParseGitBlamePorcelain(t *testing.T) {
+	sha1 := strings.Repeat("a", 40)
+	sha2 := strings.Repeat("b", 40)
+	output := sha1 + " 1 1 2\nauthor Alice\nsummary first\nfilename f.go\n\tline one\n" +
+		sha1 + " 2 2\n\tline two\n" +
+		sha2 + " 5 3 1\nauthor Bob\nfilename f.go\n\t\tindented\n"
+
+	lines, err := parseGitBlamePorcelain([]byte(output))
+	if err != nil {
+		t.Fatalf("parseGitBlamePorcelain() error = %v", err)
+	}
+	want := []gitBlameLine{
+		{SHA: sha1, Line: 1, Content: "line one"},
+		{SHA: sha1, Line: 2, Content: "line two"},
+		{SHA: sha2, Line: 3, Content: "\tindented"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d: %+v", len(lines), len(want), lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestToolPathMatches(t *testing.T) {
+	tests := []struct {
+		toolPath string
+		want     bool
+	}{
+		{"src/main.go", true},
+		{"/home/me/repo/src/main.go", true},
+		{"./src/main.go", true},
+		{"main.go", false},
+		{"/home/me/repo/othersrc/main.go", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := toolPathMatches(tt.toolPath, "src/main.go"); got != tt.want {
+			t.Errorf("toolPathMatches(%q) = %v, want %v", tt.toolPath, got, tt.want)
+		}
+	}
+}
+
+// agentFunc and humanFunc are two functions whose bodies are identical trivial lines.
+const (
+	agentFunc = "func a() error {\n\treturn nil\n}\n"
+	humanFunc = "func b() error {\n\treturn nil\n}\n"
+)
+
+func TestRunBlame_IdenticalTrivialLines(t *testing.T) {
+	tmpDir, repo := setupCheckpointTestRepo(t, "hello\n")
+	writeBlameTestCheckpoint(t, repo, "main.go", "package main\n\n"+agentFunc, "Add a")
+
+	// The human wrote a function with the same body before committing
+	commitWithCheckpoint(t, tmpDir, "Add a and b", "abc123def456", map[string]string{
+		"main.go": "package main\n\n" + agentFunc + "\n" + humanFunc,
+	})
+
+	report, err := buildBlameReport(context.Background(), "main.go")
+	if err != nil {
+		t.Fatalf("buildBlameReport() error = %v", err)
+	}
+	want := []string{
+		blameAgent, blameAgent, blameAgent, blameAgent, blameAgent,
+		blameHumanEdited, blameHumanEdited, blameHumanEdited, blameHumanEdited,
+	}
+	assertBlameAttributions(t, report, want)
+}
+
+func TestRunBlame_ShadowIdenticalTrivialLines(t *testing.T) {
+	tmpDir, repo := setupCheckpointTestRepo(t, "hello\n")
+	testutil.WriteFile(t, tmpDir, "main.go", "package main\n")
+	testutil.GitAdd(t, tmpDir, "main.go")
+	testutil.GitCommit(t, tmpDir, "Add main.go")
+	base := plumbing.NewHash(testutil.GetHeadHash(t, tmpDir))
+
+	// The agent's snapshot of the worktree on the shadow branch
+	testutil.WriteFile(t, tmpDir, "main.go", "package main\n"+agentFunc)
+	testutil.GitAdd(t, tmpDir, "main.go")
+	testutil.GitCommit(t, tmpDir, "Checkpoint\n\nEntire-Session: session-shadow\n")
+	shadow := plumbing.NewBranchReferenceName(checkpoint.ShadowBranchNameForCommit(base.String(), ""))
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(shadow, plumbing.NewHash(testutil.GetHeadHash(t, tmpDir)))); err != nil {
+		t.Fatalf("failed to set shadow branch: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if err := wt.Reset(&git.ResetOptions{Commit: base, Mode: git.MixedReset}); err != nil {
+		t.Fatalf("failed to reset: %v", err)
+	}
+
+	// The human wrote a function with the same body, not yet committed
+	testutil.WriteFile(t, tmpDir, "main.go", "package main\n"+agentFunc+humanFunc)
+
+	report, err := buildBlameReport(context.Background(), "main.go")
+	if err != nil {
+		t.Fatalf("buildBlameReport() error = %v", err)
+	}
+	want := []string{
+		blameHuman, blameAgent, blameAgent, blameAgent,
+		blameUncommitted, blameUncommitted, blameUncommitted,
+	}
+	assertBlameAttributions(t, report, want)
+	if report.Lines[2].SessionID != "session-shadow" {
+		t.Errorf("agent line = %+v, want session from the shadow branch", report.Lines[2])
+	}
+}
+
+func assertBlameAttributions(t *testing.T, report *blameReport, want []string) {
+	t.Helper()
+	if len(report.Lines) != len(want) {
+		t.Fatalf("got %d lines, want %d: %+v", len(report.Lines), len(want), report.Lines)
+	}
+	for i, l := range report.Lines {
+		if l.Attribution != want[i] {
+			t.Errorf("line %d %q: attribution %q, want %q", l.Line, l.Content, l.Attribution, want[i])
+		}
+	}
+}
@@ -0,0 +1,516 @@
+package cli
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/versioninfo"
+
+	"github.com/spf13/cobra"
+)
+
+// A checkpoint bundle is a tar.gz or zip archive with a single top-level
+// directory (entire-checkpoint-<id>/) containing:
+//
+//	manifest.json          # bundleManifest
+//	summary.txt            # Human-readable summary of the latest summarized session (if any)
+//	commits/<sha>.patch    # git format-patch output for each linked commit
+//	checkpoint/            # The checkpoint directory from entire/checkpoints/v1,
+//	  metadata.json        # with transcripts reassembled into a single full.jsonl
+//	  0/metadata.json
+//	  0/full.jsonl
+//	  ...
+//
+// Only checkpoint/ is imported; the other files are for human readers.
+const (
+	bundleFormatVersion = 1
+	bundleManifestFile  = "manifest.json"
+	bundleSummaryFile   = "summary.txt"
+	bundleCommitsDir    = "commits/"
+	bundleCheckpointDir = "checkpoint/"
+
+	bundleFormatTarGz = "tar.gz"
+	bundleFormatZip   = "zip"
+
+	// maxBundleFileSize caps each file read from a bundle.
+	maxBundleFileSize = 1 << 30 // 1 GiB
+)
+
+// bundleManifest describes a checkpoint bundle.
+type bundleManifest struct {
+	FormatVersion int            `json:"format_version"`
+	CheckpointID  string         `json:"checkpoint_id"`
+	ExportedAt    time.Time      `json:"exported_at"`
+	CLIVersion    string         `json:"cli_version"`
+	Commits       []bundleCommit `json:"commits"`
+}
+
+// bundleCommit is a commit linked to the checkpoint by its Entire-Checkpoint trailer.
+type bundleCommit struct {
+	SHA     string    `json:"sha"`
+	Message string    `json:"message"`
+	Author  string    `json:"author,omitempty"`
+	Date    time.Time `json:"date"`
+	Patch   string    `json:"patch,omitempty"` // Path of the patch within the bundle
+}
+
+func newExportCmd() *cobra.Command {
+	var checkpointFlag string
+	var outputFlag string
+	var formatFlag string
+	var searchAllFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export a checkpoint as a portable bundle",
+		Long: `Export a committed checkpoint as a self-contained archive that can be shared
+without repository access.
+
+The bundle contains the checkpoint and session metadata, reassembled
+transcripts, prompts, context, the summary, and a patch of each commit linked
+to the checkpoint. Use 'entire import' to load it into another repository.
+
+The archive format is taken from --format, or from the --output extension
+(.zip or .tar.gz), defaulting to tar.gz.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if checkDisabledGuard(cmd.Context(), cmd.OutOrStdout()) {
+				return nil
+			}
+			return runExport(cmd.Context(), cmd.OutOrStdout(), checkpointFlag, outputFlag, formatFlag, searchAllFlag)
+		},
+	}
+
+	cmd.Flags().StringVarP(&checkpointFlag, "checkpoint", "c", "", "Checkpoint ID or prefix to export")
+	cmd.Flags().StringVarP(&outputFlag, "output", "o", "", "Output file (default entire-checkpoint-<id>.tar.gz)")
+	cmd.Flags().StringVar(&formatFlag, "format", "", "Archive format: tar.gz or zip")
+	cmd.Flags().BoolVar(&searchAllFlag, "search-all", false, "Search all commits for linked commits (no branch/depth limit, may be slow)")
+	_ = cmd.MarkFlagRequired("checkpoint") //nolint:errcheck // flag is defined above
+
+	return cmd
+}
+
+func newImportCmd() *cobra.Command {
+	var forceFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "import <bundle>",
+		Short: "Import a checkpoint bundle",
+		Long: `Import a checkpoint bundle created by 'entire export' onto the
+entire/checkpoints/v1 branch as a new metadata commit.
+
+Transcripts, prompts and context are redacted again with this repository's
+redaction rules before they are written. An existing checkpoint with the same
+ID is only replaced with --force.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkDisabledGuard(cmd.Context(), cmd.OutOrStdout()) {
+				return nil
+			}
+			return runImport(cmd.Context(), cmd.OutOrStdout(), args[0], forceFlag)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&forceFlag, "force", "f", false, "Replace an existing checkpoint with the same ID")
+
+	return cmd
+}
+
+func runExport(ctx context.Context, w io.Writer, checkpointIDPrefix, output, format string, searchAll bool) error {
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	store := checkpoint.NewGitStore(repo)
+
+	matches, err := matchCommittedCheckpoints(ctx, store, checkpointIDPrefix)
+	if err != nil {
+		return err
+	}
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("checkpoint not found: %s", checkpointIDPrefix)
+	case 1:
+	default:
+		return ambiguousCheckpointError(checkpointIDPrefix, matches)
+	}
+	checkpointID := matches[0]
+
+	format, err = resolveBundleFormat(format, output)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		output = fmt.Sprintf("entire-checkpoint-%s.%s", checkpointID, format)
+	}
+
+	files, err := buildBundleFiles(ctx, store, checkpointID, getLinkedCommits(ctx, checkpointID, searchAll))
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	root := fmt.Sprintf("entire-checkpoint-%s/", checkpointID)
+	if err := writeBundleArchive(&buf, format, root, files); err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, buf.Bytes(), 0o644); err != nil { //nolint:gosec // bundles are meant to be shared
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	fmt.Fprintf(w, "Exported checkpoint %s to %s\n", checkpointID, output)
+	return nil
+}
+
+// resolveBundleFormat picks the archive format from the flag or the output file extension.
+func resolveBundleFormat(format, output string) (string, error) {
+	switch format {
+	case bundleFormatTarGz, "tgz":
+		return bundleFormatTarGz, nil
+	case bundleFormatZip:
+		return bundleFormatZip, nil
+	case "":
+		if strings.HasSuffix(strings.ToLower(output), ".zip") {
+			return bundleFormatZip, nil
+		}
+		return bundleFormatTarGz, nil
+	default:
+		return "", fmt.Errorf("unsupported bundle format %q (use tar.gz or zip)", format)
+	}
+}
+
+// getLinkedCommits returns the commits whose Entire-Checkpoint trailer references
+// the checkpoint, with their patches. Best-effort: commits that can't be found
+// or formatted are left out.
+func getLinkedCommits(ctx context.Context, checkpointID id.CheckpointID, searchAll bool) []linkedCommit {
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return nil
+	}
+	commits, err := getAssociatedCommits(ctx, repo, checkpointID, searchAll)
+	if err != nil {
+		return nil
+	}
+
+	linked := make([]linkedCommit, 0, len(commits))
+	for _, c := range commits {
+		patch, patchErr := formatCommitPatch(ctx, c.SHA)
+		if patchErr != nil {
+			patch = nil
+		}
+		linked = append(linked, linkedCommit{associatedCommit: c, Patch: patch})
+	}
+	return linked
+}
+
+// linkedCommit is an associated commit with its patch.
+type linkedCommit struct {
+	associatedCommit
+
+	Patch []byte
+}
+
+// formatCommitPatch returns the git format-patch output for a single commit.
+func formatCommitPatch(ctx context.Context, sha string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", "format-patch", "-1", "--stdout", sha)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git format-patch failed: %w", err)
+	}
+	return output, nil
+}
+
+// buildBundleFiles collects the files of a bundle, keyed by path within the bundle root.
+func buildBundleFiles(ctx context.Context, store *checkpoint.GitStore, checkpointID id.CheckpointID, commits []linkedCommit) (map[string][]byte, error) {
+	checkpointFiles, err := store.ReadCheckpointFiles(ctx, checkpointID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	files := make(map[string][]byte, len(checkpointFiles)+len(commits)+2)
+	for name, content := range checkpointFiles {
+		files[bundleCheckpointDir+name] = content
+	}
+
+	manifest := bundleManifest{
+		FormatVersion: bundleFormatVersion,
+		CheckpointID:  checkpointID.String(),
+		ExportedAt:    time.Now().UTC(),
+		CLIVersion:    versioninfo.Version,
+		Commits:       []bundleCommit{},
+	}
+	for _, c := range commits {
+		bc := bundleCommit{SHA: c.SHA, Message: c.Message, Author: c.Author, Date: c.Date}
+		if len(c.Patch) > 0 {
+			bc.Patch = bundleCommitsDir + c.SHA + ".patch"
+			files[bc.Patch] = c.Patch
+		}
+		manifest.Commits = append(manifest.Commits, bc)
+	}
+	manifestJSON, err := jsonutil.MarshalIndentWithNewline(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal bundle manifest: %w", err)
+	}
+	files[bundleManifestFile] = manifestJSON
+
+	if summary := latestBundleSummary(ctx, store, checkpointID); summary != nil {
+		files[bundleSummaryFile] = []byte(formatBundleSummary(summary))
+	}
+
+	return files, nil
+}
+
+// latestBundleSummary returns the summary of the latest session that has one.
+func latestBundleSummary(ctx context.Context, store *checkpoint.GitStore, checkpointID id.CheckpointID) *checkpoint.Summary {
+	cpSummary, err := store.ReadCommitted(ctx, checkpointID)
+	if err != nil || cpSummary == nil {
+		return nil
+	}
+	for i := len(cpSummary.Sessions) - 1; i >= 0; i-- {
+		content, readErr := store.ReadSessionContent(ctx, checkpointID, i)
+		if readErr == nil && content.Metadata.Summary != nil {
+			return content.Metadata.Summary
+		}
+	}
+	return nil
+}
+
+// formatBundleSummary renders a summary in the same layout as 'entire explain'.
+func formatBundleSummary(summary *checkpoint.Summary) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Intent: %s\n", summary.Intent)
+	fmt.Fprintf(&sb, "Outcome: %s\n", summary.Outcome)
+	formatSummaryDetails(&sb, summary)
+	return sb.String()
+}
+
+// writeBundleArchive writes files under root into a tar.gz or zip archive.
+func writeBundleArchive(w io.Writer, format, root string, files map[string][]byte) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	modTime := time.Now()
+
+	if format == bundleFormatZip {
+		zw := zip.NewWriter(w)
+		for _, name := range names {
+			fw, err := zw.CreateHeader(&zip.FileHeader{Name: root + name, Method: zip.Deflate, Modified: modTime})
+			if err != nil {
+				return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+			}
+			if _, err := fw.Write(files[name]); err != nil {
+				return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+			}
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("failed to finish bundle: %w", err)
+		}
+		return nil
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for _, name := range names {
+		hdr := &tar.Header{
+			Name:     root + name,
+			Mode:     0o644,
+			Size:     int64(len(files[name])),
+			ModTime:  modTime,
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	return nil
+}
+
+func runImport(ctx context.Context, w io.Writer, bundlePath string, force bool) error {
+	files, err := readBundleArchive(bundlePath)
+	if err != nil {
+		return err
+	}
+
+	manifestJSON, ok := files[bundleManifestFile]
+	if !ok {
+		return fmt.Errorf("invalid bundle %s: missing %s", bundlePath, bundleManifestFile)
+	}
+	var manifest bundleManifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return fmt.Errorf("invalid bundle %s: failed to parse %s: %w", bundlePath, bundleManifestFile, err)
+	}
+	if manifest.FormatVersion > bundleFormatVersion {
+		return fmt.Errorf("bundle format version %d is newer than supported (%d); upgrade entire to import it", manifest.FormatVersion, bundleFormatVersion)
+	}
+	checkpointID, err := id.NewCheckpointID(manifest.CheckpointID)
+	if err != nil {
+		return fmt.Errorf("invalid bundle %s: %w", bundlePath, err)
+	}
+
+	checkpointFiles := make(map[string][]byte)
+	for name, content := range files {
+		if rel, found := strings.CutPrefix(name, bundleCheckpointDir); found && rel != "" {
+			checkpointFiles[rel] = content
+		}
+	}
+
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	store := checkpoint.NewGitStore(repo)
+	authorName, authorEmail := checkpoint.GetGitAuthorFromRepo(repo)
+
+	err = store.ImportCheckpoint(ctx, checkpoint.ImportCheckpointOptions{
+		CheckpointID: checkpointID,
+		Files:        checkpointFiles,
+		Force:        force,
+		AuthorName:   authorName,
+		AuthorEmail:  authorEmail,
+	})
+	if errors.Is(err, checkpoint.ErrCheckpointExists) {
+		return fmt.Errorf("checkpoint %s already exists (use --force to replace it)", checkpointID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to import checkpoint: %w", err)
+	}
+
+	fmt.Fprintf(w, "Imported checkpoint %s onto %s\n", checkpointID, paths.MetadataBranchName)
+	fmt.Fprintf(w, "View it with: entire explain --checkpoint %s\n", checkpointID)
+	return nil
+}
+
+// readBundleArchive reads a tar.gz or zip bundle and returns its files keyed
+// by path with the single top-level directory stripped.
+func readBundleArchive(bundlePath string) (map[string][]byte, error) {
+	f, err := os.Open(bundlePath) //nolint:gosec // bundlePath is the user-supplied bundle to import
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	magic, err := br.Peek(4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	raw := make(map[string][]byte)
+	switch {
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")):
+		info, statErr := f.Stat()
+		if statErr != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", statErr)
+		}
+		zr, zipErr := zip.NewReader(f, info.Size())
+		if zipErr != nil {
+			return nil, fmt.Errorf("failed to read zip bundle: %w", zipErr)
+		}
+		for _, zf := range zr.File {
+			if zf.FileInfo().IsDir() {
+				continue
+			}
+			rc, openErr := zf.Open()
+			if openErr != nil {
+				return nil, fmt.Errorf("failed to read %s from bundle: %w", zf.Name, openErr)
+			}
+			content, readErr := readBundleFile(rc, zf.Name)
+			rc.Close()
+			if readErr != nil {
+				return nil, readErr
+			}
+			raw[zf.Name] = content
+		}
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gr, gzErr := gzip.NewReader(br)
+		if gzErr != nil {
+			return nil, fmt.Errorf("failed to read tar.gz bundle: %w", gzErr)
+		}
+		defer gr.Close()
+		tr := tar.NewReader(gr)
+		for {
+			hdr, nextErr := tr.Next()
+			if errors.Is(nextErr, io.EOF) {
+				break
+			}
+			if nextErr != nil {
+				return nil, fmt.Errorf("failed to read tar.gz bundle: %w", nextErr)
+			}
+			if hdr.Typeflag != tar.TypeReg {
+				continue
+			}
+			content, readErr := readBundleFile(tr, hdr.Name)
+			if readErr != nil {
+				return nil, readErr
+			}
+			raw[hdr.Name] = content
+		}
+	default:
+		return nil, fmt.Errorf("%s is not a tar.gz or zip bundle", bundlePath)
+	}
+
+	return stripBundleRoot(raw)
+}
+
+// readBundleFile reads one archive member, enforcing maxBundleFileSize.
+func readBundleFile(r io.Reader, name string) ([]byte, error) {
+	content, err := io.ReadAll(io.LimitReader(r, maxBundleFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from bundle: %w", name, err)
+	}
+	if len(content) > maxBundleFileSize {
+		return nil, fmt.Errorf("%s in bundle exceeds the maximum size of %d bytes", name, maxBundleFileSize)
+	}
+	return content, nil
+}
+
+// stripBundleRoot removes the single top-level directory shared by every file.
+func stripBundleRoot(raw map[string][]byte) (map[string][]byte, error) {
+	var root string
+	for name := range raw {
+		first, _, found := strings.Cut(path.Clean(name), "/")
+		if !found {
+			return nil, fmt.Errorf("invalid bundle: %s is outside the bundle directory", name)
+		}
+		if root == "" {
+			root = first
+		} else if first != root {
+			return nil, errors.New("invalid bundle: expected a single top-level directory")
+		}
+	}
+
+	files := make(map[string][]byte, len(raw))
+	for name, content := range raw {
+		files[strings.TrimPrefix(path.Clean(name), root+"/")] = content
+	}
+	return files, nil
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+)
+
+const bundleTestTranscript = `{"type":"user","message":{"content":"add a greeting"}}` + "\n"
+
+// setupBundleTestRepo creates a repo with a committed checkpoint linked to HEAD.
+func setupBundleTestRepo(t *testing.T) string {
+	t.Helper()
+	tmpDir, repo := setupCheckpointTestRepo(t, "hello")
+	writeTestCheckpoint(t, repo, checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("abc123def456"),
+		SessionID:    "session-bundle",
+		Prompts:      []string{"add a greeting"},
+		Transcript:   []byte(bundleTestTranscript),
+	})
+	commitWithCheckpoint(t, tmpDir, "Add greeting", "abc123def456", map[string]string{"greeting.txt": "hi there\n"})
+	return tmpDir
+}
+
+func TestRunExport_TarGz(t *testing.T) {
+	tmpDir := setupBundleTestRepo(t)
+
+	var buf bytes.Buffer
+	if err := runExport(context.Background(), &buf, "abc123", "", "", false); err != nil {
+		t.Fatalf("runExport() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "Exported checkpoint abc123def456 to entire-checkpoint-abc123def456.tar.gz") {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+
+	files, err := readBundleArchive(filepath.Join(tmpDir, "entire-checkpoint-abc123def456.tar.gz"))
+	if err != nil {
+		t.Fatalf("readBundleArchive() error = %v", err)
+	}
+	for _, name := range []string{
+		"manifest.json",
+		"checkpoint/metadata.json",
+		"checkpoint/0/metadata.json",
+		"checkpoint/0/full.jsonl",
+		"checkpoint/0/prompt.txt",
+	} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("expected %s in bundle", name)
+		}
+	}
+	if got := string(files["checkpoint/0/full.jsonl"]); got != bundleTestTranscript {
+		t.Errorf("bundled transcript = %q, want %q", got, bundleTestTranscript)
+	}
+
+	var manifest bundleManifest
+	if err := json.Unmarshal(files["manifest.json"], &manifest); err != nil {
+		t.Fatalf("invalid manifest: %v", err)
+	}
+	if manifest.CheckpointID != "abc123def456" || manifest.FormatVersion != bundleFormatVersion {
+		t.Errorf("manifest = %+v", manifest)
+	}
+	if len(manifest.Commits) != 1 || manifest.Commits[0].Message != "Add greeting" {
+		t.Fatalf("expected the linked commit in the manifest, got %+v", manifest.Commits)
+	}
+	patch := string(files[manifest.Commits[0].Patch])
+	if !strings.Contains(patch, "+hi there") || !strings.Contains(patch, "greeting.txt") {
+		t.Errorf("expected the commit's diff in %s, got:\n%s", manifest.Commits[0].Patch, patch)
+	}
+}
+
+func TestRunImport_RoundTrip(t *testing.T) {
+	sourceDir := setupBundleTestRepo(t)
+	bundlePath := filepath.Join(sourceDir, "cp.zip")
+
+	var buf bytes.Buffer
+	if err := runExport(context.Background(), &buf, "abc123def456", bundlePath, "", false); err != nil {
+		t.Fatalf("runExport() error = %v", err)
+	}
+
+	// Importing over the existing checkpoint requires --force.
+	err := runImport(context.Background(), &buf, bundlePath, false)
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("runImport() error = %v, want already-exists error suggesting --force", err)
+	}
+	if err := runImport(context.Background(), &buf, bundlePath, true); err != nil {
+		t.Fatalf("runImport(force) error = %v", err)
+	}
+
+	// Import into a repository that has never seen the checkpoint.
+	_, repo := setupCheckpointTestRepo(t, "other")
+
+	buf.Reset()
+	if err := runImport(context.Background(), &buf, bundlePath, false); err != nil {
+		t.Fatalf("runImport() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "Imported checkpoint abc123def456") {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+
+	content, err := checkpoint.NewGitStore(repo).ReadSessionContent(context.Background(), id.MustCheckpointID("abc123def456"), 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if content.Metadata.SessionID != "session-bundle" || string(content.Transcript) != bundleTestTranscript {
+		t.Errorf("imported session = %q with transcript %q", content.Metadata.SessionID, content.Transcript)
+	}
+	if content.Prompts != "add a greeting" {
+		t.Errorf("imported prompts = %q", content.Prompts)
+	}
+}
+
+func TestRunImport_RejectsNonBundle(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	testutil.WriteFile(t, tmpDir, "notes.txt", "not an archive")
+
+	var buf bytes.Buffer
+	err := runImport(context.Background(), &buf, "notes.txt", false)
+	if err == nil || !strings.Contains(err.Error(), "not a tar.gz or zip bundle") {
+		t.Errorf("runImport() error = %v, want not-a-bundle error", err)
+	}
+}
+
+func TestResolveBundleFormat(t *testing.T) {
+	tests := []struct {
+		format, output, want string
+		wantErr              bool
+	}{
+		{"", "", bundleFormatTarGz, false},
+		{"", "out.ZIP", bundleFormatZip, false},
+		{"", "out.tar.gz", bundleFormatTarGz, false},
+		{"zip", "out.tar.gz", bundleFormatZip, false},
+		{"tgz", "", bundleFormatTarGz, false},
+		{"rar", "", "", true},
+	}
+	for _, tt := range tests {
+		got, err := resolveBundleFormat(tt.format, tt.output)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("resolveBundleFormat(%q, %q) = %q, %v; want %q (err %v)", tt.format, tt.output, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
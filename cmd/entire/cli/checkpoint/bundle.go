@@ -0,0 +1,301 @@
+package checkpoint
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/encryption"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/redact"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ReadCheckpointFiles returns every file of a committed checkpoint, keyed by its
+// slash-separated path relative to the checkpoint directory (e.g., "metadata.json",
+// "0/full.jsonl"). Chunked transcripts are reassembled into a single full.jsonl,
+// and encrypted transcripts, prompts and context are decrypted.
+// Returns ErrCheckpointNotFound if the checkpoint doesn't exist.
+func (s *GitStore) ReadCheckpointFiles(ctx context.Context, checkpointID id.CheckpointID) (map[string][]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck // Propagating context cancellation
+	}
+
+	tree, err := s.getSessionsBranchTree()
+	if err != nil {
+		return nil, ErrCheckpointNotFound
+	}
+	checkpointTree, err := tree.Tree(checkpointID.Path())
+	if err != nil {
+		return nil, ErrCheckpointNotFound
+	}
+
+	entries := make(map[string]object.TreeEntry)
+	if err := FlattenTree(s.repo, checkpointTree, "", entries); err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte, len(entries))
+	transcriptDirs := make(map[string]bool)
+	for name, entry := range entries {
+		dir, base := path.Split(name)
+		if agent.ParseChunkIndex(base, paths.TranscriptFileName) >= 0 {
+			transcriptDirs[dir] = true
+			continue
+		}
+		content, err := s.readBlob(entry.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if base == paths.PromptFileName || base == paths.ContextFileName {
+			if content, err = encryption.Open(content); err != nil {
+				return nil, fmt.Errorf("failed to decrypt %s: %w", name, err)
+			}
+		}
+		files[name] = content
+	}
+
+	for dir := range transcriptDirs {
+		dirTree := checkpointTree
+		if dir != "" {
+			if dirTree, err = checkpointTree.Tree(strings.TrimSuffix(dir, "/")); err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+			}
+		}
+		transcript, err := readTranscriptFromTree(ctx, dirTree, sessionAgentType(files, dir))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transcript in %s: %w", dir, err)
+		}
+		if transcript != nil {
+			files[dir+paths.TranscriptFileName] = transcript
+		}
+	}
+
+	return files, nil
+}
+
+// ImportCheckpointOptions configures ImportCheckpoint.
+type ImportCheckpointOptions struct {
+	// CheckpointID is the checkpoint to create. It must match the ID in the
+	// root metadata.json of Files.
+	CheckpointID id.CheckpointID
+
+	// Files are the checkpoint's files keyed by path relative to the checkpoint
+	// directory, as returned by ReadCheckpointFiles.
+	Files map[string][]byte
+
+	// Force replaces an existing checkpoint with the same ID.
+	Force bool
+
+	// AuthorName and AuthorEmail are used for the metadata commit.
+	AuthorName  string
+	AuthorEmail string
+}
+
+// ImportCheckpoint writes a checkpoint's files onto the entire/checkpoints/v1
+// branch in a new commit. Transcripts are re-chunked as WriteCommitted would
+// chunk them, and transcripts, prompts, context and JSON metadata are redacted
+// as a safety net.
+// Returns ErrCheckpointExists if the checkpoint exists and Force is not set.
+func (s *GitStore) ImportCheckpoint(ctx context.Context, opts ImportCheckpointOptions) error {
+	if opts.CheckpointID.IsEmpty() {
+		return errors.New("invalid import options: checkpoint ID is required")
+	}
+
+	rootMetadata, ok := opts.Files[paths.MetadataFileName]
+	if !ok {
+		return fmt.Errorf("invalid checkpoint: missing %s", paths.MetadataFileName)
+	}
+	var summary CheckpointSummary
+	if err := json.Unmarshal(rootMetadata, &summary); err != nil {
+		return fmt.Errorf("invalid checkpoint: failed to parse %s: %w", paths.MetadataFileName, err)
+	}
+	if summary.CheckpointID != opts.CheckpointID {
+		return fmt.Errorf("invalid checkpoint: %s is for checkpoint %s, not %s", paths.MetadataFileName, summary.CheckpointID, opts.CheckpointID)
+	}
+	for name := range opts.Files {
+		if !isValidCheckpointFilePath(name) {
+			return fmt.Errorf("invalid checkpoint: unsafe file path %q", name)
+		}
+	}
+
+	if err := s.ensureSessionsBranch(); err != nil {
+		return fmt.Errorf("failed to ensure sessions branch: %w", err)
+	}
+	parentHash, rootTreeHash, err := s.getSessionsBranchRef()
+	if err != nil {
+		return err
+	}
+
+	basePath := opts.CheckpointID.Path() + "/"
+	existing, err := s.flattenCheckpointEntries(rootTreeHash, opts.CheckpointID.Path())
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 && !opts.Force {
+		return fmt.Errorf("%w: %s", ErrCheckpointExists, opts.CheckpointID)
+	}
+
+	entries := make(map[string]object.TreeEntry, len(opts.Files))
+	addBlob := func(name string, content []byte) error {
+		hash, err := CreateBlobFromContent(s.repo, content)
+		if err != nil {
+			return err
+		}
+		entries[basePath+name] = object.TreeEntry{Name: basePath + name, Mode: filemode.Regular, Hash: hash}
+		return nil
+	}
+
+	names := make([]string, 0, len(opts.Files))
+	for name := range opts.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		dir, base := path.Split(name)
+		content := opts.Files[name]
+		switch {
+		case base == paths.TranscriptFileName:
+			if err := s.importTranscript(ctx, opts.Files, dir, content, addBlob); err != nil {
+				return err
+			}
+		case agent.ParseChunkIndex(base, paths.TranscriptFileName) > 0:
+			// Chunks are regenerated from full.jsonl
+		case base == paths.ContentHashFileName && hasFile(opts.Files, dir+paths.TranscriptFileName):
+			// Recomputed from the (possibly re-redacted) transcript by importTranscript
+		case base == paths.PromptFileName || base == paths.ContextFileName:
+			sealed, err := encryption.Seal(redact.Bytes(content))
+			if err != nil {
+				return fmt.Errorf("failed to encrypt %s: %w", name, err)
+			}
+			if err := addBlob(name, sealed); err != nil {
+				return err
+			}
+		case strings.HasSuffix(base, ".jsonl"):
+			redacted, jsonlErr := redact.JSONLBytes(content)
+			if jsonlErr != nil {
+				redacted = redact.Bytes(content)
+			}
+			if err := addBlob(name, redacted); err != nil {
+				return err
+			}
+		case strings.HasSuffix(base, ".json"):
+			redacted, jsonErr := redact.JSONBytes(content)
+			if jsonErr != nil {
+				redacted = redact.Bytes(content)
+			}
+			if err := addBlob(name, redacted); err != nil {
+				return err
+			}
+		default:
+			if err := addBlob(name, content); err != nil {
+				return err
+			}
+		}
+	}
+
+	newTreeHash, err := s.spliceCheckpointSubtree(rootTreeHash, opts.CheckpointID, basePath, entries)
+	if err != nil {
+		return err
+	}
+
+	commitMsg := fmt.Sprintf("Import checkpoint: %s\n", opts.CheckpointID)
+	newCommitHash, err := s.createCommit(newTreeHash, parentHash, commitMsg, opts.AuthorName, opts.AuthorEmail)
+	if err != nil {
+		return err
+	}
+
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(refName, newCommitHash)); err != nil {
+		return fmt.Errorf("failed to set branch reference: %w", err)
+	}
+	return nil
+}
+
+// importTranscript redacts and chunks a session transcript and writes its content hash.
+func (s *GitStore) importTranscript(ctx context.Context, files map[string][]byte, dir string, transcript []byte, addBlob func(string, []byte) error) error {
+	redacted, err := redact.JSONLBytes(transcript)
+	if err != nil {
+		return fmt.Errorf("failed to redact transcript secrets: %w", err)
+	}
+
+	chunks, err := agent.ChunkTranscript(ctx, redacted, sessionAgentType(files, dir))
+	if err != nil {
+		return fmt.Errorf("failed to chunk transcript: %w", err)
+	}
+	for i, chunk := range chunks {
+		sealed, err := encryption.Seal(chunk)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt transcript: %w", err)
+		}
+		if err := addBlob(dir+agent.ChunkFileName(paths.TranscriptFileName, i), sealed); err != nil {
+			return err
+		}
+	}
+
+	contentHash := fmt.Sprintf("sha256:%x", sha256.Sum256(redacted))
+	return addBlob(dir+paths.ContentHashFileName, []byte(contentHash))
+}
+
+// isValidCheckpointFilePath reports whether name is a clean relative path
+// that stays inside the checkpoint directory.
+func isValidCheckpointFilePath(name string) bool {
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, "\\") {
+		return false
+	}
+	if path.Clean(name) != name {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." || part == "." || part == ".git" {
+			return false
+		}
+	}
+	return true
+}
+
+// sessionAgentType returns the agent recorded in dir's session metadata.json,
+// or "" if there is none.
+func sessionAgentType(files map[string][]byte, dir string) agent.AgentType {
+	var m CommittedMetadata
+	if meta, ok := files[dir+paths.MetadataFileName]; ok && json.Unmarshal(meta, &m) == nil {
+		return m.Agent
+	}
+	return ""
+}
+
+func hasFile(files map[string][]byte, name string) bool {
+	_, ok := files[name]
+	return ok
+}
+
+// readBlob reads the full content of a blob.
+func (s *GitStore) readBlob(hash plumbing.Hash) ([]byte, error) {
+	blob, err := s.repo.BlobObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob: %w", err)
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blob: %w", err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob: %w", err)
+	}
+	return content, nil
+}
@@ -0,0 +1,187 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+func TestReadCheckpointFiles_ImportRoundTrip(t *testing.T) {
+	transcript := `{"type":"user","message":"hello"}` + "\n" + `{"type":"assistant","message":"hi"}` + "\n"
+	store, checkpointID := writeSingleSession(t, "b1b2b3b4b5b6", "bundle-session", transcript)
+
+	files, err := store.ReadCheckpointFiles(context.Background(), checkpointID)
+	if err != nil {
+		t.Fatalf("ReadCheckpointFiles() error = %v", err)
+	}
+	for _, name := range []string{paths.MetadataFileName, "0/" + paths.MetadataFileName, "0/" + paths.TranscriptFileName} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("expected %s in checkpoint files, got %v", name, fileNames(files))
+		}
+	}
+	if got := string(files["0/"+paths.TranscriptFileName]); got != transcript {
+		t.Errorf("transcript = %q, want %q", got, transcript)
+	}
+
+	repo, _ := setupBranchTestRepo(t)
+	target := NewGitStore(repo)
+	err = target.ImportCheckpoint(context.Background(), ImportCheckpointOptions{
+		CheckpointID: checkpointID,
+		Files:        files,
+		AuthorName:   "Importer",
+		AuthorEmail:  "importer@example.com",
+	})
+	if err != nil {
+		t.Fatalf("ImportCheckpoint() error = %v", err)
+	}
+
+	content, err := target.ReadSessionContent(context.Background(), checkpointID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() after import error = %v", err)
+	}
+	if content.Metadata.SessionID != "bundle-session" {
+		t.Errorf("SessionID = %q, want bundle-session", content.Metadata.SessionID)
+	}
+	if string(content.Transcript) != transcript {
+		t.Errorf("imported transcript = %q, want %q", content.Transcript, transcript)
+	}
+
+	// The imported checkpoint reads back identically, including the recomputed content hash.
+	reimported, err := target.ReadCheckpointFiles(context.Background(), checkpointID)
+	if err != nil {
+		t.Fatalf("ReadCheckpointFiles() after import error = %v", err)
+	}
+	for name, want := range files {
+		if got, ok := reimported[name]; !ok || string(got) != string(want) {
+			t.Errorf("%s differs after import:\ngot  %q\nwant %q", name, got, want)
+		}
+	}
+}
+
+func TestImportCheckpoint_ExistingRequiresForce(t *testing.T) {
+	store, checkpointID := writeSingleSession(t, "c1c2c3c4c5c6", "existing-session", `{"msg":"one"}`)
+
+	files, err := store.ReadCheckpointFiles(context.Background(), checkpointID)
+	if err != nil {
+		t.Fatalf("ReadCheckpointFiles() error = %v", err)
+	}
+	opts := ImportCheckpointOptions{
+		CheckpointID: checkpointID,
+		Files:        files,
+		AuthorName:   "Importer",
+		AuthorEmail:  "importer@example.com",
+	}
+
+	if err := store.ImportCheckpoint(context.Background(), opts); !errors.Is(err, ErrCheckpointExists) {
+		t.Fatalf("ImportCheckpoint() error = %v, want ErrCheckpointExists", err)
+	}
+
+	opts.Force = true
+	if err := store.ImportCheckpoint(context.Background(), opts); err != nil {
+		t.Fatalf("ImportCheckpoint(Force) error = %v", err)
+	}
+}
+
+func TestImportCheckpoint_RejectsInvalidFiles(t *testing.T) {
+	store, checkpointID := writeSingleSession(t, "d1d2d3d4d5d6", "invalid-session", `{"msg":"one"}`)
+
+	files, err := store.ReadCheckpointFiles(context.Background(), checkpointID)
+	if err != nil {
+		t.Fatalf("ReadCheckpointFiles() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		checkpointID id.CheckpointID
+		extra        string
+		omitMetadata bool
+	}{
+		{name: "id mismatch", checkpointID: id.MustCheckpointID("d1d2d3d4d5d7")},
+		{name: "path traversal", checkpointID: checkpointID, extra: "../escape.txt"},
+		{name: "absolute path", checkpointID: checkpointID, extra: "/etc/passwd"},
+		{name: "git dir", checkpointID: checkpointID, extra: "0/.git/config"},
+		{name: "missing metadata", checkpointID: checkpointID, omitMetadata: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidate := make(map[string][]byte, len(files)+1)
+			for name, content := range files {
+				candidate[name] = content
+			}
+			if tt.extra != "" {
+				candidate[tt.extra] = []byte("x")
+			}
+			if tt.omitMetadata {
+				delete(candidate, paths.MetadataFileName)
+			}
+
+			err := store.ImportCheckpoint(context.Background(), ImportCheckpointOptions{
+				CheckpointID: tt.checkpointID,
+				Files:        candidate,
+				Force:        true,
+			})
+			if err == nil || errors.Is(err, ErrCheckpointExists) {
+				t.Errorf("ImportCheckpoint() error = %v, want validation error", err)
+			}
+		})
+	}
+}
+
+func TestImportCheckpoint_RedactsMetadata(t *testing.T) {
+	store, checkpointID := writeSingleSession(t, "f1f2f3f4f5f6", "metadata-session", `{"msg":"one"}`)
+
+	files, err := store.ReadCheckpointFiles(context.Background(), checkpointID)
+	if err != nil {
+		t.Fatalf("ReadCheckpointFiles() error = %v", err)
+	}
+	var meta map[string]any
+	if err := json.Unmarshal(files["0/"+paths.MetadataFileName], &meta); err != nil {
+		t.Fatalf("failed to parse session metadata: %v", err)
+	}
+	meta["branch"] = highEntropySecret
+	files["0/"+paths.MetadataFileName], err = json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal session metadata: %v", err)
+	}
+
+	repo, _ := setupBranchTestRepo(t)
+	target := NewGitStore(repo)
+	err = target.ImportCheckpoint(context.Background(), ImportCheckpointOptions{
+		CheckpointID: checkpointID,
+		Files:        files,
+		AuthorName:   "Importer",
+		AuthorEmail:  "importer@example.com",
+	})
+	if err != nil {
+		t.Fatalf("ImportCheckpoint() error = %v", err)
+	}
+
+	content, err := target.ReadSessionContent(context.Background(), checkpointID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() after import error = %v", err)
+	}
+	if content.Metadata.Branch != "REDACTED" || content.Metadata.SessionID != "metadata-session" {
+		t.Errorf("imported metadata = %+v, want branch redacted and session ID kept", content.Metadata)
+	}
+}
+
+func TestReadCheckpointFiles_NotFound(t *testing.T) {
+	store, _ := writeSingleSession(t, "e6e5e4e3e2e1", "some-session", `{"msg":"one"}`)
+
+	_, err := store.ReadCheckpointFiles(context.Background(), id.MustCheckpointID("ffffffffffff"))
+	if !errors.Is(err, ErrCheckpointNotFound) {
+		t.Errorf("ReadCheckpointFiles() error = %v, want ErrCheckpointNotFound", err)
+	}
+}
+
+func fileNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	return names
+}
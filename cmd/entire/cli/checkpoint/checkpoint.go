@@ -24,6 +24,9 @@ var (
 
 	// ErrNoTranscript is returned when a checkpoint exists but has no transcript.
 	ErrNoTranscript = errors.New("no transcript found for checkpoint")
+
+	// ErrCheckpointExists is returned when importing over an existing checkpoint.
+	ErrCheckpointExists = errors.New("checkpoint already exists")
 )
 
 // Checkpoint represents a save point within a session.
@@ -357,6 +360,10 @@ type SessionContent struct {
 
 	// Context is the context.md content
 	Context string
+
+	// Encrypted is true when the transcript, prompts or context are encrypted
+	// and no configured identity can decrypt them. The undecryptable fields are empty.
+	Encrypted bool
 }
 
 // CommittedMetadata contains the metadata stored in metadata.json for each checkpoint.
@@ -397,6 +404,15 @@ type CommittedMetadata struct {
 
 	// InitialAttribution is line-level attribution calculated at commit time
 	InitialAttribution *InitialAttribution `json:"initial_attribution,omitempty"`
+
+	// RedactionCounts is the number of secrets redacted from this session's transcript,
+	// prompts and context when the checkpoint was written, keyed by rule ID
+	// ("entropy", a gitleaks rule ID, or a custom rule ID). Empty when nothing was redacted.
+	RedactionCounts map[string]int `json:"redaction_counts,omitempty"`
+
+	// CompactedAt is when 'entire gc' removed this session's transcript under the
+	// retention policy. Prompts, context and the rest of the metadata are kept.
+	CompactedAt *time.Time `json:"compacted_at,omitempty"`
 }
 
 // GetTranscriptStart returns the transcript line offset at which this checkpoint's data begins.
@@ -448,6 +464,9 @@ type CheckpointSummary struct {
 	FilesTouched     []string           `json:"files_touched"`
 	Sessions         []SessionFilePaths `json:"sessions"`
 	TokenUsage       *agent.TokenUsage  `json:"token_usage,omitempty"`
+	// Commits lists commits that git created by rewriting the commit this
+	// checkpoint was linked to (amend, rebase, squash), oldest first.
+	Commits []string `json:"commits,omitempty"`
 }
 
 // Summary contains AI-generated summary of a checkpoint.
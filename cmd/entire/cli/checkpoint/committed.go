@@ -17,6 +17,7 @@ import (
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/encryption"
 	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
@@ -325,7 +326,7 @@ func (s *GitStore) writeStandardCheckpointEntries(ctx context.Context, opts Writ
 	sessions[sessionIndex] = sessionFilePaths
 
 	// Update root metadata.json with CheckpointSummary
-	return s.writeCheckpointSummary(opts, basePath, entries, sessions)
+	return s.writeCheckpointSummary(opts, basePath, entries, sessions, existingSummary)
 }
 
 // writeSessionToSubdirectory writes a single session's files to a numbered subdirectory.
@@ -341,8 +342,11 @@ func (s *GitStore) writeSessionToSubdirectory(ctx context.Context, opts WriteCom
 		}
 	}
 
+	// Tally what redaction scrubs from this session's files for metadata.json.
+	redactions := redact.Counts{}
+
 	// Write transcript
-	if err := s.writeTranscript(ctx, opts, sessionPath, entries); err != nil {
+	if err := s.writeTranscript(ctx, opts, sessionPath, entries, redactions); err != nil {
 		return filePaths, err
 	}
 	filePaths.Transcript = "/" + sessionPath + paths.TranscriptFileName
@@ -350,8 +354,8 @@ func (s *GitStore) writeSessionToSubdirectory(ctx context.Context, opts WriteCom
 
 	// Write prompts
 	if len(opts.Prompts) > 0 {
-		promptContent := redact.String(strings.Join(opts.Prompts, "\n\n---\n\n"))
-		blobHash, err := CreateBlobFromContent(s.repo, []byte(promptContent))
+		promptContent := redact.StringCounted(strings.Join(opts.Prompts, "\n\n---\n\n"), redactions)
+		blobHash, err := createSealedBlob(s.repo, []byte(promptContent))
 		if err != nil {
 			return filePaths, err
 		}
@@ -365,7 +369,7 @@ func (s *GitStore) writeSessionToSubdirectory(ctx context.Context, opts WriteCom
 
 	// Write context
 	if len(opts.Context) > 0 {
-		blobHash, err := CreateBlobFromContent(s.repo, redact.Bytes(opts.Context))
+		blobHash, err := createSealedBlob(s.repo, redact.BytesCounted(opts.Context, redactions))
 		if err != nil {
 			return filePaths, err
 		}
@@ -396,6 +400,7 @@ func (s *GitStore) writeSessionToSubdirectory(ctx context.Context, opts WriteCom
 		TokenUsage:                  opts.TokenUsage,
 		InitialAttribution:          opts.InitialAttribution,
 		Summary:                     redactSummary(opts.Summary),
+		RedactionCounts:             redactions,
 		CLIVersion:                  versioninfo.Version,
 	}
 
@@ -419,7 +424,8 @@ func (s *GitStore) writeSessionToSubdirectory(ctx context.Context, opts WriteCom
 
 // writeCheckpointSummary writes the root-level CheckpointSummary with aggregated statistics.
 // sessions is the complete sessions array (already built by the caller).
-func (s *GitStore) writeCheckpointSummary(opts WriteCommittedOptions, basePath string, entries map[string]object.TreeEntry, sessions []SessionFilePaths) error {
+// Commits recorded on the existing summary by history rewrites are carried over.
+func (s *GitStore) writeCheckpointSummary(opts WriteCommittedOptions, basePath string, entries map[string]object.TreeEntry, sessions []SessionFilePaths, existing *CheckpointSummary) error {
 	checkpointsCount, filesTouched, tokenUsage, err :=
 		s.reaggregateFromEntries(basePath, len(sessions), entries)
 	if err != nil {
@@ -436,6 +442,9 @@ func (s *GitStore) writeCheckpointSummary(opts WriteCommittedOptions, basePath s
 		Sessions:         sessions,
 		TokenUsage:       tokenUsage,
 	}
+	if existing != nil {
+		summary.Commits = existing.Commits
+	}
 
 	metadataJSON, err := jsonutil.MarshalIndentWithNewline(summary, "", "  ")
 	if err != nil {
@@ -530,19 +539,22 @@ func (s *GitStore) readSummaryFromBlob(hash plumbing.Hash) (*CheckpointSummary,
 	return readJSONFromBlob[CheckpointSummary](s.repo, hash)
 }
 
-// aggregateTokenUsage sums two TokenUsage structs.
+// aggregateTokenUsage sums two TokenUsage structs, including subagent and per-model usage.
 // Returns nil if both inputs are nil.
 func aggregateTokenUsage(a, b *agent.TokenUsage) *agent.TokenUsage {
 	if a == nil && b == nil {
 		return nil
 	}
 	result := &agent.TokenUsage{}
+	var subA, subB *agent.TokenUsage
 	if a != nil {
 		result.InputTokens = a.InputTokens
 		result.CacheCreationTokens = a.CacheCreationTokens
 		result.CacheReadTokens = a.CacheReadTokens
 		result.OutputTokens = a.OutputTokens
 		result.APICallCount = a.APICallCount
+		result.Models = agent.MergeModelUsage(nil, a.Models)
+		subA = a.SubagentTokens
 	}
 	if b != nil {
 		result.InputTokens += b.InputTokens
@@ -550,13 +562,17 @@ func aggregateTokenUsage(a, b *agent.TokenUsage) *agent.TokenUsage {
 		result.CacheReadTokens += b.CacheReadTokens
 		result.OutputTokens += b.OutputTokens
 		result.APICallCount += b.APICallCount
+		result.Models = agent.MergeModelUsage(result.Models, b.Models)
+		subB = b.SubagentTokens
 	}
+	result.SubagentTokens = aggregateTokenUsage(subA, subB)
 	return result
 }
 
 // writeTranscript writes the transcript file from in-memory content or file path.
 // If the transcript exceeds MaxChunkSize, it's split into multiple chunk files.
-func (s *GitStore) writeTranscript(ctx context.Context, opts WriteCommittedOptions, basePath string, entries map[string]object.TreeEntry) error {
+// Secrets redacted from the transcript are tallied in redactions.
+func (s *GitStore) writeTranscript(ctx context.Context, opts WriteCommittedOptions, basePath string, entries map[string]object.TreeEntry, redactions redact.Counts) error {
 	transcript := opts.Transcript
 	if len(transcript) == 0 && opts.TranscriptPath != "" {
 		var readErr error
@@ -571,7 +587,7 @@ func (s *GitStore) writeTranscript(ctx context.Context, opts WriteCommittedOptio
 	}
 
 	// Redact secrets before chunking so content hash reflects redacted content
-	transcript, err := redact.JSONLBytes(transcript)
+	transcript, err := redact.JSONLBytesCounted(transcript, redactions)
 	if err != nil {
 		return fmt.Errorf("failed to redact transcript secrets: %w", err)
 	}
@@ -585,7 +601,7 @@ func (s *GitStore) writeTranscript(ctx context.Context, opts WriteCommittedOptio
 	// Write chunk files
 	for i, chunk := range chunks {
 		chunkPath := basePath + agent.ChunkFileName(paths.TranscriptFileName, i)
-		blobHash, err := CreateBlobFromContent(s.repo, chunk)
+		blobHash, err := createSealedBlob(s.repo, chunk)
 		if err != nil {
 			return err
 		}
@@ -822,23 +838,35 @@ func (s *GitStore) ReadSessionContent(ctx context.Context, checkpointID id.Check
 	}
 
 	// Read transcript
-	if transcript, transcriptErr := readTranscriptFromTree(ctx, sessionTree, agentType); transcriptErr == nil && transcript != nil {
+	transcript, transcriptErr := readTranscriptFromTree(ctx, sessionTree, agentType)
+	if transcriptErr == nil && transcript != nil {
 		result.Transcript = transcript
 	}
-
-	// Read prompts
-	if file, fileErr := sessionTree.File(paths.PromptFileName); fileErr == nil {
-		if content, contentErr := file.Contents(); contentErr == nil {
-			result.Prompts = content
-		}
+	if errors.Is(transcriptErr, encryption.ErrNoIdentity) {
+		result.Encrypted = true
 	}
 
-	// Read context
-	if file, fileErr := sessionTree.File(paths.ContextFileName); fileErr == nil {
-		if content, contentErr := file.Contents(); contentErr == nil {
-			result.Context = content
+	// Read prompts and context
+	readOpened := func(name string) string {
+		file, fileErr := sessionTree.File(name)
+		if fileErr != nil {
+			return ""
+		}
+		content, contentErr := file.Contents()
+		if contentErr != nil {
+			return ""
 		}
+		opened, openErr := encryption.Open([]byte(content))
+		if openErr != nil {
+			if errors.Is(openErr, encryption.ErrNoIdentity) {
+				result.Encrypted = true
+			}
+			return ""
+		}
+		return string(opened)
 	}
+	result.Prompts = readOpened(paths.PromptFileName)
+	result.Context = readOpened(paths.ContextFileName)
 
 	return result, nil
 }
@@ -861,6 +889,33 @@ func (s *GitStore) ReadLatestSessionContent(ctx context.Context, checkpointID id
 	return s.ReadSessionContent(ctx, checkpointID, latestIndex)
 }
 
+// ReadLatestSessionMetadata reads only the latest session's metadata.json,
+// without the transcript, prompts and context.
+// Returns ErrCheckpointNotFound if the checkpoint doesn't exist.
+func (s *GitStore) ReadLatestSessionMetadata(ctx context.Context, checkpointID id.CheckpointID) (*CommittedMetadata, error) {
+	summary, err := s.ReadCommitted(ctx, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	if summary == nil {
+		return nil, ErrCheckpointNotFound
+	}
+	if len(summary.Sessions) == 0 {
+		return nil, fmt.Errorf("checkpoint has no sessions: %s", checkpointID)
+	}
+
+	tree, err := s.getSessionsBranchTree()
+	if err != nil {
+		return nil, ErrCheckpointNotFound
+	}
+	metadataPath := checkpointID.Path() + "/" + strconv.Itoa(len(summary.Sessions)-1) + "/" + paths.MetadataFileName
+	file, err := tree.File(metadataPath)
+	if err != nil {
+		return nil, fmt.Errorf("session metadata not found: %w", err)
+	}
+	return s.readMetadataFromBlob(file.Hash)
+}
+
 // ReadSessionContentByID reads a session's content by its session ID.
 // This is useful when you have the session ID but don't know its index within the checkpoint.
 // Returns ErrCheckpointNotFound if the checkpoint doesn't exist.
@@ -1207,7 +1262,7 @@ func (s *GitStore) UpdateCommitted(ctx context.Context, opts UpdateCommittedOpti
 	// Replace prompts (apply redaction as safety net)
 	if len(opts.Prompts) > 0 {
 		promptContent := redact.String(strings.Join(opts.Prompts, "\n\n---\n\n"))
-		blobHash, err := CreateBlobFromContent(s.repo, []byte(promptContent))
+		blobHash, err := createSealedBlob(s.repo, []byte(promptContent))
 		if err != nil {
 			return fmt.Errorf("failed to create prompt blob: %w", err)
 		}
@@ -1220,7 +1275,7 @@ func (s *GitStore) UpdateCommitted(ctx context.Context, opts UpdateCommittedOpti
 
 	// Replace context (apply redaction as safety net)
 	if len(opts.Context) > 0 {
-		contextBlob, err := CreateBlobFromContent(s.repo, redact.Bytes(opts.Context))
+		contextBlob, err := createSealedBlob(s.repo, redact.Bytes(opts.Context))
 		if err != nil {
 			return fmt.Errorf("failed to create context blob: %w", err)
 		}
@@ -1273,7 +1328,7 @@ func (s *GitStore) replaceTranscript(ctx context.Context, transcript []byte, age
 	// Write chunk files
 	for i, chunk := range chunks {
 		chunkPath := sessionPath + agent.ChunkFileName(paths.TranscriptFileName, i)
-		blobHash, err := CreateBlobFromContent(s.repo, chunk)
+		blobHash, err := createSealedBlob(s.repo, chunk)
 		if err != nil {
 			return fmt.Errorf("failed to create transcript blob: %w", err)
 		}
@@ -1382,6 +1437,17 @@ func CreateBlobFromContent(repo *git.Repository, content []byte) (plumbing.Hash,
 	return hash, nil
 }
 
+// createSealedBlob stores content as a blob, encrypted for the configured
+// recipients when transcript encryption is enabled. Used for transcripts,
+// prompts and context; metadata.json always stays in clear text.
+func createSealedBlob(repo *git.Repository, content []byte) (plumbing.Hash, error) {
+	sealed, err := encryption.Seal(content)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encrypt checkpoint content: %w", err)
+	}
+	return CreateBlobFromContent(repo, sealed)
+}
+
 // copyMetadataDir copies all files from a directory to the checkpoint path.
 // Used to include additional metadata files like task checkpoints, subagent transcripts, etc.
 func (s *GitStore) copyMetadataDir(metadataDir, basePath string, entries map[string]object.TreeEntry) error {
@@ -1465,8 +1531,9 @@ func createRedactedBlobFromFile(repo *git.Repository, filePath, treePath string)
 
 	// Skip redaction for binary files — they can't contain text secrets and
 	// running string replacement on them would corrupt the data.
+	// Files matched by the redaction path allowlist are stored verbatim too.
 	isBin, binErr := binary.IsBinary(bytes.NewReader(content))
-	if binErr != nil || isBin {
+	if binErr != nil || isBin || redact.PathAllowed(treePath) {
 		hash, err := CreateBlobFromContent(repo, content)
 		if err != nil {
 			return plumbing.ZeroHash, 0, fmt.Errorf("failed to create blob: %w", err)
@@ -1528,6 +1595,8 @@ func GetGitAuthorFromRepo(repo *git.Repository) (name, email string) {
 // readTranscriptFromTree reads a transcript from a git tree, handling both chunked and non-chunked formats.
 // It checks for chunk files first (.001, .002, etc.), then falls back to the base file.
 // The agentType is used for reassembling chunks in the correct format.
+// Encrypted chunks are decrypted; the error wraps encryption.ErrNoIdentity when no
+// configured identity can decrypt them.
 func readTranscriptFromTree(ctx context.Context, tree *object.Tree, agentType agent.AgentType) ([]byte, error) {
 	// Collect all transcript-related files
 	var chunkFiles []string
@@ -1576,7 +1645,11 @@ func readTranscriptFromTree(ctx context.Context, tree *object.Tree, agentType ag
 				)
 				continue
 			}
-			chunks = append(chunks, []byte(content))
+			chunk, err := encryption.Open([]byte(content))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt %s: %w", chunkFile, err)
+			}
+			chunks = append(chunks, chunk)
 		}
 
 		if len(chunks) > 0 {
@@ -1591,7 +1664,11 @@ func readTranscriptFromTree(ctx context.Context, tree *object.Tree, agentType ag
 	// Fall back to reading base file (non-chunked or backwards compatibility)
 	if file, err := tree.File(paths.TranscriptFileName); err == nil {
 		if content, err := file.Contents(); err == nil {
-			return []byte(content), nil
+			transcript, err := encryption.Open([]byte(content))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt %s: %w", paths.TranscriptFileName, err)
+			}
+			return transcript, nil
 		}
 	}
 
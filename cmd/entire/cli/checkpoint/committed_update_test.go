@@ -8,8 +8,10 @@ import (
 	"testing"
 
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/encryption"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 
+	"filippo.io/age"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -611,3 +613,76 @@ func TestGetGitAuthorFromRepo_NoConfig(t *testing.T) {
 
 // Verify go-git config import is used (compile-time check).
 var _ = config.GlobalScope
+
+func TestCommitted_EncryptedContent(t *testing.T) {
+	// Not parallel: encryption is configured process-wide.
+	t.Cleanup(func() { _ = encryption.Configure(nil) }) //nolint:errcheck // restoring defaults cannot fail
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "key.txt")
+	if err := os.WriteFile(keyFile, []byte(identity.String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := encryption.Configure(&encryption.Config{
+		Recipients:    []string{identity.Recipient().String()},
+		IdentityFiles: []string{keyFile},
+	}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	_, store, cpID := setupRepoForUpdate(t)
+	ctx := context.Background()
+	if err := store.UpdateCommitted(ctx, UpdateCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-001",
+		Transcript:   []byte("final transcript\n"),
+		Prompts:      []string{"final prompt"},
+	}); err != nil {
+		t.Fatalf("UpdateCommitted() error = %v", err)
+	}
+
+	tree, err := store.getSessionsBranchTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionDir := cpID.Path() + "/0/"
+	for _, name := range []string{paths.TranscriptFileName, paths.PromptFileName, paths.ContextFileName, paths.MetadataFileName} {
+		file, err := tree.File(sessionDir + name)
+		if err != nil {
+			t.Fatalf("missing %s: %v", name, err)
+		}
+		raw, err := file.Contents()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := name != paths.MetadataFileName; encryption.IsEncrypted([]byte(raw)) != want {
+			t.Errorf("%s encrypted = %v, want %v", name, !want, want)
+		}
+	}
+
+	content, err := store.ReadSessionContent(ctx, cpID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if string(content.Transcript) != "final transcript\n" || content.Prompts != "final prompt" || content.Context != "initial context" || content.Encrypted {
+		t.Errorf("ReadSessionContent() = %+v, want decrypted content", content)
+	}
+
+	// Without a matching identity, reads degrade instead of failing.
+	if err := encryption.Configure(&encryption.Config{IdentityFiles: []string{filepath.Join(t.TempDir(), "missing")}}); err != nil {
+		t.Fatal(err)
+	}
+	content, err = store.ReadSessionContent(ctx, cpID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() without identity error = %v", err)
+	}
+	if !content.Encrypted || len(content.Transcript) != 0 || content.Prompts != "" {
+		t.Errorf("ReadSessionContent() without identity = %+v, want empty encrypted content", content)
+	}
+	if content.Metadata.SessionID != "session-001" {
+		t.Errorf("metadata should stay readable, got session %q", content.Metadata.SessionID)
+	}
+}
@@ -0,0 +1,349 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// RetentionPolicy selects the committed checkpoints whose transcripts Compact removes.
+// A zero limit is disabled.
+type RetentionPolicy struct {
+	// MaxAge compacts checkpoints created longer ago than this.
+	MaxAge time.Duration
+
+	// MaxPerBranch keeps transcripts for only the newest MaxPerBranch checkpoints
+	// created on each branch.
+	MaxPerBranch int
+
+	// Now is the reference time for MaxAge. Defaults to time.Now().
+	Now time.Time
+}
+
+// IsZero reports whether the policy has no limits.
+func (p RetentionPolicy) IsZero() bool {
+	return p.MaxAge <= 0 && p.MaxPerBranch <= 0
+}
+
+// CompactResult describes what Compact removed (or would remove, for a dry run).
+type CompactResult struct {
+	// Checkpoints are the checkpoints whose transcripts were removed.
+	Checkpoints []id.CheckpointID
+
+	// Files is the number of distinct transcript blobs removed from the branch history.
+	Files int
+
+	// ReclaimedBytes is the uncompressed size of those blobs. Git frees the space
+	// once the old history is no longer referenced and has been garbage collected.
+	ReclaimedBytes int64
+
+	// RewrittenCommits is the number of commits on the branch that were rewritten.
+	RewrittenCommits int
+
+	// OldHead and NewHead are the branch tip before and after compaction.
+	// They are equal when there was nothing to compact.
+	OldHead plumbing.Hash
+	NewHead plumbing.Hash
+}
+
+// Compact removes transcripts from committed checkpoints selected by policy,
+// keeping metadata.json (summaries, attribution, token usage), prompt.txt and
+// context.md. Every commit on entire/checkpoints/v1 is rewritten without the
+// transcript files, so the space can be reclaimed, a commit marks the
+// compacted sessions with CompactedAt, and a last one records the rewrite in
+// the purge log. Commits that didn't contain any of the
+// removed files keep their hashes; authors and messages are preserved.
+//
+// With dryRun, nothing is written and the result reports what would be removed.
+func (s *GitStore) Compact(ctx context.Context, policy RetentionPolicy, dryRun bool) (*CompactResult, error) {
+	oldHead, _, err := s.getSessionsBranchRef()
+	if err != nil {
+		return nil, err
+	}
+	result := &CompactResult{OldHead: oldHead, NewHead: oldHead}
+	if policy.IsZero() {
+		return result, nil
+	}
+
+	entries, err := s.ListIndexed(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	selected := selectForCompaction(entries, policy)
+	if len(selected) == 0 {
+		return result, nil
+	}
+
+	dropped := make(map[plumbing.Hash]struct{})
+	compacted := make(map[id.CheckpointID]bool)
+	rw := newHistoryRewriter(s.repo, dryRun)
+	rw.selects = func(cpID id.CheckpointID) bool { return selected[cpID] }
+	rw.rewriteDir = func(cpID id.CheckpointID, _ string, entries []object.TreeEntry) ([]object.TreeEntry, bool, error) {
+		kept := entries[:0]
+		for _, entry := range entries {
+			if entry.Mode != filemode.Dir && isTranscriptFile(entry.Name) {
+				dropped[entry.Hash] = struct{}{}
+				compacted[cpID] = true
+				continue
+			}
+			kept = append(kept, entry)
+		}
+		return kept, len(kept) != len(entries), nil
+	}
+	newHead, err := rw.rewriteCommit(ctx, oldHead)
+	if err != nil {
+		return nil, err
+	}
+	result.RewrittenCommits = rw.rewritten
+
+	// Blobs still referenced after compaction (e.g. identical content in a
+	// checkpoint that was kept) aren't reclaimed.
+	oldTip, err := s.repo.CommitObject(oldHead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit: %w", err)
+	}
+	oldTree, err := oldTip.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree: %w", err)
+	}
+	kept := make(map[string]object.TreeEntry)
+	if err := FlattenTree(s.repo, oldTree, "", kept); err != nil {
+		return nil, fmt.Errorf("failed to read tree: %w", err)
+	}
+	for p, e := range kept {
+		if cpID, ok := checkpointIDFromPath(p); ok && selected[id.CheckpointID(cpID)] && isTranscriptFile(path.Base(p)) {
+			continue
+		}
+		delete(dropped, e.Hash)
+	}
+	for hash := range dropped {
+		size, sizeErr := s.repo.Storer.EncodedObjectSize(hash)
+		if sizeErr != nil {
+			continue
+		}
+		result.Files++
+		result.ReclaimedBytes += size
+	}
+
+	for cpID := range compacted {
+		result.Checkpoints = append(result.Checkpoints, cpID)
+	}
+	sort.Slice(result.Checkpoints, func(i, j int) bool {
+		return result.Checkpoints[i] < result.Checkpoints[j]
+	})
+
+	if dryRun {
+		return result, nil
+	}
+
+	newTip, err := s.repo.CommitObject(newHead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rewritten commit: %w", err)
+	}
+	newHead, err = s.markCompacted(newTip, compacted, policy.now())
+	if err != nil {
+		return nil, err
+	}
+	if newHead == oldHead {
+		return result, nil
+	}
+
+	// Logged like a purge, so clones with the old history can't merge the
+	// removed transcripts back
+	record := PurgeRecord{
+		PurgedAt:      policy.now().UTC(),
+		Command:       PurgeCommandGC,
+		RewrittenFrom: oldHead.String(),
+		Checkpoints:   result.Checkpoints,
+	}
+	if name, email := GetGitAuthorFromRepo(s.repo); name != "" {
+		record.PurgedBy = fmt.Sprintf("%s <%s>", name, email)
+	}
+	if newHead, err = s.appendPurgeRecord(newHead, record); err != nil {
+		return nil, err
+	}
+
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(refName, newHead)); err != nil {
+		return nil, fmt.Errorf("failed to set branch reference: %w", err)
+	}
+	result.NewHead = newHead
+	return result, nil
+}
+
+func (p RetentionPolicy) now() time.Time {
+	if p.Now.IsZero() {
+		return time.Now()
+	}
+	return p.Now
+}
+
+// selectForCompaction returns the checkpoints policy selects.
+func selectForCompaction(entries []IndexEntry, policy RetentionPolicy) map[id.CheckpointID]bool {
+	selected := make(map[id.CheckpointID]bool)
+	cutoff := policy.now().Add(-policy.MaxAge)
+
+	byBranch := make(map[string][]IndexEntry)
+	for _, e := range entries {
+		if policy.MaxAge > 0 && e.CreatedAt.Before(cutoff) {
+			selected[e.CheckpointID] = true
+		}
+		byBranch[e.Branch] = append(byBranch[e.Branch], e)
+	}
+
+	if policy.MaxPerBranch > 0 {
+		for _, branchEntries := range byBranch {
+			sort.Slice(branchEntries, func(i, j int) bool {
+				return branchEntries[i].CreatedAt.After(branchEntries[j].CreatedAt)
+			})
+			for _, e := range branchEntries[min(policy.MaxPerBranch, len(branchEntries)):] {
+				selected[e.CheckpointID] = true
+			}
+		}
+	}
+	return selected
+}
+
+// isTranscriptFile reports whether a file in a checkpoint directory is
+// transcript content that compaction removes: the session transcript and its
+// chunks, the transcript content hash, and subagent transcripts of task checkpoints.
+func isTranscriptFile(name string) bool {
+	switch {
+	case name == paths.TranscriptFileNameLegacy, name == paths.ContentHashFileName:
+		return true
+	case agent.ParseChunkIndex(name, paths.TranscriptFileName) >= 0:
+		return true
+	default:
+		return strings.HasSuffix(name, ".jsonl")
+	}
+}
+
+// markCompacted commits CompactedAt into the session metadata of the compacted
+// checkpoints that aren't marked yet. Returns tip's hash when nothing changed.
+func (s *GitStore) markCompacted(tip *object.Commit, compacted map[id.CheckpointID]bool, now time.Time) (plumbing.Hash, error) {
+	tree, err := tip.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read tree: %w", err)
+	}
+
+	var changes []TreeChange
+	for cpID := range compacted {
+		cpPath := cpID.Path()
+		cpTree, err := tree.Tree(cpPath)
+		if err != nil {
+			continue // Checkpoint no longer exists at the tip
+		}
+		for _, entry := range cpTree.Entries {
+			if entry.Mode != filemode.Dir {
+				continue
+			}
+			metadataPath := cpPath + "/" + entry.Name + "/" + paths.MetadataFileName
+			file, err := tree.File(metadataPath)
+			if err != nil {
+				continue // Not a session directory
+			}
+			metadata, err := s.readMetadataFromBlob(file.Hash)
+			if err != nil {
+				return plumbing.ZeroHash, fmt.Errorf("failed to read %s: %w", metadataPath, err)
+			}
+			if metadata.CompactedAt != nil {
+				continue
+			}
+			compactedAt := now.UTC()
+			metadata.CompactedAt = &compactedAt
+			metadataJSON, err := jsonutil.MarshalIndentWithNewline(metadata, "", "  ")
+			if err != nil {
+				return plumbing.ZeroHash, fmt.Errorf("failed to marshal metadata: %w", err)
+			}
+			blobHash, err := CreateBlobFromContent(s.repo, metadataJSON)
+			if err != nil {
+				return plumbing.ZeroHash, err
+			}
+			changes = append(changes, TreeChange{
+				Path:  metadataPath,
+				Entry: &object.TreeEntry{Name: paths.MetadataFileName, Mode: filemode.Regular, Hash: blobHash},
+			})
+		}
+	}
+	if len(changes) == 0 {
+		return tip.Hash, nil
+	}
+
+	newTreeHash, err := ApplyTreeChanges(s.repo, tip.TreeHash, changes)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to update tree: %w", err)
+	}
+	authorName, authorEmail := GetGitAuthorFromRepo(s.repo)
+	message := fmt.Sprintf("Compact %d checkpoint session(s)\n", len(changes))
+	return s.createCommit(newTreeHash, tip.Hash, message, authorName, authorEmail)
+}
+
+// DropCompactedTranscripts removes transcript files from merged, the union of
+// the flattened trees in sides, for every checkpoint that one of the sides has
+// compacted. Without this, merging a copy of the branch from before
+// 'entire gc' ran would bring the removed transcripts back.
+//
+// Only session metadata that differs between the sides is read, so the cost
+// is proportional to the checkpoints that actually diverged.
+func DropCompactedTranscripts(repo *git.Repository, merged map[string]object.TreeEntry, sides ...map[string]object.TreeEntry) error {
+	compacted := make(map[string]bool)
+	for p := range merged {
+		parts := strings.Split(p, "/")
+		if len(parts) != 4 || len(parts[0]) != 2 || parts[3] != paths.MetadataFileName {
+			continue
+		}
+		var versions []object.TreeEntry
+		for _, side := range sides {
+			if e, ok := side[p]; ok {
+				versions = append(versions, e)
+			}
+		}
+		if len(versions) < 2 || !hashesDiffer(versions) {
+			continue
+		}
+		for _, e := range versions {
+			metadata, err := readJSONFromBlob[CommittedMetadata](repo, e.Hash)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", p, err)
+			}
+			if metadata.CompactedAt != nil {
+				merged[p] = e
+				compacted[parts[0]+"/"+parts[1]] = true
+				break
+			}
+		}
+	}
+	if len(compacted) == 0 {
+		return nil
+	}
+
+	for p := range merged {
+		parts := strings.SplitN(p, "/", 3)
+		if len(parts) == 3 && compacted[parts[0]+"/"+parts[1]] && isTranscriptFile(path.Base(p)) {
+			delete(merged, p)
+		}
+	}
+	return nil
+}
+
+func hashesDiffer(entries []object.TreeEntry) bool {
+	for _, e := range entries[1:] {
+		if e.Hash != entries[0].Hash {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestCompact(t *testing.T) {
+	t.Parallel()
+	repo, store, oldID := setupRepoForUpdate(t)
+	ctx := context.Background()
+
+	// setupRepoForUpdate wrote oldID on main; add a newer one on main and one
+	// on another branch.
+	newID := id.MustCheckpointID("b1b2c3d4e5f6")
+	featureID := id.MustCheckpointID("c1b2c3d4e5f6")
+	for _, cp := range []struct {
+		id     id.CheckpointID
+		branch string
+	}{{newID, "main"}, {featureID, "feature"}} {
+		if err := store.WriteCommitted(ctx, WriteCommittedOptions{
+			CheckpointID: cp.id,
+			SessionID:    "session-" + cp.id.String(),
+			Strategy:     "manual-commit",
+			Branch:       cp.branch,
+			Transcript:   []byte("transcript of " + cp.id.String() + "\n"),
+			Prompts:      []string{"prompt"},
+			AuthorName:   "Other",
+			AuthorEmail:  "other@test.com",
+		}); err != nil {
+			t.Fatalf("WriteCommitted() error = %v", err)
+		}
+	}
+	// oldID was written without a branch; put it on main with newID.
+	entries, err := store.ListIndexed(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range entries {
+		if entries[i].Branch == "" {
+			entries[i].Branch = "main"
+		}
+	}
+	policy := RetentionPolicy{MaxPerBranch: 1}
+	selected := selectForCompaction(entries, policy)
+	if len(selected) != 1 || !selected[oldID] {
+		t.Fatalf("selectForCompaction() = %v, want only %s", selected, oldID)
+	}
+	if got := selectForCompaction(entries, RetentionPolicy{MaxAge: time.Hour, Now: time.Now().Add(2 * time.Hour)}); len(got) != 3 {
+		t.Errorf("selectForCompaction() by age selected %d checkpoints, want 3", len(got))
+	}
+
+	// Compact by age only the first checkpoint, which is the oldest.
+	oldInfo, err := store.LookupIndexed(ctx, oldID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy = RetentionPolicy{MaxAge: time.Hour, Now: oldInfo.CreatedAt.Add(time.Hour + time.Nanosecond)}
+
+	oldHead := branchHead(t, repo)
+	dry, err := store.Compact(ctx, policy, true)
+	if err != nil {
+		t.Fatalf("Compact(dry run) error = %v", err)
+	}
+	if len(dry.Checkpoints) != 1 || dry.Checkpoints[0] != oldID || dry.Files == 0 || dry.ReclaimedBytes == 0 {
+		t.Fatalf("Compact(dry run) = %+v, want %s with files to reclaim", dry, oldID)
+	}
+	if branchHead(t, repo) != oldHead {
+		t.Fatal("dry run changed the branch")
+	}
+
+	result, err := store.Compact(ctx, policy, false)
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if result.Files != dry.Files || result.ReclaimedBytes != dry.ReclaimedBytes {
+		t.Errorf("Compact() reclaimed %d files/%d bytes, dry run reported %d/%d",
+			result.Files, result.ReclaimedBytes, dry.Files, dry.ReclaimedBytes)
+	}
+	if result.NewHead == oldHead || branchHead(t, repo) != result.NewHead {
+		t.Fatal("Compact() did not replace the branch")
+	}
+
+	content, err := store.ReadSessionContent(ctx, oldID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if len(content.Transcript) != 0 || content.Metadata.CompactedAt == nil || content.Prompts != "initial prompt" {
+		t.Errorf("compacted checkpoint: transcript %q, compacted_at %v, prompts %q", content.Transcript, content.Metadata.CompactedAt, content.Prompts)
+	}
+	for _, kept := range []id.CheckpointID{newID, featureID} {
+		if c, err := store.ReadSessionContent(ctx, kept, 0); err != nil || len(c.Transcript) == 0 {
+			t.Errorf("checkpoint %s lost its transcript: %v", kept, err)
+		}
+	}
+
+	// No commit in the rewritten history still has the transcript, and the
+	// checkpoint's author is preserved.
+	transcriptPath := oldID.Path() + "/0/" + paths.TranscriptFileName
+	iter, err := repo.Log(&git.LogOptions{From: result.NewHead})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = iter.ForEach(func(c *object.Commit) error {
+		tree, err := c.Tree()
+		if err != nil {
+			return err
+		}
+		if _, err := tree.File(transcriptPath); err == nil {
+			t.Errorf("commit %s still contains %s", c.Hash, transcriptPath)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if author, err := store.GetCheckpointAuthor(ctx, oldID); err != nil || author.Name != "Test" {
+		t.Errorf("GetCheckpointAuthor() = %+v, %v; want the original author", author, err)
+	}
+
+	// Running again has nothing to do.
+	again, err := store.Compact(ctx, policy, false)
+	if err != nil {
+		t.Fatalf("Compact() second run error = %v", err)
+	}
+	if len(again.Checkpoints) != 0 || again.NewHead != result.NewHead {
+		t.Errorf("Compact() second run = %+v, want no changes", again)
+	}
+
+	// The rewrite is logged, so the old history can't be merged back.
+	var purgedErr *PurgedHistoryError
+	if err := CheckPurgedHistory(repo, result.NewHead, oldHead); !errors.As(err, &purgedErr) || !purgedErr.Local || purgedErr.Record.Command != PurgeCommandGC {
+		t.Errorf("CheckPurgedHistory(compacted, stale) = %v, want a local *PurgedHistoryError from gc", err)
+	}
+	if err := CheckPurgedHistory(repo, oldHead, result.NewHead); !errors.As(err, &purgedErr) || purgedErr.Local {
+		t.Errorf("CheckPurgedHistory(stale, compacted) = %v, want a remote *PurgedHistoryError", err)
+	}
+
+	// Even merged trees don't restore the transcript.
+	local, remote := flattenCommit(t, repo, result.NewHead), flattenCommit(t, repo, oldHead)
+	merged := make(map[string]object.TreeEntry)
+	for p, e := range local {
+		merged[p] = e
+	}
+	for p, e := range remote {
+		merged[p] = e
+	}
+	if err := DropCompactedTranscripts(repo, merged, local, remote); err != nil {
+		t.Fatalf("DropCompactedTranscripts() error = %v", err)
+	}
+	for p := range merged {
+		if strings.HasPrefix(p, oldID.Path()+"/") && isTranscriptFile(p[strings.LastIndex(p, "/")+1:]) {
+			t.Errorf("merge restored %s", p)
+		}
+	}
+	if merged[oldID.Path()+"/0/"+paths.MetadataFileName] != local[oldID.Path()+"/0/"+paths.MetadataFileName] {
+		t.Error("merge did not keep the compacted metadata")
+	}
+}
+
+func branchHead(t *testing.T, repo *git.Repository) plumbing.Hash {
+	t.Helper()
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref.Hash()
+}
+
+func flattenCommit(t *testing.T, repo *git.Repository, hash plumbing.Hash) map[string]object.TreeEntry {
+	t.Helper()
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := make(map[string]object.TreeEntry)
+	if err := FlattenTree(repo, tree, "", entries); err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
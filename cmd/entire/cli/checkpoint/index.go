@@ -0,0 +1,415 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// IndexFileName is the name of the local checkpoint index file inside the git directory.
+const IndexFileName = "entire-checkpoint-index.json"
+
+// indexVersion is bumped whenever the on-disk index format changes.
+// Indexes with a different version are discarded and rebuilt.
+const indexVersion = 2
+
+// errIndexUnavailable is returned when the repository has no on-disk git directory
+// (e.g., in-memory storage), so there is nowhere to persist the index.
+var errIndexUnavailable = errors.New("checkpoint index unavailable")
+
+// IndexEntry is the indexed view of a committed checkpoint.
+// It holds the fields needed to list and filter checkpoints without reading
+// every metadata.json blob on the entire/checkpoints/v1 branch.
+type IndexEntry struct {
+	CheckpointID     id.CheckpointID   `json:"checkpoint_id"`
+	SessionIDs       []string          `json:"session_ids,omitempty"` // In session index order (oldest first)
+	Agent            agent.AgentType   `json:"agent,omitempty"`       // Agent of the latest session
+	Branch           string            `json:"branch,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"` // Creation time of the latest session
+	CheckpointsCount int               `json:"checkpoints_count"`
+	FilesTouched     []string          `json:"files_touched,omitempty"`
+	IsTask           bool              `json:"is_task,omitempty"`
+	ToolUseID        string            `json:"tool_use_id,omitempty"`
+	TokenUsage       *agent.TokenUsage `json:"token_usage,omitempty"`
+	Intent           string            `json:"intent,omitempty"`  // Summary intent of the latest session that has one
+	Commits          []string          `json:"commits,omitempty"` // Commits rewritten from the linked commit
+}
+
+// SessionID returns the ID of the latest session in the checkpoint.
+func (e *IndexEntry) SessionID() string {
+	if len(e.SessionIDs) == 0 {
+		return ""
+	}
+	return e.SessionIDs[len(e.SessionIDs)-1]
+}
+
+// CommittedInfo converts the entry to the CommittedInfo returned by ListCommitted.
+func (e *IndexEntry) CommittedInfo() CommittedInfo {
+	return CommittedInfo{
+		CheckpointID:     e.CheckpointID,
+		SessionID:        e.SessionID(),
+		CreatedAt:        e.CreatedAt,
+		CheckpointsCount: e.CheckpointsCount,
+		FilesTouched:     e.FilesTouched,
+		Agent:            e.Agent,
+		IsTask:           e.IsTask,
+		ToolUseID:        e.ToolUseID,
+		SessionCount:     len(e.SessionIDs),
+		SessionIDs:       e.SessionIDs,
+	}
+}
+
+// checkpointIndex is the on-disk index format.
+// Tip is the entire/checkpoints/v1 commit the entries were built from.
+type checkpointIndex struct {
+	Version int                   `json:"version"`
+	Tip     string                `json:"tip"`
+	Entries map[string]IndexEntry `json:"entries"`
+}
+
+// ListIndexed returns index entries for all committed checkpoints, most recent first.
+// The index is brought up to date with the current entire/checkpoints/v1 tip before
+// returning: new metadata commits are applied incrementally, and the index is rebuilt
+// from a full scan if it is missing, corrupt, or the old tip is no longer reachable.
+func (s *GitStore) ListIndexed(ctx context.Context) ([]IndexEntry, error) {
+	idx, err := s.loadIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]IndexEntry, 0, len(idx.Entries))
+	for _, e := range idx.Entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
+
+// LookupIndexed returns the index entry for a checkpoint.
+// Returns nil, nil if the checkpoint is not in the index.
+func (s *GitStore) LookupIndexed(ctx context.Context, checkpointID id.CheckpointID) (*IndexEntry, error) {
+	idx, err := s.loadIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := idx.Entries[checkpointID.String()]
+	if !ok {
+		return nil, nil //nolint:nilnil // Not found is not an error
+	}
+	return &entry, nil
+}
+
+// ListCommittedIndexed is like ListCommitted but served from the local index.
+// Falls back to a full scan via ListCommitted if the index cannot be used.
+func (s *GitStore) ListCommittedIndexed(ctx context.Context) ([]CommittedInfo, error) {
+	entries, err := s.ListIndexed(ctx)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr //nolint:wrapcheck // Propagating context cancellation
+		}
+		if !errors.Is(err, errIndexUnavailable) {
+			logging.Debug(ctx, "checkpoint index unusable, falling back to full scan",
+				slog.String("error", err.Error()),
+			)
+		}
+		return s.ListCommitted(ctx)
+	}
+
+	infos := make([]CommittedInfo, 0, len(entries))
+	for i := range entries {
+		infos = append(infos, entries[i].CommittedInfo())
+	}
+	return infos, nil
+}
+
+// indexPath returns the path of the index file inside the repository's git directory.
+func (s *GitStore) indexPath() (string, error) {
+	fsStorage, ok := s.repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return "", errIndexUnavailable
+	}
+	return filepath.Join(fsStorage.Filesystem().Root(), IndexFileName), nil
+}
+
+// sessionsBranchTip returns the commit the index should be built from.
+// Mirrors getSessionsBranchTree: the local branch, falling back to origin.
+// Returns ZeroHash if neither exists.
+func (s *GitStore) sessionsBranchTip() plumbing.Hash {
+	ref, err := s.repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	if err != nil {
+		ref, err = s.repo.Reference(plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName), true)
+		if err != nil {
+			return plumbing.ZeroHash
+		}
+	}
+	return ref.Hash()
+}
+
+// loadIndex reads the index from disk and brings it up to date with the sessions branch tip.
+// The updated index is written back to disk (best-effort).
+func (s *GitStore) loadIndex(ctx context.Context) (*checkpointIndex, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck // Propagating context cancellation
+	}
+
+	indexPath, err := s.indexPath()
+	if err != nil {
+		return nil, err
+	}
+
+	tip := s.sessionsBranchTip()
+	if tip == plumbing.ZeroHash {
+		return &checkpointIndex{Version: indexVersion, Entries: map[string]IndexEntry{}}, nil
+	}
+
+	idx := readIndexFile(ctx, indexPath)
+	if idx != nil && idx.Tip == tip.String() {
+		return idx, nil
+	}
+
+	tipCommit, err := s.repo.CommitObject(tip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions branch commit: %w", err)
+	}
+	tipTree, err := tipCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions branch tree: %w", err)
+	}
+
+	updated := false
+	if idx != nil {
+		updated = s.updateIndexIncremental(ctx, idx, tipTree)
+	}
+	if !updated {
+		idx = &checkpointIndex{Version: indexVersion, Entries: map[string]IndexEntry{}}
+		if err := s.rebuildIndex(ctx, idx, tipTree); err != nil {
+			return nil, err
+		}
+	}
+	idx.Tip = tip.String()
+
+	if err := writeIndexFile(indexPath, idx); err != nil {
+		logging.Debug(ctx, "failed to write checkpoint index",
+			slog.String("path", indexPath),
+			slog.String("error", err.Error()),
+		)
+	}
+	return idx, nil
+}
+
+// readIndexFile reads the index from disk.
+// Returns nil if the file is missing, unreadable, corrupt, or from another format version.
+func readIndexFile(ctx context.Context, indexPath string) *checkpointIndex {
+	data, err := os.ReadFile(indexPath) //nolint:gosec // Path is inside the git directory
+	if err != nil {
+		return nil
+	}
+	var idx checkpointIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		logging.Debug(ctx, "checkpoint index is corrupt, rebuilding",
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+	if idx.Version != indexVersion || idx.Entries == nil {
+		return nil
+	}
+	return &idx
+}
+
+// writeIndexFile writes the index atomically via a temp file and rename.
+func writeIndexFile(indexPath string, idx *checkpointIndex) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint index: %w", err)
+	}
+	// A unique temp file keeps concurrent writers from clobbering each other's
+	// partial writes; the last rename wins.
+	tmpFile, err := os.CreateTemp(filepath.Dir(indexPath), filepath.Base(indexPath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint index temp file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name()) //nolint:errcheck // Best-effort cleanup; gone after a successful rename
+
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("failed to write checkpoint index: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint index: %w", err)
+	}
+	if err := os.Rename(tmpFile.Name(), indexPath); err != nil {
+		return fmt.Errorf("failed to rename checkpoint index: %w", err)
+	}
+	return nil
+}
+
+// updateIndexIncremental applies the checkpoints changed between the indexed tip and
+// newTree. Returns false if the indexed tip is unknown (e.g., the branch was rewritten
+// or garbage collected), in which case the caller should rebuild from scratch.
+func (s *GitStore) updateIndexIncremental(ctx context.Context, idx *checkpointIndex, newTree *object.Tree) bool {
+	oldCommit, err := s.repo.CommitObject(plumbing.NewHash(idx.Tip))
+	if err != nil {
+		return false
+	}
+	oldTree, err := oldCommit.Tree()
+	if err != nil {
+		return false
+	}
+
+	changes, err := object.DiffTreeContext(ctx, oldTree, newTree)
+	if err != nil {
+		return false
+	}
+
+	changed := make(map[string]struct{})
+	for _, change := range changes {
+		for _, name := range []string{change.From.Name, change.To.Name} {
+			if cpID, ok := checkpointIDFromPath(name); ok {
+				changed[cpID] = struct{}{}
+			}
+		}
+	}
+
+	for cpIDStr := range changed {
+		cpID, err := id.NewCheckpointID(cpIDStr)
+		if err != nil {
+			continue
+		}
+		cpTree, err := newTree.Tree(cpID.Path())
+		if err != nil {
+			// Checkpoint directory removed
+			delete(idx.Entries, cpIDStr)
+			continue
+		}
+		if entry, ok := s.readIndexEntry(cpTree, cpID); ok {
+			idx.Entries[cpIDStr] = entry
+		} else {
+			delete(idx.Entries, cpIDStr)
+		}
+	}
+	return true
+}
+
+// rebuildIndex populates idx from a full scan of the sharded checkpoint tree.
+func (s *GitStore) rebuildIndex(ctx context.Context, idx *checkpointIndex, tree *object.Tree) error {
+	for _, bucketEntry := range tree.Entries {
+		if bucketEntry.Mode != filemode.Dir || len(bucketEntry.Name) != 2 {
+			continue
+		}
+		bucketTree, err := s.repo.TreeObject(bucketEntry.Hash)
+		if err != nil {
+			continue
+		}
+		for _, checkpointEntry := range bucketTree.Entries {
+			if err := ctx.Err(); err != nil {
+				return err //nolint:wrapcheck // Propagating context cancellation
+			}
+			if checkpointEntry.Mode != filemode.Dir {
+				continue
+			}
+			cpID, err := id.NewCheckpointID(bucketEntry.Name + checkpointEntry.Name)
+			if err != nil {
+				continue
+			}
+			cpTree, err := s.repo.TreeObject(checkpointEntry.Hash)
+			if err != nil {
+				continue
+			}
+			if entry, ok := s.readIndexEntry(cpTree, cpID); ok {
+				idx.Entries[cpID.String()] = entry
+			}
+		}
+	}
+	return nil
+}
+
+// readIndexEntry builds an index entry from a checkpoint directory tree.
+// Returns false if the directory has no readable root metadata.json.
+func (s *GitStore) readIndexEntry(cpTree *object.Tree, cpID id.CheckpointID) (IndexEntry, bool) {
+	file, err := cpTree.File(paths.MetadataFileName)
+	if err != nil {
+		return IndexEntry{}, false
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return IndexEntry{}, false
+	}
+	var summary CheckpointSummary
+	if err := json.Unmarshal([]byte(content), &summary); err != nil {
+		return IndexEntry{}, false
+	}
+
+	entry := IndexEntry{
+		CheckpointID:     cpID,
+		Branch:           summary.Branch,
+		CheckpointsCount: summary.CheckpointsCount,
+		FilesTouched:     summary.FilesTouched,
+		TokenUsage:       summary.TokenUsage,
+		Commits:          summary.Commits,
+	}
+
+	for i := range len(summary.Sessions) {
+		sessionTree, err := cpTree.Tree(strconv.Itoa(i))
+		if err != nil {
+			continue
+		}
+		metadataFile, err := sessionTree.File(paths.MetadataFileName)
+		if err != nil {
+			continue
+		}
+		metadataContent, err := metadataFile.Contents()
+		if err != nil {
+			continue
+		}
+		var meta CommittedMetadata
+		if err := json.Unmarshal([]byte(metadataContent), &meta); err != nil {
+			continue
+		}
+
+		entry.SessionIDs = append(entry.SessionIDs, meta.SessionID)
+		// Latest session wins, matching ListCommitted
+		entry.Agent = meta.Agent
+		entry.CreatedAt = meta.CreatedAt
+		entry.IsTask = meta.IsTask
+		entry.ToolUseID = meta.ToolUseID
+		if entry.Branch == "" {
+			entry.Branch = meta.Branch
+		}
+		if meta.Summary != nil && meta.Summary.Intent != "" {
+			entry.Intent = meta.Summary.Intent
+		}
+	}
+
+	return entry, true
+}
+
+// checkpointIDFromPath extracts the checkpoint ID from a sharded tree path
+// (<id[:2]>/<id[2:]>/...). Returns false for paths outside a checkpoint directory.
+func checkpointIDFromPath(p string) (string, bool) {
+	parts := strings.SplitN(p, "/", 3)
+	if len(parts) < 3 || len(parts[0]) != 2 {
+		return "", false
+	}
+	return parts[0] + parts[1], true
+}
@@ -0,0 +1,238 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+func writeIndexTestCheckpoint(t *testing.T, store *GitStore, cpIDStr, sessionID string, summary *Summary) {
+	t.Helper()
+	err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID:     id.MustCheckpointID(cpIDStr),
+		SessionID:        sessionID,
+		Strategy:         "manual-commit",
+		Branch:           "feature",
+		Agent:            agent.AgentTypeClaudeCode,
+		Transcript:       []byte(`{"type":"user"}` + "\n"),
+		FilesTouched:     []string{"main.go"},
+		CheckpointsCount: 1,
+		TokenUsage:       &agent.TokenUsage{InputTokens: 10, OutputTokens: 5},
+		Summary:          summary,
+		AuthorName:       "Test Author",
+		AuthorEmail:      "test@example.com",
+	})
+	if err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+}
+
+func readIndexTip(t *testing.T, store *GitStore) string {
+	t.Helper()
+	indexPath, err := store.indexPath()
+	if err != nil {
+		t.Fatalf("indexPath() error = %v", err)
+	}
+	idx := readIndexFile(context.Background(), indexPath)
+	if idx == nil {
+		t.Fatal("expected index file to exist and be valid")
+	}
+	return idx.Tip
+}
+
+func TestListIndexed_BuildsAndPersistsIndex(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	writeIndexTestCheckpoint(t, store, "a1a2a3a4a5a6", "session-1", &Summary{Intent: "Add login"})
+
+	entries, err := store.ListIndexed(context.Background())
+	if err != nil {
+		t.Fatalf("ListIndexed() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.SessionID() != "session-1" || e.Agent != agent.AgentTypeClaudeCode || e.Branch != "feature" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if e.Intent != "Add login" {
+		t.Errorf("Intent = %q, want %q", e.Intent, "Add login")
+	}
+	if e.TokenUsage == nil || e.TokenUsage.InputTokens != 10 {
+		t.Errorf("TokenUsage = %+v, want InputTokens 10", e.TokenUsage)
+	}
+	if len(e.FilesTouched) != 1 || e.FilesTouched[0] != "main.go" {
+		t.Errorf("FilesTouched = %v, want [main.go]", e.FilesTouched)
+	}
+
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	if err != nil {
+		t.Fatalf("failed to get metadata branch: %v", err)
+	}
+	if tip := readIndexTip(t, store); tip != ref.Hash().String() {
+		t.Errorf("index tip = %s, want %s", tip, ref.Hash())
+	}
+}
+
+func TestListIndexed_IncrementalUpdate(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	writeIndexTestCheckpoint(t, store, "b1b2b3b4b5b6", "session-1", nil)
+
+	if _, err := store.ListIndexed(context.Background()); err != nil {
+		t.Fatalf("ListIndexed() error = %v", err)
+	}
+
+	// A new checkpoint and a second session on the existing checkpoint
+	writeIndexTestCheckpoint(t, store, "c1c2c3c4c5c6", "session-2", nil)
+	writeIndexTestCheckpoint(t, store, "b1b2b3b4b5b6", "session-3", &Summary{Intent: "Follow-up"})
+
+	entries, err := store.ListIndexed(context.Background())
+	if err != nil {
+		t.Fatalf("ListIndexed() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	entry, err := store.LookupIndexed(context.Background(), id.MustCheckpointID("b1b2b3b4b5b6"))
+	if err != nil {
+		t.Fatalf("LookupIndexed() error = %v", err)
+	}
+	if entry == nil {
+		t.Fatal("expected entry for b1b2b3b4b5b6")
+	}
+	if len(entry.SessionIDs) != 2 || entry.SessionIDs[1] != "session-3" {
+		t.Errorf("SessionIDs = %v, want [session-1 session-3]", entry.SessionIDs)
+	}
+	if entry.Intent != "Follow-up" {
+		t.Errorf("Intent = %q, want %q", entry.Intent, "Follow-up")
+	}
+}
+
+func TestListIndexed_RebuildsCorruptIndex(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	writeIndexTestCheckpoint(t, store, "d1d2d3d4d5d6", "session-1", nil)
+
+	indexPath, err := store.indexPath()
+	if err != nil {
+		t.Fatalf("indexPath() error = %v", err)
+	}
+	if err := os.WriteFile(indexPath, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("failed to write corrupt index: %v", err)
+	}
+
+	entries, err := store.ListIndexed(context.Background())
+	if err != nil {
+		t.Fatalf("ListIndexed() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry after rebuild, got %d", len(entries))
+	}
+
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatalf("failed to read index: %v", err)
+	}
+	var idx checkpointIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		t.Errorf("expected index to be rewritten as valid JSON: %v", err)
+	}
+}
+
+func TestListIndexed_RebuildsWhenTipUnknown(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	writeIndexTestCheckpoint(t, store, "e1e2e3e4e5e6", "session-1", nil)
+
+	indexPath, err := store.indexPath()
+	if err != nil {
+		t.Fatalf("indexPath() error = %v", err)
+	}
+	// Simulate an index built from a tip that no longer exists (e.g., rewritten branch)
+	stale := checkpointIndex{
+		Version: indexVersion,
+		Tip:     "0123456789abcdef0123456789abcdef01234567",
+		Entries: map[string]IndexEntry{"ffffffffffff": {CheckpointID: id.MustCheckpointID("ffffffffffff")}},
+	}
+	if err := writeIndexFile(indexPath, &stale); err != nil {
+		t.Fatalf("writeIndexFile() error = %v", err)
+	}
+
+	entries, err := store.ListIndexed(context.Background())
+	if err != nil {
+		t.Fatalf("ListIndexed() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].CheckpointID.String() != "e1e2e3e4e5e6" {
+		t.Errorf("expected stale entries to be discarded, got %+v", entries)
+	}
+}
+
+func TestWriteIndexFile_LeavesNoTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	indexPath := filepath.Join(dir, "index.json")
+	for range 2 {
+		if err := writeIndexFile(indexPath, &checkpointIndex{Version: indexVersion, Entries: map[string]IndexEntry{}}); err != nil {
+			t.Fatalf("writeIndexFile() error = %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read index dir: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "index.json" {
+		t.Errorf("index dir = %v, want only index.json", entries)
+	}
+}
+
+func TestListCommittedIndexed_FallsBackWithoutGitDir(t *testing.T) {
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		t.Fatalf("failed to init in-memory repo: %v", err)
+	}
+	store := NewGitStore(repo)
+
+	if _, err := store.ListIndexed(context.Background()); err == nil {
+		t.Error("expected ListIndexed to fail without an on-disk git directory")
+	}
+	infos, err := store.ListCommittedIndexed(context.Background())
+	if err != nil {
+		t.Fatalf("ListCommittedIndexed() error = %v", err)
+	}
+	if len(infos) != 0 {
+		t.Errorf("expected no checkpoints, got %d", len(infos))
+	}
+}
+
+func TestCheckpointIDFromPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{"a1/b2c3d4e5f6/metadata.json", "a1b2c3d4e5f6", true},
+		{"a1/b2c3d4e5f6/0/full.jsonl", "a1b2c3d4e5f6", true},
+		{"a1/b2c3d4e5f6", "", false},
+		{"README.md", "", false},
+		{"abc/def/metadata.json", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := checkpointIDFromPath(tt.path)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("checkpointIDFromPath(%q) = (%q, %v), want (%q, %v)", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,680 @@
+package checkpoint
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/encryption"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/redact"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// PurgeOptions selects what Purge removes from the metadata branch history.
+type PurgeOptions struct {
+	// Pattern is replaced with REDACTED in every file of every checkpoint,
+	// including metadata.json, in commit messages, and in the checkpoint notes
+	// under refs/notes/entire. Transcripts are reassembled before matching and
+	// chunked again afterwards.
+	Pattern *regexp.Regexp
+
+	// CheckpointIDs and SessionIDs select checkpoints and sessions whose
+	// transcripts, prompts and context are removed. metadata.json is kept so
+	// commits still link to the checkpoint.
+	CheckpointIDs []id.CheckpointID
+	SessionIDs    []string
+
+	// DryRun reports what would change without writing anything.
+	DryRun bool
+}
+
+// PurgeResult describes what Purge changed (or would change, for a dry run).
+type PurgeResult struct {
+	// Checkpoints are the checkpoints whose content changed somewhere in history.
+	Checkpoints []id.CheckpointID
+
+	// Files is the number of distinct file versions scrubbed or removed.
+	Files int
+
+	// RewrittenCommits is the number of commits on the branch that were rewritten.
+	RewrittenCommits int
+
+	// OldHead and NewHead are the branch tip before and after the purge.
+	// They are equal when nothing matched.
+	OldHead plumbing.Hash
+	NewHead plumbing.Hash
+
+	// OldNotesHead and NewNotesHead are the refs/notes/entire tip before and
+	// after a pattern purge. They are equal when no note matched, and zero
+	// when there are no notes.
+	OldNotesHead plumbing.Hash
+	NewNotesHead plumbing.Hash
+}
+
+// PurgeCommandGC marks purge log records written by 'entire gc'.
+const PurgeCommandGC = "gc"
+
+// PurgeRecord is one line of the purge log (purge_log.jsonl at the root of
+// the metadata branch), written for every history rewrite by 'entire purge'
+// or 'entire gc'. Clones compare logs before merging so that a copy of the
+// branch from before the rewrite can't bring the removed content back.
+type PurgeRecord struct {
+	PurgedAt time.Time `json:"purged_at"`
+	PurgedBy string    `json:"purged_by,omitempty"`
+
+	// Command is PurgeCommandGC for a rewrite by 'entire gc', and empty for
+	// 'entire purge'.
+	Command string `json:"command,omitempty"`
+
+	// RewrittenFrom is the branch tip the purge rewrote. It identifies the record.
+	RewrittenFrom string `json:"rewritten_from"`
+
+	// Checkpoints are the checkpoints whose content changed.
+	Checkpoints []id.CheckpointID `json:"checkpoints"`
+
+	// PatternSHA256 identifies the pattern without storing it, since the
+	// pattern itself usually contains the secret.
+	PatternSHA256 string `json:"pattern_sha256,omitempty"`
+
+	// NotesRewrittenFrom is the refs/notes/entire tip the purge rewrote, when
+	// a note matched the pattern. Notes that still contain it are stale.
+	NotesRewrittenFrom string `json:"notes_rewritten_from,omitempty"`
+}
+
+// PurgedHistoryError is returned when merging two versions of the metadata
+// branch where one side was purged or compacted and the other still has the
+// removed content.
+type PurgedHistoryError struct {
+	Record PurgeRecord
+
+	// Local is true when the local branch was purged and the remote wasn't
+	// replaced yet; false when the remote was purged and the local branch
+	// predates it.
+	Local bool
+}
+
+func (e *PurgedHistoryError) Error() string {
+	purged, stale := "remote", "local branch"
+	if e.Local {
+		purged, stale = "local", "remote"
+	}
+	verb := "purged"
+	if e.Record.Command == PurgeCommandGC {
+		verb = "compacted by 'entire gc'"
+	}
+	return fmt.Sprintf("%s checkpoints were %s on %s and the %s still has the removed content",
+		purged, verb, e.Record.PurgedAt.Format("2006-01-02"), stale)
+}
+
+// Purge rewrites every commit on entire/checkpoints/v1 to scrub opts.Pattern
+// and remove the content of the selected checkpoints and sessions, rewrites
+// refs/notes/entire the same way for a pattern, then appends a record to the
+// purge log. Unlike UpdateCommitted, which only
+// replaces the latest tree, this removes the content from all of history.
+func (s *GitStore) Purge(ctx context.Context, opts PurgeOptions) (*PurgeResult, error) {
+	if opts.Pattern == nil && len(opts.CheckpointIDs) == 0 && len(opts.SessionIDs) == 0 {
+		return nil, errors.New("nothing to purge: a pattern, checkpoint or session is required")
+	}
+	oldHead, _, err := s.getSessionsBranchRef()
+	if err != nil {
+		return nil, err
+	}
+	result := &PurgeResult{OldHead: oldHead, NewHead: oldHead}
+
+	p := &purger{
+		ctx:               ctx,
+		repo:              s.repo,
+		pattern:           opts.Pattern,
+		removeCheckpoints: make(map[id.CheckpointID]bool),
+		removeSessions:    make(map[string]bool),
+		sessionDirs:       make(map[string]bool),
+		sessionIDs:        make(map[plumbing.Hash]string),
+		blobs:             make(map[plumbing.Hash]plumbing.Hash),
+		changed:           make(map[id.CheckpointID]bool),
+	}
+	for _, cpID := range opts.CheckpointIDs {
+		p.removeCheckpoints[cpID] = true
+	}
+	for _, sessionID := range opts.SessionIDs {
+		p.removeSessions[sessionID] = true
+	}
+	p.rw = newHistoryRewriter(s.repo, opts.DryRun)
+	p.rw.selects = func(cpID id.CheckpointID) bool {
+		// Any checkpoint may hold a selected session, in any commit
+		return p.removeCheckpoints[cpID] || len(p.removeSessions) > 0 || opts.Pattern != nil
+	}
+	p.rw.enterDir = p.enterDir
+	p.rw.rewriteDir = p.rewriteDir
+	if opts.Pattern != nil {
+		p.rw.rewriteMessage = func(message string) string {
+			return opts.Pattern.ReplaceAllLiteralString(message, redact.RedactedPlaceholder)
+		}
+	}
+
+	newHead, err := p.rw.rewriteCommit(ctx, oldHead)
+	if err != nil {
+		return nil, err
+	}
+	result.RewrittenCommits = p.rw.rewritten
+	for cpID := range p.changed {
+		result.Checkpoints = append(result.Checkpoints, cpID)
+	}
+	slices.Sort(result.Checkpoints)
+
+	// Notes repeat checkpoint intents, so they can hold the same secrets
+	notesRef := plumbing.ReferenceName(paths.CheckpointNotesRef)
+	if ref, refErr := s.repo.Reference(notesRef, true); refErr == nil && opts.Pattern != nil {
+		result.OldNotesHead = ref.Hash()
+		if result.NewNotesHead, err = p.scrubNotes(ctx, opts.DryRun, ref.Hash()); err != nil {
+			return nil, err
+		}
+	}
+	result.Files = p.files
+	notesChanged := result.NewNotesHead != result.OldNotesHead
+
+	if opts.DryRun || (newHead == oldHead && !notesChanged) {
+		return result, nil
+	}
+
+	record := PurgeRecord{
+		PurgedAt:      time.Now().UTC(),
+		RewrittenFrom: oldHead.String(),
+		Checkpoints:   result.Checkpoints,
+	}
+	if name, email := GetGitAuthorFromRepo(s.repo); name != "" {
+		record.PurgedBy = fmt.Sprintf("%s <%s>", name, email)
+	}
+	if opts.Pattern != nil {
+		sum := sha256.Sum256([]byte(opts.Pattern.String()))
+		record.PatternSHA256 = hex.EncodeToString(sum[:])
+	}
+	if notesChanged {
+		record.NotesRewrittenFrom = result.OldNotesHead.String()
+		if err := s.repo.Storer.SetReference(plumbing.NewHashReference(notesRef, result.NewNotesHead)); err != nil {
+			return nil, fmt.Errorf("failed to update %s: %w", paths.CheckpointNotesRef, err)
+		}
+	}
+	if newHead, err = s.appendPurgeRecord(newHead, record); err != nil {
+		return nil, err
+	}
+
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(refName, newHead)); err != nil {
+		return nil, fmt.Errorf("failed to set branch reference: %w", err)
+	}
+	result.NewHead = newHead
+	return result, nil
+}
+
+// purger holds the state of one Purge.
+type purger struct {
+	ctx     context.Context //nolint:containedctx // Needed by chunking inside historyRewriter callbacks
+	repo    *git.Repository
+	rw      *historyRewriter
+	pattern *regexp.Regexp
+
+	removeCheckpoints map[id.CheckpointID]bool
+	removeSessions    map[string]bool
+	sessionDirs       map[string]bool          // session directory -> holds a selected session
+	sessionIDs        map[plumbing.Hash]string // session metadata.json blob -> session ID
+
+	blobs   map[plumbing.Hash]plumbing.Hash // scrubbed blob cache
+	changed map[id.CheckpointID]bool
+	files   int
+}
+
+// isMetadataFile reports whether a checkpoint file is kept when a checkpoint
+// or session's content is removed.
+func isMetadataFile(name string) bool {
+	return name == paths.MetadataFileName || name == paths.CheckpointFileName
+}
+
+func (p *purger) rewriteDir(cpID id.CheckpointID, dir string, entries []object.TreeEntry) ([]object.TreeEntry, bool, error) {
+	if p.removes(cpID, dir) {
+		kept := make([]object.TreeEntry, 0, len(entries))
+		for _, entry := range entries {
+			if entry.Mode == filemode.Dir || isMetadataFile(entry.Name) {
+				kept = append(kept, entry)
+			}
+		}
+		if len(kept) == len(entries) {
+			return entries, false, nil
+		}
+		p.files += len(entries) - len(kept)
+		p.changed[cpID] = true
+		return kept, true, nil
+	}
+	if p.pattern == nil {
+		return entries, false, nil
+	}
+
+	out, changed, err := p.scrubTranscript(dir, entries)
+	if err != nil {
+		return nil, false, err
+	}
+	for i, entry := range out {
+		if entry.Mode == filemode.Dir || isChunkFile(entry.Name) || entry.Name == paths.ContentHashFileName {
+			continue
+		}
+		newHash, err := p.scrubBlob(dir+"/"+entry.Name, entry.Hash)
+		if err != nil {
+			return nil, false, err
+		}
+		if newHash != entry.Hash {
+			out[i].Hash = newHash
+			changed = true
+		}
+	}
+	if changed {
+		p.changed[cpID] = true
+	}
+	return out, changed, nil
+}
+
+// enterDir resolves which session a session directory ("<shard>/<id>/<n>")
+// holds from its own metadata.json, before anything inside it is rewritten.
+// The directory's position in the checkpoint index can't be used: the index
+// skips sessions whose metadata is unreadable.
+func (p *purger) enterDir(_ id.CheckpointID, dir string, tree *object.Tree) error {
+	if len(p.removeSessions) == 0 || strings.Count(dir, "/") != 2 {
+		return nil
+	}
+	p.sessionDirs[dir] = false
+	for _, entry := range tree.Entries {
+		if entry.Name != paths.MetadataFileName || entry.Mode == filemode.Dir {
+			continue
+		}
+		sessionID, ok := p.sessionIDs[entry.Hash]
+		if !ok {
+			// Unreadable metadata names no session, so nothing is selected
+			if metadata, err := readJSONFromBlob[CommittedMetadata](p.repo, entry.Hash); err == nil {
+				sessionID = metadata.SessionID
+			}
+			p.sessionIDs[entry.Hash] = sessionID
+		}
+		p.sessionDirs[dir] = p.removeSessions[sessionID]
+	}
+	return nil
+}
+
+// removes reports whether the content of dir is removed: dir is inside a
+// selected checkpoint, or inside a selected session's directory.
+func (p *purger) removes(cpID id.CheckpointID, dir string) bool {
+	if p.removeCheckpoints[cpID] {
+		return true
+	}
+	parts := strings.SplitN(dir, "/", 4)
+	return len(parts) >= 3 && p.sessionDirs[strings.Join(parts[:3], "/")]
+}
+
+func isChunkFile(name string) bool {
+	return agent.ParseChunkIndex(name, paths.TranscriptFileName) >= 0
+}
+
+// scrubTranscript reassembles a chunked transcript in entries, scrubs it as a
+// whole so matches spanning chunks are found, and chunks it again with
+// agent.ChunkTranscript. The content hash is recomputed. Returns entries
+// unchanged when there is no transcript or nothing matched.
+func (p *purger) scrubTranscript(dir string, entries []object.TreeEntry) ([]object.TreeEntry, bool, error) {
+	var chunkNames []string
+	byName := make(map[string]object.TreeEntry, len(entries))
+	for _, entry := range entries {
+		byName[entry.Name] = entry
+		if entry.Mode != filemode.Dir && isChunkFile(entry.Name) {
+			chunkNames = append(chunkNames, entry.Name)
+		}
+	}
+	if len(chunkNames) == 0 {
+		return entries, false, nil
+	}
+	chunkNames = agent.SortChunkFiles(chunkNames, paths.TranscriptFileName)
+	if i := slices.Index(chunkNames, paths.TranscriptFileName); i > 0 {
+		// SortChunkFiles orders by index, but make sure the base file leads.
+		chunkNames = append([]string{paths.TranscriptFileName}, slices.Delete(chunkNames, i, i+1)...)
+	}
+
+	var agentType agent.AgentType
+	if metadataEntry, ok := byName[paths.MetadataFileName]; ok {
+		if metadata, err := readJSONFromBlob[CommittedMetadata](p.repo, metadataEntry.Hash); err == nil {
+			agentType = metadata.Agent
+		}
+	}
+
+	encrypted := false
+	chunks := make([][]byte, 0, len(chunkNames))
+	for _, name := range chunkNames {
+		content, wasEncrypted, err := p.readBlob(dir+"/"+name, byName[name].Hash)
+		if err != nil {
+			return nil, false, err
+		}
+		encrypted = encrypted || wasEncrypted
+		chunks = append(chunks, content)
+	}
+	transcript, err := agent.ReassembleTranscript(chunks, agentType)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to reassemble transcript in %s: %w", dir, err)
+	}
+	if !p.pattern.Match(transcript) {
+		return entries, false, nil
+	}
+	transcript = p.pattern.ReplaceAllLiteral(transcript, []byte(redact.RedactedPlaceholder))
+
+	newChunks, err := agent.ChunkTranscript(p.ctx, transcript, agentType)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to chunk transcript in %s: %w", dir, err)
+	}
+	out := make([]object.TreeEntry, 0, len(entries)+len(newChunks))
+	for _, entry := range entries {
+		if entry.Mode == filemode.Dir || (!isChunkFile(entry.Name) && entry.Name != paths.ContentHashFileName) {
+			out = append(out, entry)
+		}
+	}
+	for i, chunk := range newChunks {
+		hash, err := p.writeBlob(chunk, encrypted)
+		if err != nil {
+			return nil, false, err
+		}
+		out = append(out, object.TreeEntry{Name: agent.ChunkFileName(paths.TranscriptFileName, i), Mode: filemode.Regular, Hash: hash})
+	}
+	if _, ok := byName[paths.ContentHashFileName]; ok {
+		hash, err := p.rw.storeBlob([]byte(fmt.Sprintf("sha256:%x", sha256.Sum256(transcript))))
+		if err != nil {
+			return nil, false, err
+		}
+		out = append(out, object.TreeEntry{Name: paths.ContentHashFileName, Mode: filemode.Regular, Hash: hash})
+	}
+	p.files += len(chunkNames)
+
+	sortTreeEntries(out)
+	return out, true, nil
+}
+
+// scrubBlob returns the hash of the blob with the pattern replaced.
+func (p *purger) scrubBlob(filePath string, hash plumbing.Hash) (plumbing.Hash, error) {
+	if newHash, ok := p.blobs[hash]; ok {
+		return newHash, nil
+	}
+	content, encrypted, err := p.readBlob(filePath, hash)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	newHash := hash
+	if p.pattern.Match(content) {
+		if newHash, err = p.writeBlob(p.pattern.ReplaceAllLiteral(content, []byte(redact.RedactedPlaceholder)), encrypted); err != nil {
+			return plumbing.ZeroHash, err
+		}
+		p.files++
+	}
+	p.blobs[hash] = newHash
+	return newHash, nil
+}
+
+// readBlob returns a blob's content, decrypted if it is encrypted. Purging
+// encrypted content requires an identity that can decrypt it.
+func (p *purger) readBlob(filePath string, hash plumbing.Hash) ([]byte, bool, error) {
+	blob, err := p.repo.BlobObject(hash)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	defer reader.Close()
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(reader); err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	if !encryption.IsEncrypted(buf.Bytes()) {
+		return buf.Bytes(), false, nil
+	}
+	content, err := encryption.Open(buf.Bytes())
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to decrypt %s: %w", filePath, err)
+	}
+	return content, true, nil
+}
+
+// writeBlob stores content, encrypting it again when the original was encrypted.
+func (p *purger) writeBlob(content []byte, encrypted bool) (plumbing.Hash, error) {
+	if encrypted {
+		if !encryption.Enabled() {
+			return plumbing.ZeroHash, errors.New("cannot re-encrypt scrubbed content: no encryption recipients are configured")
+		}
+		sealed, err := encryption.Seal(content)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to encrypt checkpoint content: %w", err)
+		}
+		content = sealed
+	}
+	return p.rw.storeBlob(content)
+}
+
+// scrubNotes rewrites every commit reachable from notesHead, the
+// refs/notes/entire tip, with the pattern replaced in every note and commit
+// message. Returns the new tip.
+func (p *purger) scrubNotes(ctx context.Context, dryRun bool, notesHead plumbing.Hash) (plumbing.Hash, error) {
+	trees := make(map[plumbing.Hash]plumbing.Hash)
+	rw := newHistoryRewriter(p.repo, dryRun)
+	rw.rewriteMessage = p.rw.rewriteMessage
+	rw.rewriteRoot = func(hash plumbing.Hash) (plumbing.Hash, error) {
+		return p.scrubNotesTree(rw, trees, hash, "")
+	}
+	return rw.rewriteCommit(ctx, notesHead)
+}
+
+// scrubNotesTree returns the hash of the notes tree at dir with the pattern
+// replaced in every note.
+func (p *purger) scrubNotesTree(rw *historyRewriter, trees map[plumbing.Hash]plumbing.Hash, hash plumbing.Hash, dir string) (plumbing.Hash, error) {
+	if newHash, ok := trees[hash]; ok {
+		return newHash, nil
+	}
+	tree, err := p.repo.TreeObject(hash)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read notes tree: %w", err)
+	}
+	changed := false
+	entries := slices.Clone(tree.Entries)
+	for i, entry := range entries {
+		var newHash plumbing.Hash
+		if entry.Mode == filemode.Dir {
+			newHash, err = p.scrubNotesTree(rw, trees, entry.Hash, path.Join(dir, entry.Name))
+		} else {
+			newHash, err = p.scrubBlob(path.Join(paths.CheckpointNotesRef, dir, entry.Name), entry.Hash)
+		}
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		if newHash != entry.Hash {
+			entries[i].Hash = newHash
+			changed = true
+		}
+	}
+	newHash := hash
+	if changed {
+		if newHash, err = rw.storeTree(entries); err != nil {
+			return plumbing.ZeroHash, err
+		}
+	}
+	trees[hash] = newHash
+	return newHash, nil
+}
+
+// appendPurgeRecord commits record to the purge log on top of tip.
+func (s *GitStore) appendPurgeRecord(tip plumbing.Hash, record PurgeRecord) (plumbing.Hash, error) {
+	commit, err := s.repo.CommitObject(tip)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read commit: %w", err)
+	}
+	records, err := readPurgeLog(commit)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	records = append(records, record)
+
+	var buf bytes.Buffer
+	for _, r := range records {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to marshal purge record: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	blobHash, err := CreateBlobFromContent(s.repo, buf.Bytes())
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	treeHash, err := ApplyTreeChanges(s.repo, commit.TreeHash, []TreeChange{{
+		Path:  paths.PurgeLogFileName,
+		Entry: &object.TreeEntry{Name: paths.PurgeLogFileName, Mode: filemode.Regular, Hash: blobHash},
+	}})
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to update tree: %w", err)
+	}
+	authorName, authorEmail := GetGitAuthorFromRepo(s.repo)
+	message := fmt.Sprintf("Purge %d checkpoint(s)\n", len(record.Checkpoints))
+	if record.Command == PurgeCommandGC {
+		message = fmt.Sprintf("Log compaction of %d checkpoint(s)\n", len(record.Checkpoints))
+	}
+	return s.createCommit(treeHash, tip, message, authorName, authorEmail)
+}
+
+// readPurgeLog returns the purge records in commit's tree.
+func readPurgeLog(commit *object.Commit) ([]PurgeRecord, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree: %w", err)
+	}
+	file, err := tree.File(paths.PurgeLogFileName)
+	if err != nil {
+		return nil, nil //nolint:nilerr // No purge log means no purges
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read purge log: %w", err)
+	}
+	var records []PurgeRecord
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var r PurgeRecord
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			return nil, fmt.Errorf("failed to parse purge log: %w", err)
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// CheckPurgedHistory returns a *PurgedHistoryError when one of the two
+// metadata branch commits records a purge or compaction that the other
+// predates while still containing an affected checkpoint. Merging them would restore the purged
+// content, so callers must not merge; the stale side has to be replaced.
+func CheckPurgedHistory(repo *git.Repository, localHash, remoteHash plumbing.Hash) error {
+	local, err := repo.CommitObject(localHash)
+	if err != nil {
+		return fmt.Errorf("failed to get local commit: %w", err)
+	}
+	remote, err := repo.CommitObject(remoteHash)
+	if err != nil {
+		return fmt.Errorf("failed to get remote commit: %w", err)
+	}
+	localLog, err := readPurgeLog(local)
+	if err != nil {
+		return err
+	}
+	remoteLog, err := readPurgeLog(remote)
+	if err != nil {
+		return err
+	}
+
+	for _, side := range []struct {
+		purged []PurgeRecord
+		other  []PurgeRecord
+		stale  *object.Commit
+		local  bool
+	}{
+		{remoteLog, localLog, local, false},
+		{localLog, remoteLog, remote, true},
+	} {
+		staleTree, err := side.stale.Tree()
+		if err != nil {
+			return fmt.Errorf("failed to read tree: %w", err)
+		}
+		for _, record := range side.purged {
+			if slices.ContainsFunc(side.other, func(r PurgeRecord) bool { return r.RewrittenFrom == record.RewrittenFrom }) {
+				continue
+			}
+			for _, cpID := range record.Checkpoints {
+				if _, err := staleTree.Tree(cpID.Path()); err == nil {
+					return &PurgedHistoryError{Record: record, Local: side.local}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// CheckPurgedNotes returns a *PurgedHistoryError when a purge recorded on one
+// of the metadata branch commits rewrote checkpoint notes that exactly one of
+// the two refs/notes/entire commits still contains. Merging them would
+// restore the purged notes.
+func CheckPurgedNotes(repo *git.Repository, metadataHashes []plumbing.Hash, localNotes, remoteNotes plumbing.Hash) error {
+	for _, hash := range metadataHashes {
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			continue
+		}
+		records, err := readPurgeLog(commit)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if record.NotesRewrittenFrom == "" {
+				continue
+			}
+			rewritten := plumbing.NewHash(record.NotesRewrittenFrom)
+			localStale := containsCommit(repo, localNotes, rewritten)
+			remoteStale := containsCommit(repo, remoteNotes, rewritten)
+			if localStale != remoteStale {
+				return &PurgedHistoryError{Record: record, Local: remoteStale}
+			}
+		}
+	}
+	return nil
+}
+
+// containsCommit reports whether hash is tip or one of its ancestors.
+func containsCommit(repo *git.Repository, tip, hash plumbing.Hash) bool {
+	if tip == hash {
+		return true
+	}
+	tipCommit, err := repo.CommitObject(tip)
+	if err != nil {
+		return false
+	}
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return false
+	}
+	isAncestor, err := commit.IsAncestor(tipCommit)
+	return err == nil && isAncestor
+}
@@ -0,0 +1,353 @@
+package checkpoint
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/redact"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestPurge_Pattern(t *testing.T) {
+	t.Parallel()
+	repo, store, cpID := setupRepoForUpdate(t)
+	ctx := context.Background()
+	const secret = "sk-live-0123456789abcdef"
+
+	// The secret is only in an older version of the transcript and prompts.
+	if err := store.UpdateCommitted(ctx, UpdateCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-001",
+		Transcript:   []byte("export KEY=" + secret + "\n"),
+		Prompts:      []string{"use " + secret},
+	}); err != nil {
+		t.Fatalf("UpdateCommitted() error = %v", err)
+	}
+	if err := store.UpdateCommitted(ctx, UpdateCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-001",
+		Transcript:   []byte("export KEY=<set>\n"),
+		Prompts:      []string{"use the key"},
+	}); err != nil {
+		t.Fatalf("UpdateCommitted() error = %v", err)
+	}
+	oldHead := branchHead(t, repo)
+	pattern := regexp.MustCompile(`sk-live-[0-9a-f]+`)
+
+	dry, err := store.Purge(ctx, PurgeOptions{Pattern: pattern, DryRun: true})
+	if err != nil {
+		t.Fatalf("Purge(dry run) error = %v", err)
+	}
+	if len(dry.Checkpoints) != 1 || dry.Checkpoints[0] != cpID || dry.Files == 0 {
+		t.Fatalf("Purge(dry run) = %+v, want %s with scrubbed files", dry, cpID)
+	}
+	if branchHead(t, repo) != oldHead {
+		t.Fatal("dry run changed the branch")
+	}
+
+	result, err := store.Purge(ctx, PurgeOptions{Pattern: pattern})
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if result.NewHead == oldHead || branchHead(t, repo) != result.NewHead {
+		t.Fatal("Purge() did not replace the branch")
+	}
+	if result.Files != dry.Files {
+		t.Errorf("Purge() scrubbed %d files, dry run reported %d", result.Files, dry.Files)
+	}
+
+	var sawRedacted bool
+	forEachBlob(t, repo, result.NewHead, func(tree *object.Tree, p, content string) {
+		if strings.Contains(content, secret) {
+			t.Errorf("%s still contains the secret", p)
+		}
+		if strings.HasSuffix(p, "/"+paths.TranscriptFileName) && strings.Contains(content, redact.RedactedPlaceholder) {
+			sawRedacted = true
+			hashFile, err := tree.File(path.Dir(p) + "/" + paths.ContentHashFileName)
+			if err != nil {
+				t.Fatalf("missing content hash next to %s: %v", p, err)
+			}
+			if hash, _ := hashFile.Contents(); hash != fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(content))) {
+				t.Errorf("content hash next to %s was not recomputed", p)
+			}
+		}
+	})
+	if !sawRedacted {
+		t.Error("no transcript in history was redacted")
+	}
+
+	// The latest content didn't contain the secret and is unchanged.
+	content, err := store.ReadSessionContent(ctx, cpID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if string(content.Transcript) != "export KEY=<set>\n" {
+		t.Errorf("transcript = %q", content.Transcript)
+	}
+	if author, err := store.GetCheckpointAuthor(ctx, cpID); err != nil || author.Name != "Test" {
+		t.Errorf("GetCheckpointAuthor() = %+v, %v; want the original author", author, err)
+	}
+
+	tip, err := repo.CommitObject(result.NewHead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := readPurgeLog(tip)
+	if err != nil {
+		t.Fatalf("readPurgeLog() error = %v", err)
+	}
+	if len(records) != 1 || records[0].RewrittenFrom != oldHead.String() || records[0].PatternSHA256 == "" {
+		t.Fatalf("purge log = %+v", records)
+	}
+
+	// Purging again finds nothing.
+	again, err := store.Purge(ctx, PurgeOptions{Pattern: pattern})
+	if err != nil {
+		t.Fatalf("Purge() second run error = %v", err)
+	}
+	if len(again.Checkpoints) != 0 || again.NewHead != result.NewHead {
+		t.Errorf("Purge() second run = %+v, want no changes", again)
+	}
+}
+
+func TestPurge_Session(t *testing.T) {
+	t.Parallel()
+	repo, store, cpID := setupRepoForUpdate(t)
+	ctx := context.Background()
+
+	// Add a second session to the checkpoint.
+	if err := store.WriteCommitted(ctx, WriteCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-002",
+		Strategy:     "manual-commit",
+		Transcript:   []byte("second session\n"),
+		Prompts:      []string{"second prompt"},
+		AuthorName:   "Test",
+		AuthorEmail:  "test@test.com",
+	}); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+
+	result, err := store.Purge(ctx, PurgeOptions{SessionIDs: []string{"session-002"}})
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if len(result.Checkpoints) != 1 || result.Checkpoints[0] != cpID {
+		t.Fatalf("Purge() = %+v, want %s", result, cpID)
+	}
+
+	forEachBlob(t, repo, result.NewHead, func(_ *object.Tree, p, _ string) {
+		if strings.HasPrefix(p, cpID.Path()+"/1/") && !isMetadataFile(path.Base(p)) {
+			t.Errorf("history still contains %s", p)
+		}
+	})
+	first, err := store.ReadSessionContent(ctx, cpID, 0)
+	if err != nil || len(first.Transcript) == 0 {
+		t.Errorf("first session lost its transcript: %v", err)
+	}
+	second, err := store.ReadSessionContent(ctx, cpID, 1)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if len(second.Transcript) != 0 || second.Prompts != "" || second.Metadata.SessionID != "session-002" {
+		t.Errorf("purged session: transcript %q, prompts %q, session %q", second.Transcript, second.Prompts, second.Metadata.SessionID)
+	}
+}
+
+// TestPurge_SessionResolvedFromMetadata checks that sessions are found by
+// their metadata.json, not by their position in the index, which skips
+// sessions whose metadata can't be read.
+func TestPurge_SessionResolvedFromMetadata(t *testing.T) {
+	t.Parallel()
+	repo, store, cpID := setupRepoForUpdate(t)
+	ctx := context.Background()
+
+	if err := store.WriteCommitted(ctx, WriteCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-002",
+		Strategy:     "manual-commit",
+		Transcript:   []byte("second session\n"),
+		AuthorName:   "Test",
+		AuthorEmail:  "test@test.com",
+	}); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+
+	// Break the first session's metadata so the index lists session-002 first.
+	head, err := repo.CommitObject(branchHead(t, repo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobHash, err := CreateBlobFromContent(repo, []byte("{"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadataPath := cpID.Path() + "/0/" + paths.MetadataFileName
+	treeHash, err := ApplyTreeChanges(repo, head.TreeHash, []TreeChange{{
+		Path:  metadataPath,
+		Entry: &object.TreeEntry{Name: paths.MetadataFileName, Mode: filemode.Regular, Hash: blobHash},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newHead, err := store.createCommit(treeHash, head.Hash, "Break metadata\n", "Test", "test@test.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(refName, newHead)); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := store.ListIndexed(ctx)
+	if err != nil || len(entries) != 1 || len(entries[0].SessionIDs) != 1 || entries[0].SessionIDs[0] != "session-002" {
+		t.Fatalf("ListIndexed() = %+v, %v; want only session-002", entries, err)
+	}
+
+	result, err := store.Purge(ctx, PurgeOptions{SessionIDs: []string{"session-002"}})
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	files := flattenCommit(t, repo, result.NewHead)
+	if _, ok := files[cpID.Path()+"/0/"+paths.TranscriptFileName]; !ok {
+		t.Error("the first session's transcript should be kept")
+	}
+	if _, ok := files[cpID.Path()+"/1/"+paths.TranscriptFileName]; ok {
+		t.Error("session-002's transcript should be removed")
+	}
+}
+
+func TestPurge_PatternScrubsNotes(t *testing.T) {
+	t.Parallel()
+	repo, store, cpID := setupRepoForUpdate(t)
+	ctx := context.Background()
+	const secret = "sk-live-0123456789abcdef"
+
+	// Two notes commits, the first with the secret in an intent.
+	noteName := strings.Repeat("ab", 20)
+	var notesHead plumbing.Hash
+	for _, note := range []string{"Entire-Intent: use " + secret + "\n", "Entire-Intent: use the key\n"} {
+		blobHash, err := CreateBlobFromContent(repo, []byte("Entire-Checkpoint: "+cpID.String()+"\n"+note))
+		if err != nil {
+			t.Fatal(err)
+		}
+		treeHash, err := BuildTreeFromEntries(repo, map[string]object.TreeEntry{
+			noteName: {Name: noteName, Mode: filemode.Regular, Hash: blobHash},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if notesHead, err = store.createCommit(treeHash, notesHead, "Notes added by 'entire'\n", "Test", "test@test.com"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	notesRef := plumbing.ReferenceName(paths.CheckpointNotesRef)
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(notesRef, notesHead)); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := store.Purge(ctx, PurgeOptions{Pattern: regexp.MustCompile(`sk-live-[0-9a-f]+`)})
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if result.OldNotesHead != notesHead || result.NewNotesHead == notesHead {
+		t.Fatalf("Purge() notes %s -> %s, want the notes rewritten from %s", result.OldNotesHead, result.NewNotesHead, notesHead)
+	}
+	ref, err := repo.Reference(notesRef, true)
+	if err != nil || ref.Hash() != result.NewNotesHead {
+		t.Fatalf("%s = %v, %v; want %s", paths.CheckpointNotesRef, ref, err, result.NewNotesHead)
+	}
+	forEachBlob(t, repo, result.NewNotesHead, func(_ *object.Tree, p, content string) {
+		if strings.Contains(content, secret) {
+			t.Errorf("notes history still contains the secret in %s", p)
+		}
+	})
+
+	// Notes from before the purge can't be merged with the purged ones.
+	var purgedErr *PurgedHistoryError
+	if err := CheckPurgedNotes(repo, []plumbing.Hash{result.NewHead}, result.NewNotesHead, notesHead); !errors.As(err, &purgedErr) || !purgedErr.Local {
+		t.Errorf("CheckPurgedNotes(purged, stale) = %v, want a local *PurgedHistoryError", err)
+	}
+	if err := CheckPurgedNotes(repo, []plumbing.Hash{result.NewHead}, notesHead, result.NewNotesHead); !errors.As(err, &purgedErr) || purgedErr.Local {
+		t.Errorf("CheckPurgedNotes(stale, purged) = %v, want a remote *PurgedHistoryError", err)
+	}
+	if err := CheckPurgedNotes(repo, []plumbing.Hash{result.NewHead}, result.NewNotesHead, result.NewNotesHead); err != nil {
+		t.Errorf("CheckPurgedNotes(purged, purged) = %v, want nil", err)
+	}
+}
+
+func TestCheckPurgedHistory(t *testing.T) {
+	t.Parallel()
+	repo, store, cpID := setupRepoForUpdate(t)
+	ctx := context.Background()
+
+	oldHead := branchHead(t, repo)
+	result, err := store.Purge(ctx, PurgeOptions{CheckpointIDs: []id.CheckpointID{cpID}})
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+
+	var purgedErr *PurgedHistoryError
+	if err := CheckPurgedHistory(repo, result.NewHead, oldHead); !errors.As(err, &purgedErr) || !purgedErr.Local {
+		t.Errorf("CheckPurgedHistory(purged, stale) = %v, want a local *PurgedHistoryError", err)
+	}
+	if err := CheckPurgedHistory(repo, oldHead, result.NewHead); !errors.As(err, &purgedErr) || purgedErr.Local {
+		t.Errorf("CheckPurgedHistory(stale, purged) = %v, want a remote *PurgedHistoryError", err)
+	}
+	if err := CheckPurgedHistory(repo, result.NewHead, result.NewHead); err != nil {
+		t.Errorf("CheckPurgedHistory(purged, purged) = %v, want nil", err)
+	}
+
+	// A later checkpoint on the purged side doesn't make the sides conflict.
+	if err := store.WriteCommitted(ctx, WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("b1b2c3d4e5f6"),
+		SessionID:    "session-002",
+		Strategy:     "manual-commit",
+		Transcript:   []byte("later\n"),
+		AuthorName:   "Test",
+		AuthorEmail:  "test@test.com",
+	}); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+	if err := CheckPurgedHistory(repo, branchHead(t, repo), result.NewHead); err != nil {
+		t.Errorf("CheckPurgedHistory(after purge) = %v, want nil", err)
+	}
+}
+
+// forEachBlob calls fn with the tree, path and content of every file in every
+// commit reachable from head.
+func forEachBlob(t *testing.T, repo *git.Repository, head plumbing.Hash, fn func(tree *object.Tree, filePath, content string)) {
+	t.Helper()
+	iter, err := repo.Log(&git.LogOptions{From: head})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = iter.ForEach(func(c *object.Commit) error {
+		tree, err := c.Tree()
+		if err != nil {
+			return err
+		}
+		return tree.Files().ForEach(func(f *object.File) error {
+			content, err := f.Contents()
+			if err != nil {
+				return err
+			}
+			fn(tree, f.Name, content)
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
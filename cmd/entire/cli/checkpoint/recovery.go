@@ -0,0 +1,193 @@
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// WriteRecoveryOptions contains options for writing a recovery point.
+type WriteRecoveryOptions struct {
+	// CommitMessage is the full commit message, including any trailers
+	CommitMessage string
+
+	// AuthorName is the name to use for commits
+	AuthorName string
+
+	// AuthorEmail is the email to use for commits
+	AuthorEmail string
+
+	// SessionStates maps session state file names (e.g. "<session-id>.json")
+	// to their content, stored under RecoverySessionsDir
+	SessionStates map[string][]byte
+}
+
+// RecoverySessionsDir is where a recovery point's tree keeps the session state
+// files saved with it. It is inside .entire, which rewinds and restores never
+// write to the working tree.
+const RecoverySessionsDir = ".entire/recovery-sessions"
+
+// RecoveryPointInfo describes a recovery point.
+type RecoveryPointInfo struct {
+	// CommitHash is the hash of the recovery point commit
+	CommitHash plumbing.Hash
+
+	// HeadCommit is the HEAD commit when the recovery point was written
+	HeadCommit plumbing.Hash
+
+	// Message is the full commit message
+	Message string
+
+	// Timestamp is when the recovery point was written
+	Timestamp time.Time
+}
+
+// WriteRecoveryPoint snapshots the working tree, including untracked files that
+// aren't ignored, and makes it the latest recovery point on paths.RecoveryRef.
+// The tree is built like a first temporary checkpoint: HEAD's tree plus every
+// changed file, plus opts.SessionStates under RecoverySessionsDir. Like a git
+// stash entry, the commit's first parent is HEAD; its second parent is the
+// previous recovery point, if any.
+func (s *GitStore) WriteRecoveryPoint(ctx context.Context, opts WriteRecoveryOptions) (plumbing.Hash, error) {
+	head, err := s.repo.Head()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	headCommit, err := s.repo.CommitObject(head.Hash())
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+
+	changes, err := collectChangedFiles(ctx, s.repo)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to collect changed files: %w", err)
+	}
+	treeHash, err := s.buildTreeWithChanges(ctx, headCommit.TreeHash, changes.Changed, changes.Deleted, "", "")
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to build tree: %w", err)
+	}
+	if len(opts.SessionStates) > 0 {
+		entries := make([]object.TreeEntry, 0, len(opts.SessionStates))
+		for name, content := range opts.SessionStates {
+			blobHash, err := CreateBlobFromContent(s.repo, content)
+			if err != nil {
+				return plumbing.ZeroHash, fmt.Errorf("failed to store session state %s: %w", name, err)
+			}
+			entries = append(entries, object.TreeEntry{Name: name, Mode: filemode.Regular, Hash: blobHash})
+		}
+		sortTreeEntries(entries)
+		treeHash, err = UpdateSubtree(s.repo, treeHash, strings.Split(RecoverySessionsDir, "/"), entries, UpdateSubtreeOptions{MergeMode: ReplaceAll})
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to add session states: %w", err)
+		}
+	}
+
+	parents := []plumbing.Hash{head.Hash()}
+	refName := plumbing.ReferenceName(paths.RecoveryRef)
+	if ref, err := s.repo.Reference(refName, true); err == nil {
+		parents = append(parents, ref.Hash())
+	}
+
+	sig := object.Signature{Name: opts.AuthorName, Email: opts.AuthorEmail, When: time.Now()}
+	commit := &object.Commit{
+		TreeHash:     treeHash,
+		ParentHashes: parents,
+		Author:       sig,
+		Committer:    sig,
+		Message:      opts.CommitMessage,
+	}
+	obj := s.repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode commit: %w", err)
+	}
+	commitHash, err := s.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to store commit: %w", err)
+	}
+
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(refName, commitHash)); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to update %s: %w", paths.RecoveryRef, err)
+	}
+	return commitHash, nil
+}
+
+// ReadRecoverySessionStates returns the session state files saved with a
+// recovery point, keyed by file name. Recovery points saved without session
+// states return nil.
+func (s *GitStore) ReadRecoverySessionStates(commitHash plumbing.Hash) (map[string][]byte, error) {
+	commit, err := s.repo.CommitObject(commitHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recovery point %s: %w", commitHash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recovery point tree: %w", err)
+	}
+	dir, err := tree.Tree(RecoverySessionsDir)
+	if errors.Is(err, object.ErrDirectoryNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", RecoverySessionsDir, err)
+	}
+
+	states := make(map[string][]byte, len(dir.Entries))
+	for _, entry := range dir.Entries {
+		if !entry.Mode.IsFile() {
+			continue
+		}
+		content, err := s.readBlob(entry.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session state %s: %w", entry.Name, err)
+		}
+		states[entry.Name] = content
+	}
+	return states, nil
+}
+
+// ListRecoveryPoints returns recovery points, most recent first.
+// limit <= 0 returns all of them.
+func (s *GitStore) ListRecoveryPoints(ctx context.Context, limit int) ([]RecoveryPointInfo, error) {
+	ref, err := s.repo.Reference(plumbing.ReferenceName(paths.RecoveryRef), true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", paths.RecoveryRef, err)
+	}
+
+	var result []RecoveryPointInfo
+	hash := ref.Hash()
+	for hash != plumbing.ZeroHash && (limit <= 0 || len(result) < limit) {
+		if err := ctx.Err(); err != nil {
+			return nil, err //nolint:wrapcheck // Propagating context cancellation
+		}
+		commit, err := s.repo.CommitObject(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get recovery point %s: %w", hash, err)
+		}
+		info := RecoveryPointInfo{
+			CommitHash: commit.Hash,
+			Message:    commit.Message,
+			Timestamp:  commit.Committer.When,
+		}
+		if len(commit.ParentHashes) > 0 {
+			info.HeadCommit = commit.ParentHashes[0]
+		}
+		result = append(result, info)
+
+		hash = plumbing.ZeroHash
+		if len(commit.ParentHashes) > 1 {
+			hash = commit.ParentHashes[1]
+		}
+	}
+	return result, nil
+}
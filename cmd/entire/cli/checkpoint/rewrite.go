@@ -0,0 +1,245 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// historyRewriter rewrites every commit reachable from a metadata branch tip,
+// changing only the directories of the checkpoints it selects. Authors,
+// committers and messages are kept, and commits whose tree and parents are
+// unchanged keep their hashes. Used by Compact and Purge.
+type historyRewriter struct {
+	repo   *git.Repository
+	dryRun bool // compute hashes without storing objects
+
+	// selects reports whether a checkpoint's directory is rewritten.
+	selects func(cpID id.CheckpointID) bool
+
+	// enterDir, when set, is called with the original tree of a directory
+	// inside a selected checkpoint before its subdirectories are rewritten.
+	enterDir func(cpID id.CheckpointID, dir string, tree *object.Tree) error
+
+	// rewriteDir returns the entries of a directory inside a selected
+	// checkpoint, after its subdirectories were rewritten, and whether they
+	// changed. A directory left without entries is removed.
+	rewriteDir func(cpID id.CheckpointID, dir string, entries []object.TreeEntry) ([]object.TreeEntry, bool, error)
+
+	// rewriteMessage, when set, returns the new message of every commit.
+	rewriteMessage func(message string) string
+
+	// rewriteRoot, when set, replaces the checkpoint-aware walk of each
+	// commit's root tree. Used for refs/notes/entire, which isn't sharded by
+	// checkpoint.
+	rewriteRoot func(hash plumbing.Hash) (plumbing.Hash, error)
+
+	trees   map[string]plumbing.Hash        // "<dir>:<old tree hash>" -> new tree hash
+	commits map[plumbing.Hash]plumbing.Hash // old commit -> new commit
+
+	rewritten int // number of commits that changed
+}
+
+func newHistoryRewriter(repo *git.Repository, dryRun bool) *historyRewriter {
+	return &historyRewriter{
+		repo:    repo,
+		dryRun:  dryRun,
+		trees:   make(map[string]plumbing.Hash),
+		commits: make(map[plumbing.Hash]plumbing.Hash),
+	}
+}
+
+func (rw *historyRewriter) rewriteCommit(ctx context.Context, hash plumbing.Hash) (plumbing.Hash, error) {
+	if newHash, ok := rw.commits[hash]; ok {
+		return newHash, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return plumbing.ZeroHash, err //nolint:wrapcheck // Propagating context cancellation
+	}
+
+	commit, err := rw.repo.CommitObject(hash)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+
+	changed := false
+	parents := make([]plumbing.Hash, len(commit.ParentHashes))
+	for i, parent := range commit.ParentHashes {
+		if parents[i], err = rw.rewriteCommit(ctx, parent); err != nil {
+			return plumbing.ZeroHash, err
+		}
+		changed = changed || parents[i] != parent
+	}
+	var treeHash plumbing.Hash
+	if rw.rewriteRoot != nil {
+		treeHash, err = rw.rewriteRoot(commit.TreeHash)
+	} else {
+		treeHash, err = rw.rewriteTree(commit.TreeHash, "")
+	}
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if treeHash.IsZero() {
+		// Every checkpoint was removed; keep the commit with an empty tree.
+		if treeHash, err = rw.storeTree(nil); err != nil {
+			return plumbing.ZeroHash, err
+		}
+	}
+	changed = changed || treeHash != commit.TreeHash
+	message := commit.Message
+	if rw.rewriteMessage != nil {
+		message = rw.rewriteMessage(message)
+		changed = changed || message != commit.Message
+	}
+
+	newHash := hash
+	if changed {
+		rewritten := &object.Commit{
+			Author:       commit.Author,
+			Committer:    commit.Committer,
+			Message:      message,
+			TreeHash:     treeHash,
+			ParentHashes: parents,
+		}
+		obj := rw.repo.Storer.NewEncodedObject()
+		if err := rewritten.Encode(obj); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to encode commit: %w", err)
+		}
+		if newHash, err = rw.store(obj); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to store commit: %w", err)
+		}
+		rw.rewritten++
+	}
+	rw.commits[hash] = newHash
+	return newHash, nil
+}
+
+// rewriteTree returns the new hash of the tree at dir, or the zero hash when
+// the tree ends up empty. Subtrees outside selected checkpoints are returned
+// unchanged without being read.
+func (rw *historyRewriter) rewriteTree(hash plumbing.Hash, dir string) (plumbing.Hash, error) {
+	depth := 0
+	if dir != "" {
+		depth = strings.Count(dir, "/") + 1
+	}
+	var cpID id.CheckpointID
+	if depth >= 2 {
+		parts := strings.SplitN(dir, "/", 3)
+		cpID = id.CheckpointID(parts[0] + parts[1])
+		if !rw.selects(cpID) {
+			return hash, nil
+		}
+	}
+
+	key := dir + ":" + hash.String()
+	if newHash, ok := rw.trees[key]; ok {
+		return newHash, nil
+	}
+
+	tree, err := rw.repo.TreeObject(hash)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read tree %s: %w", dir, err)
+	}
+	if depth >= 2 && rw.enterDir != nil {
+		if err := rw.enterDir(cpID, dir, tree); err != nil {
+			return plumbing.ZeroHash, err
+		}
+	}
+
+	changed := false
+	entries := make([]object.TreeEntry, 0, len(tree.Entries))
+	for _, entry := range tree.Entries {
+		// Only shard directories hold checkpoints; other root entries are kept as is.
+		if entry.Mode != filemode.Dir || (depth == 0 && len(entry.Name) != 2) {
+			entries = append(entries, entry)
+			continue
+		}
+		newHash, err := rw.rewriteTree(entry.Hash, path.Join(dir, entry.Name))
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		if newHash != entry.Hash {
+			changed = true
+		}
+		if newHash.IsZero() {
+			continue
+		}
+		entry.Hash = newHash
+		entries = append(entries, entry)
+	}
+	if depth >= 2 {
+		var dirChanged bool
+		entries, dirChanged, err = rw.rewriteDir(cpID, dir, entries)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		changed = changed || dirChanged
+	}
+
+	newHash := hash
+	switch {
+	case len(entries) == 0:
+		newHash = plumbing.ZeroHash
+	case changed:
+		if newHash, err = rw.storeTree(entries); err != nil {
+			return plumbing.ZeroHash, err
+		}
+	}
+	rw.trees[key] = newHash
+	return newHash, nil
+}
+
+func (rw *historyRewriter) storeTree(entries []object.TreeEntry) (plumbing.Hash, error) {
+	obj := rw.repo.Storer.NewEncodedObject()
+	if err := (&object.Tree{Entries: entries}).Encode(obj); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode tree: %w", err)
+	}
+	hash, err := rw.store(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to store tree: %w", err)
+	}
+	return hash, nil
+}
+
+// storeBlob stores content as a blob, or only computes its hash for a dry run.
+func (rw *historyRewriter) storeBlob(content []byte) (plumbing.Hash, error) {
+	obj := rw.repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	obj.SetSize(int64(len(content)))
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get object writer: %w", err)
+	}
+	if _, err := w.Write(content); err != nil {
+		_ = w.Close()
+		return plumbing.ZeroHash, fmt.Errorf("failed to write blob content: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to close blob writer: %w", err)
+	}
+	hash, err := rw.store(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to store blob: %w", err)
+	}
+	return hash, nil
+}
+
+// store writes obj, or only computes its hash for a dry run.
+func (rw *historyRewriter) store(obj plumbing.EncodedObject) (plumbing.Hash, error) {
+	if rw.dryRun {
+		return obj.Hash(), nil
+	}
+	hash, err := rw.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, err //nolint:wrapcheck // Callers add context
+	}
+	return hash, nil
+}
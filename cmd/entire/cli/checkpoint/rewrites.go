@@ -0,0 +1,150 @@
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// LinkRewrittenCommits records that git rewrote the commits linked to checkpoints
+// (amend, rebase, squash). links maps each checkpoint to the commits that now
+// contain its work; they are appended to the Commits list of the checkpoint's
+// root metadata.json. All updates are written in a single commit.
+// Checkpoints that don't exist on the metadata branch are skipped.
+// Returns the checkpoints that were updated.
+func (s *GitStore) LinkRewrittenCommits(ctx context.Context, links map[id.CheckpointID][]string) ([]id.CheckpointID, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err //nolint:wrapcheck // Propagating context cancellation
+	}
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	parentHash, rootTreeHash, err := s.getSessionsBranchRef()
+	if err != nil {
+		return nil, err
+	}
+	rootTree, err := s.repo.TreeObject(rootTreeHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions branch tree: %w", err)
+	}
+
+	cpIDs := make([]id.CheckpointID, 0, len(links))
+	for cpID := range links {
+		cpIDs = append(cpIDs, cpID)
+	}
+	sort.Slice(cpIDs, func(i, j int) bool { return cpIDs[i].String() < cpIDs[j].String() })
+
+	var changes []TreeChange
+	var updated []id.CheckpointID
+	for _, cpID := range cpIDs {
+		metadataPath := cpID.Path() + "/" + paths.MetadataFileName
+		file, err := rootTree.File(metadataPath)
+		if err != nil {
+			continue
+		}
+		summary, err := s.readSummaryFromBlob(file.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read checkpoint summary for %s: %w", cpID, err)
+		}
+
+		changed := false
+		for _, commit := range links[cpID] {
+			if !slices.Contains(summary.Commits, commit) {
+				summary.Commits = append(summary.Commits, commit)
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		metadataJSON, err := jsonutil.MarshalIndentWithNewline(summary, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal checkpoint summary: %w", err)
+		}
+		blobHash, err := CreateBlobFromContent(s.repo, metadataJSON)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, TreeChange{
+			Path:  metadataPath,
+			Entry: &object.TreeEntry{Name: paths.MetadataFileName, Mode: filemode.Regular, Hash: blobHash},
+		})
+		updated = append(updated, cpID)
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	newTreeHash, err := ApplyTreeChanges(s.repo, rootTreeHash, changes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update sessions branch tree: %w", err)
+	}
+	authorName, authorEmail := GetGitAuthorFromRepo(s.repo)
+	commitMsg := fmt.Sprintf("Link rewritten commits for %d checkpoint(s)", len(updated))
+	newCommitHash, err := s.createCommit(newTreeHash, parentHash, commitMsg, authorName, authorEmail)
+	if err != nil {
+		return nil, err
+	}
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(refName, newCommitHash)); err != nil {
+		return nil, fmt.Errorf("failed to set branch reference: %w", err)
+	}
+	return updated, nil
+}
+
+// CheckpointsForCommit returns the checkpoints whose Commits list contains
+// commitHash, i.e. checkpoints whose commits were rewritten into it.
+// Served from the local index, falling back to a full scan without one.
+func (s *GitStore) CheckpointsForCommit(ctx context.Context, commitHash string) ([]id.CheckpointID, error) {
+	idx, err := s.loadIndex(ctx)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr //nolint:wrapcheck // Propagating context cancellation
+		}
+		if !errors.Is(err, errIndexUnavailable) {
+			logging.Debug(ctx, "checkpoint index unusable, falling back to full scan",
+				slog.String("error", err.Error()),
+			)
+		}
+		idx, err = s.scanIndex(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var result []id.CheckpointID
+	for _, entry := range idx.Entries {
+		if slices.Contains(entry.Commits, commitHash) {
+			result = append(result, entry.CheckpointID)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].String() < result[j].String() })
+	return result, nil
+}
+
+// scanIndex builds an in-memory index from a full scan of the sessions branch.
+func (s *GitStore) scanIndex(ctx context.Context) (*checkpointIndex, error) {
+	idx := &checkpointIndex{Version: indexVersion, Entries: map[string]IndexEntry{}}
+	tree, err := s.getSessionsBranchTree()
+	if err != nil {
+		return idx, nil //nolint:nilerr // No sessions branch means no checkpoints
+	}
+	if err := s.rebuildIndex(ctx, idx, tree); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
@@ -0,0 +1,77 @@
+package checkpoint
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+)
+
+func TestLinkRewrittenCommits(t *testing.T) {
+	t.Parallel()
+	repo, store, cpID := setupRepoForUpdate(t)
+	ctx := context.Background()
+	const amended = "1111111111111111111111111111111111111111"
+	const squashed = "2222222222222222222222222222222222222222"
+
+	missing := id.MustCheckpointID("ffffffffffff")
+	updated, err := store.LinkRewrittenCommits(ctx, map[id.CheckpointID][]string{
+		cpID:    {amended},
+		missing: {amended},
+	})
+	if err != nil {
+		t.Fatalf("LinkRewrittenCommits() error = %v", err)
+	}
+	if len(updated) != 1 || updated[0] != cpID {
+		t.Fatalf("LinkRewrittenCommits() updated %v, want only %s", updated, cpID)
+	}
+
+	// Linking the same commit again doesn't add a commit to the branch.
+	head := branchHead(t, repo)
+	if updated, err := store.LinkRewrittenCommits(ctx, map[id.CheckpointID][]string{cpID: {amended}}); err != nil || len(updated) != 0 {
+		t.Fatalf("LinkRewrittenCommits(again) = %v, %v; want no updates", updated, err)
+	}
+	if branchHead(t, repo) != head {
+		t.Error("relinking the same commit changed the branch")
+	}
+
+	// A later session on the checkpoint keeps the links.
+	if err := store.WriteCommitted(ctx, WriteCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-002",
+		Strategy:     "manual-commit",
+		Transcript:   []byte("second session\n"),
+		AuthorName:   "Test",
+		AuthorEmail:  "test@test.com",
+	}); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+	if _, err := store.LinkRewrittenCommits(ctx, map[id.CheckpointID][]string{cpID: {squashed}}); err != nil {
+		t.Fatalf("LinkRewrittenCommits() error = %v", err)
+	}
+
+	summary, err := store.ReadCommitted(ctx, cpID)
+	if err != nil {
+		t.Fatalf("ReadCommitted() error = %v", err)
+	}
+	if !slices.Equal(summary.Commits, []string{amended, squashed}) {
+		t.Errorf("Commits = %v, want [%s %s]", summary.Commits, amended, squashed)
+	}
+	if len(summary.Sessions) != 2 {
+		t.Errorf("Sessions = %d, want 2", len(summary.Sessions))
+	}
+
+	for _, commit := range []string{amended, squashed} {
+		got, err := store.CheckpointsForCommit(ctx, commit)
+		if err != nil {
+			t.Fatalf("CheckpointsForCommit(%s) error = %v", commit, err)
+		}
+		if len(got) != 1 || got[0] != cpID {
+			t.Errorf("CheckpointsForCommit(%s) = %v, want [%s]", commit, got, cpID)
+		}
+	}
+	if got, err := store.CheckpointsForCommit(ctx, "3333333333333333333333333333333333333333"); err != nil || len(got) != 0 {
+		t.Errorf("CheckpointsForCommit(unlinked) = %v, %v; want none", got, err)
+	}
+}
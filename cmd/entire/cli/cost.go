@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/pricing"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+)
+
+// configurePricing applies the "pricing" settings section on top of the built-in
+// price table for every later cost estimate in this process. Settings that fail
+// to load are reported by the command itself; an invalid table is reported here
+// and the built-in prices stay in effect.
+func configurePricing(ctx context.Context) {
+	s, err := settings.Load(ctx)
+	if err != nil {
+		return
+	}
+	if err := pricing.Configure(s.Pricing); err != nil {
+		logging.Warn(ctx, "invalid pricing config, using built-in prices", slog.String("error", err.Error()))
+		fmt.Fprintf(os.Stderr, "Warning: invalid pricing config, using built-in prices: %v\n", err)
+	}
+}
+
+// formatCost returns the estimated cost of tu for display (e.g., "~$1.24"),
+// or false when tu records no models to price.
+func formatCost(tu *agent.TokenUsage) (string, bool) {
+	est, ok := pricing.Cost(tu)
+	if !ok {
+		return "", false
+	}
+	var s string
+	switch {
+	case len(est.Unpriced) > 0 && est.USD == 0:
+		return "unknown (no price for " + strings.Join(est.Unpriced, ", ") + ")", true
+	case est.USD > 0 && est.USD < 0.01:
+		s = "<$0.01"
+	default:
+		s = fmt.Sprintf("~$%.2f", est.USD)
+	}
+	if len(est.Unpriced) > 0 {
+		s += " (excludes " + strings.Join(est.Unpriced, ", ") + ")"
+	}
+	return s, true
+}
+
+// costUSD returns the estimated cost of tu in US dollars for structured output,
+// or nil when tu records no models to price.
+func costUSD(tu *agent.TokenUsage) *float64 {
+	est, ok := pricing.Cost(tu)
+	if !ok {
+		return nil
+	}
+	return &est.USD
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/spf13/cobra"
+)
+
+// diffPointSearchLimit is how many recent rewind points are searched for a match.
+const diffPointSearchLimit = 100
+
+func newDiffCmd() *cobra.Command {
+	var (
+		statFlag     bool
+		nameOnlyFlag bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff <point-a> [<point-b>]",
+		Short: "Show the changes between two checkpoints or rewind points",
+		Long: `Show what changed in the code between two agent steps.
+
+A point is a rewind point ID from 'entire rewind --list' (a shadow branch
+commit, 7+ characters), or a committed checkpoint ID (or prefix), which stands
+for the commit it is linked to. With one point, it is compared against the
+working tree, including untracked files that aren't ignored.
+
+Use --stat for a summary of changed lines per file, or --name-only for just
+the changed paths.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkDisabledGuard(cmd.Context(), cmd.OutOrStdout()) {
+				return nil
+			}
+			to := ""
+			if len(args) == 2 {
+				to = args[1]
+			}
+			return runDiff(cmd.Context(), cmd.OutOrStdout(), args[0], to, statFlag, nameOnlyFlag)
+		},
+	}
+
+	cmd.Flags().BoolVar(&statFlag, "stat", false, "Show changed lines per file instead of the diff")
+	cmd.Flags().BoolVar(&nameOnlyFlag, "name-only", false, "Show only the names of changed files")
+	cmd.MarkFlagsMutuallyExclusive("stat", "name-only")
+
+	return cmd
+}
+
+// runDiff prints the diff from point from to point to (the working tree when empty).
+func runDiff(ctx context.Context, w io.Writer, from, to string, stat, nameOnly bool) error {
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+
+	fromHash, err := resolveDiffPoint(ctx, repo, from)
+	if err != nil {
+		return err
+	}
+	toHash := plumbing.ZeroHash
+	if to != "" {
+		if toHash, err = resolveDiffPoint(ctx, repo, to); err != nil {
+			return err
+		}
+	}
+
+	result, err := strategy.DiffCheckpoints(ctx, repo, fromHash, toHash)
+	if err != nil {
+		return fmt.Errorf("failed to compute diff: %w", err)
+	}
+
+	switch {
+	case nameOnly:
+		for _, p := range result.Paths() {
+			fmt.Fprintln(w, p)
+		}
+	case stat:
+		if !result.IsEmpty() {
+			fmt.Fprint(w, result.Stats().String())
+		}
+	default:
+		if err := fdiff.NewUnifiedEncoder(w, fdiff.DefaultContextLines).Encode(result); err != nil {
+			return fmt.Errorf("failed to write diff: %w", err)
+		}
+	}
+	return nil
+}
+
+// resolveDiffPoint resolves a rewind point ID or committed checkpoint ID to
+// the commit whose files it stands for. Rewind points are matched first, the
+// same way 'entire rewind --to' matches them.
+func resolveDiffPoint(ctx context.Context, repo *git.Repository, point string) (plumbing.Hash, error) {
+	points, err := GetStrategy(ctx).GetRewindPoints(ctx, diffPointSearchLimit)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to find rewind points: %w", err)
+	}
+	for _, p := range points {
+		if p.ID == point || (len(point) >= 7 && len(p.ID) >= 7 && strings.HasPrefix(p.ID, point)) {
+			return plumbing.NewHash(p.ID), nil
+		}
+	}
+
+	store := checkpoint.NewGitStore(repo)
+	matches, err := matchCommittedCheckpoints(ctx, store, point)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if len(matches) == 0 {
+		return plumbing.ZeroHash, fmt.Errorf("no rewind point or checkpoint found: %s", point)
+	}
+	if len(matches) > 1 {
+		return plumbing.ZeroHash, ambiguousCheckpointError(point, matches)
+	}
+
+	// The most recent commit linked to the checkpoint, searching merged branches too
+	for _, searchAll := range []bool{false, true} {
+		commits, err := getAssociatedCommits(ctx, repo, matches[0], searchAll)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to find commits for checkpoint %s: %w", matches[0], err)
+		}
+		if len(commits) > 0 {
+			return plumbing.NewHash(commits[0].SHA), nil
+		}
+	}
+	return plumbing.ZeroHash, fmt.Errorf("checkpoint %s is not linked to any commit reachable from HEAD", matches[0])
+}
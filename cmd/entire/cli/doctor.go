@@ -9,6 +9,7 @@ import (
 
 	"github.com/charmbracelet/huh"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/session"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
 
@@ -38,8 +39,12 @@ For each stuck session, you can choose to:
   - Skip: Leave the session as-is
 
 Use --force to condense all fixable sessions without prompting.  Sessions that can't
-be condensed will be discarded.`,
+be condensed will be discarded.
+
+It also checks that git runs Entire's hooks, including through Husky, Lefthook
+or pre-commit when Entire's commands are registered there.`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			checkGitHooks(cmd.Context(), cmd.OutOrStdout())
 			return runSessionsFix(cmd, forceFlag)
 		},
 	}
@@ -49,6 +54,28 @@ be condensed will be discarded.`,
 	return cmd
 }
 
+// checkGitHooks reports hooks that won't run Entire's commands: missing or
+// outdated git hooks, and hook manager integrations whose hooks aren't installed.
+func checkGitHooks(ctx context.Context, w io.Writer) {
+	if _, err := paths.WorktreeRoot(ctx); err != nil {
+		return
+	}
+	if enabled, err := IsEnabled(ctx); err == nil && !enabled {
+		return
+	}
+
+	for _, integration := range strategy.RegisteredHookManagerIntegrations(ctx) {
+		if !integration.Live {
+			fmt.Fprintf(w, "Note: Entire's git hooks are registered in %s, but %s hasn't installed its hooks.\n", integration.ConfigPath, integration.Name)
+			fmt.Fprintf(w, "  Run '%s', then 'entire enable'.\n", integration.ActivateCommand)
+		}
+	}
+	if !strategy.IsGitHookInstalled(ctx) {
+		fmt.Fprintln(w, "Warning: some git hooks don't run Entire, so commits may miss checkpoint trailers.")
+		fmt.Fprintln(w, "  Run 'entire enable' to reinstall them.")
+	}
+}
+
 // stuckSession holds a session state along with diagnostic info.
 type stuckSession struct {
 	State             *strategy.SessionState
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/encryption"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+)
+
+// configureEncryption applies the "encryption" settings section to every later
+// checkpoint write and read in this process. Relative identity file paths are
+// resolved against the repository root. An invalid recipient is reported, and
+// checkpoint writes then fail rather than store plaintext.
+func configureEncryption(ctx context.Context) {
+	s, err := settings.Load(ctx)
+	if err != nil {
+		return
+	}
+	cfg := s.Encryption
+	if cfg != nil {
+		resolved := *cfg
+		resolved.IdentityFiles = make([]string, len(cfg.IdentityFiles))
+		for i, file := range cfg.IdentityFiles {
+			if !filepath.IsAbs(file) && !strings.HasPrefix(file, "~/") {
+				if abs, absErr := paths.AbsPath(ctx, file); absErr == nil {
+					file = abs
+				}
+			}
+			resolved.IdentityFiles[i] = file
+		}
+		cfg = &resolved
+	}
+	if err := encryption.Configure(cfg); err != nil {
+		logging.Warn(ctx, "invalid encryption config, checkpoint writes will fail", slog.String("error", err.Error()))
+		fmt.Fprintf(os.Stderr, "Warning: invalid encryption config, checkpoints can't be written until it is fixed: %v\n", err)
+	}
+}
@@ -0,0 +1,62 @@
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+)
+
+// ageIntro is the first line of every age v1 file (https://age-encryption.org/v1).
+const ageIntro = "age-encryption.org/v1\n"
+
+// ErrNoIdentity is returned when none of the available identities can decrypt
+// a file.
+var ErrNoIdentity = errors.New("no identity matches any of the recipients")
+
+// Recipient wraps a file key so that a matching Identity can recover it.
+type Recipient = age.Recipient
+
+// Identity recovers a file key from the recipient stanzas addressed to it.
+type Identity = age.Identity
+
+// IsEncrypted reports whether data is an age-encrypted file.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(ageIntro))
+}
+
+// Encrypt encrypts plaintext to every recipient.
+func Encrypt(plaintext []byte, recipients ...Recipient) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Decrypt decrypts an age file with the first identity that matches one of
+// its recipients. Returns ErrNoIdentity when none does.
+func Decrypt(ciphertext []byte, identities ...Identity) ([]byte, error) {
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), identities...)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			return nil, ErrNoIdentity
+		}
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return plaintext, nil
+}
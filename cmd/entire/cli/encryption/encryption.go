@@ -0,0 +1,194 @@
+// Package encryption encrypts checkpoint transcripts, prompts and context at
+// rest on the entire/checkpoints/v1 branch. Files use the age format
+// (https://age-encryption.org) and are encrypted to age X25519 or SSH public
+// keys configured in the "encryption" settings section, so they can also be
+// decrypted with the age CLI.
+package encryption
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+)
+
+// Config is the "encryption" settings section.
+type Config struct {
+	// Recipients are the public keys checkpoint content is encrypted to:
+	// age keys ("age1...") or SSH keys ("ssh-ed25519 ...", "ssh-rsa ...").
+	// Encryption is off when empty.
+	Recipients []string `json:"recipients,omitempty"`
+
+	// IdentityFiles are age identity files or unencrypted SSH private keys used
+	// to decrypt. A leading "~/" is expanded. SSH keys are only used when listed
+	// here; without identity files, encrypted content can't be read.
+	IdentityFiles []string `json:"identity_files,omitempty"`
+}
+
+// IsZero reports whether cfg configures nothing.
+func (cfg *Config) IsZero() bool {
+	return cfg == nil || (len(cfg.Recipients) == 0 && len(cfg.IdentityFiles) == 0)
+}
+
+// state is the configuration used by Seal and Open.
+type state struct {
+	recipients []Recipient
+	// sealErr makes Seal fail instead of writing plaintext when the configured
+	// recipients are invalid.
+	sealErr error
+
+	identityFiles []string
+	loadOnce      sync.Once
+	identities    []Identity
+}
+
+// active is the state used by Seal and Open. nil means encryption is off and
+// nothing can be decrypted.
+var active atomic.Pointer[state]
+
+// defaultState is used when Configure hasn't been called.
+var defaultState = &state{}
+
+// Configure makes cfg the configuration used by every later Seal and Open in
+// this process. A nil or empty cfg turns encryption off.
+//
+// If a recipient is invalid, Configure returns an error and Seal fails until
+// the configuration is fixed, so a typo never writes plaintext.
+func Configure(cfg *Config) error {
+	if cfg.IsZero() {
+		active.Store(nil)
+		return nil
+	}
+
+	st := &state{identityFiles: cfg.IdentityFiles}
+	for _, r := range cfg.Recipients {
+		recipient, err := ParseRecipient(r)
+		if err != nil {
+			st.recipients = nil
+			st.sealErr = fmt.Errorf("invalid encryption recipient: %w", err)
+			break
+		}
+		st.recipients = append(st.recipients, recipient)
+	}
+	active.Store(st)
+	return st.sealErr
+}
+
+func current() *state {
+	if st := active.Load(); st != nil {
+		return st
+	}
+	return defaultState
+}
+
+// Enabled reports whether Seal encrypts, or fails because the configured
+// recipients are invalid.
+func Enabled() bool {
+	st := current()
+	return len(st.recipients) > 0 || st.sealErr != nil
+}
+
+// Seal encrypts data for the configured recipients. Without recipients, data
+// is returned unchanged.
+func Seal(data []byte) ([]byte, error) {
+	st := current()
+	if st.sealErr != nil {
+		return nil, st.sealErr
+	}
+	if len(st.recipients) == 0 {
+		return data, nil
+	}
+	return Encrypt(data, st.recipients...)
+}
+
+// Open decrypts data with the configured identities. Data that isn't
+// encrypted is returned unchanged. Returns ErrNoIdentity when no configured
+// identity can decrypt it.
+func Open(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	st := current()
+	st.loadOnce.Do(func() {
+		st.identities = loadIdentityFiles(st.identityFiles)
+	})
+	if len(st.identities) == 0 {
+		return nil, ErrNoIdentity
+	}
+	return Decrypt(data, st.identities...)
+}
+
+// ParseRecipient parses an age ("age1...") or SSH ("ssh-ed25519 ...",
+// "ssh-rsa ...") public key.
+func ParseRecipient(s string) (Recipient, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "age1"):
+		r, err := age.ParseX25519Recipient(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipient: %w", err)
+		}
+		return r, nil
+	case strings.HasPrefix(s, "ssh-"):
+		r, err := agessh.ParseRecipient(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SSH recipient: %w", err)
+		}
+		return r, nil
+	default:
+		return nil, fmt.Errorf("unknown recipient type %q", s)
+	}
+}
+
+// ParseIdentities parses the contents of an age identity file or an
+// unencrypted SSH private key.
+func ParseIdentities(data []byte) ([]Identity, error) {
+	if bytes.Contains(data, []byte("-----BEGIN")) {
+		id, err := agessh.ParseIdentity(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SSH identity: %w", err)
+		}
+		return []Identity{id}, nil
+	}
+	ids, err := age.ParseIdentities(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid age identity file: %w", err)
+	}
+	return ids, nil
+}
+
+// loadIdentityFiles reads every identity file that exists and parses. Missing,
+// passphrase-protected or malformed files are skipped: decryption then
+// degrades to ErrNoIdentity rather than failing the command.
+func loadIdentityFiles(files []string) []Identity {
+	var ids []Identity
+	for _, file := range files {
+		data, err := os.ReadFile(expandHome(file))
+		if err != nil {
+			continue
+		}
+		parsed, err := ParseIdentities(data)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, parsed...)
+	}
+	return ids
+}
+
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
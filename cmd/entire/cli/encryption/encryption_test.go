@@ -0,0 +1,257 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestEncryptDecrypt_X25519(t *testing.T) {
+	t.Parallel()
+
+	alice, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eve, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, size := range []int{0, 1, 64 << 10, 200 << 10} {
+		plaintext := bytes.Repeat([]byte("x"), size)
+		ciphertext, err := Encrypt(plaintext, alice.Recipient(), bob.Recipient())
+		if err != nil {
+			t.Fatalf("Encrypt(%d bytes) error = %v", size, err)
+		}
+		if !IsEncrypted(ciphertext) {
+			t.Fatalf("Encrypt(%d bytes) output is not recognized as encrypted", size)
+		}
+		got, err := Decrypt(ciphertext, eve, bob)
+		if err != nil {
+			t.Fatalf("Decrypt(%d bytes) error = %v", size, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("Decrypt(%d bytes) returned different content", size)
+		}
+		if _, err := Decrypt(ciphertext, eve); !errors.Is(err, ErrNoIdentity) {
+			t.Fatalf("Decrypt() with the wrong identity error = %v, want ErrNoIdentity", err)
+		}
+	}
+}
+
+func TestDecrypt_Tampered(t *testing.T) {
+	t.Parallel()
+
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := Encrypt([]byte("secret plans"), id.Recipient())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := bytes.Clone(ciphertext)
+	payload[len(payload)-1] ^= 1
+	if _, err := Decrypt(payload, id); err == nil {
+		t.Error("expected error for a modified payload")
+	}
+
+	header := bytes.Replace(ciphertext, []byte("-> X25519 "), []byte("-> X25519 A"), 1)
+	if _, err := Decrypt(header, id); err == nil {
+		t.Error("expected error for a modified header")
+	}
+}
+
+func TestKeyEncoding(t *testing.T) {
+	t.Parallel()
+
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(id.String(), "AGE-SECRET-KEY-1") || !strings.HasPrefix(id.Recipient().String(), "age1") {
+		t.Fatalf("unexpected key encodings %q, %q", id.String(), id.Recipient().String())
+	}
+
+	parsed, err := ParseIdentities([]byte(id.String() + "\n"))
+	if err != nil || len(parsed) != 1 {
+		t.Fatalf("ParseIdentities() = %v, %v", parsed, err)
+	}
+	if _, err := ParseRecipient(id.Recipient().String()); err != nil {
+		t.Errorf("ParseRecipient() error = %v", err)
+	}
+
+	corrupted := []byte(id.Recipient().String())
+	if last := len(corrupted) - 1; corrupted[last] == 'q' {
+		corrupted[last] = 'p'
+	} else {
+		corrupted[last] = 'q'
+	}
+	if _, err := ParseRecipient(string(corrupted)); err == nil {
+		t.Error("expected checksum error for a corrupted recipient")
+	}
+	if _, err := ParseRecipient("pgp:ABCDEF"); err == nil {
+		t.Error("expected error for an unknown recipient type")
+	}
+}
+
+func TestEncryptDecrypt_SSH(t *testing.T) {
+	t.Parallel()
+
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, keys := range map[string]struct {
+		pub  any
+		priv any
+	}{
+		"ed25519": {edPub, edPriv},
+		"rsa":     {&rsaPriv.PublicKey, rsaPriv},
+	} {
+		sshPub, err := ssh.NewPublicKey(keys.pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recipient, err := ParseRecipient(string(ssh.MarshalAuthorizedKey(sshPub)))
+		if err != nil {
+			t.Fatalf("%s: ParseRecipient() error = %v", name, err)
+		}
+		block, err := ssh.MarshalPrivateKey(keys.priv, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids, err := ParseIdentities(pem.EncodeToMemory(block))
+		if err != nil {
+			t.Fatalf("%s: ParseIdentities() error = %v", name, err)
+		}
+
+		other, err := age.GenerateX25519Identity()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ciphertext, err := Encrypt([]byte("transcript"), other.Recipient(), recipient)
+		if err != nil {
+			t.Fatalf("%s: Encrypt() error = %v", name, err)
+		}
+		got, err := Decrypt(ciphertext, ids...)
+		if err != nil || string(got) != "transcript" {
+			t.Errorf("%s: Decrypt() = %q, %v", name, got, err)
+		}
+	}
+}
+
+// TestOpen_AgeExample decrypts the example file from the age repository, so
+// files written by the age CLI can be read back.
+func TestOpen_AgeExample(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(nil) }) //nolint:errcheck // restoring defaults cannot fail
+
+	data, err := os.ReadFile(filepath.Join("testdata", "example.age"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(data) {
+		t.Fatal("IsEncrypted() = false for an age file")
+	}
+	if err := Configure(&Config{IdentityFiles: []string{filepath.Join("testdata", "example_keys.txt")}}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Open(data)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if string(got) != "Black lives matter." {
+		t.Errorf("Open() = %q", got)
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(nil) }) //nolint:errcheck // restoring defaults cannot fail
+
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "key.txt")
+	if err := os.WriteFile(keyFile, []byte("# created: test\n"+id.String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without configuration, Seal is a no-op.
+	if err := Configure(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Seal([]byte("plain")); err != nil || string(got) != "plain" || Enabled() {
+		t.Fatalf("Seal() without recipients = %q, %v", got, err)
+	}
+
+	if err := Configure(&Config{Recipients: []string{id.Recipient().String()}, IdentityFiles: []string{keyFile}}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	sealed, err := Seal([]byte("secret"))
+	if err != nil || !IsEncrypted(sealed) {
+		t.Fatalf("Seal() = %q, %v; want encrypted output", sealed, err)
+	}
+	if got, err := Open(sealed); err != nil || string(got) != "secret" {
+		t.Errorf("Open() = %q, %v", got, err)
+	}
+	if got, err := Open([]byte("not encrypted")); err != nil || string(got) != "not encrypted" {
+		t.Errorf("Open() of plaintext = %q, %v", got, err)
+	}
+
+	// SSH keys aren't tried unless listed, so without identity files nothing
+	// decrypts.
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".ssh"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".ssh", "id_ed25519"), []byte(id.String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, cfg := range []*Config{nil, {Recipients: []string{id.Recipient().String()}}} {
+		if err := Configure(cfg); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Open(sealed); !errors.Is(err, ErrNoIdentity) {
+			t.Errorf("Open() without identity files error = %v, want ErrNoIdentity", err)
+		}
+	}
+
+	// Without a matching identity, Open degrades to ErrNoIdentity.
+	if err := Configure(&Config{IdentityFiles: []string{filepath.Join(t.TempDir(), "missing.txt")}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(sealed); !errors.Is(err, ErrNoIdentity) {
+		t.Errorf("Open() without identity error = %v, want ErrNoIdentity", err)
+	}
+
+	// An invalid recipient fails closed.
+	if err := Configure(&Config{Recipients: []string{"age1bogus"}}); err == nil {
+		t.Fatal("Configure() should reject an invalid recipient")
+	}
+	if _, err := Seal([]byte("secret")); err == nil {
+		t.Error("Seal() should fail while the recipients are invalid")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -72,6 +73,10 @@ func newExplainCmd() *cobra.Command {
 	var generateFlag bool
 	var forceFlag bool
 	var searchAllFlag bool
+	var jsonFlag bool
+	var formatFlag string
+	var renderFlag string
+	var outputFlag string
 
 	cmd := &cobra.Command{
 		Use:   "explain",
@@ -104,6 +109,17 @@ Summary generation (for --checkpoint):
 Performance options:
   --search-all  Remove branch/depth limits when searching for commits (may be slow)
 
+Structured output (for the list, --commit, and --checkpoint views):
+  --json        Shorthand for --format=json
+  --format      Output format: text (default), json, yaml, or markdown
+  Structured output is never paged and includes a schema_version field.
+
+Rendering a transcript (for --checkpoint):
+  --render      Render all sessions as a standalone html or markdown document
+                with prompts, responses, collapsible tool calls, file diffs,
+                subagent transcripts and token usage
+  --output, -o  Write the rendered document to a file instead of stdout
+
 Checkpoint detail view shows:
   - Author of the checkpoint
   - Associated git commits that reference the checkpoint
@@ -132,6 +148,26 @@ Note: --session filters the list view; --commit and --checkpoint are mutually ex
 			if rawTranscriptFlag && checkpointFlag == "" {
 				return errors.New("--raw-transcript requires --checkpoint/-c flag")
 			}
+			if outputFlag != "" && renderFlag == "" {
+				return errors.New("--output requires --render flag")
+			}
+			if renderFlag != "" {
+				if checkpointFlag == "" {
+					return errors.New("--render requires --checkpoint/-c flag")
+				}
+				return runExplainRender(cmd.Context(), cmd.OutOrStdout(), checkpointFlag, renderFlag, outputFlag)
+			}
+
+			format, err := resolveExplainFormat(formatFlag, jsonFlag)
+			if err != nil {
+				return err
+			}
+			if format != explainFormatText {
+				if rawTranscriptFlag {
+					return errors.New("--raw-transcript cannot be combined with --json or --format")
+				}
+				return runExplainStructured(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr(), format, sessionFlag, commitFlag, checkpointFlag, generateFlag, forceFlag, searchAllFlag)
+			}
 
 			// Convert short flag to verbose (verbose = !short)
 			verbose := !shortFlag
@@ -149,17 +185,42 @@ Note: --session filters the list view; --commit and --checkpoint are mutually ex
 	cmd.Flags().BoolVar(&generateFlag, "generate", false, "Generate an AI summary for the checkpoint")
 	cmd.Flags().BoolVar(&forceFlag, "force", false, "Regenerate summary even if one already exists (requires --generate)")
 	cmd.Flags().BoolVar(&searchAllFlag, "search-all", false, "Search all commits (no branch/depth limit, may be slow)")
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output as JSON (shorthand for --format=json)")
+	cmd.Flags().StringVar(&formatFlag, "format", "", "Output format: text, json, yaml, or markdown")
+	cmd.Flags().StringVar(&renderFlag, "render", "", "Render the checkpoint transcript as a document: html or markdown")
+	cmd.Flags().StringVarP(&outputFlag, "output", "o", "", "Write the rendered document to a file (requires --render)")
 
 	// Make --short, --full, and --raw-transcript mutually exclusive
 	cmd.MarkFlagsMutuallyExclusive("short", "full", "raw-transcript")
 	// --generate and --raw-transcript are incompatible (summary would be generated but not shown)
 	cmd.MarkFlagsMutuallyExclusive("generate", "raw-transcript")
+	cmd.MarkFlagsMutuallyExclusive("json", "format")
+	cmd.MarkFlagsMutuallyExclusive("render", "format", "json", "raw-transcript", "generate")
 
 	return cmd
 }
 
 // runExplain routes to the appropriate explain function based on flags.
 func runExplain(ctx context.Context, w, errW io.Writer, sessionID, commitRef, checkpointID string, noPager, verbose, full, rawTranscript, generate, force, searchAll bool) error {
+	if err := validateExplainTargets(sessionID, commitRef, checkpointID); err != nil {
+		return err
+	}
+
+	// Route to appropriate handler
+	if commitRef != "" {
+		return runExplainCommit(ctx, w, commitRef, noPager, verbose, full, searchAll)
+	}
+	if checkpointID != "" {
+		return runExplainCheckpoint(ctx, w, errW, checkpointID, noPager, verbose, full, rawTranscript, generate, force, searchAll)
+	}
+
+	// Default or with session filter: show list view (optionally filtered by session)
+	return runExplainBranchWithFilter(ctx, w, noPager, sessionID)
+}
+
+// validateExplainTargets checks that at most one of --commit and --checkpoint is set,
+// and that --session (a list filter) isn't combined with either.
+func validateExplainTargets(sessionID, commitRef, checkpointID string) error {
 	// Count mutually exclusive flags (--commit and --checkpoint are mutually exclusive)
 	// --session is now a filter for the list view, not a separate mode
 	flagCount := 0
@@ -176,17 +237,7 @@ func runExplain(ctx context.Context, w, errW io.Writer, sessionID, commitRef, ch
 	if flagCount > 1 {
 		return errors.New("cannot specify multiple of --session, --commit, --checkpoint")
 	}
-
-	// Route to appropriate handler
-	if commitRef != "" {
-		return runExplainCommit(ctx, w, commitRef, noPager, verbose, full, searchAll)
-	}
-	if checkpointID != "" {
-		return runExplainCheckpoint(ctx, w, errW, checkpointID, noPager, verbose, full, rawTranscript, generate, force, searchAll)
-	}
-
-	// Default or with session filter: show list view (optionally filtered by session)
-	return runExplainBranchWithFilter(ctx, w, noPager, sessionID)
+	return nil
 }
 
 // runExplainCheckpoint explains a specific checkpoint.
@@ -205,17 +256,9 @@ func runExplainCheckpoint(ctx context.Context, w, errW io.Writer, checkpointIDPr
 	store := checkpoint.NewGitStore(repo)
 
 	// First, try to find in committed checkpoints by checkpoint ID prefix
-	committed, err := store.ListCommitted(ctx)
+	matches, err := matchCommittedCheckpoints(ctx, store, checkpointIDPrefix)
 	if err != nil {
-		return fmt.Errorf("failed to list checkpoints: %w", err)
-	}
-
-	// Collect all matching checkpoint IDs to detect ambiguity
-	var matches []id.CheckpointID
-	for _, info := range committed {
-		if strings.HasPrefix(info.CheckpointID.String(), checkpointIDPrefix) {
-			matches = append(matches, info.CheckpointID)
-		}
+		return err
 	}
 
 	var fullCheckpointID id.CheckpointID
@@ -238,12 +281,7 @@ func runExplainCheckpoint(ctx context.Context, w, errW io.Writer, checkpointIDPr
 	case 1:
 		fullCheckpointID = matches[0]
 	default:
-		// Ambiguous prefix - show up to 5 examples
-		examples := make([]string, 0, 5)
-		for i := 0; i < len(matches) && i < 5; i++ {
-			examples = append(examples, matches[i].String())
-		}
-		return fmt.Errorf("ambiguous checkpoint prefix %q matches %d checkpoints: %s", checkpointIDPrefix, len(matches), strings.Join(examples, ", "))
+		return ambiguousCheckpointError(checkpointIDPrefix, matches)
 	}
 
 	// Load checkpoint summary
@@ -275,6 +313,12 @@ func runExplainCheckpoint(ctx context.Context, w, errW io.Writer, checkpointIDPr
 
 	// Handle raw transcript output
 	if rawTranscript {
+		if len(content.Transcript) == 0 && content.Encrypted {
+			return fmt.Errorf("checkpoint %s transcript is encrypted and no configured identity can decrypt it (see encryption.identity_files)", fullCheckpointID)
+		}
+		if len(content.Transcript) == 0 && content.Metadata.CompactedAt != nil {
+			return fmt.Errorf("checkpoint %s transcript was removed by 'entire gc' on %s", fullCheckpointID, content.Metadata.CompactedAt.Format("2006-01-02"))
+		}
 		if len(content.Transcript) == 0 {
 			return fmt.Errorf("checkpoint %s has no transcript", fullCheckpointID)
 		}
@@ -297,6 +341,32 @@ func runExplainCheckpoint(ctx context.Context, w, errW io.Writer, checkpointIDPr
 	return nil
 }
 
+// matchCommittedCheckpoints returns the IDs of all committed checkpoints matching the prefix.
+func matchCommittedCheckpoints(ctx context.Context, store *checkpoint.GitStore, prefix string) ([]id.CheckpointID, error) {
+	committed, err := store.ListCommittedIndexed(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+
+	// Collect all matching checkpoint IDs to detect ambiguity
+	var matches []id.CheckpointID
+	for _, info := range committed {
+		if strings.HasPrefix(info.CheckpointID.String(), prefix) {
+			matches = append(matches, info.CheckpointID)
+		}
+	}
+	return matches, nil
+}
+
+// ambiguousCheckpointError reports an ambiguous checkpoint prefix with up to 5 examples.
+func ambiguousCheckpointError(prefix string, matches []id.CheckpointID) error {
+	examples := make([]string, 0, 5)
+	for i := 0; i < len(matches) && i < 5; i++ {
+		examples = append(examples, matches[i].String())
+	}
+	return fmt.Errorf("ambiguous checkpoint prefix %q matches %d checkpoints: %s", prefix, len(matches), strings.Join(examples, ", "))
+}
+
 // generateCheckpointSummary generates an AI summary for a checkpoint and persists it.
 // The summary is generated from the scoped transcript (only this checkpoint's portion),
 // not the entire session transcript.
@@ -320,7 +390,12 @@ func generateCheckpointSummary(ctx context.Context, w, _ io.Writer, store *check
 	// Generate summary using shared helper
 	logging.Info(ctx, "generating checkpoint summary")
 
-	summary, err := summarize.GenerateFromTranscript(ctx, scopedTranscript, cpSummary.FilesTouched, content.Metadata.Agent, nil)
+	generator, err := summarize.GeneratorFromSettings(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to configure summary provider: %w", err)
+	}
+
+	summary, err := summarize.GenerateFromTranscript(ctx, scopedTranscript, cpSummary.FilesTouched, content.Metadata.Agent, generator)
 	if err != nil {
 		return fmt.Errorf("failed to generate summary: %w", err)
 	}
@@ -455,7 +530,8 @@ func explainTemporaryCheckpoint(ctx context.Context, w io.Writer, repo *git.Repo
 }
 
 // getAssociatedCommits finds git commits that reference the given checkpoint ID.
-// Searches commits on the current branch for Entire-Checkpoint trailer matches.
+// Searches commits on the current branch for Entire-Checkpoint trailer matches,
+// and for the commits recorded on the checkpoint when its commit was rewritten.
 // When searchAll is true, uses full DAG walk with no depth limit (may be slow).
 // This finds checkpoint commits on merged feature branches (second parents of merges).
 func getAssociatedCommits(ctx context.Context, repo *git.Repository, checkpointID id.CheckpointID, searchAll bool) ([]associatedCommit, error) {
@@ -465,7 +541,17 @@ func getAssociatedCommits(ctx context.Context, repo *git.Repository, checkpointI
 	}
 
 	commits := []associatedCommit{} // Initialize as empty slice, not nil (nil means "not searched")
-	targetID := checkpointID.String()
+
+	// Commits the checkpoint's commit was amended, rebased or squashed into (best-effort)
+	rewritten := make(map[string]bool)
+	if entry, lookupErr := checkpoint.NewGitStore(repo).LookupIndexed(ctx, checkpointID); lookupErr == nil && entry != nil {
+		for _, sha := range entry.Commits {
+			rewritten[sha] = true
+		}
+	}
+	references := func(c *object.Commit) bool {
+		return rewritten[c.Hash.String()] || slices.Contains(trailers.ParseAllCheckpoints(c.Message), checkpointID)
+	}
 
 	collectCommit := func(c *object.Commit) {
 		fullSHA := c.Hash.String()
@@ -498,8 +584,7 @@ func getAssociatedCommits(ctx context.Context, repo *git.Repository, checkpointI
 			if err := ctx.Err(); err != nil {
 				return err //nolint:wrapcheck // Propagating context cancellation
 			}
-			cpID, found := trailers.ParseCheckpoint(c.Message)
-			if found && cpID.String() == targetID {
+			if references(c) {
 				collectCommit(c)
 			}
 			return nil
@@ -516,8 +601,7 @@ func getAssociatedCommits(ctx context.Context, repo *git.Repository, checkpointI
 				return errStopIteration
 			}
 
-			cpID, found := trailers.ParseCheckpoint(c.Message)
-			if found && cpID.String() == targetID {
+			if references(c) {
 				collectCommit(c)
 			}
 			return nil
@@ -549,7 +633,7 @@ func scopeTranscriptForCheckpoint(fullTranscript []byte, startOffset int, agentT
 			return nil
 		}
 		return scoped
-	case agent.AgentTypeClaudeCode, agent.AgentTypeCursor, agent.AgentTypeUnknown:
+	case agent.AgentTypeClaudeCode, agent.AgentTypeCursor, agent.AgentTypeAider, agent.AgentTypeUnknown:
 		return transcript.SliceFromLine(fullTranscript, startOffset)
 	}
 	return transcript.SliceFromLine(fullTranscript, startOffset)
@@ -619,6 +703,25 @@ func formatCheckpointOutput(summary *checkpoint.CheckpointSummary, content *chec
 			tokenUsage.CacheReadTokens + tokenUsage.OutputTokens
 		fmt.Fprintf(&sb, "Tokens: %d\n", totalTokens)
 	}
+	if cost, ok := formatCost(tokenUsage); ok {
+		fmt.Fprintf(&sb, "Cost: %s\n", cost)
+	}
+	// Checkpoints with several sessions also show the cost of all of them
+	if summary != nil && len(summary.Sessions) > 1 {
+		if cost, ok := formatCost(summary.TokenUsage); ok {
+			fmt.Fprintf(&sb, "Checkpoint cost: %s (%d sessions)\n", cost, len(summary.Sessions))
+		}
+	}
+
+	if len(meta.RedactionCounts) > 0 {
+		fmt.Fprintf(&sb, "Redactions: %s\n", formatRedactionCounts(meta.RedactionCounts))
+	}
+	if content.Encrypted {
+		sb.WriteString("Encrypted: transcript is encrypted and no configured identity can decrypt it\n")
+	}
+	if meta.CompactedAt != nil {
+		fmt.Fprintf(&sb, "Compacted: transcript removed by retention policy on %s\n", meta.CompactedAt.Format("2006-01-02"))
+	}
 
 	// Associated commits section
 	if len(associatedCommits) > 0 {
@@ -895,7 +998,7 @@ func getBranchCheckpoints(ctx context.Context, repo *git.Repository, limit int)
 	store := checkpoint.NewGitStore(repo)
 
 	// Get all committed checkpoints for lookup
-	committedInfos, err := store.ListCommitted(ctx)
+	committedInfos, err := store.ListCommittedIndexed(ctx)
 	if err != nil {
 		committedInfos = nil // Continue without committed checkpoints
 	}
@@ -1173,7 +1276,7 @@ func outputExplainContent(w io.Writer, content string, noPager bool) {
 }
 
 // runExplainCommit looks up the checkpoint associated with a commit.
-// Extracts the Entire-Checkpoint trailer and delegates to checkpoint detail view.
+// Extracts the Entire-Checkpoint trailer (or checkpoint note) and delegates to checkpoint detail view.
 // If no trailer found, shows a message indicating no associated checkpoint.
 func runExplainCommit(ctx context.Context, w io.Writer, commitRef string, noPager, verbose, full, searchAll bool) error {
 	repo, err := openRepository(ctx)
@@ -1192,18 +1295,55 @@ func runExplainCommit(ctx context.Context, w io.Writer, commitRef string, noPage
 		return fmt.Errorf("failed to get commit: %w", err)
 	}
 
-	// Extract Entire-Checkpoint trailer
-	checkpointID, hasCheckpoint := trailers.ParseCheckpoint(commit.Message)
-	if !hasCheckpoint {
+	checkpointIDs := commitCheckpointIDs(ctx, repo, commit)
+	switch len(checkpointIDs) {
+	case 0:
 		fmt.Fprintln(w, "No associated Entire checkpoint")
-		fmt.Fprintf(w, "\nCommit %s does not have an Entire-Checkpoint trailer.\n", hash.String()[:7])
+		fmt.Fprintf(w, "\nCommit %s does not have an Entire-Checkpoint trailer or checkpoint note.\n", hash.String()[:7])
 		fmt.Fprintln(w, "This commit was not created during an Entire session, or the trailer was removed.")
 		return nil
+	case 1:
+		// Delegate to checkpoint detail view
+		// Note: errW is only used for generate mode, but we pass w for safety
+		return runExplainCheckpoint(ctx, w, w, checkpointIDs[0].String(), noPager, verbose, full, false, false, false, searchAll)
+	}
+
+	// Squashed commits combine the work of several checkpoints
+	store := checkpoint.NewGitStore(repo)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Commit %s combines %d checkpoints:\n\n", hash.String()[:7], len(checkpointIDs))
+	for _, cpID := range checkpointIDs {
+		line := "  " + cpID.String()
+		if entry, err := store.LookupIndexed(ctx, cpID); err == nil && entry != nil {
+			line += " " + entry.CreatedAt.Format("2006-01-02 15:04")
+			if entry.Intent != "" {
+				line += " " + strings.SplitN(entry.Intent, "\n", 2)[0]
+			}
+		}
+		sb.WriteString(line + "\n")
 	}
+	sb.WriteString("\nRun 'entire explain --checkpoint <id>' for details.\n")
+	outputExplainContent(w, sb.String(), noPager)
+	return nil
+}
 
-	// Delegate to checkpoint detail view
-	// Note: errW is only used for generate mode, but we pass w for safety
-	return runExplainCheckpoint(ctx, w, w, checkpointID.String(), noPager, verbose, full, false, false, false, searchAll)
+// commitCheckpointIDs returns the checkpoints linked to commit: its
+// Entire-Checkpoint trailers (several after a squash) or checkpoint note, and
+// the checkpoints whose commits were amended, rebased or squashed into it.
+func commitCheckpointIDs(ctx context.Context, repo *git.Repository, commit *object.Commit) []id.CheckpointID {
+	checkpointIDs := trailers.ParseAllCheckpoints(commit.Message)
+	if len(checkpointIDs) == 0 {
+		if cpID, found := strategy.ReadCheckpointNote(repo, commit.Hash); found {
+			checkpointIDs = append(checkpointIDs, cpID)
+		}
+	}
+	rewritten, _ := checkpoint.NewGitStore(repo).CheckpointsForCommit(ctx, commit.Hash.String()) //nolint:errcheck // Best-effort
+	for _, cpID := range rewritten {
+		if !slices.Contains(checkpointIDs, cpID) {
+			checkpointIDs = append(checkpointIDs, cpID)
+		}
+	}
+	return checkpointIDs
 }
 
 // formatSessionInfo formats session information for display.
@@ -1353,15 +1493,7 @@ func formatBranchCheckpoints(branchName string, points []strategy.RewindPoint, s
 	fmt.Fprintf(&sb, "Branch: %s\n", branchName)
 
 	// Filter by session if specified
-	if sessionFilter != "" {
-		var filtered []strategy.RewindPoint
-		for _, p := range points {
-			if p.SessionID == sessionFilter || strings.HasPrefix(p.SessionID, sessionFilter) {
-				filtered = append(filtered, p)
-			}
-		}
-		points = filtered
-	}
+	points = filterPointsBySession(points, sessionFilter)
 
 	if len(points) == 0 {
 		sb.WriteString("Checkpoints: 0\n")
@@ -1391,6 +1523,21 @@ func formatBranchCheckpoints(branchName string, points []strategy.RewindPoint, s
 	return sb.String()
 }
 
+// filterPointsBySession returns the points whose session ID matches the filter (or prefix).
+// An empty filter returns points unchanged.
+func filterPointsBySession(points []strategy.RewindPoint, sessionFilter string) []strategy.RewindPoint {
+	if sessionFilter == "" {
+		return points
+	}
+	var filtered []strategy.RewindPoint
+	for _, p := range points {
+		if p.SessionID == sessionFilter || strings.HasPrefix(p.SessionID, sessionFilter) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
 // checkpointGroup represents a group of commits sharing the same checkpoint ID.
 type checkpointGroup struct {
 	checkpointID string
@@ -1403,6 +1550,7 @@ type checkpointGroup struct {
 // commitEntry represents a single git commit within a checkpoint.
 type commitEntry struct {
 	date    time.Time
+	sha     string // full git SHA
 	gitSHA  string // short git SHA
 	message string
 }
@@ -1450,6 +1598,7 @@ func groupByCheckpointID(points []strategy.RewindPoint) []checkpointGroup {
 
 		group.commits = append(group.commits, commitEntry{
 			date:    point.Date,
+			sha:     point.ID,
 			gitSHA:  gitSHA,
 			message: point.Message,
 		})
@@ -1565,7 +1714,7 @@ func transcriptOffset(transcriptBytes []byte, agentType agent.AgentType) int {
 			return 0
 		}
 		return len(t.Messages)
-	case agent.AgentTypeClaudeCode, agent.AgentTypeOpenCode, agent.AgentTypeCursor, agent.AgentTypeUnknown:
+	case agent.AgentTypeClaudeCode, agent.AgentTypeOpenCode, agent.AgentTypeCursor, agent.AgentTypeAider, agent.AgentTypeUnknown:
 		return countLines(transcriptBytes)
 	}
 	return countLines(transcriptBytes)
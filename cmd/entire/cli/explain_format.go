@@ -0,0 +1,551 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"gopkg.in/yaml.v3"
+)
+
+// explainSchemaVersion is the version of the structured explain output schema.
+// Bump it on breaking changes (renamed/removed fields or changed semantics).
+// Adding new optional fields does not require a bump.
+const explainSchemaVersion = 1
+
+// explainFormat selects how explain renders its output.
+type explainFormat string
+
+const (
+	explainFormatText     explainFormat = "text"
+	explainFormatJSON     explainFormat = "json"
+	explainFormatYAML     explainFormat = "yaml"
+	explainFormatMarkdown explainFormat = "markdown"
+)
+
+// Document kinds for the structured explain output.
+const (
+	explainKindBranch     = "branch"
+	explainKindCheckpoint = "checkpoint"
+	explainKindCommit     = "commit"
+)
+
+// promptSeparator is the separator used when prompts are joined into prompt.txt.
+const promptSeparator = "\n\n---\n\n"
+
+// resolveExplainFormat resolves the --format and --json flags into an output format.
+func resolveExplainFormat(format string, jsonFlag bool) (explainFormat, error) {
+	if jsonFlag {
+		return explainFormatJSON, nil
+	}
+	switch strings.ToLower(format) {
+	case "", string(explainFormatText):
+		return explainFormatText, nil
+	case string(explainFormatJSON):
+		return explainFormatJSON, nil
+	case string(explainFormatYAML), "yml":
+		return explainFormatYAML, nil
+	case string(explainFormatMarkdown), "md":
+		return explainFormatMarkdown, nil
+	default:
+		return "", fmt.Errorf("invalid --format %q (expected text, json, yaml, or markdown)", format)
+	}
+}
+
+// explainCommitDoc describes a git commit in structured explain output.
+type explainCommitDoc struct {
+	SHA     string    `json:"sha"`
+	Message string    `json:"message"`
+	Author  string    `json:"author,omitempty"`
+	Date    time.Time `json:"date"`
+}
+
+// explainAuthorDoc is the author of a committed checkpoint.
+type explainAuthorDoc struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// explainSessionDoc is one session of a checkpoint.
+// Prompts are scoped to this checkpoint's portion of the session transcript.
+type explainSessionDoc struct {
+	Metadata         checkpoint.CommittedMetadata `json:"metadata"`
+	Prompts          []string                     `json:"prompts"`
+	EstimatedCostUSD *float64                     `json:"estimated_cost_usd,omitempty"`
+}
+
+// explainCheckpointDoc is the structured output of --checkpoint and --commit.
+// Summary and InitialAttribution are taken from the latest session, matching the text view.
+type explainCheckpointDoc struct {
+	SchemaVersion      int                            `json:"schema_version"`
+	Kind               string                         `json:"kind"`
+	Commit             *explainCommitDoc              `json:"commit,omitempty"` // The explained commit (--commit only)
+	CheckpointID       string                         `json:"checkpoint_id,omitempty"`
+	Author             *explainAuthorDoc              `json:"author,omitempty"`
+	Checkpoint         *checkpoint.CheckpointSummary  `json:"checkpoint,omitempty"`
+	Summary            *checkpoint.Summary            `json:"summary,omitempty"`
+	InitialAttribution *checkpoint.InitialAttribution `json:"initial_attribution,omitempty"`
+	EstimatedCostUSD   *float64                       `json:"estimated_cost_usd,omitempty"` // All sessions, from the per-model token usage
+	Sessions           []explainSessionDoc            `json:"sessions"`
+	Commits            []explainCommitDoc             `json:"commits"` // Commits referencing the checkpoint
+}
+
+// explainBranchCheckpointDoc is one checkpoint in the branch list view.
+// For temporary checkpoints CheckpointID holds the session ID and Checkpoint is nil.
+type explainBranchCheckpointDoc struct {
+	CheckpointID string                        `json:"checkpoint_id"`
+	Temporary    bool                          `json:"temporary"`
+	Task         bool                          `json:"task,omitempty"`
+	Prompt       string                        `json:"prompt,omitempty"`
+	Checkpoint   *checkpoint.CheckpointSummary `json:"checkpoint,omitempty"`
+	Commits      []explainCommitDoc            `json:"commits"`
+}
+
+// explainBranchDoc is the structured output of the default list view.
+type explainBranchDoc struct {
+	SchemaVersion int                          `json:"schema_version"`
+	Kind          string                       `json:"kind"`
+	Branch        string                       `json:"branch"`
+	SessionFilter string                       `json:"session_filter,omitempty"`
+	Checkpoints   []explainBranchCheckpointDoc `json:"checkpoints"`
+}
+
+// runExplainStructured is the structured-output counterpart of runExplain.
+// Progress messages (e.g., from --generate) go to errW so w only receives the document.
+func runExplainStructured(ctx context.Context, w, errW io.Writer, format explainFormat, sessionID, commitRef, checkpointID string, generate, force, searchAll bool) error {
+	if err := validateExplainTargets(sessionID, commitRef, checkpointID); err != nil {
+		return err
+	}
+
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+
+	var doc any
+	switch {
+	case commitRef != "":
+		doc, err = buildExplainCommitDoc(ctx, repo, commitRef, searchAll)
+	case checkpointID != "":
+		doc, err = buildExplainCheckpointDoc(ctx, errW, repo, checkpointID, generate, force, searchAll)
+	default:
+		doc, err = buildExplainBranchDoc(ctx, repo, sessionID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeExplainDoc(w, format, doc)
+}
+
+// buildExplainCheckpointDoc builds the structured document for a committed checkpoint.
+func buildExplainCheckpointDoc(ctx context.Context, errW io.Writer, repo *git.Repository, checkpointIDPrefix string, generate, force, searchAll bool) (*explainCheckpointDoc, error) {
+	store := checkpoint.NewGitStore(repo)
+
+	matches, err := matchCommittedCheckpoints(ctx, store, checkpointIDPrefix)
+	if err != nil {
+		return nil, err
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("checkpoint not found: %s (structured output only supports committed checkpoints)", checkpointIDPrefix)
+	case 1:
+	default:
+		return nil, ambiguousCheckpointError(checkpointIDPrefix, matches)
+	}
+	cpID := matches[0]
+
+	summary, err := store.ReadCommitted(ctx, cpID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if summary == nil {
+		return nil, fmt.Errorf("checkpoint not found: %s", cpID)
+	}
+
+	if generate {
+		content, err := store.ReadLatestSessionContent(ctx, cpID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read checkpoint content: %w", err)
+		}
+		if err := generateCheckpointSummary(ctx, errW, errW, store, cpID, summary, content, force); err != nil {
+			return nil, err
+		}
+	}
+
+	doc := &explainCheckpointDoc{
+		SchemaVersion:    explainSchemaVersion,
+		Kind:             explainKindCheckpoint,
+		CheckpointID:     cpID.String(),
+		Checkpoint:       summary,
+		EstimatedCostUSD: costUSD(summary.TokenUsage),
+		Sessions:         []explainSessionDoc{},
+		Commits:          []explainCommitDoc{},
+	}
+
+	for i := range len(summary.Sessions) {
+		content, readErr := store.ReadSessionContent(ctx, cpID, i)
+		if readErr != nil {
+			continue
+		}
+		doc.Sessions = append(doc.Sessions, explainSessionDoc{
+			Metadata:         content.Metadata,
+			Prompts:          scopedPromptsForSession(content),
+			EstimatedCostUSD: costUSD(content.Metadata.TokenUsage),
+		})
+	}
+	if n := len(doc.Sessions); n > 0 {
+		latest := doc.Sessions[n-1].Metadata
+		doc.Summary = latest.Summary
+		doc.InitialAttribution = latest.InitialAttribution
+	}
+
+	if author, authorErr := store.GetCheckpointAuthor(ctx, cpID); authorErr == nil && author.Name != "" {
+		doc.Author = &explainAuthorDoc{Name: author.Name, Email: author.Email}
+	}
+
+	associated, _ := getAssociatedCommits(ctx, repo, cpID, searchAll) //nolint:errcheck // Best-effort
+	for _, c := range associated {
+		doc.Commits = append(doc.Commits, explainCommitDoc{
+			SHA:     c.SHA,
+			Message: c.Message,
+			Author:  c.Author,
+			Date:    c.Date,
+		})
+	}
+
+	return doc, nil
+}
+
+// buildExplainCommitDoc builds the structured document for --commit.
+// Commits without an Entire-Checkpoint trailer produce a document with no checkpoint.
+func buildExplainCommitDoc(ctx context.Context, repo *git.Repository, commitRef string, searchAll bool) (*explainCheckpointDoc, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(commitRef))
+	if err != nil {
+		return nil, fmt.Errorf("commit not found: %s", commitRef)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit: %w", err)
+	}
+
+	commitDoc := &explainCommitDoc{
+		SHA:     commit.Hash.String(),
+		Message: strings.Split(commit.Message, "\n")[0],
+		Author:  commit.Author.Name,
+		Date:    commit.Author.When,
+	}
+
+	cpID, found := trailers.ParseCheckpoint(commit.Message)
+	if !found {
+		return &explainCheckpointDoc{
+			SchemaVersion: explainSchemaVersion,
+			Kind:          explainKindCommit,
+			Commit:        commitDoc,
+			Sessions:      []explainSessionDoc{},
+			Commits:       []explainCommitDoc{},
+		}, nil
+	}
+
+	doc, err := buildExplainCheckpointDoc(ctx, io.Discard, repo, cpID.String(), false, false, searchAll)
+	if err != nil {
+		return nil, err
+	}
+	doc.Kind = explainKindCommit
+	doc.Commit = commitDoc
+	return doc, nil
+}
+
+// buildExplainBranchDoc builds the structured document for the branch list view.
+func buildExplainBranchDoc(ctx context.Context, repo *git.Repository, sessionFilter string) (*explainBranchDoc, error) {
+	branchName := strategy.GetCurrentBranchName(repo)
+	if branchName == "" {
+		if head, err := repo.Head(); err == nil {
+			branchName = "HEAD (" + head.Hash().String()[:7] + ")"
+		} else {
+			branchName = "HEAD"
+		}
+	}
+
+	points, err := getBranchCheckpoints(ctx, repo, branchCheckpointsLimit)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, NewSilentError(ctx.Err())
+		}
+		return nil, err
+	}
+
+	doc := &explainBranchDoc{
+		SchemaVersion: explainSchemaVersion,
+		Kind:          explainKindBranch,
+		Branch:        branchName,
+		SessionFilter: sessionFilter,
+		Checkpoints:   []explainBranchCheckpointDoc{},
+	}
+
+	store := checkpoint.NewGitStore(repo)
+	for _, group := range groupByCheckpointID(filterPointsBySession(points, sessionFilter)) {
+		entry := explainBranchCheckpointDoc{
+			CheckpointID: group.checkpointID,
+			Temporary:    group.isTemporary,
+			Task:         group.isTask,
+			Prompt:       group.prompt,
+			Commits:      make([]explainCommitDoc, 0, len(group.commits)),
+		}
+		if !group.isTemporary {
+			if cpID, idErr := id.NewCheckpointID(group.checkpointID); idErr == nil {
+				entry.Checkpoint, _ = store.ReadCommitted(ctx, cpID) //nolint:errcheck // Best-effort
+			}
+		}
+		for _, c := range group.commits {
+			entry.Commits = append(entry.Commits, explainCommitDoc{
+				SHA:     c.sha,
+				Message: c.message,
+				Date:    c.date,
+			})
+		}
+		doc.Checkpoints = append(doc.Checkpoints, entry)
+	}
+
+	return doc, nil
+}
+
+// scopedPromptsForSession returns the prompts in this checkpoint's portion of the session.
+// Falls back to prompt.txt for older checkpoints without a parseable transcript.
+func scopedPromptsForSession(content *checkpoint.SessionContent) []string {
+	meta := content.Metadata
+	scoped := scopeTranscriptForCheckpoint(content.Transcript, meta.GetTranscriptStart(), meta.Agent)
+	if prompts := extractPromptsFromTranscript(scoped, meta.Agent); len(prompts) > 0 {
+		return prompts
+	}
+	prompts := []string{}
+	for _, p := range strings.Split(content.Prompts, promptSeparator) {
+		if p = strings.TrimSpace(p); p != "" {
+			prompts = append(prompts, p)
+		}
+	}
+	return prompts
+}
+
+// writeExplainDoc renders a structured document in the requested format.
+func writeExplainDoc(w io.Writer, format explainFormat, doc any) error {
+	var data []byte
+	var err error
+	switch format {
+	case explainFormatJSON:
+		data, err = jsonutil.MarshalIndentWithNewline(doc, "", "  ")
+	case explainFormatYAML:
+		data, err = marshalExplainYAML(doc)
+	case explainFormatMarkdown:
+		data = []byte(renderExplainMarkdown(doc))
+	case explainFormatText:
+		return errors.New("text format is not a structured format")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode explain output: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write explain output: %w", err)
+	}
+	return nil
+}
+
+// marshalExplainYAML encodes doc as YAML using its JSON field names and order,
+// so the YAML and JSON outputs share one schema.
+func marshalExplainYAML(doc any) ([]byte, error) {
+	jsonData, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(jsonData, &node); err != nil {
+		return nil, fmt.Errorf("failed to convert JSON to YAML: %w", err)
+	}
+	clearYAMLStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// clearYAMLStyle resets node styles parsed from JSON (flow mappings, double quotes)
+// so the encoder emits idiomatic block-style YAML.
+func clearYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearYAMLStyle(child)
+	}
+}
+
+// renderExplainMarkdown renders a structured explain document as Markdown.
+func renderExplainMarkdown(doc any) string {
+	switch d := doc.(type) {
+	case *explainCheckpointDoc:
+		return renderCheckpointMarkdown(d)
+	case *explainBranchDoc:
+		return renderBranchMarkdown(d)
+	}
+	return ""
+}
+
+func renderCheckpointMarkdown(d *explainCheckpointDoc) string {
+	var sb strings.Builder
+
+	if d.Commit != nil {
+		fmt.Fprintf(&sb, "# Commit %s\n\n", shortSHA(d.Commit.SHA))
+		fmt.Fprintf(&sb, "- **Message:** %s\n", d.Commit.Message)
+		fmt.Fprintf(&sb, "- **Author:** %s\n", d.Commit.Author)
+		fmt.Fprintf(&sb, "- **Date:** %s\n\n", d.Commit.Date.Format("2006-01-02 15:04:05"))
+		if d.CheckpointID == "" {
+			sb.WriteString("No associated Entire checkpoint.\n")
+			return sb.String()
+		}
+	}
+
+	heading := "#"
+	if d.Commit != nil {
+		heading = "##"
+	}
+	fmt.Fprintf(&sb, "%s Checkpoint %s\n\n", heading, d.CheckpointID)
+
+	if n := len(d.Sessions); n > 0 {
+		latest := d.Sessions[n-1].Metadata
+		fmt.Fprintf(&sb, "- **Session:** %s\n", latest.SessionID)
+		fmt.Fprintf(&sb, "- **Created:** %s\n", latest.CreatedAt.Format("2006-01-02 15:04:05"))
+		if latest.Agent != "" {
+			fmt.Fprintf(&sb, "- **Agent:** %s\n", latest.Agent)
+		}
+		if latest.Branch != "" {
+			fmt.Fprintf(&sb, "- **Branch:** %s\n", latest.Branch)
+		}
+	}
+	if d.Author != nil {
+		fmt.Fprintf(&sb, "- **Author:** %s <%s>\n", d.Author.Name, d.Author.Email)
+	}
+	if d.Checkpoint != nil && d.Checkpoint.TokenUsage != nil {
+		tu := d.Checkpoint.TokenUsage
+		fmt.Fprintf(&sb, "- **Tokens:** %d\n", tu.InputTokens+tu.CacheCreationTokens+tu.CacheReadTokens+tu.OutputTokens)
+		if cost, ok := formatCost(tu); ok {
+			fmt.Fprintf(&sb, "- **Estimated cost:** %s\n", cost)
+		}
+	}
+	if a := d.InitialAttribution; a != nil {
+		fmt.Fprintf(&sb, "- **Agent attribution:** %.1f%% (%d of %d lines)\n", a.AgentPercentage, a.AgentLines, a.TotalCommitted)
+	}
+
+	if s := d.Summary; s != nil {
+		sb.WriteString("\n## Summary\n\n")
+		fmt.Fprintf(&sb, "**Intent:** %s\n\n", s.Intent)
+		fmt.Fprintf(&sb, "**Outcome:** %s\n", s.Outcome)
+		writeMarkdownList(&sb, "Repository learnings", s.Learnings.Repo)
+		codeLearnings := make([]string, 0, len(s.Learnings.Code))
+		for _, cl := range s.Learnings.Code {
+			codeLearnings = append(codeLearnings, fmt.Sprintf("`%s`: %s", cl.Path, cl.Finding))
+		}
+		writeMarkdownList(&sb, "Code learnings", codeLearnings)
+		writeMarkdownList(&sb, "Workflow learnings", s.Learnings.Workflow)
+		writeMarkdownList(&sb, "Friction", s.Friction)
+		writeMarkdownList(&sb, "Open items", s.OpenItems)
+	}
+
+	if len(d.Commits) > 0 {
+		sb.WriteString("\n## Commits\n\n")
+		for _, c := range d.Commits {
+			fmt.Fprintf(&sb, "- `%s` %s %s\n", shortSHA(c.SHA), c.Date.Format("2006-01-02"), c.Message)
+		}
+	}
+
+	if d.Checkpoint != nil && len(d.Checkpoint.FilesTouched) > 0 {
+		sb.WriteString("\n## Files\n\n")
+		for _, f := range d.Checkpoint.FilesTouched {
+			fmt.Fprintf(&sb, "- `%s`\n", f)
+		}
+	}
+
+	for _, s := range d.Sessions {
+		if len(s.Prompts) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n## Prompts (session %s)\n\n", s.Metadata.SessionID)
+		for i, p := range s.Prompts {
+			fmt.Fprintf(&sb, "%d. %s\n", i+1, strings.ReplaceAll(strings.TrimSpace(p), "\n", "\n   "))
+		}
+	}
+
+	return sb.String()
+}
+
+func renderBranchMarkdown(d *explainBranchDoc) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Branch %s\n\n", d.Branch)
+	if d.SessionFilter != "" {
+		fmt.Fprintf(&sb, "Filtered by session: `%s`\n\n", d.SessionFilter)
+	}
+	if len(d.Checkpoints) == 0 {
+		sb.WriteString("No checkpoints found on this branch.\n")
+		return sb.String()
+	}
+
+	sb.WriteString("| Checkpoint | Prompt | Commits |\n")
+	sb.WriteString("| --- | --- | --- |\n")
+	for _, cp := range d.Checkpoints {
+		label := "`" + cp.CheckpointID + "`"
+		if cp.Task {
+			label += " [Task]"
+		}
+		if cp.Temporary {
+			label += " [temporary]"
+		}
+		commits := make([]string, 0, len(cp.Commits))
+		for _, c := range cp.Commits {
+			commits = append(commits, fmt.Sprintf("`%s` %s", shortSHA(c.SHA), escapeMarkdownCell(c.Message)))
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s |\n", label, escapeMarkdownCell(cp.Prompt), strings.Join(commits, "<br>"))
+	}
+	return sb.String()
+}
+
+// writeMarkdownList writes a titled bullet list, skipping empty lists.
+func writeMarkdownList(sb *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "\n**%s:**\n\n", title)
+	for _, item := range items {
+		fmt.Fprintf(sb, "- %s\n", item)
+	}
+}
+
+// escapeMarkdownCell makes text safe for a single Markdown table cell.
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// shortSHA returns the 7-character abbreviation of a commit SHA.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
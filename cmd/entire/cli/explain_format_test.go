@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"gopkg.in/yaml.v3"
+)
+
+const structuredTestCheckpointID = "f1e2d3c4b5a6"
+
+// setupStructuredExplainRepo creates a repo with one committed checkpoint linked
+// from HEAD via the Entire-Checkpoint trailer. Returns the HEAD commit SHA.
+func setupStructuredExplainRepo(t *testing.T) string {
+	t.Helper()
+	tmpDir, repo := setupCheckpointTestRepo(t, "hello")
+	writeTestCheckpoint(t, repo, checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID(structuredTestCheckpointID),
+		SessionID:    "structured-session",
+		Agent:        agent.AgentTypeClaudeCode,
+		FilesTouched: []string{"main.go"},
+		Prompts:      []string{"Add a main function"},
+		Transcript:   []byte(`{"type":"user","uuid":"u1","message":{"content":"Add a main function"}}` + "\n"),
+		TokenUsage:   &agent.TokenUsage{InputTokens: 100, OutputTokens: 20},
+		Summary:      &checkpoint.Summary{Intent: "Create entry point", Outcome: "Added main.go"},
+		InitialAttribution: &checkpoint.InitialAttribution{
+			AgentLines:      8,
+			TotalCommitted:  10,
+			AgentPercentage: 80,
+		},
+	})
+	commitWithCheckpoint(t, tmpDir, "Add main", structuredTestCheckpointID, map[string]string{"main.go": "package main\n"})
+	return testutil.GetHeadHash(t, tmpDir)
+}
+
+func TestResolveExplainFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		json    bool
+		want    explainFormat
+		wantErr bool
+	}{
+		{"", false, explainFormatText, false},
+		{"", true, explainFormatJSON, false},
+		{"JSON", false, explainFormatJSON, false},
+		{"yml", false, explainFormatYAML, false},
+		{"md", false, explainFormatMarkdown, false},
+		{"xml", false, "", true},
+	}
+	for _, tt := range tests {
+		got, err := resolveExplainFormat(tt.format, tt.json)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resolveExplainFormat(%q, %v) error = %v, wantErr %v", tt.format, tt.json, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resolveExplainFormat(%q, %v) = %q, want %q", tt.format, tt.json, got, tt.want)
+		}
+	}
+}
+
+func TestExplainCmd_JSONAndFormatMutuallyExclusive(t *testing.T) {
+	cmd := newExplainCmd()
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"--json", "--format", "yaml"})
+
+	if err := cmd.Execute(); err == nil {
+		t.Error("expected error when combining --json and --format")
+	}
+}
+
+func TestRunExplainStructured_CheckpointJSON(t *testing.T) {
+	headSHA := setupStructuredExplainRepo(t)
+
+	var buf, errBuf bytes.Buffer
+	err := runExplainStructured(context.Background(), &buf, &errBuf, explainFormatJSON, "", "", structuredTestCheckpointID[:6], false, false, false)
+	if err != nil {
+		t.Fatalf("runExplainStructured() error = %v", err)
+	}
+
+	var doc explainCheckpointDoc
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if doc.SchemaVersion != explainSchemaVersion || doc.Kind != explainKindCheckpoint {
+		t.Errorf("unexpected header: schema_version=%d kind=%q", doc.SchemaVersion, doc.Kind)
+	}
+	if doc.CheckpointID != structuredTestCheckpointID {
+		t.Errorf("checkpoint_id = %q, want %q", doc.CheckpointID, structuredTestCheckpointID)
+	}
+	if doc.Checkpoint == nil || len(doc.Checkpoint.FilesTouched) != 1 {
+		t.Errorf("expected checkpoint summary with files, got %+v", doc.Checkpoint)
+	}
+	if doc.Summary == nil || doc.Summary.Intent != "Create entry point" {
+		t.Errorf("summary = %+v, want intent 'Create entry point'", doc.Summary)
+	}
+	if doc.InitialAttribution == nil || doc.InitialAttribution.AgentLines != 8 {
+		t.Errorf("initial_attribution = %+v, want AgentLines 8", doc.InitialAttribution)
+	}
+	if len(doc.Sessions) != 1 || doc.Sessions[0].Metadata.SessionID != "structured-session" {
+		t.Fatalf("unexpected sessions: %+v", doc.Sessions)
+	}
+	if len(doc.Sessions[0].Prompts) != 1 || doc.Sessions[0].Prompts[0] != "Add a main function" {
+		t.Errorf("prompts = %v, want [Add a main function]", doc.Sessions[0].Prompts)
+	}
+	if len(doc.Commits) != 1 || doc.Commits[0].SHA != headSHA {
+		t.Errorf("commits = %+v, want HEAD %s", doc.Commits, headSHA)
+	}
+}
+
+func TestRunExplainStructured_CommitWithoutCheckpoint(t *testing.T) {
+	setupStructuredExplainRepo(t)
+
+	var buf, errBuf bytes.Buffer
+	if err := runExplainStructured(context.Background(), &buf, &errBuf, explainFormatJSON, "", "HEAD~1", "", false, false, false); err != nil {
+		t.Fatalf("runExplainStructured() error = %v", err)
+	}
+
+	var doc explainCheckpointDoc
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if doc.Kind != explainKindCommit || doc.Commit == nil {
+		t.Fatalf("expected commit document, got %+v", doc)
+	}
+	if doc.CheckpointID != "" || doc.Checkpoint != nil {
+		t.Errorf("expected no checkpoint for commit without trailer, got %+v", doc)
+	}
+}
+
+func TestRunExplainStructured_CommitYAML(t *testing.T) {
+	setupStructuredExplainRepo(t)
+
+	var buf, errBuf bytes.Buffer
+	if err := runExplainStructured(context.Background(), &buf, &errBuf, explainFormatYAML, "", "HEAD", "", false, false, false); err != nil {
+		t.Fatalf("runExplainStructured() error = %v", err)
+	}
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid YAML: %v\n%s", err, buf.String())
+	}
+	if doc["kind"] != explainKindCommit {
+		t.Errorf("kind = %v, want %q", doc["kind"], explainKindCommit)
+	}
+	if doc["checkpoint_id"] != structuredTestCheckpointID {
+		t.Errorf("checkpoint_id = %v, want %q", doc["checkpoint_id"], structuredTestCheckpointID)
+	}
+	if !strings.HasPrefix(buf.String(), "schema_version: 1\n") {
+		t.Errorf("expected block-style YAML in JSON field order, got:\n%s", buf.String())
+	}
+}
+
+func TestRunExplainStructured_BranchJSON(t *testing.T) {
+	setupStructuredExplainRepo(t)
+
+	var buf, errBuf bytes.Buffer
+	if err := runExplainStructured(context.Background(), &buf, &errBuf, explainFormatJSON, "", "", "", false, false, false); err != nil {
+		t.Fatalf("runExplainStructured() error = %v", err)
+	}
+
+	var doc explainBranchDoc
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if doc.Kind != explainKindBranch {
+		t.Errorf("kind = %q, want %q", doc.Kind, explainKindBranch)
+	}
+	if len(doc.Checkpoints) != 1 {
+		t.Fatalf("expected 1 checkpoint, got %d", len(doc.Checkpoints))
+	}
+	cp := doc.Checkpoints[0]
+	if cp.CheckpointID != structuredTestCheckpointID || cp.Temporary {
+		t.Errorf("unexpected checkpoint entry: %+v", cp)
+	}
+	if cp.Checkpoint == nil {
+		t.Error("expected checkpoint summary for committed checkpoint")
+	}
+	if len(cp.Commits) != 1 || len(cp.Commits[0].SHA) != 40 {
+		t.Errorf("expected one commit with full SHA, got %+v", cp.Commits)
+	}
+}
+
+func TestRunExplainStructured_Markdown(t *testing.T) {
+	setupStructuredExplainRepo(t)
+
+	var buf, errBuf bytes.Buffer
+	if err := runExplainStructured(context.Background(), &buf, &errBuf, explainFormatMarkdown, "", "", structuredTestCheckpointID, false, false, false); err != nil {
+		t.Fatalf("runExplainStructured() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"# Checkpoint " + structuredTestCheckpointID,
+		"**Intent:** Create entry point",
+		"**Agent attribution:** 80.0% (8 of 10 lines)",
+		"- `main.go`",
+		"1. Add a main function",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunExplainStructured_RejectsMultipleTargets(t *testing.T) {
+	var buf, errBuf bytes.Buffer
+	err := runExplainStructured(context.Background(), &buf, &errBuf, explainFormatJSON, "", "HEAD", "abc123", false, false, false)
+	if err == nil || !strings.Contains(err.Error(), "cannot specify multiple") {
+		t.Errorf("expected multiple-targets error, got %v", err)
+	}
+}
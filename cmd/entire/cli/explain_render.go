@@ -0,0 +1,539 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/summarize"
+)
+
+// renderFormat selects the document format for 'explain --render'.
+type renderFormat string
+
+const (
+	renderFormatHTML     renderFormat = "html"
+	renderFormatMarkdown renderFormat = "markdown"
+)
+
+// resolveRenderFormat parses the --render flag.
+func resolveRenderFormat(format string) (renderFormat, error) {
+	switch strings.ToLower(format) {
+	case string(renderFormatHTML), "htm":
+		return renderFormatHTML, nil
+	case string(renderFormatMarkdown), "md":
+		return renderFormatMarkdown, nil
+	default:
+		return "", fmt.Errorf("invalid --render %q (expected html or markdown)", format)
+	}
+}
+
+// renderDoc is a checkpoint's sessions laid out for rendering.
+type renderDoc struct {
+	CheckpointID string
+	Sessions     []renderSession
+}
+
+// renderSession is one session of the checkpoint.
+type renderSession struct {
+	SessionID  string
+	Agent      agent.AgentType
+	CreatedAt  time.Time
+	TokenUsage *agent.TokenUsage
+	Turns      []renderTurn
+}
+
+// renderTurn is a user prompt and everything the agent did in response.
+type renderTurn struct {
+	Prompt       string
+	Steps        []renderStep
+	FilesTouched []string
+}
+
+// renderStep is an assistant response (Text) or a tool call (ToolName set).
+type renderStep struct {
+	Text       string
+	ToolName   string
+	ToolDetail string
+	ToolInput  string // Indented JSON
+	Diffs      []renderDiff
+	Subagent   []renderTurn // Transcript of the subagent spawned by this tool call
+}
+
+// renderDiff is a file change made by a tool call, in diff syntax.
+type renderDiff struct {
+	Path string
+	Body string
+}
+
+// runExplainRender renders a committed checkpoint's transcripts as a standalone
+// document, written to output or to w if output is empty.
+func runExplainRender(ctx context.Context, w io.Writer, checkpointIDPrefix, format, output string) error {
+	rf, err := resolveRenderFormat(format)
+	if err != nil {
+		return err
+	}
+
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	store := checkpoint.NewGitStore(repo)
+
+	matches, err := matchCommittedCheckpoints(ctx, store, checkpointIDPrefix)
+	if err != nil {
+		return err
+	}
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("committed checkpoint not found: %s", checkpointIDPrefix)
+	case 1:
+	default:
+		return ambiguousCheckpointError(checkpointIDPrefix, matches)
+	}
+
+	doc, err := buildRenderDoc(ctx, store, matches[0])
+	if err != nil {
+		return err
+	}
+
+	var rendered string
+	if rf == renderFormatHTML {
+		if rendered, err = renderTranscriptHTML(doc); err != nil {
+			return err
+		}
+	} else {
+		rendered = renderTranscriptMarkdown(doc)
+	}
+
+	if output == "" {
+		_, err := io.WriteString(w, rendered)
+		if err != nil {
+			return fmt.Errorf("failed to write rendered transcript: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(output, []byte(rendered), 0o644); err != nil { //nolint:gosec // rendered transcripts are meant to be shared
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	fmt.Fprintf(w, "Rendered checkpoint %s to %s\n", doc.CheckpointID, output)
+	return nil
+}
+
+// buildRenderDoc reads every session of a committed checkpoint and condenses its transcript.
+func buildRenderDoc(ctx context.Context, store *checkpoint.GitStore, checkpointID id.CheckpointID) (*renderDoc, error) {
+	summary, err := store.ReadCommitted(ctx, checkpointID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if summary == nil {
+		return nil, fmt.Errorf("checkpoint not found: %s", checkpointID)
+	}
+
+	// Subagent transcripts are stored per tool call at tasks/<tool-use-id>/agent-<agent-id>.jsonl.
+	subagents := make(map[string][]byte)
+	if files, err := store.ReadCheckpointFiles(ctx, checkpointID); err == nil {
+		for name, content := range files {
+			dir, base := path.Split(name)
+			toolUseID, found := strings.CutPrefix(strings.TrimSuffix(dir, "/"), "tasks/")
+			if found && !strings.Contains(toolUseID, "/") && strings.HasPrefix(base, "agent-") && strings.HasSuffix(base, ".jsonl") {
+				subagents[toolUseID] = content
+			}
+		}
+	}
+
+	doc := &renderDoc{CheckpointID: checkpointID.String()}
+	for i := range max(len(summary.Sessions), 1) {
+		content, err := store.ReadSessionContent(ctx, checkpointID, i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session %d of checkpoint %s: %w", i, checkpointID, err)
+		}
+		meta := content.Metadata
+
+		session := renderSession{
+			SessionID:  meta.SessionID,
+			Agent:      meta.Agent,
+			CreatedAt:  meta.CreatedAt,
+			TokenUsage: meta.TokenUsage,
+		}
+		entries, parseErr := summarize.BuildCondensedTranscriptFromBytes(content.Transcript, meta.Agent)
+		if parseErr == nil && len(entries) > 0 {
+			session.Turns = buildRenderTurns(entries, subagents, meta.Agent)
+		} else {
+			// Fall back to the stored prompts when the transcript can't be parsed.
+			for _, prompt := range strings.Split(content.Prompts, promptSeparator) {
+				if prompt = strings.TrimSpace(prompt); prompt != "" {
+					session.Turns = append(session.Turns, renderTurn{Prompt: prompt})
+				}
+			}
+		}
+		doc.Sessions = append(doc.Sessions, session)
+	}
+	return doc, nil
+}
+
+// buildRenderTurns groups condensed transcript entries into turns, one per user prompt.
+// Tool calls that spawned a subagent with a stored transcript get it attached;
+// subagent transcripts are not searched for further subagents.
+func buildRenderTurns(entries []summarize.Entry, subagents map[string][]byte, agentType agent.AgentType) []renderTurn {
+	var turns []renderTurn
+	current := func() *renderTurn {
+		if len(turns) == 0 {
+			turns = append(turns, renderTurn{})
+		}
+		return &turns[len(turns)-1]
+	}
+
+	for _, entry := range entries {
+		switch entry.Type {
+		case summarize.EntryTypeUser:
+			turns = append(turns, renderTurn{Prompt: entry.Content})
+		case summarize.EntryTypeAssistant:
+			turn := current()
+			turn.Steps = append(turn.Steps, renderStep{Text: entry.Content})
+		case summarize.EntryTypeTool:
+			step := renderStep{
+				ToolName:   entry.ToolName,
+				ToolDetail: entry.ToolDetail,
+				ToolInput:  indentToolInput(entry.ToolInput),
+				Diffs:      toolInputDiffs(entry.ToolInput),
+			}
+			if transcript, ok := subagents[entry.ToolUseID]; ok && entry.ToolUseID != "" {
+				if subEntries, err := summarize.BuildCondensedTranscriptFromBytes(transcript, agentType); err == nil {
+					step.Subagent = buildRenderTurns(subEntries, nil, agentType)
+				}
+			}
+
+			turn := current()
+			for _, d := range step.Diffs {
+				if d.Path != "" && !slices.Contains(turn.FilesTouched, d.Path) {
+					turn.FilesTouched = append(turn.FilesTouched, d.Path)
+				}
+			}
+			turn.Steps = append(turn.Steps, step)
+		}
+	}
+	return turns
+}
+
+// indentToolInput pretty-prints a tool input JSON object.
+func indentToolInput(input string) string {
+	if input == "" {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(input), "", "  "); err != nil {
+		return input
+	}
+	return buf.String()
+}
+
+// toolInputDiffs derives file diffs from a tool's input. It recognizes the
+// input shapes of the supported agents' edit and write tools:
+// old/new string replacements (including MultiEdit's edits list), whole-file
+// writes, and patches.
+func toolInputDiffs(input string) []renderDiff {
+	if input == "" {
+		return nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(input), &fields); err != nil {
+		return nil
+	}
+
+	if patch := firstStringField(fields, "patch", "patchText"); patch != "" {
+		return []renderDiff{{Path: firstStringField(fields, "file_path", "filePath", "path"), Body: patch}}
+	}
+
+	filePath := firstStringField(fields, "file_path", "filePath", "path", "notebook_path")
+	if filePath == "" {
+		return nil
+	}
+
+	var diffs []renderDiff
+	if edits, ok := fields["edits"].([]any); ok {
+		var body strings.Builder
+		for _, e := range edits {
+			if edit, ok := e.(map[string]any); ok {
+				body.WriteString(replacementDiff(firstStringField(edit, "old_string", "oldString"), firstStringField(edit, "new_string", "newString")))
+			}
+		}
+		if body.Len() > 0 {
+			diffs = append(diffs, renderDiff{Path: filePath, Body: body.String()})
+		}
+		return diffs
+	}
+
+	oldText, hasOld := stringField(fields, "old_string", "oldString", "old_str")
+	newText, hasNew := stringField(fields, "new_string", "newString", "new_str", "new_source")
+	if hasOld || hasNew {
+		return []renderDiff{{Path: filePath, Body: replacementDiff(oldText, newText)}}
+	}
+	if content, ok := stringField(fields, "content", "contents"); ok {
+		return []renderDiff{{Path: filePath, Body: replacementDiff("", content)}}
+	}
+	return nil
+}
+
+// replacementDiff renders a text replacement as removed and added lines.
+func replacementDiff(oldText, newText string) string {
+	var sb strings.Builder
+	for _, line := range splitDiffLines(oldText) {
+		sb.WriteString("-" + line + "\n")
+	}
+	for _, line := range splitDiffLines(newText) {
+		sb.WriteString("+" + line + "\n")
+	}
+	return sb.String()
+}
+
+func splitDiffLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// stringField returns the first of keys present in fields as a string.
+func stringField(fields map[string]any, keys ...string) (string, bool) {
+	for _, key := range keys {
+		if v, ok := fields[key].(string); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// firstStringField returns the first non-empty string value among keys.
+func firstStringField(fields map[string]any, keys ...string) string {
+	for _, key := range keys {
+		if v, ok := fields[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// formatRenderTokens summarizes token usage, e.g.
+// "1,234 (input 1,000, output 200, cache read 34, 3 API calls)".
+func formatRenderTokens(tu *agent.TokenUsage) string {
+	if tu == nil {
+		return ""
+	}
+	total := tu.InputTokens + tu.CacheCreationTokens + tu.CacheReadTokens + tu.OutputTokens
+	parts := []string{
+		"input " + formatThousands(tu.InputTokens),
+		"output " + formatThousands(tu.OutputTokens),
+	}
+	if tu.CacheReadTokens > 0 {
+		parts = append(parts, "cache read "+formatThousands(tu.CacheReadTokens))
+	}
+	if tu.CacheCreationTokens > 0 {
+		parts = append(parts, "cache write "+formatThousands(tu.CacheCreationTokens))
+	}
+	if tu.APICallCount > 0 {
+		parts = append(parts, fmt.Sprintf("%d API calls", tu.APICallCount))
+	}
+	s := fmt.Sprintf("%s (%s)", formatThousands(total), strings.Join(parts, ", "))
+	if sub := tu.SubagentTokens; sub != nil {
+		s += "; subagents " + formatRenderTokens(sub)
+	}
+	return s
+}
+
+// formatThousands formats n with comma thousands separators.
+func formatThousands(n int) string {
+	if n < 0 {
+		return "-" + formatThousands(-n)
+	}
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// renderTranscriptMarkdown renders a document as GitHub-flavored Markdown.
+// Tool calls are collapsible <details> blocks.
+func renderTranscriptMarkdown(doc *renderDoc) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Checkpoint %s\n", doc.CheckpointID)
+
+	for _, s := range doc.Sessions {
+		fmt.Fprintf(&sb, "\n## Session %s\n\n", s.SessionID)
+		if s.Agent != "" {
+			fmt.Fprintf(&sb, "- **Agent:** %s\n", s.Agent)
+		}
+		if !s.CreatedAt.IsZero() {
+			fmt.Fprintf(&sb, "- **Created:** %s\n", s.CreatedAt.Format("2006-01-02 15:04:05"))
+		}
+		if s.TokenUsage != nil {
+			fmt.Fprintf(&sb, "- **Tokens:** %s\n", formatRenderTokens(s.TokenUsage))
+		}
+		writeMarkdownTurns(&sb, s.Turns, "###")
+	}
+	return sb.String()
+}
+
+func writeMarkdownTurns(sb *strings.Builder, turns []renderTurn, heading string) {
+	for i, turn := range turns {
+		fmt.Fprintf(sb, "\n%s Turn %d\n\n", heading, i+1)
+		if turn.Prompt != "" {
+			sb.WriteString("**User:**\n\n")
+			for _, line := range strings.Split(strings.TrimSpace(turn.Prompt), "\n") {
+				sb.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+			}
+			sb.WriteString("\n")
+		}
+
+		for _, step := range turn.Steps {
+			if step.ToolName == "" {
+				sb.WriteString("**Assistant:**\n\n")
+				sb.WriteString(strings.TrimSpace(step.Text) + "\n\n")
+				continue
+			}
+
+			summary := "Tool: " + step.ToolName
+			if step.ToolDetail != "" {
+				summary += " — " + step.ToolDetail
+			}
+			fmt.Fprintf(sb, "<details>\n<summary>%s</summary>\n\n", template.HTMLEscapeString(summary))
+			if step.ToolInput != "" {
+				writeMarkdownCodeBlock(sb, "json", step.ToolInput)
+			}
+			for _, d := range step.Diffs {
+				if d.Path != "" {
+					fmt.Fprintf(sb, "`%s`\n\n", d.Path)
+				}
+				writeMarkdownCodeBlock(sb, "diff", d.Body)
+			}
+			if len(step.Subagent) > 0 {
+				sb.WriteString("**Subagent transcript:**\n")
+				writeMarkdownTurns(sb, step.Subagent, "####")
+				sb.WriteString("\n")
+			}
+			sb.WriteString("</details>\n\n")
+		}
+
+		if len(turn.FilesTouched) > 0 {
+			files := make([]string, 0, len(turn.FilesTouched))
+			for _, f := range turn.FilesTouched {
+				files = append(files, "`"+f+"`")
+			}
+			fmt.Fprintf(sb, "**Files touched:** %s\n", strings.Join(files, ", "))
+		}
+	}
+}
+
+// writeMarkdownCodeBlock writes a fenced code block whose fence is longer
+// than any backtick run in content.
+func writeMarkdownCodeBlock(sb *strings.Builder, lang, content string) {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(sb, "%s%s\n%s\n%s\n\n", fence, lang, strings.TrimSuffix(content, "\n"), fence)
+}
+
+// renderTranscriptHTML renders a document as a self-contained HTML page.
+func renderTranscriptHTML(doc *renderDoc) (string, error) {
+	var buf bytes.Buffer
+	if err := renderHTMLTemplate.Execute(&buf, doc); err != nil {
+		return "", fmt.Errorf("failed to render HTML: %w", err)
+	}
+	return buf.String(), nil
+}
+
+var renderHTMLTemplate = template.Must(template.New("doc").Funcs(template.FuncMap{
+	"tokens":    formatRenderTokens,
+	"inc":       func(i int) int { return i + 1 },
+	"isAdded":   func(line string) bool { return strings.HasPrefix(line, "+") },
+	"isRemoved": func(line string) bool { return strings.HasPrefix(line, "-") },
+	"lines":     splitDiffLines,
+	"date":      func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Checkpoint {{.CheckpointID}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; color: #1f2328; line-height: 1.5; }
+h1, h2 { border-bottom: 1px solid #d0d7de; padding-bottom: .3em; }
+dl.meta { display: grid; grid-template-columns: max-content auto; gap: .2em 1em; }
+dl.meta dt { font-weight: 600; }
+dl.meta dd { margin: 0; }
+.turn { border-left: 3px solid #d0d7de; padding-left: 1em; margin: 1.5em 0; }
+.prompt { background: #ddf4ff; border-radius: 6px; padding: .6em 1em; white-space: pre-wrap; }
+.assistant { white-space: pre-wrap; margin: .8em 0; }
+.label { font-size: .8em; font-weight: 600; text-transform: uppercase; color: #59636e; }
+details { border: 1px solid #d0d7de; border-radius: 6px; margin: .6em 0; padding: .3em .8em; }
+summary { cursor: pointer; font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: .9em; }
+pre { background: #f6f8fa; border-radius: 6px; padding: .8em; overflow-x: auto; font-size: .85em; }
+.diff .add { color: #1a7f37; background: #dafbe1; display: block; }
+.diff .del { color: #cf222e; background: #ffebe9; display: block; }
+.files code { background: #f6f8fa; padding: .1em .3em; border-radius: 4px; }
+.subagent { border-left: 3px solid #8250df; padding-left: 1em; }
+</style>
+</head>
+<body>
+<h1>Checkpoint {{.CheckpointID}}</h1>
+{{range .Sessions}}
+<h2>Session {{.SessionID}}</h2>
+<dl class="meta">
+{{- if .Agent}}<dt>Agent</dt><dd>{{.Agent}}</dd>{{end}}
+{{- if not .CreatedAt.IsZero}}<dt>Created</dt><dd>{{date .CreatedAt}}</dd>{{end}}
+{{- if .TokenUsage}}<dt>Tokens</dt><dd>{{tokens .TokenUsage}}</dd>{{end}}
+</dl>
+{{template "turns" .Turns}}
+{{end}}
+</body>
+</html>
+{{define "turns"}}{{range $i, $turn := .}}
+<section class="turn">
+<h3>Turn {{inc $i}}</h3>
+{{- if $turn.Prompt}}
+<div class="label">User</div>
+<div class="prompt">{{$turn.Prompt}}</div>
+{{- end}}
+{{- range $turn.Steps}}
+{{- if not .ToolName}}
+<div class="label">Assistant</div>
+<div class="assistant">{{.Text}}</div>
+{{- else}}
+<details>
+<summary>{{.ToolName}}{{if .ToolDetail}} — {{.ToolDetail}}{{end}}</summary>
+{{- if .ToolInput}}
+<pre>{{.ToolInput}}</pre>
+{{- end}}
+{{- range .Diffs}}
+{{- if .Path}}<div class="files"><code>{{.Path}}</code></div>{{end}}
+<pre class="diff">{{range lines .Body}}{{if isAdded .}}<span class="add">{{.}}</span>{{else if isRemoved .}}<span class="del">{{.}}</span>{{else}}{{.}}
+{{end}}{{end}}</pre>
+{{- end}}
+{{- if .Subagent}}
+<div class="subagent">
+<div class="label">Subagent transcript</div>
+{{template "turns" .Subagent}}
+</div>
+{{- end}}
+</details>
+{{- end}}
+{{- end}}
+{{- if $turn.FilesTouched}}
+<p class="files"><span class="label">Files touched</span> {{range $turn.FilesTouched}}<code>{{.}}</code> {{end}}</p>
+{{- end}}
+</section>
+{{- end}}{{end}}`))
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/summarize"
+)
+
+const renderTestTranscript = `{"type":"user","message":{"content":"Rename the greeting"}}
+{"type":"assistant","message":{"content":[{"type":"text","text":"I'll update main.go."},{"type":"tool_use","id":"toolu_edit1","name":"Edit","input":{"file_path":"main.go","old_string":"fmt.Println(\"hi\")","new_string":"fmt.Println(\"hello\")"}}]}}
+{"type":"assistant","message":{"content":[{"type":"tool_use","id":"toolu_task1","name":"Task","input":{"description":"Check the tests","prompt":"Run the tests"}}]}}
+{"type":"user","message":{"content":"Thanks <b>!</b>"}}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Done."}]}}
+`
+
+const renderTestSubagentTranscript = `{"type":"user","message":{"content":"Run the tests"}}
+{"type":"assistant","message":{"content":[{"type":"tool_use","id":"toolu_bash1","name":"Bash","input":{"command":"go test ./..."}}]}}
+`
+
+func setupRenderTestRepo(t *testing.T) {
+	t.Helper()
+	_, repo := setupCheckpointTestRepo(t, "hello")
+
+	subagentPath := filepath.Join(t.TempDir(), "agent-sub1.jsonl")
+	if err := os.WriteFile(subagentPath, []byte(renderTestSubagentTranscript), 0o644); err != nil {
+		t.Fatalf("failed to write subagent transcript: %v", err)
+	}
+	writeTestCheckpoint(t, repo, checkpoint.WriteCommittedOptions{
+		CheckpointID:           id.MustCheckpointID("abc123def456"),
+		SessionID:              "session-render",
+		Agent:                  agent.AgentTypeClaudeCode,
+		Transcript:             []byte(renderTestTranscript),
+		TokenUsage:             &agent.TokenUsage{InputTokens: 1200, OutputTokens: 300, APICallCount: 3},
+		IsTask:                 true,
+		ToolUseID:              "toolu_task1",
+		AgentID:                "sub1",
+		SubagentTranscriptPath: subagentPath,
+	})
+}
+
+func TestRunExplainRender_Markdown(t *testing.T) {
+	setupRenderTestRepo(t)
+
+	var buf bytes.Buffer
+	if err := runExplainRender(context.Background(), &buf, "abc123", "md", ""); err != nil {
+		t.Fatalf("runExplainRender() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# Checkpoint abc123def456",
+		"## Session session-render",
+		"- **Tokens:** 1,500 (input 1,200, output 300, 3 API calls)",
+		"### Turn 1",
+		"> Rename the greeting",
+		"I'll update main.go.",
+		"<summary>Tool: Edit — main.go</summary>",
+		"\"old_string\": \"fmt.Println(\\\"hi\\\")\"",
+		"```diff\n-fmt.Println(\"hi\")\n+fmt.Println(\"hello\")\n```",
+		"**Files touched:** `main.go`",
+		"<summary>Tool: Task — Check the tests</summary>",
+		"**Subagent transcript:**",
+		"#### Turn 1",
+		"> Run the tests",
+		"<summary>Tool: Bash — go test ./...</summary>",
+		"### Turn 2",
+		"Done.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunExplainRender_HTMLToFile(t *testing.T) {
+	setupRenderTestRepo(t)
+
+	var buf bytes.Buffer
+	if err := runExplainRender(context.Background(), &buf, "abc123def456", "html", "out.html"); err != nil {
+		t.Fatalf("runExplainRender() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "Rendered checkpoint abc123def456 to out.html") {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+
+	data, err := os.ReadFile("out.html")
+	if err != nil {
+		t.Fatalf("failed to read rendered file: %v", err)
+	}
+	out := string(data)
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<title>Checkpoint abc123def456</title>",
+		"<summary>Edit — main.go</summary>",
+		`<span class="del">-fmt.Println(&#34;hi&#34;)</span>`,
+		`<span class="add">&#43;fmt.Println(&#34;hello&#34;)</span>`,
+		`<div class="subagent">`,
+		"go test ./...",
+		"Thanks &lt;b&gt;!&lt;/b&gt;",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in HTML:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<b>!</b>") {
+		t.Error("prompt HTML was not escaped")
+	}
+}
+
+func TestRunExplainRender_Errors(t *testing.T) {
+	setupRenderTestRepo(t)
+
+	var buf bytes.Buffer
+	if err := runExplainRender(context.Background(), &buf, "abc123", "pdf", ""); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+	if err := runExplainRender(context.Background(), &buf, "fff999", "html", ""); err == nil {
+		t.Error("expected error for unknown checkpoint")
+	}
+}
+
+func TestToolInputDiffs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []renderDiff
+	}{
+		{
+			name:  "claude edit",
+			input: `{"file_path":"a.go","old_string":"x\ny","new_string":"z"}`,
+			want:  []renderDiff{{Path: "a.go", Body: "-x\n-y\n+z\n"}},
+		},
+		{
+			name:  "opencode edit",
+			input: `{"filePath":"b.go","oldString":"old","newString":"new"}`,
+			want:  []renderDiff{{Path: "b.go", Body: "-old\n+new\n"}},
+		},
+		{
+			name:  "write",
+			input: `{"file_path":"c.txt","content":"one\ntwo\n"}`,
+			want:  []renderDiff{{Path: "c.txt", Body: "+one\n+two\n"}},
+		},
+		{
+			name:  "multi edit",
+			input: `{"file_path":"d.go","edits":[{"old_string":"a","new_string":"b"},{"old_string":"c","new_string":"d"}]}`,
+			want:  []renderDiff{{Path: "d.go", Body: "-a\n+b\n-c\n+d\n"}},
+		},
+		{
+			name:  "read has no diff",
+			input: `{"file_path":"e.go"}`,
+		},
+		{
+			name:  "not json",
+			input: `nope`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := toolInputDiffs(tt.input)
+			if len(got) != len(tt.want) {
+				t.Fatalf("toolInputDiffs() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("diff %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBuildRenderTurns_LeadingAssistantOutput(t *testing.T) {
+	turns := buildRenderTurns([]summarize.Entry{
+		{Type: summarize.EntryTypeAssistant, Content: "resumed"},
+		{Type: summarize.EntryTypeUser, Content: "next"},
+	}, nil, agent.AgentTypeClaudeCode)
+
+	if len(turns) != 2 || turns[0].Prompt != "" || len(turns[0].Steps) != 1 || turns[1].Prompt != "next" {
+		t.Errorf("buildRenderTurns() = %+v", turns)
+	}
+}
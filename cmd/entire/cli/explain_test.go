@@ -916,6 +916,52 @@ func TestFormatCheckpointOutput_Short(t *testing.T) {
 	}
 }
 
+func TestFormatCheckpointOutput_Cost(t *testing.T) {
+	sessionUsage := &agent.TokenUsage{
+		InputTokens:  1_000_000,
+		OutputTokens: 100_000,
+		Models: map[string]*agent.ModelUsage{
+			"claude-sonnet-4-20250514": {InputTokens: 1_000_000, OutputTokens: 100_000, APICallCount: 4},
+		},
+	}
+	summary := &checkpoint.CheckpointSummary{
+		CheckpointID: id.MustCheckpointID("abc123def456"),
+		Sessions:     make([]checkpoint.SessionFilePaths, 2),
+		TokenUsage: &agent.TokenUsage{
+			InputTokens: 2_000_000,
+			Models: map[string]*agent.ModelUsage{
+				"claude-sonnet-4-20250514": {InputTokens: 1_000_000, OutputTokens: 100_000},
+				"in-house-model":           {InputTokens: 1_000_000},
+			},
+		},
+	}
+	content := &checkpoint.SessionContent{
+		Metadata: checkpoint.CommittedMetadata{
+			CheckpointID: "abc123def456",
+			SessionID:    "2026-01-21-test-session",
+			TokenUsage:   sessionUsage,
+		},
+	}
+
+	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, false, false)
+
+	// Session: 1M input at $3 + 100k output at $15
+	if !strings.Contains(output, "Cost: ~$4.50\n") {
+		t.Errorf("expected session cost in output:\n%s", output)
+	}
+	if !strings.Contains(output, "Checkpoint cost: ~$4.50 (excludes in-house-model) (2 sessions)") {
+		t.Errorf("expected checkpoint cost in output:\n%s", output)
+	}
+
+	// Usage recorded before models were tracked has no cost line
+	content.Metadata.TokenUsage = &agent.TokenUsage{InputTokens: 10}
+	summary.Sessions = summary.Sessions[:1]
+	output = formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, false, false)
+	if strings.Contains(output, "Cost:") {
+		t.Errorf("expected no cost without a model breakdown:\n%s", output)
+	}
+}
+
 func TestFormatCheckpointOutput_Verbose(t *testing.T) {
 	// Transcript with user prompts that match what we expect to see
 	transcriptContent := []byte(`{"type":"user","uuid":"u1","message":{"content":"Add a new feature"}}
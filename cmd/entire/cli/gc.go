@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/spf13/cobra"
+)
+
+func newGCCmd() *cobra.Command {
+	var (
+		dryRunFlag     bool
+		pushFlag       bool
+		maxAgeDaysFlag int
+		maxPerBranch   int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove old transcripts from the checkpoints branch",
+		Long: `Apply the retention policy to the ` + paths.MetadataBranchName + ` branch.
+
+Checkpoints created more than max_age_days ago, or beyond the newest
+max_checkpoints_per_branch checkpoints of the branch they were created on,
+have their transcripts removed. Their metadata.json (summary, attribution,
+token usage), prompts and context are kept, so they still show up in explain,
+search and report.
+
+The limits come from the "retention" section of .entire/settings.json:
+
+  "retention": {"max_age_days": 90, "max_checkpoints_per_branch": 200}
+
+and can be overridden with --max-age-days and --max-per-branch.
+
+Every commit on the branch is rewritten without the removed files, keeping
+authors and messages, so git can reclaim the space. The local branch is
+replaced; use --push to replace the branch on the metadata remote too. The
+old objects are freed by the next 'git gc' once nothing references them.
+
+The rewrite is recorded in ` + paths.PurgeLogFileName + ` on the branch, like a purge.
+Clones that still have the old history refuse to merge it back on push or
+sync, and can replace their local branch with 'entire sync --resync'.
+
+Use --dry-run to see what would be removed without changing anything.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if checkDisabledGuard(cmd.Context(), cmd.OutOrStdout()) {
+				return nil
+			}
+			policy, err := retentionPolicy(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("max-age-days") {
+				policy.MaxAge = time.Duration(maxAgeDaysFlag) * 24 * time.Hour
+			}
+			if cmd.Flags().Changed("max-per-branch") {
+				policy.MaxPerBranch = maxPerBranch
+			}
+			return runGC(cmd.Context(), cmd.OutOrStdout(), policy, dryRunFlag, pushFlag)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Show what would be removed without changing anything")
+	cmd.Flags().BoolVar(&pushFlag, "push", false, "Replace the branch on the metadata remote after compacting")
+	cmd.Flags().IntVar(&maxAgeDaysFlag, "max-age-days", 0, "Remove transcripts of checkpoints older than this many days (0 disables)")
+	cmd.Flags().IntVar(&maxPerBranch, "max-per-branch", 0, "Keep transcripts for only the newest N checkpoints per branch (0 disables)")
+
+	return cmd
+}
+
+// retentionPolicy returns the policy from the "retention" settings section.
+func retentionPolicy(ctx context.Context) (checkpoint.RetentionPolicy, error) {
+	s, err := settings.Load(ctx)
+	if err != nil {
+		return checkpoint.RetentionPolicy{}, fmt.Errorf("failed to load settings: %w", err)
+	}
+	if s.Retention == nil {
+		return checkpoint.RetentionPolicy{}, nil
+	}
+	return checkpoint.RetentionPolicy{
+		MaxAge:       time.Duration(s.Retention.MaxAgeDays) * 24 * time.Hour,
+		MaxPerBranch: s.Retention.MaxCheckpointsPerBranch,
+	}, nil
+}
+
+func runGC(ctx context.Context, w io.Writer, policy checkpoint.RetentionPolicy, dryRun, push bool) error {
+	if policy.IsZero() {
+		return errors.New(`no retention limits set: add "retention": {"max_age_days": N} to .entire/settings.json or pass --max-age-days/--max-per-branch`)
+	}
+
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	result, err := checkpoint.NewGitStore(repo).Compact(ctx, policy, dryRun)
+	if err != nil {
+		return fmt.Errorf("failed to compact checkpoints: %w", err)
+	}
+
+	if len(result.Checkpoints) == 0 {
+		fmt.Fprintln(w, "Nothing to remove: all transcripts are within the retention policy")
+	} else {
+		verb := "Removed"
+		if dryRun {
+			verb = "Would remove"
+		}
+		fmt.Fprintf(w, "%s transcripts from %d checkpoint(s): %d file(s), %s\n",
+			verb, len(result.Checkpoints), result.Files, formatByteSize(result.ReclaimedBytes))
+		for _, cpID := range result.Checkpoints {
+			fmt.Fprintf(w, "  %s\n", cpID)
+		}
+		if !dryRun {
+			fmt.Fprintf(w, "Rewrote %d commit(s) on %s\n", result.RewrittenCommits, paths.MetadataBranchName)
+		}
+	}
+	if dryRun {
+		return nil
+	}
+
+	// Pushing also runs when there was nothing new to remove, so a previous
+	// local-only gc can be published.
+	if push {
+		pushed, err := strategy.ReplaceRemoteMetadataBranch(ctx, "origin")
+		for _, remote := range pushed {
+			fmt.Fprintf(w, "Replaced %s on %s\n", paths.MetadataBranchName, remote)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to push compacted branch: %w", err)
+		}
+	} else if len(result.Checkpoints) > 0 {
+		fmt.Fprintln(w, "Run 'entire gc --push' to replace the branch on the metadata remote")
+	}
+	if len(result.Checkpoints) > 0 {
+		fmt.Fprintln(w, "To free the space now, run 'git reflog expire --expire-unreachable=now --all && git gc --prune=now'")
+	}
+	return nil
+}
+
+// formatByteSize formats n bytes for display (e.g. "1.5 MB").
+func formatByteSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+)
+
+func TestRunGC(t *testing.T) {
+	_, repo := setupCheckpointTestRepo(t, "hello")
+	cpID := id.MustCheckpointID("abc123def456")
+	writeTestCheckpoint(t, repo, checkpoint.WriteCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-1",
+		Transcript:   []byte(`{"type":"user","message":{"content":"hi"}}` + "\n"),
+	})
+
+	var buf bytes.Buffer
+	if err := runGC(context.Background(), &buf, checkpoint.RetentionPolicy{}, false, false); err == nil {
+		t.Error("runGC() without limits should fail")
+	}
+
+	policy := checkpoint.RetentionPolicy{MaxAge: time.Hour, Now: time.Now().Add(2 * time.Hour)}
+	if err := runGC(context.Background(), &buf, policy, true, false); err != nil {
+		t.Fatalf("runGC(dry run) error = %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Would remove transcripts from 1 checkpoint(s)") || !strings.Contains(out, cpID.String()) {
+		t.Errorf("dry run output = %q", out)
+	}
+
+	buf.Reset()
+	if err := runGC(context.Background(), &buf, policy, false, false); err != nil {
+		t.Fatalf("runGC() error = %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Removed transcripts from 1 checkpoint(s)") || !strings.Contains(out, "Rewrote") {
+		t.Errorf("output = %q", out)
+	}
+
+	buf.Reset()
+	if err := runGC(context.Background(), &buf, policy, false, false); err != nil {
+		t.Fatalf("runGC() second run error = %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Nothing to remove") {
+		t.Errorf("second run output = %q", out)
+	}
+}
@@ -53,6 +53,8 @@ func newAgentHooksCmd(agentName agent.AgentName, handler agent.HookSupport) *cob
 		Hidden: true,
 		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
 			agentHookLogCleanup = initHookLogging(cmd.Context())
+			configureRedaction(cmd.Context())
+			configureEncryption(cmd.Context())
 			return nil
 		},
 		PersistentPostRunE: func(_ *cobra.Command, _ []string) error {
@@ -99,6 +99,8 @@ func newHooksGitCmd() *cobra.Command {
 				return nil
 			}
 			hookLogCleanup = initHookLogging(ctx)
+			configureRedaction(ctx)
+			configureEncryption(ctx)
 			return nil
 		},
 		PersistentPostRunE: func(_ *cobra.Command, _ []string) error {
@@ -113,6 +115,9 @@ func newHooksGitCmd() *cobra.Command {
 	cmd.AddCommand(newHooksGitCommitMsgCmd())
 	cmd.AddCommand(newHooksGitPostCommitCmd())
 	cmd.AddCommand(newHooksGitPrePushCmd())
+	cmd.AddCommand(newHooksGitPostMergeCmd())
+	cmd.AddCommand(newHooksGitPostCheckoutCmd())
+	cmd.AddCommand(newHooksGitPostRewriteCmd())
 
 	return cmd
 }
@@ -209,3 +214,74 @@ func newHooksGitPrePushCmd() *cobra.Command {
 		},
 	}
 }
+
+func newHooksGitPostMergeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "post-merge [squash]",
+		Short: "Handle post-merge git hook",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if gitHooksDisabled {
+				return nil
+			}
+
+			g := newGitHookContext(cmd.Context(), "post-merge")
+			g.logInvoked()
+
+			hookErr := g.strategy.PostMerge(g.ctx)
+			g.logCompleted(hookErr)
+
+			return nil
+		},
+	}
+}
+
+func newHooksGitPostCheckoutCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "post-checkout <prev-head> <new-head> <branch-flag>",
+		Short: "Handle post-checkout git hook",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if gitHooksDisabled {
+				return nil
+			}
+
+			branchCheckout := args[2] == "1"
+
+			g := newGitHookContext(cmd.Context(), "post-checkout")
+			g.logInvoked(slog.Bool("branch_checkout", branchCheckout))
+
+			hookErr := g.strategy.PostCheckout(g.ctx, branchCheckout)
+			g.logCompleted(hookErr, slog.Bool("branch_checkout", branchCheckout))
+
+			return nil
+		},
+	}
+}
+
+func newHooksGitPostRewriteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "post-rewrite <amend|rebase>",
+		Short: "Handle post-rewrite git hook",
+		Long:  "Reads the \"<old-sha> <new-sha>\" lines git writes to the post-rewrite hook's stdin.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if gitHooksDisabled {
+				return nil
+			}
+
+			rewriteType := args[0]
+
+			g := newGitHookContext(cmd.Context(), "post-rewrite")
+			g.logInvoked(slog.String("rewrite_type", rewriteType))
+
+			mappings, hookErr := strategy.ParseRewriteMappings(cmd.InOrStdin())
+			if hookErr == nil {
+				hookErr = g.strategy.PostRewrite(g.ctx, rewriteType, mappings)
+			}
+			g.logCompleted(hookErr, slog.String("rewrite_type", rewriteType), slog.Int("commits", len(mappings)))
+
+			return nil
+		},
+	}
+}
@@ -31,11 +31,20 @@ const (
 	CheckpointFileName       = "checkpoint.json"
 	ContentHashFileName      = "content_hash.txt"
 	SettingsFileName         = "settings.json"
+	PurgeLogFileName         = "purge_log.jsonl"
 )
 
 // MetadataBranchName is the orphan branch used by manual-commit strategy to store metadata
 const MetadataBranchName = "entire/checkpoints/v1"
 
+// CheckpointNotesRef holds git notes that mirror the Entire-Checkpoint trailer
+// of user commits, written when strategy_options.git_notes is enabled.
+const CheckpointNotesRef = "refs/notes/entire"
+
+// RecoveryRef points at the latest recovery point: a snapshot of the working
+// tree saved before a rewind or reset, so that it can be undone.
+const RecoveryRef = "refs/entire/recovery"
+
 // CheckpointPath returns the sharded storage path for a checkpoint ID.
 // Uses first 2 characters as shard (256 buckets), remaining as folder name.
 // Example: "a3b2c4d5e6f7" -> "a3/b2c4d5e6f7"
@@ -0,0 +1,147 @@
+// Package pricing estimates the dollar cost of agent token usage from a
+// per-model price table. The built-in table covers common Anthropic, Google
+// and OpenAI models and can be extended or overridden through the "pricing"
+// settings section.
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// Price is the cost of one model in US dollars per million tokens.
+type Price struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheRead  float64 `json:"cache_read,omitempty"`
+	CacheWrite float64 `json:"cache_write,omitempty"`
+}
+
+// Table maps a model name, or a model name prefix, to its price.
+type Table map[string]Price
+
+// defaultTable lists list prices at the time of writing. Keys are prefixes, so
+// "claude-sonnet-4" also prices dated snapshots such as "claude-sonnet-4-20250514".
+var defaultTable = Table{
+	"claude-opus-4":         {Input: 15, Output: 75, CacheRead: 1.5, CacheWrite: 18.75},
+	"claude-opus-4-5":       {Input: 5, Output: 25, CacheRead: 0.5, CacheWrite: 6.25},
+	"claude-sonnet-4":       {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+	"claude-3-7-sonnet":     {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+	"claude-3-5-sonnet":     {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+	"claude-haiku-4-5":      {Input: 1, Output: 5, CacheRead: 0.1, CacheWrite: 1.25},
+	"claude-3-5-haiku":      {Input: 0.8, Output: 4, CacheRead: 0.08, CacheWrite: 1},
+	"gemini-2.5-pro":        {Input: 1.25, Output: 10, CacheRead: 0.125},
+	"gemini-2.5-flash":      {Input: 0.3, Output: 2.5, CacheRead: 0.03},
+	"gemini-2.5-flash-lite": {Input: 0.1, Output: 0.4, CacheRead: 0.01},
+	"gpt-5":                 {Input: 1.25, Output: 10, CacheRead: 0.125},
+	"gpt-5-mini":            {Input: 0.25, Output: 2, CacheRead: 0.025},
+	"gpt-4.1":               {Input: 2, Output: 8, CacheRead: 0.5},
+}
+
+// DefaultTable returns a copy of the built-in price table.
+func DefaultTable() Table {
+	return maps.Clone(defaultTable)
+}
+
+// active is the table used by Cost. nil means the built-in table.
+var active atomic.Pointer[Table]
+
+// Configure makes the built-in table, extended and overridden by overrides,
+// the table used by Cost. A nil or empty overrides restores the built-in table.
+// On error the active table is left unchanged.
+func Configure(overrides Table) error {
+	if len(overrides) == 0 {
+		active.Store(nil)
+		return nil
+	}
+	for model, p := range overrides {
+		if model == "" {
+			return errors.New("pricing entry has an empty model name")
+		}
+		if p.Input < 0 || p.Output < 0 || p.CacheRead < 0 || p.CacheWrite < 0 {
+			return fmt.Errorf("pricing for %q must not be negative", model)
+		}
+	}
+	table := DefaultTable()
+	maps.Copy(table, overrides)
+	active.Store(&table)
+	return nil
+}
+
+// current returns the active table.
+func current() Table {
+	if t := active.Load(); t != nil {
+		return *t
+	}
+	return defaultTable
+}
+
+// Lookup returns the price for a model: an exact match if there is one,
+// otherwise the longest key that prefixes the model name. A provider prefix
+// such as "anthropic/" is ignored.
+func (t Table) Lookup(model string) (Price, bool) {
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+	if p, ok := t[model]; ok {
+		return p, true
+	}
+	best := ""
+	for key := range t {
+		if strings.HasPrefix(model, key) && len(key) > len(best) {
+			best = key
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return t[best], true
+}
+
+// Estimate is the estimated cost of some token usage.
+type Estimate struct {
+	// USD is the cost of the usage whose model has a known price.
+	USD float64
+	// Unpriced lists models with usage but no price; their tokens are not in USD.
+	Unpriced []string
+}
+
+// Cost estimates the cost of tu, including subagent usage, using the active table.
+// Returns false when tu has no per-model breakdown to price.
+func Cost(tu *agent.TokenUsage) (Estimate, bool) {
+	return current().Cost(tu)
+}
+
+// Cost estimates the cost of tu, including subagent usage, using table t.
+// Returns false when tu has no per-model breakdown to price.
+func (t Table) Cost(tu *agent.TokenUsage) (Estimate, bool) {
+	var est Estimate
+	found := false
+	for u := tu; u != nil; u = u.SubagentTokens {
+		for model, mu := range u.Models {
+			if mu == nil {
+				continue
+			}
+			found = true
+			p, ok := t.Lookup(model)
+			if !ok {
+				if !slices.Contains(est.Unpriced, model) {
+					est.Unpriced = append(est.Unpriced, model)
+				}
+				continue
+			}
+			est.USD += (float64(mu.InputTokens)*p.Input +
+				float64(mu.OutputTokens)*p.Output +
+				float64(mu.CacheReadTokens)*p.CacheRead +
+				float64(mu.CacheCreationTokens)*p.CacheWrite) / 1_000_000
+		}
+	}
+	slices.Sort(est.Unpriced)
+	return est, found
+}
@@ -0,0 +1,104 @@
+package pricing
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestLookup(t *testing.T) {
+	table := DefaultTable()
+	tests := []struct {
+		model string
+		want  float64 // input price
+		found bool
+	}{
+		{"claude-sonnet-4-20250514", 3, true},
+		{"claude-opus-4-1-20250805", 15, true},
+		{"claude-opus-4-5-20251101", 5, true}, // longer prefix wins
+		{"anthropic/claude-haiku-4-5", 1, true},
+		{"gemini-2.5-flash-lite", 0.1, true},
+		{"gemini-2.5-flash", 0.3, true},
+		{"mystery-model", 0, false},
+	}
+	for _, tt := range tests {
+		p, ok := table.Lookup(tt.model)
+		if ok != tt.found || p.Input != tt.want {
+			t.Errorf("Lookup(%q) = %+v, %v; want input %v, %v", tt.model, p, ok, tt.want, tt.found)
+		}
+	}
+}
+
+func TestTableCost(t *testing.T) {
+	table := Table{
+		"big":   {Input: 10, Output: 20, CacheRead: 1, CacheWrite: 12},
+		"small": {Input: 1, Output: 2},
+	}
+	tu := &agent.TokenUsage{
+		Models: map[string]*agent.ModelUsage{
+			"big":     {InputTokens: 1_000_000, OutputTokens: 500_000, CacheReadTokens: 2_000_000, CacheCreationTokens: 100_000},
+			"unknown": {InputTokens: 5},
+		},
+		SubagentTokens: &agent.TokenUsage{
+			Models: map[string]*agent.ModelUsage{
+				"small": {InputTokens: 1_000_000, OutputTokens: 1_000_000},
+			},
+		},
+	}
+
+	est, ok := table.Cost(tu)
+	if !ok {
+		t.Fatal("Cost() ok = false, want true")
+	}
+	// big: 10 + 10 + 2 + 1.2; small (subagent): 1 + 2
+	if want := 26.2; math.Abs(est.USD-want) > 1e-9 {
+		t.Errorf("Cost() USD = %v, want %v", est.USD, want)
+	}
+	if !slices.Equal(est.Unpriced, []string{"unknown"}) {
+		t.Errorf("Cost() Unpriced = %v, want [unknown]", est.Unpriced)
+	}
+
+	if _, ok := table.Cost(&agent.TokenUsage{InputTokens: 10}); ok {
+		t.Error("Cost() without a model breakdown should report false")
+	}
+	if _, ok := table.Cost(nil); ok {
+		t.Error("Cost(nil) should report false")
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(nil) }) //nolint:errcheck // restoring defaults cannot fail
+
+	tu := &agent.TokenUsage{Models: map[string]*agent.ModelUsage{
+		"claude-sonnet-4-5": {OutputTokens: 1_000_000},
+		"in-house":          {InputTokens: 1_000_000},
+	}}
+
+	if err := Configure(Table{"claude-sonnet-4": {Input: 3, Output: 30}, "in-house": {Input: 2}}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	est, _ := Cost(tu)
+	if est.USD != 32 || len(est.Unpriced) != 0 {
+		t.Errorf("Cost() with overrides = %+v, want 32 USD", est)
+	}
+	if _, ok := current().Lookup("gpt-5"); !ok {
+		t.Error("overrides should keep the built-in entries")
+	}
+
+	if err := Configure(Table{"bad": {Input: -1}}); err == nil {
+		t.Error("Configure() should reject negative prices")
+	}
+	if est, _ := Cost(tu); est.USD != 32 {
+		t.Error("a failed Configure() should leave the active table unchanged")
+	}
+
+	if err := Configure(nil); err != nil {
+		t.Fatalf("Configure(nil) error = %v", err)
+	}
+	est, _ = Cost(tu)
+	if est.USD != 15 || !slices.Equal(est.Unpriced, []string{"in-house"}) {
+		t.Errorf("Cost() after reset = %+v, want 15 USD with in-house unpriced", est)
+	}
+}
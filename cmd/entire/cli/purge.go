@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/cobra"
+)
+
+func newPurgeCmd() *cobra.Command {
+	var (
+		patternFlag    string
+		checkpointFlag []string
+		sessionFlag    []string
+		dryRunFlag     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Remove leaked secrets or checkpoints from the checkpoints branch history",
+		Long: `Rewrite every commit on the ` + paths.MetadataBranchName + ` branch to remove
+content that should never have been committed.
+
+  --pattern <regex>    Replace every match with REDACTED in transcripts,
+                       prompts, context, metadata, commit messages and
+                       checkpoint notes (` + paths.CheckpointNotesRef + `).
+                       Transcripts are reassembled before matching and
+                       chunked again, so matches spanning chunks are found.
+  --checkpoint <id>    Remove the transcripts, prompts and context of a
+                       checkpoint (ID or prefix). Repeatable.
+  --session <id>       Remove the content of one session from every
+                       checkpoint it contributed to. Repeatable.
+
+metadata.json is kept for removed checkpoints and sessions, so commits still
+link to them. Encrypted content is decrypted and encrypted again, which
+requires an identity that can decrypt it.
+
+The purge is recorded in ` + paths.PurgeLogFileName + ` on the branch. Clones that still
+have the purged content refuse to merge it back on push or sync, and can
+replace their local branch with 'entire sync --resync'.
+
+The local branch is replaced; the command prints the force-push that
+replaces the remote branch. Treat any secret that was pushed as compromised
+and rotate it: other clones and forks may still have the old history.
+
+Use --dry-run to see what would change without changing anything.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if checkDisabledGuard(cmd.Context(), cmd.OutOrStdout()) {
+				return nil
+			}
+			opts := checkpoint.PurgeOptions{SessionIDs: sessionFlag, DryRun: dryRunFlag}
+			if patternFlag != "" {
+				pattern, err := regexp.Compile(patternFlag)
+				if err != nil {
+					return fmt.Errorf("invalid --pattern: %w", err)
+				}
+				opts.Pattern = pattern
+			}
+			return runPurge(cmd.Context(), cmd.OutOrStdout(), opts, checkpointFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&patternFlag, "pattern", "", "Replace matches of this regular expression with REDACTED")
+	cmd.Flags().StringArrayVar(&checkpointFlag, "checkpoint", nil, "Remove the content of this checkpoint (ID or prefix)")
+	cmd.Flags().StringArrayVar(&sessionFlag, "session", nil, "Remove the content of this session")
+	cmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Show what would change without changing anything")
+	cmd.MarkFlagsOneRequired("pattern", "checkpoint", "session")
+
+	return cmd
+}
+
+// runPurge resolves checkpoint prefixes into opts.CheckpointIDs and purges.
+func runPurge(ctx context.Context, w io.Writer, opts checkpoint.PurgeOptions, checkpointPrefixes []string) error {
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	store := checkpoint.NewGitStore(repo)
+
+	for _, prefix := range checkpointPrefixes {
+		matches, err := matchCommittedCheckpoints(ctx, store, prefix)
+		if err != nil {
+			return fmt.Errorf("failed to list checkpoints: %w", err)
+		}
+		switch len(matches) {
+		case 0:
+			return fmt.Errorf("checkpoint not found: %s", prefix)
+		case 1:
+			opts.CheckpointIDs = append(opts.CheckpointIDs, matches[0])
+		default:
+			return ambiguousCheckpointError(prefix, matches)
+		}
+	}
+
+	result, err := store.Purge(ctx, opts)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		fmt.Fprintln(w, "Nothing to purge: there are no committed checkpoints")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to purge checkpoints: %w", err)
+	}
+
+	notesChanged := result.NewNotesHead != result.OldNotesHead
+	if len(result.Checkpoints) == 0 && !notesChanged {
+		fmt.Fprintln(w, "Nothing to purge: no checkpoint content matched")
+		return nil
+	}
+	verb := "Purged"
+	if opts.DryRun {
+		verb = "Would purge"
+	}
+	fmt.Fprintf(w, "%s %d checkpoint(s), %d file(s):\n", verb, len(result.Checkpoints), result.Files)
+	for _, cpID := range result.Checkpoints {
+		fmt.Fprintf(w, "  %s\n", cpID)
+	}
+	if notesChanged {
+		fmt.Fprintf(w, "  %s\n", paths.CheckpointNotesRef)
+	}
+	if opts.DryRun {
+		return nil
+	}
+
+	fmt.Fprintf(w, "Rewrote %d commit(s) on %s\n", result.RewrittenCommits, paths.MetadataBranchName)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "To replace the branch on the remote, run:")
+	for _, command := range strategy.ForcePushMetadataCommands(ctx, "origin") {
+		fmt.Fprintf(w, "  %s\n", command)
+	}
+	if notesChanged {
+		for _, command := range strategy.ForcePushNotesCommands(ctx, "origin") {
+			fmt.Fprintf(w, "  %s\n", command)
+		}
+	}
+	fmt.Fprintln(w, "Other clones will be asked to run 'entire sync --resync' before they can push checkpoints again.")
+	fmt.Fprintln(w, "To drop the old objects locally, run 'git reflog expire --expire-unreachable=now --all && git gc --prune=now'")
+	fmt.Fprintln(w, "Anything that was already pushed may have been copied: rotate leaked credentials.")
+	return nil
+}
@@ -0,0 +1,255 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/redact"
+
+	"github.com/spf13/cobra"
+)
+
+// redactCheckFile is the report for one scanned file.
+type redactCheckFile struct {
+	Path        string           `json:"path"`
+	Allowlisted bool             `json:"allowlisted,omitempty"` // Matched by the path allowlist; stored verbatim
+	Findings    []redact.Finding `json:"findings"`
+}
+
+// redactCheckSession is the report for one session of a committed checkpoint.
+type redactCheckSession struct {
+	SessionID string `json:"session_id"`
+	// RedactionCounts is what was redacted when the checkpoint was written.
+	RedactionCounts map[string]int    `json:"redaction_counts,omitempty"`
+	Files           []redactCheckFile `json:"files"`
+}
+
+// redactCheckReport is the output of 'entire redact check'.
+// Exactly one of Files (file target) or Sessions (checkpoint target) is set.
+type redactCheckReport struct {
+	Target       string               `json:"target"`
+	CheckpointID string               `json:"checkpoint_id,omitempty"`
+	Files        []redactCheckFile    `json:"files,omitempty"`
+	Sessions     []redactCheckSession `json:"sessions,omitempty"`
+}
+
+func newRedactCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "redact",
+		Short: "Inspect secret redaction",
+		Long: `Inspect how Entire redacts secrets from checkpoints.
+
+Redaction rules can be customized in .entire/redaction.toml.`,
+	}
+
+	cmd.AddCommand(newRedactCheckCmd())
+
+	return cmd
+}
+
+func newRedactCheckCmd() *cobra.Command {
+	var jsonFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "check <checkpoint|file>",
+		Short: "Report what redaction would replace, without changing anything",
+		Long: `Run the redaction pipeline in report mode and list every finding with the
+rule that flagged it (a gitleaks rule ID, "entropy", or a custom rule ID), its
+line, column and byte offset, and a preview with the secret masked.
+
+The target is either a file path or a committed checkpoint ID (or prefix).
+Files ending in .jsonl are scanned the same way transcripts are.
+
+Committed checkpoints were already redacted when they were written, so findings
+there show what the current rules would additionally redact. The counts
+recorded at write time are shown alongside.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkDisabledGuard(cmd.Context(), cmd.OutOrStdout()) {
+				return nil
+			}
+			return runRedactCheck(cmd.Context(), cmd.OutOrStdout(), args[0], jsonFlag)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output findings as JSON")
+
+	return cmd
+}
+
+func runRedactCheck(ctx context.Context, w io.Writer, target string, jsonOutput bool) error {
+	report, err := buildRedactCheckReport(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		data, err := jsonutil.MarshalIndentWithNewline(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal redaction report: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to write redaction report: %w", err)
+		}
+		return nil
+	}
+
+	fmt.Fprint(w, formatRedactCheckReport(report))
+	return nil
+}
+
+// buildRedactCheckReport scans target, which is a file path if one exists and
+// a committed checkpoint ID prefix otherwise.
+func buildRedactCheckReport(ctx context.Context, target string) (*redactCheckReport, error) {
+	info, statErr := os.Stat(target)
+	if statErr == nil && !info.IsDir() {
+		data, err := os.ReadFile(target) //nolint:gosec // target is a user-supplied path to inspect
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", target, err)
+		}
+		return &redactCheckReport{
+			Target: target,
+			Files:  []redactCheckFile{scanRedactCheckFile(target, string(data))},
+		}, nil
+	}
+
+	repo, err := openRepository(ctx)
+	if err != nil {
+		if statErr != nil && errors.Is(statErr, os.ErrNotExist) {
+			return nil, fmt.Errorf("no such file: %s", target)
+		}
+		return nil, fmt.Errorf("not a git repository: %w", err)
+	}
+
+	store := checkpoint.NewGitStore(repo)
+	matches, err := matchCommittedCheckpoints(ctx, store, target)
+	if err != nil {
+		return nil, err
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no file or committed checkpoint found: %s", target)
+	case 1:
+	default:
+		return nil, ambiguousCheckpointError(target, matches)
+	}
+	checkpointID := matches[0]
+
+	summary, err := store.ReadCommitted(ctx, checkpointID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if summary == nil {
+		return nil, fmt.Errorf("checkpoint not found: %s", checkpointID)
+	}
+
+	report := &redactCheckReport{Target: target, CheckpointID: checkpointID.String()}
+	for i := range max(len(summary.Sessions), 1) {
+		content, err := store.ReadSessionContent(ctx, checkpointID, i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session %d of checkpoint %s: %w", i, checkpointID, err)
+		}
+
+		session := redactCheckSession{
+			SessionID:       content.Metadata.SessionID,
+			RedactionCounts: content.Metadata.RedactionCounts,
+		}
+		if len(content.Transcript) > 0 {
+			session.Files = append(session.Files, scanRedactCheckFile(paths.TranscriptFileName, string(content.Transcript)))
+		}
+		if content.Prompts != "" {
+			session.Files = append(session.Files, scanRedactCheckFile(paths.PromptFileName, content.Prompts))
+		}
+		if content.Context != "" {
+			session.Files = append(session.Files, scanRedactCheckFile(paths.ContextFileName, content.Context))
+		}
+		report.Sessions = append(report.Sessions, session)
+	}
+	return report, nil
+}
+
+// scanRedactCheckFile scans content the way the checkpoint writer would redact a file at path.
+func scanRedactCheckFile(path, content string) redactCheckFile {
+	file := redactCheckFile{Path: path, Findings: []redact.Finding{}}
+	switch {
+	case redact.PathAllowed(path):
+		file.Allowlisted = true
+	case strings.HasSuffix(path, ".jsonl"):
+		file.Findings = append(file.Findings, redact.ScanJSONL(content)...)
+	default:
+		file.Findings = append(file.Findings, redact.Scan(content)...)
+	}
+	return file
+}
+
+// formatRedactCheckReport renders a report as human-readable text.
+func formatRedactCheckReport(report *redactCheckReport) string {
+	var sb strings.Builder
+
+	total := 0
+	writeFile := func(f redactCheckFile, indent string) {
+		total += len(f.Findings)
+		switch {
+		case f.Allowlisted:
+			fmt.Fprintf(&sb, "%s%s: allowlisted, not redacted\n", indent, f.Path)
+			return
+		case len(f.Findings) == 0:
+			fmt.Fprintf(&sb, "%s%s: no findings\n", indent, f.Path)
+			return
+		}
+		fmt.Fprintf(&sb, "%s%s: %d finding(s)\n", indent, f.Path, len(f.Findings))
+		for _, finding := range f.Findings {
+			fmt.Fprintf(&sb, "%s  %d:%d (offset %d)  %s  %s\n",
+				indent, finding.Line, finding.Column, finding.Offset, finding.RuleID, finding.Preview)
+		}
+	}
+
+	if report.CheckpointID == "" {
+		for _, f := range report.Files {
+			writeFile(f, "")
+		}
+	} else {
+		fmt.Fprintf(&sb, "Checkpoint: %s\n", report.CheckpointID)
+		for _, session := range report.Sessions {
+			fmt.Fprintf(&sb, "\nSession: %s\n", session.SessionID)
+			if len(session.RedactionCounts) > 0 {
+				fmt.Fprintf(&sb, "  Redacted when written: %s\n", formatRedactionCounts(session.RedactionCounts))
+			}
+			for _, f := range session.Files {
+				writeFile(f, "  ")
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	if total == 0 {
+		sb.WriteString("No secrets found.\n")
+	} else {
+		fmt.Fprintf(&sb, "%d finding(s) would be redacted.\n", total)
+	}
+	return sb.String()
+}
+
+// formatRedactionCounts renders per-rule redaction counts,
+// e.g. "3 (aws-access-token: 1, entropy: 2)".
+func formatRedactionCounts(counts map[string]int) string {
+	rules := make([]string, 0, len(counts))
+	for rule := range counts {
+		rules = append(rules, rule)
+	}
+	slices.Sort(rules)
+
+	parts := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		parts = append(parts, fmt.Sprintf("%s: %d", rule, counts[rule]))
+	}
+	return fmt.Sprintf("%d (%s)", redact.Counts(counts).Total(), strings.Join(parts, ", "))
+}
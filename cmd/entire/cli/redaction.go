@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/redact"
+)
+
+// configureRedaction applies the repository's redaction config (.entire/redaction.toml
+// or the "redaction" settings section) to every later redact call in this process.
+// An invalid config is reported and the default rules stay in effect, so a typo
+// never disables redaction.
+func configureRedaction(ctx context.Context) {
+	cfg, err := settings.LoadRedactionConfig(ctx)
+	if err == nil {
+		err = redact.Configure(cfg)
+	}
+	if err != nil {
+		logging.Warn(ctx, "invalid redaction config, using default rules", slog.String("error", err.Error()))
+		fmt.Fprintf(os.Stderr, "Warning: invalid redaction config, using default rules: %v\n", err)
+	}
+}
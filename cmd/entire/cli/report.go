@@ -0,0 +1,494 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/stringutil"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/spf13/cobra"
+)
+
+// Report groupings.
+const (
+	reportGroupAuthor    = "author"
+	reportGroupAgent     = "agent"
+	reportGroupBranch    = "branch"
+	reportGroupDirectory = "directory"
+	reportGroupWeek      = "week"
+)
+
+// Report output formats.
+const (
+	reportFormatTable = "table"
+	reportFormatCSV   = "csv"
+	reportFormatJSON  = "json"
+)
+
+// reportNone labels commits without a value for the grouping (e.g., no agent).
+const reportNone = "(none)"
+
+// reportRow holds the figures for one group of commits.
+type reportRow struct {
+	Group        string `json:"group"`
+	Commits      int    `json:"commits"`
+	AgentCommits int    `json:"agent_commits"` // Commits linked to a checkpoint
+	Checkpoints  int    `json:"checkpoints"`
+	Sessions     int    `json:"sessions"`
+
+	AgentLines      int     `json:"agent_lines"`
+	HumanLines      int     `json:"human_lines"`
+	TotalLines      int     `json:"total_lines"`
+	AgentPercentage float64 `json:"agent_percentage"`
+
+	InputTokens         int `json:"input_tokens"`
+	CacheCreationTokens int `json:"cache_creation_tokens"`
+	CacheReadTokens     int `json:"cache_read_tokens"`
+	OutputTokens        int `json:"output_tokens"`
+	SubagentTokens      int `json:"subagent_tokens"` // Included in TotalTokens
+	TotalTokens         int `json:"total_tokens"`
+
+	FilesTouched int `json:"files_touched"`
+
+	files map[string]bool
+}
+
+// usageReport is the output of 'entire report'.
+type usageReport struct {
+	Range   string      `json:"range"`
+	GroupBy string      `json:"group_by"`
+	Rows    []reportRow `json:"rows"`
+	Total   reportRow   `json:"total"`
+}
+
+// reportCommit is a commit in the range with the figures of its checkpoint.
+type reportCommit struct {
+	SHA    string
+	Author string
+	Date   time.Time
+	// AddedLines are lines added per file, from git's numstat.
+	AddedLines map[string]int
+
+	CheckpointID id.CheckpointID
+	Checkpoint   *reportCheckpoint // nil if there is no checkpoint or it was already counted
+}
+
+// reportCheckpoint holds the figures of one committed checkpoint.
+type reportCheckpoint struct {
+	Agent        agent.AgentType
+	Branch       string
+	Sessions     int
+	Attribution  *checkpoint.InitialAttribution
+	TokenUsage   *agent.TokenUsage // summed over sessions, including subagent usage
+	FilesTouched []string
+}
+
+func newReportCmd() *cobra.Command {
+	var groupByFlag string
+	var formatFlag string
+	var depthFlag int
+
+	cmd := &cobra.Command{
+		Use:   "report [<rev-range>]",
+		Short: "Report agent attribution and usage over a range of commits",
+		Long: `Aggregate agent attribution, token usage, checkpoint counts and files touched
+over a range of commits (default: HEAD).
+
+The range is anything git log accepts, e.g. main..HEAD, v1.2.0..v1.3.0, or
+--since=2026-09-01 passed after --.
+
+Commits are linked to checkpoints through their Entire-Checkpoint trailer. For
+linked commits, agent and human lines come from the attribution calculated at
+commit time; other commits count all their added lines as human lines. Each
+checkpoint is counted once, even if several commits reference it.
+
+Group rows with --group-by:
+  agent      the agent of the checkpoint's latest session (default)
+  author     the commit author
+  branch     the branch the checkpoint was created on
+  directory  the directories a commit changes, to --depth components; a commit
+             touching several directories counts toward each of them
+  week       the ISO week of the commit's author date`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkDisabledGuard(cmd.Context(), cmd.OutOrStdout()) {
+				return nil
+			}
+			return runReport(cmd.Context(), cmd.OutOrStdout(), args, groupByFlag, formatFlag, depthFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&groupByFlag, "group-by", reportGroupAgent, "Group by: agent, author, branch, directory, or week")
+	cmd.Flags().StringVar(&formatFlag, "format", reportFormatTable, "Output format: table, csv, or json")
+	cmd.Flags().IntVar(&depthFlag, "depth", 1, "Number of path components for --group-by directory")
+
+	return cmd
+}
+
+func runReport(ctx context.Context, w io.Writer, revArgs []string, groupBy, format string, depth int) error {
+	switch groupBy {
+	case reportGroupAgent, reportGroupAuthor, reportGroupBranch, reportGroupDirectory, reportGroupWeek:
+	default:
+		return fmt.Errorf("invalid --group-by %q (expected agent, author, branch, directory, or week)", groupBy)
+	}
+	switch format {
+	case reportFormatTable, reportFormatCSV, reportFormatJSON:
+	default:
+		return fmt.Errorf("invalid --format %q (expected table, csv, or json)", format)
+	}
+	if depth < 1 {
+		return fmt.Errorf("invalid --depth %d (must be at least 1)", depth)
+	}
+	if len(revArgs) == 0 {
+		revArgs = []string{"HEAD"}
+	}
+
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	repoRoot, err := paths.WorktreeRoot(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get repository root: %w", err)
+	}
+
+	commits, err := listReportCommits(ctx, repoRoot, revArgs)
+	if err != nil {
+		return err
+	}
+	loadReportCheckpoints(ctx, checkpoint.NewGitStore(repo), commits)
+
+	report := buildUsageReport(commits, groupBy, depth)
+	report.Range = strings.Join(revArgs, " ")
+
+	switch format {
+	case reportFormatJSON:
+		data, err := jsonutil.MarshalIndentWithNewline(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		return nil
+	case reportFormatCSV:
+		return writeReportCSV(w, report)
+	default:
+		return writeReportTable(w, report)
+	}
+}
+
+// Field and record separators for the git log format used by listReportCommits.
+const (
+	reportRecordSep = "\x1e"
+	reportFieldSep  = "\x1f"
+)
+
+// listReportCommits lists the commits in a revision range with their added lines per file.
+func listReportCommits(ctx context.Context, repoRoot string, revArgs []string) ([]*reportCommit, error) {
+	args := append([]string{
+		"log", "--no-renames", "--numstat",
+		"--format=" + reportRecordSep + "%H" + reportFieldSep + "%an" + reportFieldSep + "%aI" + reportFieldSep + "%B" + reportFieldSep,
+	}, revArgs...)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repoRoot
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+	return parseReportLog(string(output))
+}
+
+// parseReportLog parses the output of the git log command run by listReportCommits.
+func parseReportLog(output string) ([]*reportCommit, error) {
+	var commits []*reportCommit
+	for _, record := range strings.Split(output, reportRecordSep) {
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+		fields := strings.SplitN(record, reportFieldSep, 5)
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("failed to parse git log output: unexpected record %q", record)
+		}
+		date, err := time.Parse(time.RFC3339, fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse commit date: %w", err)
+		}
+
+		c := &reportCommit{SHA: fields[0], Author: fields[1], Date: date, AddedLines: make(map[string]int)}
+		if cpID, found := trailers.ParseCheckpoint(fields[3]); found {
+			c.CheckpointID = cpID
+		}
+		for _, line := range strings.Split(fields[4], "\n") {
+			// numstat: "<added>\t<removed>\t<path>"; binary files show "-"
+			parts := strings.SplitN(line, "\t", 3)
+			if len(parts) != 3 {
+				continue
+			}
+			added, _ := strconv.Atoi(parts[0]) //nolint:errcheck // "-" for binary files counts as 0
+			c.AddedLines[parts[2]] += added
+		}
+		commits = append(commits, c)
+	}
+	return commits, nil
+}
+
+// loadReportCheckpoints reads the checkpoint of each linked commit. A checkpoint
+// referenced by several commits is attached to the first one only.
+func loadReportCheckpoints(ctx context.Context, store *checkpoint.GitStore, commits []*reportCommit) {
+	seen := make(map[id.CheckpointID]bool)
+	for _, c := range commits {
+		if c.CheckpointID.IsEmpty() || seen[c.CheckpointID] {
+			continue
+		}
+		seen[c.CheckpointID] = true
+
+		summary, err := store.ReadCommitted(ctx, c.CheckpointID)
+		if err != nil || summary == nil {
+			continue
+		}
+		cp := &reportCheckpoint{
+			Branch:       summary.Branch,
+			Sessions:     len(summary.Sessions),
+			TokenUsage:   summary.TokenUsage,
+			FilesTouched: summary.FilesTouched,
+		}
+		// Attribution and agent come from the latest session, matching explain.
+		if content, err := store.ReadLatestSessionContent(ctx, c.CheckpointID); err == nil {
+			cp.Agent = content.Metadata.Agent
+			cp.Attribution = content.Metadata.InitialAttribution
+			if cp.Branch == "" {
+				cp.Branch = content.Metadata.Branch
+			}
+		}
+		c.Checkpoint = cp
+	}
+}
+
+// buildUsageReport sums commits into rows for the grouping, sorted by group.
+func buildUsageReport(commits []*reportCommit, groupBy string, depth int) *usageReport {
+	rows := make(map[string]*reportRow)
+	total := &reportRow{Group: "Total"}
+
+	for _, c := range commits {
+		for _, key := range reportGroupKeys(c, groupBy, depth) {
+			row, ok := rows[key]
+			if !ok {
+				row = &reportRow{Group: key}
+				rows[key] = row
+			}
+			addReportCommit(row, c)
+		}
+		addReportCommit(total, c)
+	}
+
+	report := &usageReport{GroupBy: groupBy, Rows: make([]reportRow, 0, len(rows))}
+	for _, row := range rows {
+		finishReportRow(row)
+		report.Rows = append(report.Rows, *row)
+	}
+	slices.SortFunc(report.Rows, func(a, b reportRow) int { return strings.Compare(a.Group, b.Group) })
+	finishReportRow(total)
+	report.Total = *total
+	return report
+}
+
+// reportGroupKeys returns the groups a commit counts toward.
+func reportGroupKeys(c *reportCommit, groupBy string, depth int) []string {
+	cp := c.Checkpoint
+	switch groupBy {
+	case reportGroupAuthor:
+		return []string{c.Author}
+	case reportGroupWeek:
+		year, week := c.Date.ISOWeek()
+		return []string{fmt.Sprintf("%d-W%02d", year, week)}
+	case reportGroupBranch:
+		if cp != nil && cp.Branch != "" {
+			return []string{cp.Branch}
+		}
+		return []string{reportNone}
+	case reportGroupDirectory:
+		var dirs []string
+		for file := range c.AddedLines {
+			if dir := reportDirectory(file, depth); !slices.Contains(dirs, dir) {
+				dirs = append(dirs, dir)
+			}
+		}
+		if len(dirs) == 0 {
+			return []string{reportNone}
+		}
+		return dirs
+	default:
+		if cp != nil && cp.Agent != "" {
+			return []string{string(cp.Agent)}
+		}
+		if !c.CheckpointID.IsEmpty() {
+			return []string{string(agent.AgentTypeUnknown)}
+		}
+		return []string{reportNone}
+	}
+}
+
+// reportDirectory returns the first depth components of a file's directory, or "." for root files.
+func reportDirectory(file string, depth int) string {
+	dir := path.Dir(file)
+	if dir == "." {
+		return dir
+	}
+	parts := strings.Split(dir, "/")
+	if len(parts) > depth {
+		parts = parts[:depth]
+	}
+	return strings.Join(parts, "/") + "/"
+}
+
+// addReportCommit adds a commit's figures to a row.
+func addReportCommit(row *reportRow, c *reportCommit) {
+	row.Commits++
+	if c.CheckpointID.IsEmpty() {
+		added := 0
+		for _, n := range c.AddedLines {
+			added += n
+		}
+		row.HumanLines += added
+		row.TotalLines += added
+		return
+	}
+
+	row.AgentCommits++
+	cp := c.Checkpoint
+	if cp == nil {
+		return
+	}
+	row.Checkpoints++
+	row.Sessions += cp.Sessions
+
+	if a := cp.Attribution; a != nil {
+		row.AgentLines += a.AgentLines
+		row.HumanLines += a.HumanAdded + a.HumanModified
+		row.TotalLines += a.TotalCommitted
+	}
+	addReportTokens(row, cp.TokenUsage, false)
+
+	if row.files == nil {
+		row.files = make(map[string]bool)
+	}
+	for _, f := range cp.FilesTouched {
+		row.files[f] = true
+	}
+}
+
+// addReportTokens adds token usage, including subagent usage, to a row.
+func addReportTokens(row *reportRow, tu *agent.TokenUsage, subagent bool) {
+	if tu == nil {
+		return
+	}
+	row.InputTokens += tu.InputTokens
+	row.CacheCreationTokens += tu.CacheCreationTokens
+	row.CacheReadTokens += tu.CacheReadTokens
+	row.OutputTokens += tu.OutputTokens
+	total := tu.InputTokens + tu.CacheCreationTokens + tu.CacheReadTokens + tu.OutputTokens
+	row.TotalTokens += total
+	if subagent {
+		row.SubagentTokens += total
+	}
+	addReportTokens(row, tu.SubagentTokens, true)
+}
+
+// finishReportRow computes the derived figures of a row.
+func finishReportRow(row *reportRow) {
+	row.FilesTouched = len(row.files)
+	if row.TotalLines > 0 {
+		row.AgentPercentage = float64(row.AgentLines) / float64(row.TotalLines) * 100
+	}
+}
+
+// reportColumns are the table and CSV column headers.
+var reportColumns = []string{
+	"group", "commits", "agent_commits", "checkpoints", "sessions",
+	"agent_lines", "human_lines", "total_lines", "agent_pct",
+	"input_tokens", "cache_creation_tokens", "cache_read_tokens", "output_tokens", "subagent_tokens", "total_tokens",
+	"files_touched",
+}
+
+func reportRowValues(row reportRow) []string {
+	return []string{
+		row.Group,
+		strconv.Itoa(row.Commits),
+		strconv.Itoa(row.AgentCommits),
+		strconv.Itoa(row.Checkpoints),
+		strconv.Itoa(row.Sessions),
+		strconv.Itoa(row.AgentLines),
+		strconv.Itoa(row.HumanLines),
+		strconv.Itoa(row.TotalLines),
+		strconv.FormatFloat(row.AgentPercentage, 'f', 1, 64),
+		strconv.Itoa(row.InputTokens),
+		strconv.Itoa(row.CacheCreationTokens),
+		strconv.Itoa(row.CacheReadTokens),
+		strconv.Itoa(row.OutputTokens),
+		strconv.Itoa(row.SubagentTokens),
+		strconv.Itoa(row.TotalTokens),
+		strconv.Itoa(row.FilesTouched),
+	}
+}
+
+func writeReportCSV(w io.Writer, report *usageReport) error {
+	cw := csv.NewWriter(w)
+	header := append([]string{}, reportColumns...)
+	header[0] = report.GroupBy
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	for _, row := range report.Rows {
+		if err := cw.Write(reportRowValues(row)); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// writeReportTable writes a human-readable table with a total row.
+// Token columns are condensed to the total.
+func writeReportTable(w io.Writer, report *usageReport) error {
+	if len(report.Rows) == 0 {
+		fmt.Fprintf(w, "No commits in %s\n", report.Range)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tCommits\tAgent commits\tCheckpoints\tAgent lines\tHuman lines\tAgent %%\tTokens\tFiles\t\n", stringutil.CapitalizeFirst(report.GroupBy))
+	writeRow := func(row reportRow) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%.1f%%\t%s\t%d\t\n",
+			row.Group, row.Commits, row.AgentCommits, row.Checkpoints,
+			row.AgentLines, row.HumanLines, row.AgentPercentage, formatThousands(row.TotalTokens), row.FilesTouched)
+	}
+	for _, row := range report.Rows {
+		writeRow(row)
+	}
+	writeRow(report.Total)
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
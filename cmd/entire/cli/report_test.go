@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+)
+
+// setupReportTestRepo creates a human commit (2 lines) followed by an agent
+// commit linked to a checkpoint with attribution and token usage.
+func setupReportTestRepo(t *testing.T) {
+	t.Helper()
+	tmpDir, repo := setupCheckpointTestRepo(t, "one\ntwo\n")
+	writeTestCheckpoint(t, repo, checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("abc123def456"),
+		SessionID:    "session-report",
+		Branch:       "feature",
+		Agent:        agent.AgentTypeClaudeCode,
+		Transcript:   []byte(`{"type":"user","message":{"content":"add code"}}` + "\n"),
+		FilesTouched: []string{"src/a.go", "src/b.go"},
+		TokenUsage: &agent.TokenUsage{
+			InputTokens:    100,
+			OutputTokens:   50,
+			SubagentTokens: &agent.TokenUsage{InputTokens: 20, OutputTokens: 5},
+		},
+		InitialAttribution: &checkpoint.InitialAttribution{
+			AgentLines:     8,
+			HumanAdded:     1,
+			HumanModified:  1,
+			TotalCommitted: 10,
+		},
+	})
+	commitWithCheckpoint(t, tmpDir, "agent commit", "abc123def456", map[string]string{
+		"src/a.go": strings.Repeat("a\n", 6),
+		"src/b.go": strings.Repeat("b\n", 4),
+	})
+}
+
+func runReportJSON(t *testing.T, groupBy string) *usageReport {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := runReport(context.Background(), &buf, nil, groupBy, reportFormatJSON, 1); err != nil {
+		t.Fatalf("runReport(%s) error = %v", groupBy, err)
+	}
+	var report usageReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	return &report
+}
+
+func TestRunReport_GroupByAgent(t *testing.T) {
+	setupReportTestRepo(t)
+
+	report := runReportJSON(t, reportGroupAgent)
+	if report.Range != "HEAD" || len(report.Rows) != 2 {
+		t.Fatalf("report = %+v, want 2 rows over HEAD", report)
+	}
+
+	// Rows are sorted by group: "(none)" before "Claude Code"
+	human, claude := report.Rows[0], report.Rows[1]
+	if human.Group != reportNone || human.Commits != 1 || human.HumanLines != 2 || human.TotalLines != 2 || human.AgentCommits != 0 {
+		t.Errorf("human row = %+v", human)
+	}
+	if claude.Group != string(agent.AgentTypeClaudeCode) || claude.Commits != 1 || claude.Checkpoints != 1 || claude.Sessions != 1 {
+		t.Errorf("agent row = %+v", claude)
+	}
+	if claude.AgentLines != 8 || claude.HumanLines != 2 || claude.TotalLines != 10 || claude.AgentPercentage != 80 {
+		t.Errorf("agent row lines = %+v", claude)
+	}
+	if claude.TotalTokens != 175 || claude.SubagentTokens != 25 || claude.InputTokens != 120 {
+		t.Errorf("agent row tokens = %+v, want subagent tokens included", claude)
+	}
+	if claude.FilesTouched != 2 {
+		t.Errorf("agent row files = %d, want 2", claude.FilesTouched)
+	}
+
+	total := report.Total
+	if total.Commits != 2 || total.AgentLines != 8 || total.TotalLines != 12 {
+		t.Errorf("total = %+v", total)
+	}
+}
+
+var weekKeyPattern = regexp.MustCompile(`^\d{4}-W\d{2}$`)
+
+func TestRunReport_OtherGroupings(t *testing.T) {
+	setupReportTestRepo(t)
+
+	branch := runReportJSON(t, reportGroupBranch)
+	if len(branch.Rows) != 2 || branch.Rows[1].Group != "feature" || branch.Rows[1].AgentLines != 8 {
+		t.Errorf("branch rows = %+v", branch.Rows)
+	}
+
+	dir := runReportJSON(t, reportGroupDirectory)
+	if len(dir.Rows) != 2 || dir.Rows[0].Group != "." || dir.Rows[1].Group != "src/" {
+		t.Errorf("directory rows = %+v", dir.Rows)
+	}
+
+	week := runReportJSON(t, reportGroupWeek)
+	if len(week.Rows) != 1 || week.Rows[0].Commits != 2 || !weekKeyPattern.MatchString(week.Rows[0].Group) {
+		t.Errorf("week rows = %+v", week.Rows)
+	}
+
+	author := runReportJSON(t, reportGroupAuthor)
+	if len(author.Rows) != 1 || author.Rows[0].Commits != 2 {
+		t.Errorf("author rows = %+v", author.Rows)
+	}
+}
+
+func TestRunReport_TableAndCSV(t *testing.T) {
+	setupReportTestRepo(t)
+
+	var buf bytes.Buffer
+	if err := runReport(context.Background(), &buf, []string{"HEAD~1..HEAD"}, reportGroupAgent, reportFormatTable, 1); err != nil {
+		t.Fatalf("runReport(table) error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Agent", "Claude Code", "80.0%", "175", "Total"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in table:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, reportNone) {
+		t.Errorf("range HEAD~1..HEAD should exclude the human commit:\n%s", out)
+	}
+
+	buf.Reset()
+	if err := runReport(context.Background(), &buf, nil, reportGroupAgent, reportFormatCSV, 1); err != nil {
+		t.Fatalf("runReport(csv) error = %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(records) != 3 || records[0][0] != "agent" || records[2][0] != "Claude Code" || records[2][8] != "80.0" {
+		t.Errorf("CSV records = %v", records)
+	}
+}
+
+func TestRunReport_InvalidFlags(t *testing.T) {
+	setupReportTestRepo(t)
+
+	var buf bytes.Buffer
+	if err := runReport(context.Background(), &buf, nil, "team", reportFormatTable, 1); err == nil {
+		t.Error("expected error for invalid --group-by")
+	}
+	if err := runReport(context.Background(), &buf, nil, reportGroupAgent, "xml", 1); err == nil {
+		t.Error("expected error for invalid --format")
+	}
+	if err := runReport(context.Background(), &buf, []string{"nope..HEAD"}, reportGroupAgent, reportFormatTable, 1); err == nil {
+		t.Error("expected error for invalid range")
+	}
+}
+
+func TestReportDirectory(t *testing.T) {
+	tests := []struct {
+		file  string
+		depth int
+		want  string
+	}{
+		{"README.md", 1, "."},
+		{"src/a.go", 1, "src/"},
+		{"src/pkg/a.go", 1, "src/"},
+		{"src/pkg/a.go", 2, "src/pkg/"},
+		{"src/pkg/a.go", 5, "src/pkg/"},
+	}
+	for _, tt := range tests {
+		if got := reportDirectory(tt.file, tt.depth); got != tt.want {
+			t.Errorf("reportDirectory(%q, %d) = %q, want %q", tt.file, tt.depth, got, tt.want)
+		}
+	}
+}
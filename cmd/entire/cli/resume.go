@@ -15,7 +15,6 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
-	"github.com/entireio/cli/cmd/entire/cli/trailers"
 
 	"github.com/charmbracelet/huh"
 	"github.com/go-git/go-git/v5"
@@ -162,8 +161,8 @@ func resumeFromCurrentBranch(ctx context.Context, branchName string, force bool)
 		return checkRemoteMetadata(ctx, repo, checkpointID)
 	}
 
-	// Look up metadata from sharded path
-	metadata, err := strategy.ReadCheckpointMetadata(metadataTree, checkpointID.Path())
+	// Look up metadata from the local index, falling back to the sharded path
+	metadata, err := lookupCheckpointMetadata(ctx, repo, metadataTree, checkpointID)
 	if err != nil {
 		// Checkpoint exists in commit but no local metadata - check remote
 		return checkRemoteMetadata(ctx, repo, checkpointID)
@@ -172,6 +171,28 @@ func resumeFromCurrentBranch(ctx context.Context, branchName string, force bool)
 	return resumeSession(ctx, metadata.SessionID, checkpointID, force)
 }
 
+// lookupCheckpointMetadata returns checkpoint metadata from the local checkpoint index.
+// Falls back to reading metadata.json from the metadata branch tree when the index
+// is unavailable or doesn't contain the checkpoint.
+func lookupCheckpointMetadata(ctx context.Context, repo *git.Repository, metadataTree *object.Tree, checkpointID id.CheckpointID) (*strategy.CheckpointInfo, error) {
+	entry, err := checkpoint.NewGitStore(repo).LookupIndexed(ctx, checkpointID)
+	if err == nil && entry != nil && len(entry.SessionIDs) > 0 {
+		return &strategy.CheckpointInfo{
+			CheckpointID:     entry.CheckpointID,
+			SessionID:        entry.SessionIDs[0], // First session, matching ReadCheckpointMetadata
+			CreatedAt:        entry.CreatedAt,
+			CheckpointsCount: entry.CheckpointsCount,
+			FilesTouched:     entry.FilesTouched,
+			Agent:            entry.Agent,
+			IsTask:           entry.IsTask,
+			ToolUseID:        entry.ToolUseID,
+			SessionCount:     len(entry.SessionIDs),
+			SessionIDs:       entry.SessionIDs,
+		}, nil
+	}
+	return strategy.ReadCheckpointMetadata(metadataTree, checkpointID.Path()) //nolint:wrapcheck // Already wrapped by strategy
+}
+
 // branchCheckpointResult contains the result of searching for a checkpoint on a branch.
 type branchCheckpointResult struct {
 	checkpointID      id.CheckpointID
@@ -182,7 +203,7 @@ type branchCheckpointResult struct {
 }
 
 // findBranchCheckpoint finds the most recent commit with an Entire-Checkpoint trailer
-// among commits that are unique to this branch (not reachable from the default branch).
+// (or checkpoint note) among commits that are unique to this branch (not reachable from the default branch).
 // This handles the case where main has been merged into the feature branch.
 func findBranchCheckpoint(repo *git.Repository, branchName string) (*branchCheckpointResult, error) {
 	result := &branchCheckpointResult{}
@@ -199,7 +220,7 @@ func findBranchCheckpoint(repo *git.Repository, branchName string) (*branchCheck
 	}
 
 	// First, check if HEAD itself has a checkpoint (most common case)
-	if cpID, found := trailers.ParseCheckpoint(headCommit.Message); found {
+	if cpID, found := strategy.CheckpointIDForCommit(repo, headCommit); found {
 		result.checkpointID = cpID
 		result.commitHash = head.Hash().String()
 		result.commitMessage = headCommit.Message
@@ -222,39 +243,40 @@ func findBranchCheckpoint(repo *git.Repository, branchName string) (*branchCheck
 
 	// If we can't find a default branch, or we're on it, just walk all commits
 	if defaultBranch == "" || defaultBranch == branchName {
-		return findCheckpointInHistory(headCommit, nil), nil
+		return findCheckpointInHistory(repo, headCommit, nil), nil
 	}
 
 	// Get the default branch reference
 	defaultRef, err := repo.Reference(plumbing.NewBranchReferenceName(defaultBranch), true)
 	if err != nil {
 		// Default branch doesn't exist locally, fall back to walking all commits
-		return findCheckpointInHistory(headCommit, nil), nil //nolint:nilerr // Intentional fallback
+		return findCheckpointInHistory(repo, headCommit, nil), nil //nolint:nilerr // Intentional fallback
 	}
 
 	defaultCommit, err := repo.CommitObject(defaultRef.Hash())
 	if err != nil {
 		// Can't get default commit, fall back to walking all commits
-		return findCheckpointInHistory(headCommit, nil), nil //nolint:nilerr // Intentional fallback
+		return findCheckpointInHistory(repo, headCommit, nil), nil //nolint:nilerr // Intentional fallback
 	}
 
 	// Find merge base
 	mergeBase, err := headCommit.MergeBase(defaultCommit)
 	if err != nil || len(mergeBase) == 0 {
 		// No common ancestor, fall back to walking all commits
-		return findCheckpointInHistory(headCommit, nil), nil //nolint:nilerr // Intentional fallback
+		return findCheckpointInHistory(repo, headCommit, nil), nil //nolint:nilerr // Intentional fallback
 	}
 
 	// Walk from HEAD to merge base, looking for checkpoint
-	return findCheckpointInHistory(headCommit, &mergeBase[0].Hash), nil
+	return findCheckpointInHistory(repo, headCommit, &mergeBase[0].Hash), nil
 }
 
-// findCheckpointInHistory walks commit history from start looking for a checkpoint trailer.
+// findCheckpointInHistory walks commit history from start looking for a checkpoint
+// trailer, or a checkpoint note when the commit has no trailer.
 // If stopAt is provided, stops when reaching that commit (exclusive).
 // Returns the first checkpoint found and info about commits between HEAD and the checkpoint.
 // It distinguishes between merge commits (bringing in other branches) and regular commits
 // (actual branch work) to avoid false warnings after merging main.
-func findCheckpointInHistory(start *object.Commit, stopAt *plumbing.Hash) *branchCheckpointResult {
+func findCheckpointInHistory(repo *git.Repository, start *object.Commit, stopAt *plumbing.Hash) *branchCheckpointResult {
 	result := &branchCheckpointResult{}
 	branchWorkCommits := 0 // Regular commits without checkpoints (actual work)
 	const maxCommits = 100 // Limit search depth
@@ -267,8 +289,8 @@ func findCheckpointInHistory(start *object.Commit, stopAt *plumbing.Hash) *branc
 			break
 		}
 
-		// Check for checkpoint trailer
-		if cpID, found := trailers.ParseCheckpoint(current.Message); found {
+		// Check for checkpoint trailer or note
+		if cpID, found := strategy.CheckpointIDForCommit(repo, current); found {
 			result.checkpointID = cpID
 			result.commitHash = current.Hash.String()
 			result.commitMessage = current.Message
@@ -323,11 +345,14 @@ func promptResumeFromOlderCheckpoint() (bool, error) {
 	return confirmed, nil
 }
 
-// checkRemoteMetadata checks if checkpoint metadata exists on origin/entire/checkpoints/v1
+// checkRemoteMetadata checks if checkpoint metadata exists on the metadata remote's
+// entire/checkpoints/v1 (origin unless strategy_options.metadata_remote is set)
 // and automatically fetches it if available.
 func checkRemoteMetadata(ctx context.Context, repo *git.Repository, checkpointID id.CheckpointID) error {
+	remote := strategy.MetadataRemote(ctx, "origin")
+
 	// Try to get remote metadata branch tree
-	remoteTree, err := strategy.GetRemoteMetadataBranchTree(repo)
+	remoteTree, err := strategy.GetRemoteMetadataBranchTree(ctx, repo)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Checkpoint '%s' found in commit but session metadata not available\n", checkpointID)
 		fmt.Fprintf(os.Stderr, "The entire/checkpoints/v1 branch may not exist locally or on the remote.\n")
@@ -342,10 +367,10 @@ func checkRemoteMetadata(ctx context.Context, repo *git.Repository, checkpointID
 	}
 
 	// Metadata exists on remote but not locally - fetch it automatically
-	fmt.Fprintf(os.Stderr, "Fetching session metadata from origin...\n")
+	fmt.Fprintf(os.Stderr, "Fetching session metadata from %s...\n", remote)
 	if err := FetchMetadataBranch(ctx); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to fetch metadata: %v\n", err)
-		fmt.Fprintf(os.Stderr, "You can try manually: git fetch origin entire/checkpoints/v1:entire/checkpoints/v1\n")
+		fmt.Fprintf(os.Stderr, "You can try manually: git fetch %s entire/checkpoints/v1:entire/checkpoints/v1\n", remote)
 		return NewSilentError(errors.New("failed to fetch metadata"))
 	}
 
@@ -368,7 +393,7 @@ func resumeSession(ctx context.Context, sessionID string, checkpointID id.Checkp
 		return fmt.Errorf("failed to get metadata branch: %w", err)
 	}
 
-	metadata, err := strategy.ReadCheckpointMetadata(metadataTree, checkpointID.Path())
+	metadata, err := lookupCheckpointMetadata(ctx, repo, metadataTree, checkpointID)
 	if err != nil {
 		return fmt.Errorf("failed to read checkpoint metadata: %w", err)
 	}
@@ -44,29 +44,78 @@ func newRewindCmd() *cobra.Command {
 	var toFlag string
 	var logsOnlyFlag bool
 	var resetFlag bool
+	var undoFlag bool
+	var mergeFlag bool
 
 	cmd := &cobra.Command{
-		Use:   "rewind",
+		Use:   "rewind [-- <pathspec>...]",
 		Short: "Browse checkpoints and rewind your session",
 		Long: `Interactive command for rewinding and managing agent sessions.
 
 This command will show you an interactive list of recent checkpoints.  You'll be
 able to select one for Entire to rewind your branch state, including your code and
-your agent's context.`,
-		RunE: func(cmd *cobra.Command, _ []string) error {
+your agent's context.
+
+Pathspecs after -- restrict the rewind to matching files: a file, a directory or
+a glob pattern, relative to the current directory. Only those files are restored
+or deleted, and the agent's context is left as it is.
+
+Before each rewind or reset, Entire saves the working tree, including untracked
+files, and this worktree's session state as a recovery point. Use --undo to go back to the latest one, --undo --to
+<id> for an earlier one, and --undo --list to list them. An undo saves a recovery
+point too, so it can itself be undone.
+
+Use --merge to keep changes made since the agent's latest checkpoint, such as
+your own edits: each file is merged with the checkpoint instead of overwritten,
+and overlapping changes are left as conflict markers.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			// Pathspecs must follow --, so a mistyped subcommand or flag value
+			// isn't taken as a path to rewind
+			if len(args) > 0 && cmd.ArgsLenAtDash() != 0 {
+				return fmt.Errorf("unexpected argument %q: pathspecs must follow --, e.g. 'entire rewind -- %s'", args[0], args[0])
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
 			// Check if Entire is disabled
 			if checkDisabledGuard(cmd.Context(), cmd.OutOrStdout()) {
 				return nil
 			}
 
 			ctx := cmd.Context()
+			if undoFlag {
+				if len(args) > 0 || logsOnlyFlag || resetFlag || mergeFlag {
+					return errors.New("--undo cannot be used with pathspecs, --logs-only, --reset or --merge")
+				}
+				if listFlag {
+					return runRewindUndoList(ctx)
+				}
+				return runRewindUndo(ctx, toFlag)
+			}
 			if listFlag {
+				if len(args) > 0 {
+					return errors.New("pathspecs cannot be used with --list")
+				}
 				return runRewindList(ctx)
 			}
+			opts := strategy.RewindOptions{Merge: mergeFlag}
+			if mergeFlag && (logsOnlyFlag || resetFlag) {
+				return errors.New("--merge cannot be used with --logs-only or --reset")
+			}
+			if len(args) > 0 {
+				if logsOnlyFlag || resetFlag {
+					return errors.New("pathspecs cannot be used with --logs-only or --reset")
+				}
+				specs, err := resolveRewindPathspecs(ctx, args)
+				if err != nil {
+					return err
+				}
+				opts.Paths = specs
+			}
 			if toFlag != "" {
-				return runRewindToWithOptions(ctx, toFlag, logsOnlyFlag, resetFlag)
+				return runRewindToWithOptions(ctx, toFlag, logsOnlyFlag, resetFlag, opts)
 			}
-			return runRewindInteractive(ctx)
+			return runRewindInteractive(ctx, opts)
 		},
 	}
 
@@ -74,11 +123,13 @@ your agent's context.`,
 	cmd.Flags().StringVar(&toFlag, "to", "", "Rewind to specific commit ID (non-interactive)")
 	cmd.Flags().BoolVar(&logsOnlyFlag, "logs-only", false, "Only restore logs, don't modify working directory (for logs-only points)")
 	cmd.Flags().BoolVar(&resetFlag, "reset", false, "Reset branch to commit (destructive, for logs-only points)")
+	cmd.Flags().BoolVar(&undoFlag, "undo", false, "Restore the working tree saved before the last rewind or reset")
+	cmd.Flags().BoolVar(&mergeFlag, "merge", false, "Merge your changes since the latest checkpoint instead of overwriting them")
 
 	return cmd
 }
 
-func runRewindInteractive(ctx context.Context) error { //nolint:maintidx // already present in codebase
+func runRewindInteractive(ctx context.Context, opts strategy.RewindOptions) error { //nolint:maintidx // already present in codebase
 	// Get the configured strategy
 	start := GetStrategy(ctx)
 
@@ -145,12 +196,17 @@ func runRewindInteractive(ctx context.Context) error { //nolint:maintidx // alre
 	}
 	options = append(options, huh.NewOption("Cancel", "cancel"))
 
+	selectDescription := "Your working directory will be restored to this checkpoint's state"
+	if len(opts.Paths) > 0 {
+		selectDescription = fmt.Sprintf("Only %s will be restored to this checkpoint's state", strings.Join(opts.Paths, " "))
+	}
+
 	var selectedID string
 	form := NewAccessibleForm(
 		huh.NewGroup(
 			huh.NewSelect[string]().
 				Title("Select a checkpoint to restore").
-				Description("Your working directory will be restored to this checkpoint's state").
+				Description(selectDescription).
 				Options(options...).
 				Value(&selectedID),
 		),
@@ -199,11 +255,17 @@ func runRewindInteractive(ctx context.Context) error { //nolint:maintidx // alre
 
 	// Handle logs-only points with a sub-choice menu
 	if selectedPoint.IsLogsOnly {
+		if len(opts.Paths) > 0 || opts.Merge {
+			return errors.New("pathspecs and --merge cannot be used with logs-only rewind points")
+		}
 		return handleLogsOnlyRewindInteractive(ctx, start, *selectedPoint, shortID)
 	}
 
 	// Preview rewind to show warnings about files that will be deleted
-	preview, previewErr := start.PreviewRewind(ctx, *selectedPoint)
+	preview, previewErr := start.PreviewRewindWithOptions(ctx, *selectedPoint, opts)
+	if err := checkPathspecPreview(opts, preview, previewErr); err != nil {
+		return err
+	}
 	if previewErr == nil && preview != nil && len(preview.FilesToDelete) > 0 {
 		fmt.Fprintf(os.Stderr, "\nWarning: The following untracked files will be DELETED:\n")
 		for _, f := range preview.FilesToDelete {
@@ -215,6 +277,13 @@ func runRewindInteractive(ctx context.Context) error { //nolint:maintidx // alre
 	// Confirm rewind
 	var confirm bool
 	description := fmt.Sprintf("This will reset to: %s\nChanges after this point may be lost!", selectedPoint.Message)
+	if len(opts.Paths) > 0 {
+		description = fmt.Sprintf("This will reset %s to: %s\nChanges to these files after this point may be lost!",
+			strings.Join(opts.Paths, " "), selectedPoint.Message)
+	}
+	if opts.Merge {
+		description = fmt.Sprintf("This will merge to: %s\nYour changes since the latest checkpoint are kept.", selectedPoint.Message)
+	}
 	confirmForm := NewAccessibleForm(
 		huh.NewGroup(
 			huh.NewConfirm().
@@ -250,7 +319,7 @@ func runRewindInteractive(ctx context.Context) error { //nolint:maintidx // alre
 	)
 
 	// Perform the rewind using strategy
-	if err := start.Rewind(ctx, *selectedPoint); err != nil {
+	if err := start.RewindWithOptions(ctx, *selectedPoint, opts); err != nil {
 		logging.Error(logCtx, "rewind failed",
 			slog.String("checkpoint_id", selectedPoint.ID),
 			slog.String("error", err.Error()),
@@ -262,6 +331,11 @@ func runRewindInteractive(ctx context.Context) error { //nolint:maintidx // alre
 		slog.String("checkpoint_id", selectedPoint.ID),
 	)
 
+	// A partial rewind only restores files; the session keeps its transcript
+	if len(opts.Paths) > 0 {
+		return nil
+	}
+
 	// Handle transcript restoration differently for task checkpoints
 	var sessionID string
 	var transcriptFile string
@@ -330,6 +404,127 @@ func runRewindInteractive(ctx context.Context) error { //nolint:maintidx // alre
 	return nil
 }
 
+// recoveryPointListLimit is how many recovery points --undo --list shows.
+const recoveryPointListLimit = 20
+
+// runRewindUndo restores a recovery point: the latest one, or the one whose
+// ID (7+ characters) is given.
+func runRewindUndo(ctx context.Context, recoveryID string) error {
+	start := GetStrategy(ctx)
+
+	points, err := start.ListRecoveryPoints(ctx, 0)
+	if err != nil {
+		return fmt.Errorf("failed to find recovery points: %w", err)
+	}
+	if len(points) == 0 {
+		fmt.Println("No recovery points found.")
+		fmt.Println("Recovery points are saved automatically before a rewind or reset.")
+		return nil
+	}
+
+	selected := &points[0]
+	if recoveryID != "" {
+		selected = nil
+		for i, p := range points {
+			if p.ID == recoveryID || (len(recoveryID) >= 7 && strings.HasPrefix(p.ID, recoveryID)) {
+				selected = &points[i]
+				break
+			}
+		}
+		if selected == nil {
+			return fmt.Errorf("recovery point not found: %s", recoveryID)
+		}
+	}
+
+	if err := start.RestoreRecoveryPoint(ctx, *selected); err != nil {
+		return fmt.Errorf("failed to restore recovery point: %w", err)
+	}
+
+	fmt.Printf("Restored recovery point %s (%s).\n", selected.ID[:7], selected.Message)
+	fmt.Println("To undo this: entire rewind --undo")
+	return nil
+}
+
+func runRewindUndoList(ctx context.Context) error {
+	start := GetStrategy(ctx)
+
+	points, err := start.ListRecoveryPoints(ctx, recoveryPointListLimit)
+	if err != nil {
+		return fmt.Errorf("failed to find recovery points: %w", err)
+	}
+
+	// Output as JSON for programmatic use, like --list
+	type jsonRecoveryPoint struct {
+		ID             string            `json:"id"`
+		Message        string            `json:"message"`
+		Date           string            `json:"date"`
+		Head           string            `json:"head"`
+		ShadowBranches map[string]string `json:"shadow_branches,omitempty"`
+	}
+
+	output := make([]jsonRecoveryPoint, len(points))
+	for i, p := range points {
+		output[i] = jsonRecoveryPoint{
+			ID:             p.ID,
+			Message:        p.Message,
+			Date:           p.Date.Format(time.RFC3339),
+			Head:           p.Head,
+			ShadowBranches: p.ShadowBranches,
+		}
+	}
+
+	data, err := jsonutil.MarshalIndentWithNewline(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal recovery points: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// resolveRewindPathspecs converts pathspecs relative to the current directory
+// into the repo-relative, slash-separated form RewindOptions.Paths expects.
+func resolveRewindPathspecs(ctx context.Context, args []string) ([]string, error) {
+	repoRoot, err := paths.WorktreeRoot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository root: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	// The worktree root has symlinks resolved (e.g., /private/var on macOS)
+	if resolved, evalErr := filepath.EvalSymlinks(cwd); evalErr == nil {
+		cwd = resolved
+	}
+
+	specs := make([]string, 0, len(args))
+	for _, arg := range args {
+		absPath := arg
+		if !filepath.IsAbs(absPath) {
+			absPath = filepath.Join(cwd, arg)
+		}
+		rel, err := filepath.Rel(repoRoot, absPath)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%s is outside the repository", arg)
+		}
+		specs = append(specs, filepath.ToSlash(rel))
+	}
+	return specs, nil
+}
+
+// checkPathspecPreview fails a path-restricted rewind that would change nothing,
+// which usually means a mistyped pathspec. A merge rewind may legitimately
+// change nothing, when every matching file was edited by hand.
+func checkPathspecPreview(opts strategy.RewindOptions, preview *strategy.RewindPreview, previewErr error) error {
+	if len(opts.Paths) == 0 || opts.Merge || previewErr != nil || preview == nil {
+		return nil
+	}
+	if len(preview.FilesToRestore) == 0 && len(preview.FilesToDelete) == 0 {
+		return fmt.Errorf("no files in the checkpoint or working tree match %s", strings.Join(opts.Paths, " "))
+	}
+	return nil
+}
+
 func runRewindList(ctx context.Context) error {
 	start := GetStrategy(ctx)
 
@@ -377,11 +572,11 @@ func runRewindList(ctx context.Context) error {
 	return nil
 }
 
-func runRewindToWithOptions(ctx context.Context, commitID string, logsOnly bool, reset bool) error {
-	return runRewindToInternal(ctx, commitID, logsOnly, reset)
+func runRewindToWithOptions(ctx context.Context, commitID string, logsOnly bool, reset bool, opts strategy.RewindOptions) error {
+	return runRewindToInternal(ctx, commitID, logsOnly, reset, opts)
 }
 
-func runRewindToInternal(ctx context.Context, commitID string, logsOnly bool, reset bool) error {
+func runRewindToInternal(ctx context.Context, commitID string, logsOnly bool, reset bool, opts strategy.RewindOptions) error {
 	start := GetStrategy(ctx)
 
 	// Check for uncommitted changes (skip for reset which handles this itself)
@@ -424,11 +619,17 @@ func runRewindToInternal(ctx context.Context, commitID string, logsOnly bool, re
 	// 1. For logs-only points, always use logs-only restoration
 	// 2. If --logs-only flag is set, use logs-only restoration even for checkpoint points
 	if selectedPoint.IsLogsOnly || logsOnly {
+		if len(opts.Paths) > 0 || opts.Merge {
+			return errors.New("pathspecs and --merge cannot be used with logs-only rewind points")
+		}
 		return handleLogsOnlyRewindNonInteractive(ctx, start, *selectedPoint)
 	}
 
 	// Preview rewind to show warnings about files that will be deleted
-	preview, previewErr := start.PreviewRewind(ctx, *selectedPoint)
+	preview, previewErr := start.PreviewRewindWithOptions(ctx, *selectedPoint, opts)
+	if err := checkPathspecPreview(opts, preview, previewErr); err != nil {
+		return err
+	}
 	if previewErr == nil && preview != nil && len(preview.FilesToDelete) > 0 {
 		fmt.Fprintf(os.Stderr, "\nWarning: The following untracked files will be DELETED:\n")
 		for _, f := range preview.FilesToDelete {
@@ -454,7 +655,7 @@ func runRewindToInternal(ctx context.Context, commitID string, logsOnly bool, re
 	)
 
 	// Perform the rewind
-	if err := start.Rewind(ctx, *selectedPoint); err != nil {
+	if err := start.RewindWithOptions(ctx, *selectedPoint, opts); err != nil {
 		logging.Error(logCtx, "rewind failed",
 			slog.String("checkpoint_id", selectedPoint.ID),
 			slog.String("error", err.Error()),
@@ -466,6 +667,11 @@ func runRewindToInternal(ctx context.Context, commitID string, logsOnly bool, re
 		slog.String("checkpoint_id", selectedPoint.ID),
 	)
 
+	// A partial rewind only restores files; the session keeps its transcript
+	if len(opts.Paths) > 0 {
+		return nil
+	}
+
 	// Handle transcript restoration
 	var sessionID string
 	var transcriptFile string
@@ -601,6 +807,11 @@ func handleLogsOnlyResetNonInteractive(ctx context.Context, start *strategy.Manu
 		return fmt.Errorf("failed to restore logs: %w", err)
 	}
 
+	// Save the working tree so the reset can be undone
+	if _, err := start.SaveRecoveryPoint(ctx, "reset to "+point.ID[:min(len(point.ID), 7)]); err != nil {
+		return fmt.Errorf("failed to save recovery point: %w", err)
+	}
+
 	// Perform git reset --hard
 	if err := performGitResetHard(ctx, point.ID); err != nil {
 		logging.Error(logCtx, "logs-only reset failed during git reset",
@@ -625,12 +836,13 @@ func handleLogsOnlyResetNonInteractive(ctx context.Context, start *strategy.Manu
 	printMultiSessionResumeCommands(sessions)
 
 	// Show recovery instructions
+	fmt.Printf("\nTo undo this reset, including uncommitted changes: entire rewind --undo\n")
 	if currentHead != "" && currentHead != point.ID {
 		currentShort := currentHead
 		if len(currentShort) > 7 {
 			currentShort = currentShort[:7]
 		}
-		fmt.Printf("\nTo undo this reset: git reset --hard %s\n", currentShort)
+		fmt.Printf("To move the branch back only: git reset --hard %s\n", currentShort)
 	}
 
 	return nil
@@ -991,6 +1203,11 @@ func handleLogsOnlyReset(ctx context.Context, start *strategy.ManualCommitStrate
 		return nil
 	}
 
+	// Save the working tree so the reset can be undone
+	if _, err := start.SaveRecoveryPoint(ctx, "reset to "+point.ID[:min(len(point.ID), 7)]); err != nil {
+		return fmt.Errorf("failed to save recovery point: %w", err)
+	}
+
 	// Perform git reset --hard
 	if err := performGitResetHard(ctx, point.ID); err != nil {
 		logging.Error(logCtx, "logs-only reset failed during git reset",
@@ -1008,12 +1225,13 @@ func handleLogsOnlyReset(ctx context.Context, start *strategy.ManualCommitStrate
 	printMultiSessionResumeCommands(sessions)
 
 	// Show recovery instructions
+	fmt.Printf("\nTo undo this reset, including uncommitted changes: entire rewind --undo\n")
 	if currentHead != "" && currentHead != point.ID {
 		currentShort := currentHead
 		if len(currentShort) > 7 {
 			currentShort = currentShort[:7]
 		}
-		fmt.Printf("\nTo undo this reset: git reset --hard %s\n", currentShort)
+		fmt.Printf("To move the branch back only: git reset --hard %s\n", currentShort)
 	}
 
 	return nil
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestRewindCmd_PathspecsAfterDash(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		args    []string
+		wantErr bool
+	}{
+		{args: nil},
+		{args: []string{"--to", "abc123"}},
+		{args: []string{"--", "src/"}},
+		{args: []string{"--to", "abc123", "--", "src/", "*.go"}},
+		{args: []string{"src/"}, wantErr: true},
+		{args: []string{"src/", "--", "docs/"}, wantErr: true},
+	} {
+		cmd := newRewindCmd()
+		if err := cmd.ParseFlags(tc.args); err != nil {
+			t.Fatalf("ParseFlags(%q) error = %v", tc.args, err)
+		}
+		err := cmd.Args(cmd, cmd.Flags().Args())
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Args(%q) error = %v, wantErr %v", tc.args, err, tc.wantErr)
+		}
+	}
+}
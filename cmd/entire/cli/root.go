@@ -39,6 +39,14 @@ func NewRootCmd() *cobra.Command {
 		CompletionOptions: cobra.CompletionOptions{
 			HiddenDefaultCmd: true,
 		},
+		// Hook command groups define their own PersistentPreRunE, which replaces
+		// this one, and configure redaction and encryption there. Hooks never
+		// estimate cost.
+		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
+			configureRedaction(cmd.Context())
+			configureEncryption(cmd.Context())
+			configurePricing(cmd.Context())
+		},
 		PersistentPostRun: func(cmd *cobra.Command, _ []string) {
 			// Skip for hidden commands (walk parent chain — Cobra doesn't propagate Hidden)
 			for c := cmd; c != nil; c = c.Parent() {
@@ -82,6 +90,17 @@ func NewRootCmd() *cobra.Command {
 	cmd.AddCommand(newHooksCmd())
 	cmd.AddCommand(newVersionCmd())
 	cmd.AddCommand(newExplainCmd())
+	cmd.AddCommand(newSearchCmd())
+	cmd.AddCommand(newRedactCmd())
+	cmd.AddCommand(newExportCmd())
+	cmd.AddCommand(newImportCmd())
+	cmd.AddCommand(newBlameCmd())
+	cmd.AddCommand(newReportCmd())
+	cmd.AddCommand(newSyncCmd())
+	cmd.AddCommand(newGCCmd())
+	cmd.AddCommand(newPurgeCmd())
+	cmd.AddCommand(newDiffCmd())
+	cmd.AddCommand(newWatchCmd())
 	cmd.AddCommand(newDoctorCmd())
 	cmd.AddCommand(newSendAnalyticsCmd())
 	cmd.AddCommand(newCurlBashPostInstallCmd())
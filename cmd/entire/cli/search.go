@@ -0,0 +1,395 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/stringutil"
+	"github.com/entireio/cli/cmd/entire/cli/summarize"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/spf13/cobra"
+)
+
+// searchSnippetContext is the number of runes shown on each side of a match.
+const searchSnippetContext = 40
+
+// Search fields, reported in results so users know where a match came from.
+const (
+	searchFieldPrompt     = "prompt"
+	searchFieldContext    = "context"
+	searchFieldSummary    = "summary"
+	searchFieldTranscript = "transcript"
+	searchFieldFile       = "file"
+)
+
+// searchOptions holds the query and filters for a checkpoint search.
+type searchOptions struct {
+	Query  string
+	Agent  string
+	Branch string
+	Author string
+	Since  time.Time // Zero means no lower bound
+	Until  time.Time // Zero means no upper bound
+	Limit  int       // 0 means no limit
+}
+
+// searchMatch is a single match within a checkpoint session.
+type searchMatch struct {
+	Field   string `json:"field"`
+	Snippet string `json:"snippet"`
+}
+
+// searchResult is a checkpoint session that matched the query.
+type searchResult struct {
+	CheckpointID string        `json:"checkpoint_id"`
+	SessionID    string        `json:"session_id"`
+	Agent        string        `json:"agent,omitempty"`
+	Branch       string        `json:"branch,omitempty"`
+	Author       string        `json:"author,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+	Matches      []searchMatch `json:"matches"`
+}
+
+func newSearchCmd() *cobra.Command {
+	var agentFlag string
+	var branchFlag string
+	var authorFlag string
+	var sinceFlag string
+	var untilFlag string
+	var limitFlag int
+	var jsonFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search committed checkpoints",
+		Long: `Search the prompts, context, summaries, transcripts, and touched files of
+committed checkpoints on the entire/checkpoints/v1 branch.
+
+Matching is case-insensitive. Each result shows the checkpoint ID, session,
+and a snippet around every field that matched.
+
+Filters:
+  --agent    Only sessions from this agent (e.g. claude-code, "Gemini CLI")
+  --branch   Only checkpoints created on this branch
+  --author   Only checkpoints whose author name or email contains this value
+  --since    Only checkpoints created on or after this date (YYYY-MM-DD or RFC 3339)
+  --until    Only checkpoints created on or before this date (YYYY-MM-DD or RFC 3339)
+
+Use 'entire explain --checkpoint <id>' to view a result in detail.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkDisabledGuard(cmd.Context(), cmd.OutOrStdout()) {
+				return nil
+			}
+
+			opts := searchOptions{
+				Query:  strings.Join(args, " "),
+				Agent:  agentFlag,
+				Branch: branchFlag,
+				Author: authorFlag,
+				Limit:  limitFlag,
+			}
+			var err error
+			if opts.Since, err = parseSearchDate(sinceFlag, false); err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
+			if opts.Until, err = parseSearchDate(untilFlag, true); err != nil {
+				return fmt.Errorf("invalid --until: %w", err)
+			}
+			return runSearch(cmd.Context(), cmd.OutOrStdout(), opts, jsonFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&agentFlag, "agent", "", "Only show sessions from this agent")
+	cmd.Flags().StringVar(&branchFlag, "branch", "", "Only show checkpoints created on this branch")
+	cmd.Flags().StringVar(&authorFlag, "author", "", "Only show checkpoints whose author name or email contains this value")
+	cmd.Flags().StringVar(&sinceFlag, "since", "", "Only show checkpoints created on or after this date")
+	cmd.Flags().StringVar(&untilFlag, "until", "", "Only show checkpoints created on or before this date")
+	cmd.Flags().IntVarP(&limitFlag, "limit", "n", 50, "Maximum number of results (0 for no limit)")
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output results as JSON")
+
+	return cmd
+}
+
+// parseSearchDate parses a date flag value. Date-only values used as an upper
+// bound are extended to the end of that day so the bound is inclusive.
+func parseSearchDate(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected YYYY-MM-DD or RFC 3339, got %q", value)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+func runSearch(ctx context.Context, w io.Writer, opts searchOptions, jsonOutput bool) error {
+	if strings.TrimSpace(opts.Query) == "" {
+		return errors.New("search query cannot be empty")
+	}
+
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+
+	results, err := searchCheckpoints(ctx, repo, opts)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		data, err := jsonutil.MarshalIndentWithNewline(struct {
+			Query   string         `json:"query"`
+			Results []searchResult `json:"results"`
+		}{Query: opts.Query, Results: results}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal search results: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to write search results: %w", err)
+		}
+		return nil
+	}
+
+	fmt.Fprint(w, formatSearchResults(results))
+	return nil
+}
+
+// searchCheckpoints scans every committed checkpoint session and returns those
+// matching the query and filters, most recent first.
+func searchCheckpoints(ctx context.Context, repo *git.Repository, opts searchOptions) ([]searchResult, error) {
+	store := checkpoint.NewGitStore(repo)
+	committed, err := store.ListCommittedIndexed(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+
+	query := []rune(strings.ToLower(opts.Query))
+	results := []searchResult{}
+
+	for _, info := range committed {
+		if err := ctx.Err(); err != nil {
+			return nil, err //nolint:wrapcheck // Propagating context cancellation
+		}
+		if !opts.Since.IsZero() && info.CreatedAt.Before(opts.Since) {
+			continue
+		}
+		if !opts.Until.IsZero() && info.CreatedAt.After(opts.Until) {
+			continue
+		}
+
+		var author string
+		if opts.Author != "" {
+			a, authorErr := store.GetCheckpointAuthor(ctx, info.CheckpointID)
+			if authorErr != nil || !matchesAuthor(a, opts.Author) {
+				continue
+			}
+			author = a.Name
+		}
+
+		sessionCount := max(info.SessionCount, 1)
+		for i := range sessionCount {
+			content, readErr := store.ReadSessionContent(ctx, info.CheckpointID, i)
+			if readErr != nil {
+				continue
+			}
+			meta := content.Metadata
+			if !matchesAgent(meta.Agent, opts.Agent) {
+				continue
+			}
+			if opts.Branch != "" && meta.Branch != opts.Branch {
+				continue
+			}
+
+			filesTouched := meta.FilesTouched
+			if len(filesTouched) == 0 {
+				filesTouched = info.FilesTouched
+			}
+			matches := searchSessionContent(content, filesTouched, query)
+			if len(matches) == 0 {
+				continue
+			}
+
+			createdAt := meta.CreatedAt
+			if createdAt.IsZero() {
+				createdAt = info.CreatedAt
+			}
+			results = append(results, searchResult{
+				CheckpointID: info.CheckpointID.String(),
+				SessionID:    meta.SessionID,
+				Agent:        string(meta.Agent),
+				Branch:       meta.Branch,
+				Author:       author,
+				CreatedAt:    createdAt,
+				Matches:      matches,
+			})
+			if opts.Limit > 0 && len(results) >= opts.Limit {
+				return results, nil
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// searchSessionContent returns the first match in each searchable field of a session.
+func searchSessionContent(content *checkpoint.SessionContent, filesTouched []string, query []rune) []searchMatch {
+	var matches []searchMatch
+	add := func(field, text string) bool {
+		if snippet, ok := matchSnippet(text, query); ok {
+			matches = append(matches, searchMatch{Field: field, Snippet: snippet})
+			return true
+		}
+		return false
+	}
+
+	add(searchFieldPrompt, content.Prompts)
+	add(searchFieldContext, content.Context)
+	if content.Metadata.Summary != nil {
+		add(searchFieldSummary, summaryText(content.Metadata.Summary))
+	}
+	if len(content.Transcript) > 0 {
+		if entries, err := summarize.BuildCondensedTranscriptFromBytes(content.Transcript, content.Metadata.Agent); err == nil {
+			for _, entry := range entries {
+				if entry.Type == summarize.EntryTypeTool {
+					continue
+				}
+				if add(searchFieldTranscript, entry.Content) {
+					break
+				}
+			}
+		}
+	}
+	for _, f := range filesTouched {
+		if add(searchFieldFile, f) {
+			break
+		}
+	}
+
+	return matches
+}
+
+// summaryText flattens a summary into a single searchable string.
+func summaryText(s *checkpoint.Summary) string {
+	parts := []string{s.Intent, s.Outcome}
+	parts = append(parts, s.Learnings.Repo...)
+	for _, cl := range s.Learnings.Code {
+		parts = append(parts, cl.Path+": "+cl.Finding)
+	}
+	parts = append(parts, s.Learnings.Workflow...)
+	parts = append(parts, s.Friction...)
+	parts = append(parts, s.OpenItems...)
+	return strings.Join(parts, "\n")
+}
+
+// matchSnippet reports whether text contains query (case-insensitive, query
+// already lowercased) and returns a single-line snippet around the first match.
+func matchSnippet(text string, query []rune) (string, bool) {
+	if text == "" || len(query) == 0 {
+		return "", false
+	}
+	runes := []rune(stringutil.CollapseWhitespace(text))
+	idx := indexRunesFold(runes, query)
+	if idx < 0 {
+		return "", false
+	}
+
+	start := max(idx-searchSnippetContext, 0)
+	end := min(idx+len(query)+searchSnippetContext, len(runes))
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(runes) {
+		snippet += "..."
+	}
+	return snippet, true
+}
+
+// indexRunesFold returns the rune index of the first case-insensitive
+// occurrence of query in runes, or -1. Operating on runes keeps the index
+// valid for slicing the original text, unlike strings.ToLower byte offsets.
+func indexRunesFold(runes, query []rune) int {
+	for i := 0; i+len(query) <= len(runes); i++ {
+		found := true
+		for j, q := range query {
+			if unicode.ToLower(runes[i+j]) != q {
+				found = false
+				break
+			}
+		}
+		if found {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchesAgent reports whether an agent type matches the --agent filter,
+// accepting either the registry name (claude-code) or the display type (Claude Code).
+func matchesAgent(agentType agent.AgentType, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	if strings.EqualFold(string(agentType), filter) {
+		return true
+	}
+	if ag, err := agent.Get(agent.AgentName(strings.ToLower(filter))); err == nil {
+		return ag.Type() == agentType
+	}
+	return false
+}
+
+// matchesAuthor reports whether the author's name or email contains the filter (case-insensitive).
+func matchesAuthor(a checkpoint.Author, filter string) bool {
+	filter = strings.ToLower(filter)
+	return strings.Contains(strings.ToLower(a.Name), filter) ||
+		strings.Contains(strings.ToLower(a.Email), filter)
+}
+
+// formatSearchResults renders search results for terminal output.
+func formatSearchResults(results []searchResult) string {
+	if len(results) == 0 {
+		return "No matching checkpoints found.\n"
+	}
+
+	var sb strings.Builder
+	for i, r := range results {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "%s  %s", r.CheckpointID, r.CreatedAt.Local().Format("2006-01-02 15:04"))
+		if r.Agent != "" {
+			fmt.Fprintf(&sb, "  %s", r.Agent)
+		}
+		if r.Branch != "" {
+			fmt.Fprintf(&sb, "  [%s]", r.Branch)
+		}
+		sb.WriteString("\n")
+		if r.SessionID != "" {
+			fmt.Fprintf(&sb, "  session: %s\n", r.SessionID)
+		}
+		for _, m := range r.Matches {
+			fmt.Fprintf(&sb, "  %s: %s\n", m.Field, m.Snippet)
+		}
+	}
+	fmt.Fprintf(&sb, "\n%d result(s)\n", len(results))
+	return sb.String()
+}
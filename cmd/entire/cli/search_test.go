@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// setupSearchRepo creates a repo with two committed checkpoints for search tests.
+func setupSearchRepo(t *testing.T) *git.Repository {
+	t.Helper()
+	_, repo := setupCheckpointTestRepo(t, "hello")
+	writeTestCheckpoint(t, repo, checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("aaa111bbb222"),
+		SessionID:    "session-claude",
+		Branch:       "feature/login",
+		Agent:        agent.AgentTypeClaudeCode,
+		FilesTouched: []string{"auth/login.go"},
+		Prompts:      []string{"Add a login form with rate limiting"},
+		Transcript:   []byte(`{"type":"user","uuid":"u1","message":{"content":"Add a login form with rate limiting"}}` + "\n"),
+	})
+	writeTestCheckpoint(t, repo, checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("ccc333ddd444"),
+		SessionID:    "session-gemini",
+		Branch:       "main",
+		Agent:        agent.AgentTypeGemini,
+		FilesTouched: []string{"docs/guide.md"},
+		Prompts:      []string{"Rewrite the installation guide"},
+		AuthorName:   "Bob",
+		AuthorEmail:  "bob@example.com",
+	})
+	return repo
+}
+
+func TestNewSearchCmd(t *testing.T) {
+	cmd := newSearchCmd()
+
+	if cmd.Use != "search <query>" {
+		t.Errorf("expected Use to be 'search <query>', got %s", cmd.Use)
+	}
+	for _, name := range []string{"agent", "branch", "author", "since", "until", "limit", "json"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag to exist", name)
+		}
+	}
+}
+
+func TestSearchCheckpoints_MatchesPromptAndFiles(t *testing.T) {
+	repo := setupSearchRepo(t)
+
+	results, err := searchCheckpoints(context.Background(), repo, searchOptions{Query: "LOGIN"})
+	if err != nil {
+		t.Fatalf("searchCheckpoints() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d: %+v", len(results), results)
+	}
+	r := results[0]
+	if r.CheckpointID != "aaa111bbb222" {
+		t.Errorf("CheckpointID = %q, want aaa111bbb222", r.CheckpointID)
+	}
+
+	fields := make(map[string]bool)
+	for _, m := range r.Matches {
+		fields[m.Field] = true
+	}
+	for _, want := range []string{searchFieldPrompt, searchFieldTranscript, searchFieldFile} {
+		if !fields[want] {
+			t.Errorf("expected a %s match, got %+v", want, r.Matches)
+		}
+	}
+}
+
+func TestSearchCheckpoints_Filters(t *testing.T) {
+	repo := setupSearchRepo(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		opts searchOptions
+		want int
+	}{
+		{"agent by name", searchOptions{Query: "guide", Agent: "gemini"}, 1},
+		{"agent by type", searchOptions{Query: "guide", Agent: "Gemini CLI"}, 1},
+		{"agent mismatch", searchOptions{Query: "guide", Agent: "claude-code"}, 0},
+		{"branch", searchOptions{Query: "login", Branch: "feature/login"}, 1},
+		{"branch mismatch", searchOptions{Query: "login", Branch: "main"}, 0},
+		{"author", searchOptions{Query: "guide", Author: "BOB@"}, 1},
+		{"author mismatch", searchOptions{Query: "guide", Author: "alice"}, 0},
+		{"since in future", searchOptions{Query: "login", Since: time.Now().Add(time.Hour)}, 0},
+		{"until in past", searchOptions{Query: "login", Until: time.Now().Add(-24 * time.Hour)}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := searchCheckpoints(ctx, repo, tt.opts)
+			if err != nil {
+				t.Fatalf("searchCheckpoints() error = %v", err)
+			}
+			if len(results) != tt.want {
+				t.Errorf("got %d results, want %d: %+v", len(results), tt.want, results)
+			}
+		})
+	}
+}
+
+func TestRunSearch_JSON(t *testing.T) {
+	setupSearchRepo(t)
+
+	var buf bytes.Buffer
+	if err := runSearch(context.Background(), &buf, searchOptions{Query: "installation"}, true); err != nil {
+		t.Fatalf("runSearch() error = %v", err)
+	}
+
+	var out struct {
+		Query   string         `json:"query"`
+		Results []searchResult `json:"results"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, buf.String())
+	}
+	if out.Query != "installation" {
+		t.Errorf("query = %q, want installation", out.Query)
+	}
+	if len(out.Results) != 1 || out.Results[0].CheckpointID != "ccc333ddd444" {
+		t.Errorf("unexpected results: %+v", out.Results)
+	}
+}
+
+func TestRunSearch_NoResults(t *testing.T) {
+	setupSearchRepo(t)
+
+	var buf bytes.Buffer
+	if err := runSearch(context.Background(), &buf, searchOptions{Query: "nonexistent-term"}, false); err != nil {
+		t.Fatalf("runSearch() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "No matching checkpoints found") {
+		t.Errorf("expected no-results message, got: %s", buf.String())
+	}
+}
+
+func TestMatchSnippet(t *testing.T) {
+	long := strings.Repeat("a ", 50) + "Needle" + strings.Repeat(" b", 50)
+	snippet, ok := matchSnippet(long, []rune("needle"))
+	if !ok {
+		t.Fatal("expected match")
+	}
+	if !strings.HasPrefix(snippet, "...") || !strings.HasSuffix(snippet, "...") {
+		t.Errorf("expected ellipses on both sides, got %q", snippet)
+	}
+	if !strings.Contains(snippet, "Needle") {
+		t.Errorf("expected snippet to preserve original case, got %q", snippet)
+	}
+
+	if _, ok := matchSnippet("héllo wörld", []rune("wörld")); !ok {
+		t.Error("expected multi-byte match")
+	}
+	if _, ok := matchSnippet("nothing here", []rune("needle")); ok {
+		t.Error("expected no match")
+	}
+}
+
+func TestParseSearchDate(t *testing.T) {
+	start, err := parseSearchDate("2026-01-15", false)
+	if err != nil {
+		t.Fatalf("parseSearchDate() error = %v", err)
+	}
+	end, err := parseSearchDate("2026-01-15", true)
+	if err != nil {
+		t.Fatalf("parseSearchDate() error = %v", err)
+	}
+	if !end.After(start) || end.Sub(start) >= 24*time.Hour {
+		t.Errorf("expected end-of-day bound, got start=%v end=%v", start, end)
+	}
+
+	if _, err := parseSearchDate("yesterday", false); err == nil {
+		t.Error("expected error for invalid date")
+	}
+}
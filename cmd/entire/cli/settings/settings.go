@@ -7,12 +7,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/entireio/cli/cmd/entire/cli/encryption"
 	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/pricing"
+	"github.com/entireio/cli/redact"
 )
 
 const (
@@ -58,11 +65,39 @@ type EntireSettings struct {
 	// Defaults to "prompt" (preserves existing user behavior).
 	CommitLinking string `json:"commit_linking,omitempty"`
 
+	// Redaction customizes secret redaction (rules, allowlists, entropy).
+	// Ignored when .entire/redaction.toml exists.
+	Redaction *redact.Config `json:"redaction,omitempty"`
+
+	// Pricing adds or overrides per-model prices (USD per million tokens) used
+	// to estimate session cost. Keys are model names or name prefixes.
+	Pricing pricing.Table `json:"pricing,omitempty"`
+
+	// Encryption encrypts transcripts, prompts and context on the checkpoints
+	// branch for the listed age or SSH recipients.
+	Encryption *encryption.Config `json:"encryption,omitempty"`
+
+	// Retention limits how long transcripts are kept on the checkpoints
+	// branch. Applied by 'entire gc'.
+	Retention *RetentionSettings `json:"retention,omitempty"`
+
 	// Deprecated: no longer used. Exists to tolerate old settings files
 	// that still contain "strategy": "auto-commit" or similar.
 	Strategy string `json:"strategy,omitempty"`
 }
 
+// RetentionSettings is the "retention" settings section. Checkpoints outside
+// either limit have their transcripts removed by 'entire gc'; metadata,
+// summaries and attribution are kept. A zero limit is disabled.
+type RetentionSettings struct {
+	// MaxAgeDays keeps transcripts of checkpoints created in the last N days.
+	MaxAgeDays int `json:"max_age_days,omitempty"`
+
+	// MaxCheckpointsPerBranch keeps transcripts of the newest N checkpoints
+	// created on each branch.
+	MaxCheckpointsPerBranch int `json:"max_checkpoints_per_branch,omitempty"`
+}
+
 // GetCommitLinking returns the effective commit linking mode.
 // Returns the explicit value if set, otherwise defaults to "prompt"
 // to preserve existing user behavior.
@@ -232,9 +267,98 @@ func mergeJSON(settings *EntireSettings, data []byte) error {
 		}
 	}
 
+	// Override redaction if present (the local section replaces the project one)
+	if redactionRaw, ok := raw["redaction"]; ok {
+		var rc redact.Config
+		if err := json.Unmarshal(redactionRaw, &rc); err != nil {
+			return fmt.Errorf("parsing redaction field: %w", err)
+		}
+		settings.Redaction = &rc
+	}
+
+	// Merge encryption if present (local recipients and identity files each
+	// replace the project ones when set)
+	if encryptionRaw, ok := raw["encryption"]; ok {
+		var ec encryption.Config
+		if err := json.Unmarshal(encryptionRaw, &ec); err != nil {
+			return fmt.Errorf("parsing encryption field: %w", err)
+		}
+		if settings.Encryption == nil {
+			settings.Encryption = &ec
+		} else {
+			if len(ec.Recipients) > 0 {
+				settings.Encryption.Recipients = ec.Recipients
+			}
+			if len(ec.IdentityFiles) > 0 {
+				settings.Encryption.IdentityFiles = ec.IdentityFiles
+			}
+		}
+	}
+
+	// Merge retention if present (local limits each replace the project ones when set)
+	if retentionRaw, ok := raw["retention"]; ok {
+		var rs RetentionSettings
+		if err := json.Unmarshal(retentionRaw, &rs); err != nil {
+			return fmt.Errorf("parsing retention field: %w", err)
+		}
+		if settings.Retention == nil {
+			settings.Retention = &rs
+		} else {
+			if rs.MaxAgeDays != 0 {
+				settings.Retention.MaxAgeDays = rs.MaxAgeDays
+			}
+			if rs.MaxCheckpointsPerBranch != 0 {
+				settings.Retention.MaxCheckpointsPerBranch = rs.MaxCheckpointsPerBranch
+			}
+		}
+	}
+
+	// Merge pricing if present (local entries override project entries per model)
+	if pricingRaw, ok := raw["pricing"]; ok {
+		var table pricing.Table
+		if err := json.Unmarshal(pricingRaw, &table); err != nil {
+			return fmt.Errorf("parsing pricing field: %w", err)
+		}
+		if settings.Pricing == nil {
+			settings.Pricing = table
+		} else {
+			maps.Copy(settings.Pricing, table)
+		}
+	}
+
 	return nil
 }
 
+// LoadRedactionConfig returns the repository's redaction config from
+// .entire/redaction.toml, falling back to the "redaction" settings section.
+// Returns nil if neither is present. A relative gitleaks config path is
+// resolved against the repository root.
+func LoadRedactionConfig(ctx context.Context) (*redact.Config, error) {
+	tomlPath, err := paths.AbsPath(ctx, redact.ConfigFileName)
+	if err != nil {
+		tomlPath = redact.ConfigFileName // Fallback to relative
+	}
+
+	cfg, err := redact.LoadConfigFile(tomlPath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err //nolint:wrapcheck // already wrapped with the file path
+		}
+		s, loadErr := Load(ctx)
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		cfg = s.Redaction
+	}
+
+	if cfg != nil && cfg.Gitleaks.Config != "" && !filepath.IsAbs(cfg.Gitleaks.Config) {
+		if abs, absErr := paths.AbsPath(ctx, cfg.Gitleaks.Config); absErr == nil {
+			cfg.Gitleaks.Config = abs
+		}
+	}
+	return cfg, nil
+}
+
 // IsSetUp returns true if Entire has been set up in the current repository.
 // This checks if .entire/settings.json exists.
 // Use this to avoid creating files/directories in repos where Entire was never enabled.
@@ -287,6 +411,71 @@ func (s *EntireSettings) IsSummarizeEnabled() bool {
 	return enabled
 }
 
+// SummarizeOptions holds the strategy_options.summarize configuration.
+type SummarizeOptions struct {
+	// Enabled turns on auto-summarization at commit time.
+	Enabled bool
+	// Provider selects the summary generator (e.g., "claude", "openai", "gemini").
+	// Empty means the default provider.
+	Provider string
+	// Model overrides the provider's default model.
+	Model string
+	// Endpoint is the base URL for HTTP providers (e.g., "http://localhost:11434/v1").
+	Endpoint string
+	// APIKeyEnv names the environment variable holding the API key for HTTP providers.
+	// The key itself is never stored in settings, which may be committed.
+	// Endpoint and APIKeyEnv are only honored from local settings (see GetSummarizeOptions).
+	APIKeyEnv string
+}
+
+// GetSummarizeOptions returns the summarize options from settings.
+// Returns zero-value options if settings cannot be loaded.
+//
+// endpoint and api_key_env are only read from .entire/settings.local.json.
+// Summaries are generated automatically after commits, so a committed
+// settings.json could otherwise send any environment variable, along with the
+// transcript, to any URL.
+func GetSummarizeOptions(ctx context.Context) SummarizeOptions {
+	settings, err := Load(ctx)
+	if err != nil {
+		return SummarizeOptions{}
+	}
+	opts := settings.GetSummarizeOptions()
+	opts.Endpoint, opts.APIKeyEnv = "", ""
+
+	localSettingsFileAbs, err := paths.AbsPath(ctx, EntireSettingsLocalFile)
+	if err != nil {
+		localSettingsFileAbs = EntireSettingsLocalFile // Fallback to relative
+	}
+	local, err := loadFromFile(localSettingsFileAbs)
+	if err != nil {
+		return opts
+	}
+	localOpts := local.GetSummarizeOptions()
+	opts.Endpoint, opts.APIKeyEnv = localOpts.Endpoint, localOpts.APIKeyEnv
+	return opts
+}
+
+// GetSummarizeOptions returns the summarize options from this settings instance.
+// Missing or mistyped keys are left at their zero values.
+func (s *EntireSettings) GetSummarizeOptions() SummarizeOptions {
+	summarizeOpts, ok := s.StrategyOptions["summarize"].(map[string]any)
+	if !ok {
+		return SummarizeOptions{}
+	}
+	stringOpt := func(key string) string {
+		v, _ := summarizeOpts[key].(string) //nolint:errcheck // type assertion on interface{} from JSON
+		return v
+	}
+	return SummarizeOptions{
+		Enabled:   s.IsSummarizeEnabled(),
+		Provider:  stringOpt("provider"),
+		Model:     stringOpt("model"),
+		Endpoint:  stringOpt("endpoint"),
+		APIKeyEnv: stringOpt("api_key_env"),
+	}
+}
+
 // IsPushSessionsDisabled checks if push_sessions is disabled in settings.
 // Returns true if push_sessions is explicitly set to false.
 func (s *EntireSettings) IsPushSessionsDisabled() bool {
@@ -303,6 +492,59 @@ func (s *EntireSettings) IsPushSessionsDisabled() bool {
 	return false
 }
 
+// IsAutoSyncEnabled checks if auto_sync is enabled in settings.
+// When true, the post-merge and post-checkout hooks fetch and merge teammates'
+// checkpoints from the metadata remote. Defaults to false.
+func (s *EntireSettings) IsAutoSyncEnabled() bool {
+	if s.StrategyOptions == nil {
+		return false
+	}
+	val, ok := s.StrategyOptions["auto_sync"].(bool)
+	return ok && val
+}
+
+// IsGitNotesEnabled checks if git_notes is enabled in settings.
+// When true, each commit linked to a checkpoint also gets a note under
+// refs/notes/entire, which is pushed alongside the metadata branch.
+// Defaults to false.
+func (s *EntireSettings) IsGitNotesEnabled() bool {
+	if s.StrategyOptions == nil {
+		return false
+	}
+	val, ok := s.StrategyOptions["git_notes"].(bool)
+	return ok && val
+}
+
+// GetMetadataRemote returns strategy_options.metadata_remote: the remote name or
+// URL that checkpoint metadata is pushed to and fetched from. Returns "" when
+// unset, meaning metadata travels with the code remote.
+func (s *EntireSettings) GetMetadataRemote() string {
+	if s.StrategyOptions == nil {
+		return ""
+	}
+	val, _ := s.StrategyOptions["metadata_remote"].(string) //nolint:errcheck // type assertion on interface{} from JSON
+	return strings.TrimSpace(val)
+}
+
+// GetMetadataMirrors returns strategy_options.metadata_mirrors: additional remote
+// names or URLs that checkpoint metadata is pushed to. Mirrors are never read from.
+func (s *EntireSettings) GetMetadataMirrors() []string {
+	if s.StrategyOptions == nil {
+		return nil
+	}
+	vals, ok := s.StrategyOptions["metadata_mirrors"].([]any)
+	if !ok {
+		return nil
+	}
+	var mirrors []string
+	for _, v := range vals {
+		if str, ok := v.(string); ok && strings.TrimSpace(str) != "" {
+			mirrors = append(mirrors, strings.TrimSpace(str))
+		}
+	}
+	return mirrors
+}
+
 // Save saves the settings to .entire/settings.json.
 func Save(ctx context.Context, settings *EntireSettings) error {
 	return saveToFile(ctx, settings, EntireSettingsFile)
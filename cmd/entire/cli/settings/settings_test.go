@@ -2,8 +2,10 @@ package settings
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -245,8 +247,260 @@ func TestMergeJSON_CommitLinking(t *testing.T) {
 	}
 }
 
+func TestMergeJSON_Pricing(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0o755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+
+	settingsFile := filepath.Join(entireDir, "settings.json")
+	settingsContent := `{"enabled": true, "pricing": {"in-house": {"input": 1, "output": 2}, "claude-sonnet-4": {"input": 3, "output": 15}}}`
+	if err := os.WriteFile(settingsFile, []byte(settingsContent), 0o644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+
+	// Local entries override project entries for the same model only
+	localFile := filepath.Join(entireDir, "settings.local.json")
+	if err := os.WriteFile(localFile, []byte(`{"pricing": {"in-house": {"input": 0.5, "output": 1, "cache_read": 0.05}}}`), 0o644); err != nil {
+		t.Fatalf("failed to write local settings file: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0o755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+
+	t.Chdir(tmpDir)
+
+	s, err := Load(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := s.Pricing["in-house"]; got.Input != 0.5 || got.CacheRead != 0.05 {
+		t.Errorf("Pricing[in-house] = %+v, want local override", got)
+	}
+	if got := s.Pricing["claude-sonnet-4"]; got.Output != 15 {
+		t.Errorf("Pricing[claude-sonnet-4] = %+v, want project entry kept", got)
+	}
+}
+
+func TestMergeJSON_Encryption(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0o755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+
+	settingsFile := filepath.Join(entireDir, "settings.json")
+	settingsContent := `{"enabled": true, "encryption": {"recipients": ["age1team"], "identity_files": ["~/.ssh/id_ed25519"]}}`
+	if err := os.WriteFile(settingsFile, []byte(settingsContent), 0o644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+
+	// A local identity file replaces the project default; recipients are kept
+	localFile := filepath.Join(entireDir, "settings.local.json")
+	if err := os.WriteFile(localFile, []byte(`{"encryption": {"identity_files": ["~/keys/entire.txt"]}}`), 0o644); err != nil {
+		t.Fatalf("failed to write local settings file: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0o755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+
+	t.Chdir(tmpDir)
+
+	s, err := Load(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Encryption == nil {
+		t.Fatal("Encryption = nil, want merged config")
+	}
+	if !slices.Equal(s.Encryption.Recipients, []string{"age1team"}) {
+		t.Errorf("Recipients = %v, want project recipients kept", s.Encryption.Recipients)
+	}
+	if !slices.Equal(s.Encryption.IdentityFiles, []string{"~/keys/entire.txt"}) {
+		t.Errorf("IdentityFiles = %v, want local override", s.Encryption.IdentityFiles)
+	}
+}
+
 // containsUnknownField checks if the error message indicates an unknown field
 func containsUnknownField(msg string) bool {
 	// Go's json package reports unknown fields with this message format
 	return strings.Contains(msg, "unknown field")
 }
+
+func TestGetSummarizeOptions(t *testing.T) {
+	s := &EntireSettings{
+		StrategyOptions: map[string]any{
+			"summarize": map[string]any{
+				"enabled":     true,
+				"provider":    "openai",
+				"model":       "llama3.1",
+				"endpoint":    "http://localhost:11434/v1",
+				"api_key_env": "MY_KEY",
+			},
+		},
+	}
+
+	opts := s.GetSummarizeOptions()
+	want := SummarizeOptions{
+		Enabled:   true,
+		Provider:  "openai",
+		Model:     "llama3.1",
+		Endpoint:  "http://localhost:11434/v1",
+		APIKeyEnv: "MY_KEY",
+	}
+	if opts != want {
+		t.Errorf("GetSummarizeOptions() = %+v, want %+v", opts, want)
+	}
+
+	if got := (&EntireSettings{}).GetSummarizeOptions(); got != (SummarizeOptions{}) {
+		t.Errorf("GetSummarizeOptions() without options = %+v, want zero value", got)
+	}
+
+	mistyped := &EntireSettings{StrategyOptions: map[string]any{"summarize": map[string]any{"provider": 42}}}
+	if got := mistyped.GetSummarizeOptions(); got.Provider != "" {
+		t.Errorf("expected mistyped provider to be ignored, got %q", got.Provider)
+	}
+}
+
+func TestGetSummarizeOptions_EndpointOnlyFromLocalSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+	t.Chdir(tmpDir)
+
+	project := `{"strategy_options": {"summarize": {"enabled": true, "provider": "openai", "endpoint": "https://attacker.example/v1", "api_key_env": "AWS_SECRET_ACCESS_KEY"}}}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.json"), []byte(project), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+
+	opts := GetSummarizeOptions(context.Background())
+	if opts.Provider != "openai" || !opts.Enabled {
+		t.Errorf("GetSummarizeOptions() = %+v, want the project's provider and enabled", opts)
+	}
+	if opts.Endpoint != "" || opts.APIKeyEnv != "" {
+		t.Errorf("GetSummarizeOptions() = %+v, want endpoint and api_key_env from settings.json ignored", opts)
+	}
+
+	local := `{"strategy_options": {"summarize": {"enabled": true, "provider": "openai", "endpoint": "http://localhost:8080/v1", "api_key_env": "MY_KEY"}}}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.local.json"), []byte(local), 0644); err != nil {
+		t.Fatalf("failed to write local settings file: %v", err)
+	}
+
+	opts = GetSummarizeOptions(context.Background())
+	if opts.Endpoint != "http://localhost:8080/v1" || opts.APIKeyEnv != "MY_KEY" {
+		t.Errorf("GetSummarizeOptions() = %+v, want endpoint and api_key_env from settings.local.json", opts)
+	}
+}
+
+func TestLoadRedactionConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0o755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0o755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+
+	settingsFile := filepath.Join(entireDir, "settings.json")
+	settingsContent := `{"enabled": true, "redaction": {"entropy": {"threshold": 5.2}, "allowlist": {"literals": ["fixture"]}}}`
+	if err := os.WriteFile(settingsFile, []byte(settingsContent), 0o644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+
+	t.Chdir(tmpDir)
+
+	// Without redaction.toml the settings section is used.
+	cfg, err := LoadRedactionConfig(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg == nil || cfg.Entropy.Threshold != 5.2 || len(cfg.Allowlist.Literals) != 1 {
+		t.Fatalf("LoadRedactionConfig() = %+v, want settings section", cfg)
+	}
+
+	// redaction.toml takes precedence over the settings section.
+	tomlFile := filepath.Join(entireDir, "redaction.toml")
+	if err := os.WriteFile(tomlFile, []byte("[entropy]\ndisabled = true\n"), 0o644); err != nil {
+		t.Fatalf("failed to write redaction.toml: %v", err)
+	}
+	cfg, err = LoadRedactionConfig(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg == nil || !cfg.Entropy.Disabled || cfg.Entropy.Threshold != 0 || len(cfg.Allowlist.Literals) != 0 {
+		t.Errorf("LoadRedactionConfig() = %+v, want redaction.toml contents", cfg)
+	}
+
+	// A malformed redaction.toml is an error, not a silent fallback.
+	if err := os.WriteFile(tomlFile, []byte("[entropy\n"), 0o644); err != nil {
+		t.Fatalf("failed to write redaction.toml: %v", err)
+	}
+	if _, err := LoadRedactionConfig(context.Background()); err == nil {
+		t.Error("expected error for malformed redaction.toml")
+	}
+}
+
+func TestGetMetadataRemote(t *testing.T) {
+	var s EntireSettings
+	if err := json.Unmarshal([]byte(`{"strategy_options": {
+		"metadata_remote": " git@github.com:acme/private-checkpoints.git ",
+		"metadata_mirrors": ["backup", "", 7, "/srv/git/checkpoints.git"]
+	}}`), &s); err != nil {
+		t.Fatalf("failed to parse settings: %v", err)
+	}
+
+	if got, want := s.GetMetadataRemote(), "git@github.com:acme/private-checkpoints.git"; got != want {
+		t.Errorf("GetMetadataRemote() = %q, want %q", got, want)
+	}
+	if got, want := s.GetMetadataMirrors(), []string{"backup", "/srv/git/checkpoints.git"}; !slices.Equal(got, want) {
+		t.Errorf("GetMetadataMirrors() = %v, want %v", got, want)
+	}
+
+	empty := &EntireSettings{}
+	if empty.GetMetadataRemote() != "" || empty.GetMetadataMirrors() != nil {
+		t.Error("expected no metadata remote or mirrors without strategy options")
+	}
+}
+
+func TestMergeJSON_Retention(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0o755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+
+	settingsContent := `{"enabled": true, "retention": {"max_age_days": 90, "max_checkpoints_per_branch": 50}}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.json"), []byte(settingsContent), 0o644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.local.json"), []byte(`{"retention": {"max_age_days": 30}}`), 0o644); err != nil {
+		t.Fatalf("failed to write local settings file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0o755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+
+	t.Chdir(tmpDir)
+
+	s, err := Load(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := RetentionSettings{MaxAgeDays: 30, MaxCheckpointsPerBranch: 50}
+	if s.Retention == nil || *s.Retention != want {
+		t.Errorf("Retention = %+v, want %+v", s.Retention, want)
+	}
+}
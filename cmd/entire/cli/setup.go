@@ -129,7 +129,8 @@ func newDisableCmd() *cobra.Command {
 		Long: `Disable Entire integrations in the current project.
 
 By default, this command will disable Entire. Hooks will exit silently and commands will
-show a disabled message.
+show a disabled message. Entire's commands are removed from Husky, Lefthook and
+pre-commit configs; 'entire enable' registers them again.
 
 To completely remove Entire integrations from this repository, use --uninstall:
   - .entire/ directory (settings, logs, metadata)
@@ -215,6 +216,7 @@ func runEnableInteractive(ctx context.Context, w io.Writer, agents []agent.Agent
 		return fmt.Errorf("failed to save settings: %w", err)
 	}
 
+	strategy.RegisterWithHookManagers(ctx, w, localDev)
 	if _, err := strategy.InstallGitHook(ctx, true, localDev); err != nil {
 		return fmt.Errorf("failed to install git hooks: %w", err)
 	}
@@ -282,6 +284,10 @@ func runDisable(ctx context.Context, w io.Writer, useProjectSettings bool) error
 		}
 	}
 
+	if err := strategy.RemoveHookManagerIntegrations(ctx, w); err != nil {
+		fmt.Fprintf(w, "Warning: failed to remove Entire's git hooks from hook manager configs: %v\n", err)
+	}
+
 	fmt.Fprintln(w, "Entire is now disabled.")
 	return nil
 }
@@ -567,6 +573,9 @@ func setupAgentHooksNonInteractive(ctx context.Context, w io.Writer, ag agent.Ag
 	// Check if agent supports hooks
 	hookAgent, ok := ag.(agent.HookSupport)
 	if !ok {
+		if _, watchable := ag.(agent.FileWatcher); watchable {
+			return fmt.Errorf("agent %s does not support hooks; run 'entire watch' while using it to record its sessions", agentName)
+		}
 		return fmt.Errorf("agent %s does not support hooks", agentName)
 	}
 
@@ -613,6 +622,7 @@ func setupAgentHooksNonInteractive(ctx context.Context, w io.Writer, ag agent.Ag
 		return fmt.Errorf("failed to save settings: %w", err)
 	}
 
+	strategy.RegisterWithHookManagers(ctx, w, localDev)
 	if _, err := strategy.InstallGitHook(ctx, true, localDev); err != nil {
 		return fmt.Errorf("failed to install git hooks: %w", err)
 	}
@@ -710,6 +720,7 @@ func setupEntireDirectory(ctx context.Context) (bool, error) { //nolint:unparam
 func setupGitHook(ctx context.Context) error {
 	s, err := settings.Load(ctx)
 	localDev := err == nil && s.LocalDev
+	strategy.RegisterWithHookManagers(ctx, os.Stderr, localDev)
 	if _, err := strategy.InstallGitHook(ctx, false, localDev); err != nil {
 		return fmt.Errorf("failed to install git hook: %w", err)
 	}
@@ -919,11 +930,12 @@ func runUninstall(ctx context.Context, w, errW io.Writer, force bool) error {
 	sessionStateCount := countSessionStates(ctx)
 	shadowBranchCount := countShadowBranches(ctx)
 	gitHooksInstalled := strategy.IsGitHookInstalled(ctx)
+	hookManagerIntegrations := strategy.RegisteredHookManagerIntegrations(ctx)
 	agentsWithInstalledHooks := GetAgentsWithHooksInstalled(ctx)
 	entireDirExists := checkEntireDirExists(ctx)
 
 	// Check if there's anything to uninstall
-	if !entireDirExists && !gitHooksInstalled && sessionStateCount == 0 &&
+	if !entireDirExists && !gitHooksInstalled && len(hookManagerIntegrations) == 0 && sessionStateCount == 0 &&
 		shadowBranchCount == 0 && len(agentsWithInstalledHooks) == 0 {
 		fmt.Fprintln(w, "Entire is not installed in this repository.")
 		return nil
@@ -938,6 +950,9 @@ func runUninstall(ctx context.Context, w, errW io.Writer, force bool) error {
 		if gitHooksInstalled {
 			fmt.Fprintln(w, "  - Git hooks (prepare-commit-msg, commit-msg, post-commit, pre-push)")
 		}
+		for _, integration := range hookManagerIntegrations {
+			fmt.Fprintf(w, "  - Git hook commands in %s\n", integration.ConfigPath)
+		}
 		if sessionStateCount > 0 {
 			fmt.Fprintf(w, "  - Session state files (%d)\n", sessionStateCount)
 		}
@@ -990,6 +1005,9 @@ func runUninstall(ctx context.Context, w, errW io.Writer, force bool) error {
 	} else if removed > 0 {
 		fmt.Fprintf(w, "  Removed git hooks (%d)\n", removed)
 	}
+	if err := strategy.RemoveHookManagerIntegrations(ctx, w); err != nil {
+		fmt.Fprintf(errW, "Warning: failed to remove git hooks from hook manager configs: %v\n", err)
+	}
 
 	// 3. Remove session state files
 	statesRemoved, err := removeAllSessionStates(ctx)
@@ -4,17 +4,23 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	_ "github.com/entireio/cli/cmd/entire/cli/agent/claudecode"
 	_ "github.com/entireio/cli/cmd/entire/cli/agent/geminicli"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/session"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
 	"github.com/go-git/go-git/v5"
 )
 
@@ -42,6 +48,50 @@ func setupTestRepo(t *testing.T) {
 	}
 }
 
+// setupCheckpointTestRepo creates a temp directory with a git repo whose initial
+// commit adds README.md. Returns the directory and the opened repository.
+func setupCheckpointTestRepo(t *testing.T, readme string) (string, *git.Repository) {
+	t.Helper()
+	tmpDir := setupTestDir(t)
+	testutil.InitRepo(t, tmpDir)
+	testutil.WriteFile(t, tmpDir, "README.md", readme)
+	testutil.GitAdd(t, tmpDir, "README.md")
+	testutil.GitCommit(t, tmpDir, "initial commit")
+
+	repo, err := git.PlainOpen(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	return tmpDir, repo
+}
+
+// writeTestCheckpoint writes a committed checkpoint, by Alice with the
+// manual-commit strategy unless opts says otherwise.
+func writeTestCheckpoint(t *testing.T, repo *git.Repository, opts checkpoint.WriteCommittedOptions) {
+	t.Helper()
+	if opts.Strategy == "" {
+		opts.Strategy = "manual-commit"
+	}
+	if opts.AuthorName == "" {
+		opts.AuthorName = "Alice"
+		opts.AuthorEmail = "alice@example.com"
+	}
+	if err := checkpoint.NewGitStore(repo).WriteCommitted(context.Background(), opts); err != nil {
+		t.Fatalf("failed to write committed checkpoint: %v", err)
+	}
+}
+
+// commitWithCheckpoint writes files and commits them, linked to cpID through
+// the Entire-Checkpoint trailer.
+func commitWithCheckpoint(t *testing.T, dir, message, cpID string, files map[string]string) {
+	t.Helper()
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		testutil.WriteFile(t, dir, name, files[name])
+		testutil.GitAdd(t, dir, name)
+	}
+	testutil.GitCommit(t, dir, trailers.FormatCheckpoint(message, id.MustCheckpointID(cpID)))
+}
+
 // writeSettings writes settings content to the settings file.
 func writeSettings(t *testing.T, content string) {
 	t.Helper()
@@ -322,6 +322,9 @@ func writeActiveSessions(ctx context.Context, w io.Writer, sty statusStyles) {
 			}
 
 			stats = append(stats, "tokens "+formatTokenCount(totalTokens(st.TokenUsage)))
+			if cost, ok := formatCost(st.TokenUsage); ok {
+				stats = append(stats, "cost "+cost)
+			}
 
 			statsLine := strings.Join(stats, sty.render(sty.dim, " · "))
 			fmt.Fprintln(w, sty.render(sty.dim, statsLine))
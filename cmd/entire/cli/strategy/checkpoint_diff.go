@@ -0,0 +1,341 @@
+package strategy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// binarySniffLen is how much of a file is checked for NUL bytes, like git does.
+const binarySniffLen = 8000
+
+// CheckpointDiff is the file-level difference between two rewind points, or a
+// rewind point and the working tree. It implements go-git's diff.Patch, so it
+// can be printed with diff.NewUnifiedEncoder.
+type CheckpointDiff struct {
+	files []*fileDiff
+}
+
+// DiffCheckpoints compares the files of two commits: shadow branch checkpoints,
+// or the user commits of committed checkpoints. A zero to hash compares against
+// the working tree, including untracked files that aren't ignored. Entire's
+// own and agent metadata directories are excluded from both sides.
+func DiffCheckpoints(ctx context.Context, repo *git.Repository, from, to plumbing.Hash) (*CheckpointDiff, error) {
+	fromFiles, err := commitSnapshot(ctx, repo, from)
+	if err != nil {
+		return nil, err
+	}
+	var toFiles map[string]snapshotFile
+	if to == plumbing.ZeroHash {
+		toFiles, err = worktreeSnapshot(ctx)
+	} else {
+		toFiles, err = commitSnapshot(ctx, repo, to)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{}, len(fromFiles)+len(toFiles))
+	for name := range fromFiles {
+		names[name] = struct{}{}
+	}
+	for name := range toFiles {
+		names[name] = struct{}{}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	result := &CheckpointDiff{}
+	for _, name := range sorted {
+		if err := ctx.Err(); err != nil {
+			return nil, err //nolint:wrapcheck // Propagating context cancellation
+		}
+		fromFile, inFrom := fromFiles[name]
+		toFile, inTo := toFiles[name]
+		if inFrom && inTo && fromFile.hash == toFile.hash && fromFile.mode == toFile.mode {
+			continue
+		}
+		fd, err := newFileDiff(name, fromFile, inFrom, toFile, inTo)
+		if err != nil {
+			return nil, err
+		}
+		result.files = append(result.files, fd)
+	}
+	return result, nil
+}
+
+// FilePatches implements diff.Patch.
+func (d *CheckpointDiff) FilePatches() []fdiff.FilePatch {
+	patches := make([]fdiff.FilePatch, len(d.files))
+	for i, f := range d.files {
+		patches[i] = f
+	}
+	return patches
+}
+
+// Message implements diff.Patch.
+func (d *CheckpointDiff) Message() string {
+	return ""
+}
+
+// IsEmpty reports whether both sides have the same files.
+func (d *CheckpointDiff) IsEmpty() bool {
+	return len(d.files) == 0
+}
+
+// Paths returns the changed file paths, sorted.
+func (d *CheckpointDiff) Paths() []string {
+	result := make([]string, len(d.files))
+	for i, f := range d.files {
+		result[i] = f.path
+	}
+	return result
+}
+
+// Stats returns the lines added and removed per changed file, in the format
+// of `git diff --stat` when printed.
+func (d *CheckpointDiff) Stats() object.FileStats {
+	stats := make(object.FileStats, 0, len(d.files))
+	for _, f := range d.files {
+		stat := object.FileStat{Name: f.path}
+		for _, c := range f.chunks {
+			switch c.op {
+			case fdiff.Add:
+				stat.Addition += countLinesStr(c.content)
+			case fdiff.Delete:
+				stat.Deletion += countLinesStr(c.content)
+			case fdiff.Equal:
+			}
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+// snapshotFile is one file on a side of a diff. Content is read lazily so
+// only changed files are loaded.
+type snapshotFile struct {
+	hash plumbing.Hash
+	mode filemode.FileMode
+	read func() ([]byte, error)
+}
+
+// commitSnapshot lists the files in a commit's tree.
+func commitSnapshot(ctx context.Context, repo *git.Repository, hash plumbing.Hash) (map[string]snapshotFile, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	files := make(map[string]snapshotFile)
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if err := ctx.Err(); err != nil {
+			return err //nolint:wrapcheck // Propagating context cancellation
+		}
+		if isProtectedPath(f.Name) {
+			return nil
+		}
+		file := f
+		files[f.Name] = snapshotFile{
+			hash: f.Hash,
+			mode: f.Mode,
+			read: func() ([]byte, error) {
+				contents, err := file.Contents()
+				if err != nil {
+					return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+				}
+				return []byte(contents), nil
+			},
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	return files, nil
+}
+
+// worktreeSnapshot lists the tracked and non-ignored untracked files in the
+// working tree. Tracked files deleted from disk are left out.
+func worktreeSnapshot(ctx context.Context) (map[string]snapshotFile, error) {
+	repoRoot, err := paths.WorktreeRoot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree root: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "ls-files", "--cached", "--others", "--exclude-standard", "-z")
+	cmd.Dir = repoRoot
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("git ls-files failed: %s: %w", strings.TrimSpace(string(exitErr.Stderr)), err)
+		}
+		return nil, fmt.Errorf("git ls-files failed: %w", err)
+	}
+
+	files := make(map[string]snapshotFile)
+	for _, name := range strings.Split(string(output), "\x00") {
+		if name == "" || isProtectedPath(name) {
+			continue
+		}
+		if _, seen := files[name]; seen {
+			continue // Listed once per stage during a merge conflict
+		}
+		absPath := filepath.Join(repoRoot, name)
+		info, err := os.Lstat(absPath)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		var content []byte
+		mode := filemode.Regular
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(absPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read link %s: %w", name, err)
+			}
+			content = []byte(target)
+			mode = filemode.Symlink
+		default:
+			content, err = os.ReadFile(absPath) //nolint:gosec // Path comes from git ls-files
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", name, err)
+			}
+			if info.Mode()&0o111 != 0 {
+				mode = filemode.Executable
+			}
+		}
+
+		files[name] = snapshotFile{
+			hash: plumbing.ComputeHash(plumbing.BlobObject, content),
+			mode: mode,
+			read: func() ([]byte, error) { return content, nil },
+		}
+	}
+	return files, nil
+}
+
+// fileDiff is the diff of one file. It implements diff.FilePatch.
+type fileDiff struct {
+	path     string
+	from, to *diffFile
+	binary   bool
+	chunks   []diffChunk
+}
+
+func newFileDiff(path string, fromFile snapshotFile, inFrom bool, toFile snapshotFile, inTo bool) (*fileDiff, error) {
+	fd := &fileDiff{path: path}
+	var fromContent, toContent []byte
+	if inFrom {
+		content, err := fromFile.read()
+		if err != nil {
+			return nil, err
+		}
+		fromContent = content
+		fd.from = &diffFile{path: path, hash: fromFile.hash, mode: fromFile.mode}
+	}
+	if inTo {
+		content, err := toFile.read()
+		if err != nil {
+			return nil, err
+		}
+		toContent = content
+		fd.to = &diffFile{path: path, hash: toFile.hash, mode: toFile.mode}
+	}
+
+	fd.binary = isBinaryContent(fromContent) || isBinaryContent(toContent)
+	if fd.binary || bytes.Equal(fromContent, toContent) {
+		return fd, nil
+	}
+
+	dmp := diffmatchpatch.New()
+	text1, text2, lineArray := dmp.DiffLinesToChars(string(fromContent), string(toContent))
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(text1, text2, false), lineArray)
+	for _, d := range diffs {
+		chunk := diffChunk{content: d.Text}
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			chunk.op = fdiff.Equal
+		case diffmatchpatch.DiffInsert:
+			chunk.op = fdiff.Add
+		case diffmatchpatch.DiffDelete:
+			chunk.op = fdiff.Delete
+		}
+		fd.chunks = append(fd.chunks, chunk)
+	}
+	return fd, nil
+}
+
+// IsBinary implements diff.FilePatch.
+func (f *fileDiff) IsBinary() bool { return f.binary }
+
+// Files implements diff.FilePatch. A nil side must be a nil interface,
+// not a nil *diffFile, for the encoder to treat it as absent.
+func (f *fileDiff) Files() (fdiff.File, fdiff.File) {
+	var from, to fdiff.File
+	if f.from != nil {
+		from = f.from
+	}
+	if f.to != nil {
+		to = f.to
+	}
+	return from, to
+}
+
+// Chunks implements diff.FilePatch.
+func (f *fileDiff) Chunks() []fdiff.Chunk {
+	chunks := make([]fdiff.Chunk, len(f.chunks))
+	for i, c := range f.chunks {
+		chunks[i] = c
+	}
+	return chunks
+}
+
+// diffFile implements diff.File.
+type diffFile struct {
+	path string
+	hash plumbing.Hash
+	mode filemode.FileMode
+}
+
+func (f *diffFile) Hash() plumbing.Hash     { return f.hash }
+func (f *diffFile) Mode() filemode.FileMode { return f.mode }
+func (f *diffFile) Path() string            { return f.path }
+
+// diffChunk implements diff.Chunk.
+type diffChunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c diffChunk) Content() string       { return c.content }
+func (c diffChunk) Type() fdiff.Operation { return c.op }
+
+// isBinaryContent reports whether content looks binary: a NUL byte near the start.
+func isBinaryContent(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), binarySniffLen)], 0) >= 0
+}
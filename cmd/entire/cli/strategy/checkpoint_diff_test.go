@@ -0,0 +1,106 @@
+package strategy
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+)
+
+func TestDiffCheckpoints(t *testing.T) {
+	// Uses t.Chdir for the working tree side, so cannot be parallel.
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+	commitFile(t, repo, dir, "keep.txt", "same\n")
+	commitFile(t, repo, dir, "gone.txt", "bye\n")
+	first := commitFile(t, repo, dir, "main.go", "package main\n\nfunc main() {\n\tprintln(\"one\")\n}\n")
+
+	if err := os.Remove(filepath.Join(dir, "gone.txt")); err != nil {
+		t.Fatal(err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Remove("gone.txt"); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, repo, dir, "main.go", "package main\n\nfunc main() {\n\tprintln(\"two\")\n}\n")
+	second := commitFile(t, repo, dir, "new.txt", "hello\n")
+
+	result, err := DiffCheckpoints(ctx, repo, first, second)
+	if err != nil {
+		t.Fatalf("DiffCheckpoints() error = %v", err)
+	}
+	if got, want := result.Paths(), []string{"gone.txt", "main.go", "new.txt"}; !slices.Equal(got, want) {
+		t.Errorf("Paths() = %v, want %v", got, want)
+	}
+	for _, stat := range result.Stats() {
+		want := map[string][2]int{"gone.txt": {0, 1}, "main.go": {1, 1}, "new.txt": {1, 0}}[stat.Name]
+		if stat.Addition != want[0] || stat.Deletion != want[1] {
+			t.Errorf("Stats()[%s] = +%d -%d, want +%d -%d", stat.Name, stat.Addition, stat.Deletion, want[0], want[1])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := fdiff.NewUnifiedEncoder(&buf, fdiff.DefaultContextLines).Encode(result); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	for _, want := range []string{
+		"diff --git a/main.go b/main.go\n",
+		"-\tprintln(\"one\")\n+\tprintln(\"two\")\n",
+		"deleted file mode 100644\n",
+		"--- /dev/null\n+++ b/new.txt\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("diff missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	// Against the working tree: an edit, an untracked file and a binary file.
+	t.Chdir(dir)
+	paths.ClearWorktreeRootCache()
+	t.Cleanup(paths.ClearWorktreeRootCache)
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("hello\nworld\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "untracked.txt"), []byte("scratch\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "image.bin"), []byte{0x89, 'P', 'N', 'G', 0, 1}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err = DiffCheckpoints(ctx, repo, second, plumbing.ZeroHash)
+	if err != nil {
+		t.Fatalf("DiffCheckpoints(working tree) error = %v", err)
+	}
+	if got, want := result.Paths(), []string{"image.bin", "new.txt", "untracked.txt"}; !slices.Equal(got, want) {
+		t.Errorf("Paths(working tree) = %v, want %v", got, want)
+	}
+	buf.Reset()
+	if err := fdiff.NewUnifiedEncoder(&buf, fdiff.DefaultContextLines).Encode(result); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "Binary files /dev/null and b/image.bin differ") {
+		t.Errorf("diff should report the binary file:\n%s", buf.String())
+	}
+
+	result, err = DiffCheckpoints(ctx, repo, second, second)
+	if err != nil || !result.IsEmpty() {
+		t.Errorf("DiffCheckpoints(same) = %v, %v; want empty", result.Paths(), err)
+	}
+}
@@ -0,0 +1,451 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Note lines besides the Entire-Checkpoint and Entire-Agent trailer keys.
+const (
+	noteIntentKey      = "Entire-Intent"
+	noteAttributionKey = "Entire-Attribution"
+)
+
+// isGitNotesEnabled checks strategy_options.git_notes.
+func isGitNotesEnabled(ctx context.Context) bool {
+	s, err := settings.Load(ctx)
+	if err != nil {
+		return false
+	}
+	return s.IsGitNotesEnabled()
+}
+
+// CheckpointIDForCommit returns the checkpoint linked to commit: from its
+// Entire-Checkpoint trailer, or else from its note under refs/notes/entire.
+// Notes still link commits whose trailer was removed, e.g. by a tool that
+// rewrote the commit message.
+func CheckpointIDForCommit(repo *git.Repository, commit *object.Commit) (id.CheckpointID, bool) {
+	if cpID, found := trailers.ParseCheckpoint(commit.Message); found {
+		return cpID, true
+	}
+	return ReadCheckpointNote(repo, commit.Hash)
+}
+
+// ReadCheckpointNote returns the checkpoint ID in the note on commitHash
+// under refs/notes/entire, if there is one.
+func ReadCheckpointNote(repo *git.Repository, commitHash plumbing.Hash) (id.CheckpointID, bool) {
+	tree, err := notesTree(repo)
+	if err != nil || tree == nil {
+		return id.EmptyCheckpointID, false
+	}
+	// git stores notes flat, or fanned out by the first hash bytes once there are many
+	hash := commitHash.String()
+	for _, name := range []string{hash, hash[:2] + "/" + hash[2:], hash[:2] + "/" + hash[2:4] + "/" + hash[4:]} {
+		file, err := tree.File(name)
+		if err != nil {
+			continue
+		}
+		content, err := file.Contents()
+		if err != nil {
+			return id.EmptyCheckpointID, false
+		}
+		return trailers.ParseCheckpoint(content)
+	}
+	return id.EmptyCheckpointID, false
+}
+
+// notesTree returns the tree of refs/notes/entire, or nil when there are no notes.
+func notesTree(repo *git.Repository) (*object.Tree, error) {
+	ref, err := repo.Reference(plumbing.ReferenceName(paths.CheckpointNotesRef), true)
+	if err != nil {
+		return nil, nil //nolint:nilnil,nilerr // No notes ref means no notes
+	}
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notes commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notes tree: %w", err)
+	}
+	return tree, nil
+}
+
+// formatCheckpointNote renders the note for a commit linked to cpID, using
+// the latest session's metadata when available.
+func formatCheckpointNote(cpID id.CheckpointID, metadata *checkpoint.CommittedMetadata) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %s\n", trailers.CheckpointTrailerKey, cpID)
+	if metadata == nil {
+		return sb.String()
+	}
+	if metadata.Agent != "" {
+		fmt.Fprintf(&sb, "%s: %s\n", trailers.AgentTrailerKey, metadata.Agent)
+	}
+	if metadata.Summary != nil && metadata.Summary.Intent != "" {
+		// Notes are read line by line, so keep the intent on one line
+		fmt.Fprintf(&sb, "%s: %s\n", noteIntentKey, strings.Join(strings.Fields(metadata.Summary.Intent), " "))
+	}
+	if a := metadata.InitialAttribution; a != nil && a.TotalCommitted > 0 {
+		fmt.Fprintf(&sb, "%s: %.0f%% agent (%d of %d lines)\n", noteAttributionKey, a.AgentPercentage, a.AgentLines, a.TotalCommitted)
+	}
+	return sb.String()
+}
+
+// WriteCheckpointNote adds or replaces the note on commitHash under
+// refs/notes/entire with a summary of checkpoint cpID.
+func WriteCheckpointNote(ctx context.Context, repo *git.Repository, commitHash plumbing.Hash, cpID id.CheckpointID) error {
+	metadata, err := checkpoint.NewGitStore(repo).ReadLatestSessionMetadata(ctx, cpID)
+	if err != nil && !errors.Is(err, checkpoint.ErrCheckpointNotFound) {
+		return fmt.Errorf("failed to read checkpoint %s: %w", cpID, err)
+	}
+
+	blobHash, err := checkpoint.CreateBlobFromContent(repo, []byte(formatCheckpointNote(cpID, metadata)))
+	if err != nil {
+		return fmt.Errorf("failed to store note: %w", err)
+	}
+
+	refName := plumbing.ReferenceName(paths.CheckpointNotesRef)
+	var parents []plumbing.Hash
+	rootTreeHash := plumbing.ZeroHash
+	if ref, refErr := repo.Reference(refName, true); refErr == nil {
+		parent, err := repo.CommitObject(ref.Hash())
+		if err != nil {
+			return fmt.Errorf("failed to read notes commit: %w", err)
+		}
+		parents = append(parents, parent.Hash)
+		rootTreeHash = parent.TreeHash
+	}
+
+	name := commitHash.String()
+	treeHash, err := checkpoint.ApplyTreeChanges(repo, rootTreeHash, []checkpoint.TreeChange{{
+		Path:  name,
+		Entry: &object.TreeEntry{Name: name, Mode: filemode.Regular, Hash: blobHash},
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to update notes tree: %w", err)
+	}
+	if treeHash == rootTreeHash {
+		return nil // Same note already present
+	}
+
+	// Worded like git's own "Notes added by 'git notes add'"
+	notesCommit, err := createMergeCommitCommon(repo, treeHash, parents, "Notes added by 'entire'\n")
+	if err != nil {
+		return err
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(refName, notesCommit)); err != nil {
+		return fmt.Errorf("failed to update %s: %w", paths.CheckpointNotesRef, err)
+	}
+	return nil
+}
+
+// writeCheckpointNoteIfEnabled writes the note for a commit linked in
+// PostCommit. Failures are logged and never fail the hook.
+func writeCheckpointNoteIfEnabled(ctx context.Context, repo *git.Repository, commitHash plumbing.Hash, cpID id.CheckpointID) {
+	if !isGitNotesEnabled(ctx) {
+		return
+	}
+	if err := WriteCheckpointNote(ctx, repo, commitHash, cpID); err != nil {
+		logging.Warn(logging.WithComponent(ctx, "checkpoint"), "failed to write checkpoint note",
+			slog.String("commit", commitHash.String()),
+			slog.String("checkpoint_id", cpID.String()),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// notesTrackingRef records the notes commit last fetched from or pushed to
+// remote, so unchanged notes aren't pushed again.
+func notesTrackingRef(remote string) plumbing.ReferenceName {
+	return plumbing.ReferenceName("refs/notes/remotes/" + MetadataTrackingName(remote) + "/entire")
+}
+
+// pushCheckpointNotes pushes refs/notes/entire to the same targets as the
+// metadata branch: the metadata remote (remote unless
+// strategy_options.metadata_remote is set) and any metadata mirrors. Notes
+// repeat checkpoint intents, so they go wherever the checkpoints go.
+func pushCheckpointNotes(ctx context.Context, remote string) {
+	if !isGitNotesEnabled(ctx) {
+		return
+	}
+	for _, target := range metadataPushTargets(ctx, remote) {
+		pushCheckpointNotesTo(ctx, target)
+	}
+}
+
+// pushCheckpointNotesTo pushes refs/notes/entire to target. If target has
+// notes the local ref doesn't, they are fetched and merged first.
+func pushCheckpointNotesTo(ctx context.Context, target string) {
+	repo, err := OpenRepository(ctx)
+	if err != nil {
+		return
+	}
+	localRef, err := repo.Reference(plumbing.ReferenceName(paths.CheckpointNotesRef), true)
+	if err != nil {
+		return // No notes yet
+	}
+	if tracked, err := repo.Reference(notesTrackingRef(target), true); err == nil && tracked.Hash() == localRef.Hash() {
+		return
+	}
+
+	if err := tryPushNotes(ctx, target); err == nil {
+		return
+	}
+	if err := fetchAndMergeNotes(ctx, target); err != nil {
+		fmt.Fprintf(os.Stderr, "[entire] Warning: couldn't sync checkpoint notes with %s: %v\n", target, err)
+		var purgedErr *checkpoint.PurgedHistoryError
+		if errors.As(err, &purgedErr) {
+			if !purgedErr.Local {
+				fmt.Fprintf(os.Stderr, "[entire] Run 'entire sync --resync %s' to take the purged notes\n", target)
+			} else {
+				fmt.Fprintf(os.Stderr, "[entire] Replace the remote notes with: %s\n", forcePushNotesCommand(repo, target))
+			}
+		}
+		return
+	}
+	if err := tryPushNotes(ctx, target); err != nil {
+		fmt.Fprintf(os.Stderr, "[entire] Warning: failed to push checkpoint notes to %s: %v\n", target, err)
+	}
+}
+
+// ForcePushNotesCommands returns the git commands that replace
+// refs/notes/entire on the metadata remote and its mirrors with the local
+// notes, e.g. after 'entire purge' rewrote them. Each is leased on the notes
+// last fetched from or pushed to that remote. Returns nil without notes.
+func ForcePushNotesCommands(ctx context.Context, codeRemote string) []string {
+	repo, err := OpenRepository(ctx)
+	if err != nil {
+		return nil
+	}
+	if _, err := repo.Reference(plumbing.ReferenceName(paths.CheckpointNotesRef), true); err != nil {
+		return nil
+	}
+	var commands []string
+	for _, remote := range metadataPushTargets(ctx, codeRemote) {
+		commands = append(commands, forcePushNotesCommand(repo, remote))
+	}
+	return commands
+}
+
+func forcePushNotesCommand(repo *git.Repository, remote string) string {
+	expected := ""
+	if ref, err := repo.Reference(notesTrackingRef(remote), true); err == nil {
+		expected = ref.Hash().String()
+	}
+	return fmt.Sprintf("git push --force-with-lease=%s:%s %s %s", paths.CheckpointNotesRef, expected, remote, paths.CheckpointNotesRef)
+}
+
+func tryPushNotes(ctx context.Context, remote string) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	// Use --no-verify to prevent recursive hook calls
+	cmd := exec.CommandContext(ctx, "git", "push", "--no-verify", remote, paths.CheckpointNotesRef+":"+paths.CheckpointNotesRef)
+	cmd.Stdin = nil // Disconnect stdin to prevent hanging in hook context
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("push failed: %s", strings.TrimSpace(string(output)))
+	}
+
+	repo, err := OpenRepository(ctx)
+	if err != nil {
+		return nil //nolint:nilerr // Pushed; the tracking ref only saves a redundant push
+	}
+	if ref, err := repo.Reference(plumbing.ReferenceName(paths.CheckpointNotesRef), true); err == nil {
+		_ = repo.Storer.SetReference(plumbing.NewHashReference(notesTrackingRef(remote), ref.Hash())) //nolint:errcheck // Best-effort
+	}
+	return nil
+}
+
+// fetchAndMergeNotes fetches remote's refs/notes/entire into the tracking ref
+// and merges it into the local notes. Each note is keyed by the commit it
+// annotates; where both sides have a note for the same commit, the local one
+// wins, since it was written from the local checkpoint metadata. Returns a
+// *checkpoint.PurgedHistoryError instead of merging when one side still has
+// notes that 'entire purge' rewrote.
+func fetchAndMergeNotes(ctx context.Context, remote string) error {
+	fetchCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	trackingRef := notesTrackingRef(remote)
+	refSpec := fmt.Sprintf("+%s:%s", paths.CheckpointNotesRef, trackingRef)
+	cmd := exec.CommandContext(fetchCtx, "git", "fetch", "--no-tags", remote, refSpec)
+	cmd.Stdin = nil
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("fetch failed: %s", strings.TrimSpace(string(output)))
+	}
+
+	// Reopen so go-git picks up the packfiles the git CLI just fetched
+	repo, err := OpenRepository(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+	localRef, err := repo.Reference(plumbing.ReferenceName(paths.CheckpointNotesRef), true)
+	if err != nil {
+		return fmt.Errorf("failed to get local notes: %w", err)
+	}
+	remoteRef, err := repo.Reference(trackingRef, true)
+	if err != nil {
+		return fmt.Errorf("failed to get fetched notes: %w", err)
+	}
+
+	// A purge recorded on either metadata branch must not be undone by the merge
+	metadataHashes := make([]plumbing.Hash, 0, 2)
+	for _, refName := range []plumbing.ReferenceName{
+		plumbing.NewBranchReferenceName(paths.MetadataBranchName),
+		plumbing.NewRemoteReferenceName(MetadataTrackingName(remote), paths.MetadataBranchName),
+	} {
+		if ref, err := repo.Reference(refName, true); err == nil {
+			metadataHashes = append(metadataHashes, ref.Hash())
+		}
+	}
+	if err := checkpoint.CheckPurgedNotes(repo, metadataHashes, localRef.Hash(), remoteRef.Hash()); err != nil {
+		return err //nolint:wrapcheck // Callers check for *checkpoint.PurgedHistoryError
+	}
+
+	newHash, err := mergeNotesCommits(repo, localRef.Hash(), remoteRef.Hash(), remote)
+	if err != nil {
+		return err
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.ReferenceName(paths.CheckpointNotesRef), newHash)); err != nil {
+		return fmt.Errorf("failed to update %s: %w", paths.CheckpointNotesRef, err)
+	}
+	return nil
+}
+
+// mergeNotesCommits returns the notes commit that combines localHash and
+// remoteHash: one of them when it already contains the other, or else a merge
+// commit whose tree has the notes of both.
+func mergeNotesCommits(repo *git.Repository, localHash, remoteHash plumbing.Hash, remote string) (plumbing.Hash, error) {
+	localCommit, err := repo.CommitObject(localHash)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read notes commit: %w", err)
+	}
+	remoteCommit, err := repo.CommitObject(remoteHash)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read notes commit: %w", err)
+	}
+	if isAncestor, err := remoteCommit.IsAncestor(localCommit); err == nil && isAncestor {
+		return localHash, nil
+	}
+	if isAncestor, err := localCommit.IsAncestor(remoteCommit); err == nil && isAncestor {
+		return remoteHash, nil
+	}
+
+	merged := make(map[string]object.TreeEntry)
+	for _, commit := range []*object.Commit{remoteCommit, localCommit} {
+		tree, err := commit.Tree()
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to read notes tree: %w", err)
+		}
+		entries := make(map[string]object.TreeEntry)
+		if err := checkpoint.FlattenTree(repo, tree, "", entries); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to flatten notes tree: %w", err)
+		}
+		// Store every note flat, whatever fanout either side used
+		for p, e := range entries {
+			merged[strings.ReplaceAll(p, "/", "")] = e
+		}
+	}
+
+	treeHash, err := checkpoint.BuildTreeFromEntries(repo, merged)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to build notes tree: %w", err)
+	}
+	return createMergeCommitCommon(repo, treeHash, []plumbing.Hash{localHash, remoteHash}, "Merge checkpoint notes from "+remote+"\n")
+}
+
+// rebuildPurgedNotes replaces refs/notes/entire with notes written afresh
+// from the checkpoint metadata when the local notes still contain notes that
+// a purge recorded at metadataHead rewrote. The rebuilt notes have no
+// history, so the purged notes can't be pushed again. Returns whether the
+// notes were rebuilt.
+func rebuildPurgedNotes(ctx context.Context, repo *git.Repository, metadataHead plumbing.Hash) (bool, error) {
+	refName := plumbing.ReferenceName(paths.CheckpointNotesRef)
+	ref, err := repo.Reference(refName, true)
+	if err != nil {
+		return false, nil //nolint:nilerr // No notes, nothing to rebuild
+	}
+	// Compared with no notes at all, only stale local notes are reported
+	var purgedErr *checkpoint.PurgedHistoryError
+	if err := checkpoint.CheckPurgedNotes(repo, []plumbing.Hash{metadataHead}, ref.Hash(), plumbing.ZeroHash); !errors.As(err, &purgedErr) {
+		return false, err //nolint:wrapcheck // nil, or a purge log read error
+	}
+
+	tree, err := notesTree(repo)
+	if err != nil {
+		return false, err
+	}
+	notes := make(map[string]object.TreeEntry)
+	if err := checkpoint.FlattenTree(repo, tree, "", notes); err != nil {
+		return false, fmt.Errorf("failed to flatten notes tree: %w", err)
+	}
+	store := checkpoint.NewGitStore(repo)
+	entries := make(map[string]object.TreeEntry, len(notes))
+	for p, entry := range notes {
+		cpID, ok := readNoteCheckpoint(repo, entry.Hash)
+		if !ok {
+			continue
+		}
+		metadata, err := store.ReadLatestSessionMetadata(ctx, cpID)
+		if err != nil && !errors.Is(err, checkpoint.ErrCheckpointNotFound) {
+			return false, fmt.Errorf("failed to read checkpoint %s: %w", cpID, err)
+		}
+		blobHash, err := checkpoint.CreateBlobFromContent(repo, []byte(formatCheckpointNote(cpID, metadata)))
+		if err != nil {
+			return false, fmt.Errorf("failed to store note: %w", err)
+		}
+		name := strings.ReplaceAll(p, "/", "")
+		entries[name] = object.TreeEntry{Name: name, Mode: filemode.Regular, Hash: blobHash}
+	}
+
+	treeHash, err := checkpoint.BuildTreeFromEntries(repo, entries)
+	if err != nil {
+		return false, fmt.Errorf("failed to build notes tree: %w", err)
+	}
+	notesCommit, err := createMergeCommitCommon(repo, treeHash, nil, "Notes rebuilt by 'entire' after a purge\n")
+	if err != nil {
+		return false, err
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(refName, notesCommit)); err != nil {
+		return false, fmt.Errorf("failed to update %s: %w", paths.CheckpointNotesRef, err)
+	}
+	return true, nil
+}
+
+// readNoteCheckpoint returns the checkpoint ID in the note blob noteHash.
+func readNoteCheckpoint(repo *git.Repository, noteHash plumbing.Hash) (id.CheckpointID, bool) {
+	blob, err := repo.BlobObject(noteHash)
+	if err != nil {
+		return id.EmptyCheckpointID, false
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return id.EmptyCheckpointID, false
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return id.EmptyCheckpointID, false
+	}
+	return trailers.ParseCheckpoint(string(content))
+}
@@ -0,0 +1,278 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestWriteCheckpointNote(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+	first := commitFile(t, repo, dir, "a.txt", "first")
+	second := commitFile(t, repo, dir, "b.txt", "second")
+
+	cpID := id.MustCheckpointID("abcdef123456")
+	if err := checkpoint.NewGitStore(repo).WriteCommitted(ctx, checkpoint.WriteCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-1",
+		Strategy:     StrategyNameManualCommit,
+		Transcript:   []byte(`{"type":"user","message":{"content":"hi"}}` + "\n"),
+		AuthorName:   "Test",
+		AuthorEmail:  "test@test.com",
+		Agent:        agent.AgentTypeClaudeCode,
+		Summary:      &checkpoint.Summary{Intent: "Add the\nsecond file"},
+		InitialAttribution: &checkpoint.InitialAttribution{
+			AgentLines: 3, TotalCommitted: 4, AgentPercentage: 75,
+		},
+	}); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+
+	if err := WriteCheckpointNote(ctx, repo, second, cpID); err != nil {
+		t.Fatalf("WriteCheckpointNote() error = %v", err)
+	}
+	noteHead := notesHead(t, repo)
+	// Writing the same note again doesn't add a notes commit.
+	if err := WriteCheckpointNote(ctx, repo, second, cpID); err != nil {
+		t.Fatalf("WriteCheckpointNote() again error = %v", err)
+	}
+	if notesHead(t, repo) != noteHead {
+		t.Error("rewriting an identical note created a commit")
+	}
+
+	tree, err := notesTree(repo)
+	if err != nil || tree == nil {
+		t.Fatalf("notesTree() = %v, %v", tree, err)
+	}
+	file, err := tree.File(second.String())
+	if err != nil {
+		t.Fatalf("no note for %s: %v", second, err)
+	}
+	note, err := file.Contents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Entire-Checkpoint: abcdef123456\n",
+		"Entire-Agent: Claude Code\n",
+		"Entire-Intent: Add the second file\n",
+		"Entire-Attribution: 75% agent (3 of 4 lines)\n",
+	} {
+		if !strings.Contains(note, want) {
+			t.Errorf("note = %q, want it to contain %q", note, want)
+		}
+	}
+
+	secondCommit, err := repo.CommitObject(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, found := CheckpointIDForCommit(repo, secondCommit); !found || got != cpID {
+		t.Errorf("CheckpointIDForCommit(second) = %s, %v; want %s from the note", got, found, cpID)
+	}
+	firstCommit, err := repo.CommitObject(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, found := CheckpointIDForCommit(repo, firstCommit); found {
+		t.Errorf("CheckpointIDForCommit(first) = %s, want none", got)
+	}
+}
+
+func TestMergeNotesCommits(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+	first := commitFile(t, repo, dir, "a.txt", "first")
+	second := commitFile(t, repo, dir, "b.txt", "second")
+
+	if err := WriteCheckpointNote(ctx, repo, first, id.MustCheckpointID("111111111111")); err != nil {
+		t.Fatal(err)
+	}
+	base := notesHead(t, repo)
+	if err := WriteCheckpointNote(ctx, repo, second, id.MustCheckpointID("222222222222")); err != nil {
+		t.Fatal(err)
+	}
+	local := notesHead(t, repo)
+
+	// Fast-forward either way.
+	if got, err := mergeNotesCommits(repo, base, local, "origin"); err != nil || got != local {
+		t.Errorf("mergeNotesCommits(behind) = %s, %v; want %s", got, err, local)
+	}
+	if got, err := mergeNotesCommits(repo, local, base, "origin"); err != nil || got != local {
+		t.Errorf("mergeNotesCommits(ahead) = %s, %v; want %s", got, err, local)
+	}
+
+	// A diverged remote that also has a note on a third commit, stored with fanout.
+	remoteNote, err := checkpoint.CreateBlobFromContent(repo, []byte("Entire-Checkpoint: 333333333333\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	third := commitFile(t, repo, dir, "c.txt", "third")
+	remoteTree, err := checkpoint.BuildTreeFromEntries(repo, map[string]object.TreeEntry{
+		first.String(): {Hash: mustNoteBlob(t, repo, first), Mode: filemode.Regular},
+		third.String()[:2] + "/" + third.String()[2:]: {Hash: remoteNote, Mode: filemode.Regular},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, err := createMergeCommitCommon(repo, remoteTree, []plumbing.Hash{base}, "remote notes\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged, err := mergeNotesCommits(repo, local, remote, "origin")
+	if err != nil {
+		t.Fatalf("mergeNotesCommits() error = %v", err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.ReferenceName(paths.CheckpointNotesRef), merged)); err != nil {
+		t.Fatal(err)
+	}
+	for commit, want := range map[plumbing.Hash]string{first: "111111111111", second: "222222222222", third: "333333333333"} {
+		if got, found := ReadCheckpointNote(repo, commit); !found || got.String() != want {
+			t.Errorf("ReadCheckpointNote(%s) = %s, %v; want %s", commit, got, found, want)
+		}
+	}
+}
+
+func TestRebuildPurgedNotes(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+	commit := commitFile(t, repo, dir, "a.txt", "first")
+	const secret = "sk-live-0123456789abcdef"
+
+	cpID := id.MustCheckpointID("abcdef123456")
+	store := checkpoint.NewGitStore(repo)
+	if err := store.WriteCommitted(ctx, checkpoint.WriteCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-1",
+		Strategy:     StrategyNameManualCommit,
+		Transcript:   []byte(`{"type":"user","message":{"content":"hi"}}` + "\n"),
+		AuthorName:   "Test",
+		AuthorEmail:  "test@test.com",
+		Summary:      &checkpoint.Summary{Intent: "Use " + secret},
+	}); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+	if err := WriteCheckpointNote(ctx, repo, commit, cpID); err != nil {
+		t.Fatalf("WriteCheckpointNote() error = %v", err)
+	}
+	stale := notesHead(t, repo)
+
+	// Nothing to rebuild before a purge.
+	if rebuilt, err := rebuildPurgedNotes(ctx, repo, branchTip(t, repo)); err != nil || rebuilt {
+		t.Fatalf("rebuildPurgedNotes() before purge = %v, %v; want false, nil", rebuilt, err)
+	}
+
+	result, err := store.Purge(ctx, checkpoint.PurgeOptions{Pattern: regexp.MustCompile(`sk-live-[0-9a-f]+`)})
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	// A clone that took the purged branch still has its old notes.
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.ReferenceName(paths.CheckpointNotesRef), stale)); err != nil {
+		t.Fatal(err)
+	}
+
+	rebuilt, err := rebuildPurgedNotes(ctx, repo, result.NewHead)
+	if err != nil || !rebuilt {
+		t.Fatalf("rebuildPurgedNotes() = %v, %v; want true, nil", rebuilt, err)
+	}
+	head, err := repo.CommitObject(notesHead(t, repo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(head.ParentHashes) != 0 {
+		t.Error("rebuilt notes should not keep the stale history")
+	}
+	file, err := head.File(commit.String())
+	if err != nil {
+		t.Fatalf("no rebuilt note for %s: %v", commit, err)
+	}
+	note, err := file.Contents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(note, secret) || !strings.Contains(note, "Entire-Checkpoint: abcdef123456\n") {
+		t.Errorf("rebuilt note = %q", note)
+	}
+}
+
+func branchTip(t *testing.T, repo *git.Repository) plumbing.Hash {
+	t.Helper()
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	if err != nil {
+		t.Fatalf("metadata branch missing: %v", err)
+	}
+	return ref.Hash()
+}
+
+func commitFile(t *testing.T, repo *git.Repository, dir, name, content string) plumbing.Hash {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Add(name); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := w.Commit("add "+name, &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@test.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func notesHead(t *testing.T, repo *git.Repository) plumbing.Hash {
+	t.Helper()
+	ref, err := repo.Reference(plumbing.ReferenceName(paths.CheckpointNotesRef), true)
+	if err != nil {
+		t.Fatalf("notes ref missing: %v", err)
+	}
+	return ref.Hash()
+}
+
+func mustNoteBlob(t *testing.T, repo *git.Repository, commit plumbing.Hash) plumbing.Hash {
+	t.Helper()
+	tree, err := notesTree(repo)
+	if err != nil || tree == nil {
+		t.Fatalf("notesTree() = %v, %v", tree, err)
+	}
+	file, err := tree.File(commit.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file.Hash
+}
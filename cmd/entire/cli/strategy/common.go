@@ -16,6 +16,7 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/encryption"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/trailers"
 
@@ -471,7 +472,7 @@ func ExtractFirstPrompt(content string) string {
 }
 
 // ReadSessionPromptFromTree reads the first meaningful prompt from a checkpoint's prompt.txt file in a git tree.
-// Returns an empty string if the prompt cannot be read.
+// Returns an empty string if the prompt cannot be read or decrypted.
 func ReadSessionPromptFromTree(tree *object.Tree, checkpointPath string) string {
 	promptPath := checkpointPath + "/" + paths.PromptFileName
 	file, err := tree.File(promptPath)
@@ -484,7 +485,12 @@ func ReadSessionPromptFromTree(tree *object.Tree, checkpointPath string) string
 		return ""
 	}
 
-	return ExtractFirstPrompt(content)
+	opened, err := encryption.Open([]byte(content))
+	if err != nil {
+		return ""
+	}
+
+	return ExtractFirstPrompt(string(opened))
 }
 
 // ReadAgentTypeFromTree reads the agent type from a checkpoint's metadata.json file in a git tree.
@@ -560,9 +566,11 @@ func ReadAllSessionPromptsFromTree(tree *object.Tree, checkpointPath string, ses
 	return prompts
 }
 
-// GetRemoteMetadataBranchTree returns the tree object for origin/entire/checkpoints/v1.
-func GetRemoteMetadataBranchTree(repo *git.Repository) (*object.Tree, error) {
-	refName := plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName)
+// GetRemoteMetadataBranchTree returns the tree object for the remote-tracking
+// entire/checkpoints/v1 branch of the metadata remote (origin by default).
+func GetRemoteMetadataBranchTree(ctx context.Context, repo *git.Repository) (*object.Tree, error) {
+	remote := MetadataRemote(ctx, "origin")
+	refName := plumbing.NewRemoteReferenceName(MetadataTrackingName(remote), paths.MetadataBranchName)
 	ref, err := repo.Reference(refName, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get remote metadata branch reference: %w", err)
@@ -0,0 +1,870 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"gopkg.in/yaml.v3"
+)
+
+// hookIntegrationMarker marks the hook commands Entire registers in a hook
+// manager's config. It deliberately doesn't contain entireHookMarker, which
+// identifies hook scripts Entire owns outright.
+const hookIntegrationMarker = "Entire CLI hook commands"
+
+const (
+	huskyBlockBegin = "# " + hookIntegrationMarker + " (begin)"
+	huskyBlockEnd   = "# " + hookIntegrationMarker + " (end)"
+)
+
+// hookManagerCommandName is the name of Entire's command in Lefthook configs,
+// and the prefix of its hook IDs in pre-commit configs.
+const hookManagerCommandName = "entire"
+
+// hookManagerIntegration registers Entire's hook commands in an external hook
+// manager's config, so the hooks the manager installs run them. Once the
+// manager's hooks are installed, Entire's own git hooks step aside for the
+// hooks the integration covers (see InstallGitHook).
+type hookManagerIntegration interface {
+	// hooks returns the git hooks the integration covers.
+	hooks() []string
+
+	// install adds or updates Entire's commands. Returns true if the config changed.
+	install(cmdPrefix string) (bool, error)
+
+	// remove removes Entire's commands. Returns true if the config changed.
+	remove() (bool, error)
+
+	// registered reports whether the config has Entire's commands for every covered hook.
+	registered() bool
+
+	// ownsHook reports whether the manager installed the hook git runs for hook,
+	// either in place or backed up by InstallGitHook.
+	ownsHook(hooksDir, hook string) bool
+
+	// activateCommand is the command that installs the manager's hooks.
+	activateCommand() string
+}
+
+// newHookManagerIntegration returns the integration for a detected hook
+// manager, or nil if Entire can't register its commands there:
+//   - Lefthook JSON and TOML configs aren't edited, only YAML
+//   - Husky v8 and earlier run .husky/ scripts as the git hooks themselves
+func newHookManagerIntegration(repoRoot string, m hookManager) hookManagerIntegration {
+	switch m.Name {
+	case "Husky":
+		if fileExists(filepath.Join(repoRoot, ".husky", "_", "husky.sh")) {
+			return nil
+		}
+		return &huskyIntegration{repoRoot: repoRoot}
+	case "Lefthook":
+		if ext := filepath.Ext(m.ConfigPath); ext != ".yml" && ext != ".yaml" {
+			return nil
+		}
+		return &lefthookIntegration{configPath: filepath.Join(repoRoot, m.ConfigPath)}
+	case "pre-commit":
+		return &preCommitIntegration{configPath: filepath.Join(repoRoot, m.ConfigPath)}
+	case "Overcommit":
+		return &overcommitIntegration{configPath: filepath.Join(repoRoot, m.ConfigPath)}
+	default:
+		return nil
+	}
+}
+
+// HookManagerIntegration describes Entire's hook commands registered in a hook
+// manager's config.
+type HookManagerIntegration struct {
+	Name       string // e.g., "Lefthook"
+	ConfigPath string // relative path of the config, e.g., "lefthook.yml"
+
+	// Live is true when the manager's hooks are installed, so git runs
+	// Entire's commands through them.
+	Live bool
+
+	// ActivateCommand installs the manager's hooks, e.g., "lefthook install".
+	ActivateCommand string
+}
+
+// RegisteredHookManagerIntegrations returns the hook managers whose configs
+// have Entire's hook commands.
+func RegisteredHookManagerIntegrations(ctx context.Context) []HookManagerIntegration {
+	repoRoot, err := paths.WorktreeRoot(ctx)
+	if err != nil {
+		return nil
+	}
+	hooksDir, err := GetHooksDir(ctx)
+	if err != nil {
+		return nil
+	}
+
+	var result []HookManagerIntegration
+	for _, m := range detectHookManagers(repoRoot) {
+		integration := newHookManagerIntegration(repoRoot, m)
+		if integration == nil || !integration.registered() {
+			continue
+		}
+		result = append(result, HookManagerIntegration{
+			Name:            m.Name,
+			ConfigPath:      m.ConfigPath,
+			Live:            integrationLive(integration, hooksDir),
+			ActivateCommand: integration.activateCommand(),
+		})
+	}
+	return result
+}
+
+// RegisterWithHookManagers registers Entire's hook commands with each
+// supported hook manager in the repository, and writes what it did to w.
+// Failures are reported as warnings: Entire's own git hooks still work.
+// localDev controls whether commands use "go run" or the "entire" binary.
+func RegisterWithHookManagers(ctx context.Context, w io.Writer, localDev bool) {
+	repoRoot, err := paths.WorktreeRoot(ctx)
+	if err != nil {
+		return
+	}
+	hooksDir, err := GetHooksDir(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, m := range detectHookManagers(repoRoot) {
+		integration := newHookManagerIntegration(repoRoot, m)
+		if integration == nil {
+			continue
+		}
+		changed, err := integration.install(hookCmdPrefix(localDev))
+		if err != nil {
+			fmt.Fprintf(w, "Warning: failed to register Entire's git hooks in %s: %v\n", m.ConfigPath, err)
+			continue
+		}
+		if changed {
+			fmt.Fprintf(w, "✓ Registered Entire's git hooks in %s\n", m.ConfigPath)
+		}
+		if !integrationLive(integration, hooksDir) {
+			fmt.Fprintf(w, "  Run '%s' so %s runs them. Until then, Entire's own git hooks stay in place.\n", integration.activateCommand(), m.Name)
+		}
+	}
+}
+
+// RemoveHookManagerIntegrations removes Entire's hook commands from every hook
+// manager config in the repository, and writes what it did to w.
+// Outside a git repository there is nothing to remove.
+func RemoveHookManagerIntegrations(ctx context.Context, w io.Writer) error {
+	repoRoot, err := paths.WorktreeRoot(ctx)
+	if err != nil {
+		return nil //nolint:nilerr // Not a git repository: no configs to edit
+	}
+
+	var errs []error
+	for _, m := range detectHookManagers(repoRoot) {
+		integration := newHookManagerIntegration(repoRoot, m)
+		if integration == nil {
+			continue
+		}
+		changed, err := integration.remove()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", m.ConfigPath, err))
+			continue
+		}
+		if changed {
+			fmt.Fprintf(w, "  Removed Entire's git hooks from %s\n", m.ConfigPath)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// integrationLive reports whether git runs the integration's commands for
+// every hook it covers.
+func integrationLive(integration hookManagerIntegration, hooksDir string) bool {
+	if !integration.registered() {
+		return false
+	}
+	for _, hook := range integration.hooks() {
+		if !integration.ownsHook(hooksDir, hook) {
+			return false
+		}
+	}
+	return true
+}
+
+// hookManagerCoverage returns the git hooks that a hook manager runs Entire's
+// commands from, mapped to the manager's name. Entire doesn't need its own
+// hook for these.
+func hookManagerCoverage(repoRoot, hooksDir string) map[string]string {
+	covered := make(map[string]string)
+	for _, m := range detectHookManagers(repoRoot) {
+		integration := newHookManagerIntegration(repoRoot, m)
+		if integration == nil || !integration.registered() {
+			continue
+		}
+		for _, hook := range integration.hooks() {
+			if integration.ownsHook(hooksDir, hook) {
+				covered[hook] = m.Name
+			}
+		}
+	}
+	return covered
+}
+
+// hookFileContains reports whether the hook file, or the backup InstallGitHook
+// made of it, contains s.
+func hookFileContains(hooksDir, hook, s string) bool {
+	for _, name := range []string{hook, hook + backupSuffix} {
+		data, err := os.ReadFile(filepath.Join(hooksDir, name)) //nolint:gosec // Path is constructed from constants
+		if err == nil && strings.Contains(string(data), s) {
+			return true
+		}
+	}
+	return false
+}
+
+// hookManagerGuard returns a shell condition that is true when the CLI is
+// installed. Hook manager configs are usually committed and shared with people
+// who don't use Entire, so the commands are no-ops for them.
+func hookManagerGuard(cmdPrefix string) string {
+	executable, _, _ := strings.Cut(cmdPrefix, " ")
+	return fmt.Sprintf("command -v %s >/dev/null 2>&1", executable)
+}
+
+// hookManagerCommand returns the shell command a hook manager runs for hook.
+// args are the hook's arguments in the manager's syntax. Failures are ignored
+// as in the git hooks Entire installs, except in commit-msg, which can abort
+// the commit. post-merge and post-checkout check auto_sync first, as those
+// hooks do.
+func hookManagerCommand(cmdPrefix, hook, args string) string {
+	command := hookManagerGuard(cmdPrefix) + " || exit 0; "
+	if hook == "post-merge" || hook == "post-checkout" {
+		command += autoSyncCheck + " || exit 0; "
+	}
+	command += fmt.Sprintf("%s hooks git %s", cmdPrefix, hook)
+	if args != "" {
+		command += " " + args
+	}
+	switch hook {
+	case "commit-msg":
+		return command
+	case "prepare-commit-msg", "post-commit", "post-rewrite":
+		return command + " 2>/dev/null || true"
+	default:
+		return command + " || true"
+	}
+}
+
+// huskyIntegration appends Entire's hook commands to the scripts in .husky/,
+// which the hooks Husky v9 installs in .husky/_/ run with git's arguments.
+type huskyIntegration struct {
+	repoRoot string
+}
+
+func (h *huskyIntegration) hooks() []string { return gitHookNames }
+
+func (h *huskyIntegration) activateCommand() string { return "npx husky" }
+
+func (h *huskyIntegration) scriptPath(hook string) string {
+	return filepath.Join(h.repoRoot, ".husky", hook)
+}
+
+func (h *huskyIntegration) install(cmdPrefix string) (bool, error) {
+	changed := false
+	for _, spec := range buildHookSpecs(cmdPrefix) {
+		path := h.scriptPath(spec.name)
+		var perm os.FileMode = 0o644
+		existing, err := os.ReadFile(path) //nolint:gosec // Path is constructed from constants
+		switch {
+		case err == nil:
+			if info, statErr := os.Stat(path); statErr == nil {
+				perm = info.Mode().Perm()
+			}
+		case !errors.Is(err, os.ErrNotExist):
+			return changed, fmt.Errorf("failed to read %s: %w", spec.name, err)
+		}
+
+		// The same lines as the git hook, run by the user's script
+		var block strings.Builder
+		fmt.Fprintf(&block, "%s\nif %s; then\n", huskyBlockBegin, hookManagerGuard(cmdPrefix))
+		for _, line := range extractCommandLines(spec.content) {
+			fmt.Fprintln(&block, line)
+		}
+		fmt.Fprintf(&block, "fi\n%s\n", huskyBlockEnd)
+
+		content, _ := stripHuskyBlock(string(existing))
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += block.String()
+		if content == string(existing) {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(content), perm); err != nil { //nolint:gosec // Keeps the script's permissions
+			return changed, fmt.Errorf("failed to write %s: %w", spec.name, err)
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+func (h *huskyIntegration) remove() (bool, error) {
+	changed := false
+	for _, hook := range gitHookNames {
+		path := h.scriptPath(hook)
+		existing, err := os.ReadFile(path) //nolint:gosec // Path is constructed from constants
+		if err != nil {
+			continue
+		}
+		content, found := stripHuskyBlock(string(existing))
+		if !found {
+			continue
+		}
+		if strings.TrimSpace(content) == "" {
+			// Entire created the script
+			err = os.Remove(path)
+		} else {
+			err = os.WriteFile(path, []byte(content), 0o644) //nolint:gosec // WriteFile keeps an existing file's permissions
+		}
+		if err != nil {
+			return changed, fmt.Errorf("failed to update %s: %w", hook, err)
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+func (h *huskyIntegration) registered() bool {
+	for _, hook := range gitHookNames {
+		data, err := os.ReadFile(h.scriptPath(hook))
+		if err != nil || !strings.Contains(string(data), huskyBlockBegin) {
+			return false
+		}
+	}
+	return true
+}
+
+// ownsHook reports whether git's hooks directory is Husky's, which
+// `npx husky` sets with core.hooksPath.
+func (h *huskyIntegration) ownsHook(hooksDir, hook string) bool {
+	if !samePath(hooksDir, filepath.Join(h.repoRoot, ".husky", "_")) {
+		return false
+	}
+	return fileExists(filepath.Join(hooksDir, hook)) || fileExists(filepath.Join(hooksDir, hook+backupSuffix))
+}
+
+// stripHuskyBlock removes Entire's block from a Husky script. Returns the
+// remaining content and whether the block was found.
+func stripHuskyBlock(content string) (string, bool) {
+	start := strings.Index(content, huskyBlockBegin)
+	if start < 0 {
+		return content, false
+	}
+	end := strings.Index(content[start:], huskyBlockEnd)
+	if end < 0 {
+		return content, false
+	}
+	end += start + len(huskyBlockEnd)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return content[:start] + content[end:], true
+}
+
+// samePath reports whether two paths refer to the same directory, resolving
+// symlinks where possible (e.g., /var and /private/var on macOS).
+func samePath(a, b string) bool {
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// lefthookArgs are git's hook arguments in Lefthook's template syntax.
+var lefthookArgs = map[string]string{
+	"prepare-commit-msg": "{1} {2}",
+	"commit-msg":         "{1}",
+	"post-commit":        "",
+	"pre-push":           "{1}",
+	"post-merge":         "{1}",
+	"post-checkout":      "{1} {2} {3}",
+	"post-rewrite":       "{1}",
+}
+
+// lefthookIntegration adds an "entire" command to each hook in a Lefthook
+// YAML config.
+type lefthookIntegration struct {
+	configPath string
+}
+
+func (l *lefthookIntegration) hooks() []string { return gitHookNames }
+
+func (l *lefthookIntegration) activateCommand() string { return "lefthook install" }
+
+func (l *lefthookIntegration) install(cmdPrefix string) (bool, error) {
+	return editYAMLConfig(l.configPath, func(c *yamlConfig) error {
+		for _, hook := range gitHookNames {
+			command := &yaml.Node{Kind: yaml.MappingNode}
+			setMappingValue(command, "run", scalarNode(hookManagerCommand(cmdPrefix, hook, lefthookArgs[hook])))
+			if hook == "post-rewrite" {
+				// The rewritten commits are on stdin
+				setMappingValue(command, "use_stdin", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+			}
+			commands := &yaml.Node{Kind: yaml.MappingNode}
+			setMappingValue(commands, hookManagerCommandName, command)
+
+			hookNode := mappingValue(c.root(), hook)
+			if hookNode == nil {
+				hookNode = &yaml.Node{Kind: yaml.MappingNode}
+				setMappingValue(hookNode, "commands", commands)
+				if err := c.set(c.root(), hook, hookNode); err != nil {
+					return err
+				}
+				continue
+			}
+			if hookNode.Kind != yaml.MappingNode {
+				return fmt.Errorf("%s is not a mapping", hook)
+			}
+			existing := mappingValue(hookNode, "commands")
+			if existing == nil {
+				if err := c.set(hookNode, "commands", commands); err != nil {
+					return err
+				}
+				continue
+			}
+			if existing.Kind != yaml.MappingNode {
+				return fmt.Errorf("%s.commands is not a mapping", hook)
+			}
+			if err := c.set(existing, hookManagerCommandName, command); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (l *lefthookIntegration) remove() (bool, error) {
+	if !fileExists(l.configPath) {
+		return false, nil
+	}
+	return editYAMLConfig(l.configPath, func(c *yamlConfig) error {
+		for _, hook := range gitHookNames {
+			hookNode := mappingValue(c.root(), hook)
+			commands := mappingValue(hookNode, "commands")
+			if mappingValue(commands, hookManagerCommandName) == nil {
+				continue
+			}
+			// Remove what would be left empty
+			var err error
+			switch {
+			case len(commands.Content) > 2:
+				err = c.delete(commands, hookManagerCommandName)
+			case len(hookNode.Content) > 2:
+				err = c.delete(hookNode, "commands")
+			default:
+				err = c.delete(c.root(), hook)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (l *lefthookIntegration) registered() bool {
+	doc, err := loadYAMLConfig(l.configPath)
+	if err != nil {
+		return false
+	}
+	root := doc.Content[0]
+	for _, hook := range gitHookNames {
+		if mappingValue(mappingValue(mappingValue(root, hook), "commands"), hookManagerCommandName) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *lefthookIntegration) ownsHook(hooksDir, hook string) bool {
+	return hookFileContains(hooksDir, hook, "lefthook")
+}
+
+// preCommitArgs are git's hook arguments as pre-commit passes them: the commit
+// message file as the hook's filename, the rest in environment variables.
+// post-rewrite isn't covered: pre-commit doesn't pass the rewritten commits on
+// stdin, so Entire keeps its own post-rewrite hook.
+var preCommitArgs = map[string]string{
+	"prepare-commit-msg": `"$1" "$PRE_COMMIT_COMMIT_MSG_SOURCE"`,
+	"commit-msg":         `"$1"`,
+	"post-commit":        "",
+	"pre-push":           `"$PRE_COMMIT_REMOTE_NAME"`,
+	"post-merge":         `"$PRE_COMMIT_IS_SQUASH_MERGE"`,
+	"post-checkout":      `"$PRE_COMMIT_FROM_REF" "$PRE_COMMIT_TO_REF" "$PRE_COMMIT_CHECKOUT_TYPE"`,
+}
+
+// preCommitIntegration adds a local repo of "entire-<hook>" hooks to a
+// pre-commit config, and adds their stages to default_install_hook_types so
+// `pre-commit install` installs them.
+type preCommitIntegration struct {
+	configPath string
+}
+
+func (p *preCommitIntegration) hooks() []string {
+	return slices.DeleteFunc(slices.Clone(gitHookNames), func(hook string) bool {
+		_, ok := preCommitArgs[hook]
+		return !ok
+	})
+}
+
+// activateCommand overwrites the hooks pre-commit would otherwise keep as
+// .legacy and run alongside its own, which may be Entire's.
+func (p *preCommitIntegration) activateCommand() string { return "pre-commit install --overwrite" }
+
+func (p *preCommitIntegration) install(cmdPrefix string) (bool, error) {
+	return editYAMLConfig(p.configPath, func(c *yamlConfig) error {
+		if repos := mappingValue(c.root(), "repos"); repos != nil && repos.Kind != yaml.SequenceNode {
+			return errors.New("repos is not a list")
+		}
+		if types := mappingValue(c.root(), "default_install_hook_types"); types != nil && types.Kind != yaml.SequenceNode {
+			return errors.New("default_install_hook_types is not a list")
+		}
+
+		hooks := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, hook := range p.hooks() {
+			command := strings.ReplaceAll(hookManagerCommand(cmdPrefix, hook, preCommitArgs[hook]), "'", `'\''`)
+			entry := fmt.Sprintf("sh -c '%s' --", command)
+			hookNode := &yaml.Node{Kind: yaml.MappingNode}
+			setMappingValue(hookNode, "id", scalarNode(hookManagerCommandName+"-"+hook))
+			setMappingValue(hookNode, "name", scalarNode("Entire ("+hook+")"))
+			setMappingValue(hookNode, "entry", scalarNode(entry))
+			setMappingValue(hookNode, "language", scalarNode("system"))
+			setMappingValue(hookNode, "stages", &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle, Content: []*yaml.Node{scalarNode(hook)}})
+			setMappingValue(hookNode, "always_run", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+			if hook != "prepare-commit-msg" && hook != "commit-msg" {
+				setMappingValue(hookNode, "pass_filenames", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "false"})
+			}
+			hooks.Content = append(hooks.Content, hookNode)
+		}
+		localRepo := &yaml.Node{Kind: yaml.MappingNode}
+		setMappingValue(localRepo, "repo", scalarNode("local"))
+		setMappingValue(localRepo, "hooks", hooks)
+
+		// Replace Entire's hooks in place, or add a local repo for them
+		index, err := removePreCommitHooks(c)
+		if err != nil {
+			return err
+		}
+		if repos := mappingValue(c.root(), "repos"); repos == nil {
+			err = c.set(c.root(), "repos", &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{localRepo}})
+		} else {
+			if index < 0 {
+				index = len(repos.Content)
+			}
+			err = c.insertItem(repos, index, localRepo)
+		}
+		if err != nil {
+			return err
+		}
+
+		// pre-commit installs only the pre-commit hook unless told otherwise
+		if mappingValue(c.root(), "default_install_hook_types") == nil {
+			types := &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{markedScalarNode("pre-commit")}}
+			if err := c.set(c.root(), "default_install_hook_types", types); err != nil {
+				return err
+			}
+		}
+		for _, hook := range p.hooks() {
+			types := mappingValue(c.root(), "default_install_hook_types")
+			if slices.ContainsFunc(types.Content, func(n *yaml.Node) bool { return n.Value == hook }) {
+				continue
+			}
+			// A flow-style list is rewritten in block style, so each added type carries the marker
+			if err := c.insertItem(types, len(types.Content), markedScalarNode(hook)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (p *preCommitIntegration) remove() (bool, error) {
+	if !fileExists(p.configPath) {
+		return false, nil
+	}
+	return editYAMLConfig(p.configPath, func(c *yamlConfig) error {
+		if repos := mappingValue(c.root(), "repos"); repos != nil && repos.Kind == yaml.SequenceNode {
+			if _, err := removePreCommitHooks(c); err != nil {
+				return err
+			}
+		}
+		for {
+			types := mappingValue(c.root(), "default_install_hook_types")
+			if types == nil || types.Kind != yaml.SequenceNode {
+				return nil
+			}
+			index := slices.IndexFunc(types.Content, isMarkedNode)
+			switch {
+			case index < 0:
+				return nil
+			case len(types.Content) == 1:
+				return c.delete(c.root(), "default_install_hook_types")
+			}
+			if err := c.deleteItem(types, index); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+func (p *preCommitIntegration) registered() bool {
+	doc, err := loadYAMLConfig(p.configPath)
+	if err != nil {
+		return false
+	}
+	root := doc.Content[0]
+
+	ids := make(map[string]bool)
+	if repos := mappingValue(root, "repos"); repos != nil {
+		for _, repo := range repos.Content {
+			if hooks := mappingValue(repo, "hooks"); hooks != nil {
+				for _, hook := range hooks.Content {
+					if id := mappingValue(hook, "id"); id != nil {
+						ids[id.Value] = true
+					}
+				}
+			}
+		}
+	}
+	types := mappingValue(root, "default_install_hook_types")
+	for _, hook := range p.hooks() {
+		if !ids[hookManagerCommandName+"-"+hook] {
+			return false
+		}
+		if types == nil || !slices.ContainsFunc(types.Content, func(n *yaml.Node) bool { return n.Value == hook }) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *preCommitIntegration) ownsHook(hooksDir, hook string) bool {
+	return hookFileContains(hooksDir, hook, "File generated by pre-commit")
+}
+
+// removePreCommitHooks removes Entire's hooks from the local repos in a
+// pre-commit repos list, and the local repos that had only Entire's hooks.
+// Returns the index of the first repo that had them, or -1.
+func removePreCommitHooks(c *yamlConfig) (int, error) {
+	index := -1
+	for {
+		repos := mappingValue(c.root(), "repos")
+		if repos == nil || repos.Kind != yaml.SequenceNode {
+			return index, nil
+		}
+		repoIndex, hookIndex := -1, -1
+		for i, repo := range repos.Content {
+			if hooks := mappingValue(repo, "hooks"); isPreCommitLocalRepo(repo) && hooks != nil && hooks.Kind == yaml.SequenceNode {
+				if hookIndex = slices.IndexFunc(hooks.Content, isPreCommitEntireHook); hookIndex >= 0 {
+					repoIndex = i
+					break
+				}
+			}
+		}
+		if repoIndex < 0 {
+			return index, nil
+		}
+		if index < 0 {
+			index = repoIndex
+		}
+
+		var err error
+		repo := repos.Content[repoIndex]
+		hooks := mappingValue(repo, "hooks")
+		if !slices.ContainsFunc(hooks.Content, func(hook *yaml.Node) bool { return !isPreCommitEntireHook(hook) }) {
+			err = c.deleteItem(repos, repoIndex)
+		} else {
+			err = c.deleteItem(hooks, hookIndex)
+		}
+		if err != nil {
+			return index, err
+		}
+	}
+}
+
+func isPreCommitEntireHook(hook *yaml.Node) bool {
+	id := mappingValue(hook, "id")
+	return id != nil && strings.HasPrefix(id.Value, hookManagerCommandName+"-")
+}
+
+func isPreCommitLocalRepo(repo *yaml.Node) bool {
+	name := mappingValue(repo, "repo")
+	return name != nil && name.Value == "local"
+}
+
+// overcommitHookTypes are Overcommit's names for the git hooks.
+var overcommitHookTypes = map[string]string{
+	"prepare-commit-msg": "PrepareCommitMsg",
+	"commit-msg":         "CommitMsg",
+	"post-commit":        "PostCommit",
+	"pre-push":           "PrePush",
+	"post-merge":         "PostMerge",
+	"post-checkout":      "PostCheckout",
+	"post-rewrite":       "PostRewrite",
+}
+
+// overcommitArgs are git's hook arguments, which Overcommit passes to the
+// commands of ad hoc hooks together with the hook's stdin.
+var overcommitArgs = map[string]string{
+	"prepare-commit-msg": `"$1" "$2"`,
+	"commit-msg":         `"$1"`,
+	"post-commit":        "",
+	"pre-push":           `"$1"`,
+	"post-merge":         `"$1"`,
+	"post-checkout":      `"$1" "$2" "$3"`,
+	"post-rewrite":       `"$1"`,
+}
+
+// overcommitHookName is the name of Entire's ad hoc hook under each hook type
+// of an Overcommit config. Overcommit hook names are Ruby class names.
+const overcommitHookName = "Entire"
+
+// overcommitIntegration adds an "Entire" ad hoc hook to each hook type of an
+// Overcommit config. Its command runs through sh, so git's arguments are
+// available as "$1" and so on.
+type overcommitIntegration struct {
+	configPath string
+}
+
+func (o *overcommitIntegration) hooks() []string { return gitHookNames }
+
+// activateCommand also signs the changed config: Overcommit refuses to run
+// hooks from a config it hasn't signed.
+func (o *overcommitIntegration) activateCommand() string {
+	return "overcommit --install && overcommit --sign"
+}
+
+func (o *overcommitIntegration) install(cmdPrefix string) (bool, error) {
+	return editYAMLConfig(o.configPath, func(c *yamlConfig) error {
+		for _, hook := range gitHookNames {
+			hookType := overcommitHookTypes[hook]
+			command := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle, Content: []*yaml.Node{
+				scalarNode("sh"), scalarNode("-c"),
+				scalarNode(hookManagerCommand(cmdPrefix, hook, overcommitArgs[hook])),
+				scalarNode("--"),
+			}}
+			entry := &yaml.Node{Kind: yaml.MappingNode}
+			setMappingValue(entry, "enabled", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+			setMappingValue(entry, "description", scalarNode("Entire ("+hook+")"))
+			setMappingValue(entry, "command", command)
+			setMappingValue(entry, "quiet", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+
+			typeNode := mappingValue(c.root(), hookType)
+			if typeNode == nil {
+				typeNode = &yaml.Node{Kind: yaml.MappingNode}
+				setMappingValue(typeNode, overcommitHookName, entry)
+				if err := c.set(c.root(), hookType, typeNode); err != nil {
+					return err
+				}
+				continue
+			}
+			if typeNode.Kind != yaml.MappingNode {
+				return fmt.Errorf("%s is not a mapping", hookType)
+			}
+			if err := c.set(typeNode, overcommitHookName, entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (o *overcommitIntegration) remove() (bool, error) {
+	if !fileExists(o.configPath) {
+		return false, nil
+	}
+	return editYAMLConfig(o.configPath, func(c *yamlConfig) error {
+		for _, hook := range gitHookNames {
+			hookType := overcommitHookTypes[hook]
+			typeNode := mappingValue(c.root(), hookType)
+			if mappingValue(typeNode, overcommitHookName) == nil {
+				continue
+			}
+			var err error
+			if len(typeNode.Content) > 2 {
+				err = c.delete(typeNode, overcommitHookName)
+			} else {
+				err = c.delete(c.root(), hookType)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (o *overcommitIntegration) registered() bool {
+	doc, err := loadYAMLConfig(o.configPath)
+	if err != nil {
+		return false
+	}
+	for _, hook := range gitHookNames {
+		if mappingValue(mappingValue(doc.Content[0], overcommitHookTypes[hook]), overcommitHookName) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// ownsHook reports whether the hook is the Ruby script `overcommit --install`
+// copies to every hook, which loads the overcommit gem.
+func (o *overcommitIntegration) ownsHook(hooksDir, hook string) bool {
+	return hookFileContains(hooksDir, hook, "overcommit")
+}
+
+// mappingValue returns the value for key in a mapping node, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue sets key in a mapping node, keeping its position if present.
+func setMappingValue(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, scalarNode(key), value)
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// markedScalarNode returns a scalar carrying hookIntegrationMarker as a line
+// comment, so remove can tell it from the user's own entries.
+func markedScalarNode(value string) *yaml.Node {
+	n := scalarNode(value)
+	n.LineComment = "# " + hookIntegrationMarker
+	return n
+}
+
+func isMarkedNode(n *yaml.Node) bool {
+	return n.LineComment == "# "+hookIntegrationMarker
+}
@@ -0,0 +1,392 @@
+package strategy
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHuskyIntegration_InstallRemove(t *testing.T) {
+	t.Parallel()
+
+	repoRoot := t.TempDir()
+	huskyDir := filepath.Join(repoRoot, ".husky")
+	if err := os.MkdirAll(huskyDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	commitMsg := "npx --no -- commitlint --edit $1"
+	if err := os.WriteFile(filepath.Join(huskyDir, "commit-msg"), []byte(commitMsg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := &huskyIntegration{repoRoot: repoRoot}
+	changed, err := h.install("entire")
+	if err != nil || !changed {
+		t.Fatalf("install() = %v, %v; want true, nil", changed, err)
+	}
+	if !h.registered() {
+		t.Error("registered() = false after install")
+	}
+
+	data, err := os.ReadFile(filepath.Join(huskyDir, "commit-msg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), commitMsg+"\n"+huskyBlockBegin+"\n") {
+		t.Errorf("commit-msg should keep the user's command and append Entire's block:\n%s", data)
+	}
+	if !strings.Contains(string(data), `entire hooks git commit-msg "$1" || exit 1`) {
+		t.Errorf("commit-msg should run Entire's commit-msg command:\n%s", data)
+	}
+
+	if changed, err := h.install("entire"); err != nil || changed {
+		t.Errorf("second install() = %v, %v; want false, nil", changed, err)
+	}
+
+	changed, err = h.remove()
+	if err != nil || !changed {
+		t.Fatalf("remove() = %v, %v; want true, nil", changed, err)
+	}
+	data, err = os.ReadFile(filepath.Join(huskyDir, "commit-msg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != commitMsg+"\n" {
+		t.Errorf("commit-msg after remove = %q, want %q", data, commitMsg+"\n")
+	}
+	if _, err := os.Stat(filepath.Join(huskyDir, "post-commit")); !os.IsNotExist(err) {
+		t.Errorf("post-commit created by install should be removed, stat error = %v", err)
+	}
+}
+
+func TestLefthookIntegration_InstallRemove(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), "lefthook.yml")
+	config := `# Shared hooks
+pre-commit:
+  commands:
+    lint:
+      run: npm run lint
+commit-msg:
+  commands:
+    commitlint:
+      run: npx commitlint --edit {1}
+`
+	if err := os.WriteFile(configPath, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	l := &lefthookIntegration{configPath: configPath}
+	changed, err := l.install("entire")
+	if err != nil || !changed {
+		t.Fatalf("install() = %v, %v; want true, nil", changed, err)
+	}
+	if !l.registered() {
+		t.Error("registered() = false after install")
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# Shared hooks",
+		"run: npx commitlint --edit {1}",
+		"run: command -v entire >/dev/null 2>&1 || exit 0; entire hooks git commit-msg {1}\n",
+		"entire hooks git post-checkout {1} {2} {3} || true",
+		"use_stdin: true",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("lefthook.yml missing %q:\n%s", want, data)
+		}
+	}
+
+	if changed, err := l.install("entire"); err != nil || changed {
+		t.Errorf("second install() = %v, %v; want false, nil", changed, err)
+	}
+
+	if changed, err := l.remove(); err != nil || !changed {
+		t.Fatalf("remove() = %v, %v; want true, nil", changed, err)
+	}
+	data, err = os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != config {
+		t.Errorf("lefthook.yml after remove = %q, want the original %q", data, config)
+	}
+}
+
+func TestLefthookIntegration_FlowStyleHook(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), "lefthook.yml")
+	config := `pre-commit:
+  commands:
+    lint:
+      run: npm run lint
+post-commit: {commands: {a: {run: echo a}}}
+`
+	if err := os.WriteFile(configPath, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	l := &lefthookIntegration{configPath: configPath}
+	if changed, err := l.install("entire"); err != nil || !changed {
+		t.Fatalf("install() = %v, %v; want true, nil", changed, err)
+	}
+	if !l.registered() {
+		t.Error("registered() = false after install")
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "pre-commit:\n  commands:\n    lint:\n      run: npm run lint\n") ||
+		!strings.Contains(string(data), "\npost-commit: {commands: {a: {run: echo a}, entire: {run: ") {
+		t.Errorf("install() should keep the flow-style hook in flow style:\n%s", data)
+	}
+
+	if changed, err := l.remove(); err != nil || !changed {
+		t.Fatalf("remove() = %v, %v; want true, nil", changed, err)
+	}
+	data, err = os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != config {
+		t.Errorf("lefthook.yml after remove = %q, want the original %q", data, config)
+	}
+}
+
+func TestPreCommitIntegration_InstallRemove(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), ".pre-commit-config.yaml")
+	config := `default_install_hook_types: [pre-commit, commit-msg]
+repos:
+  - repo: https://github.com/pre-commit/pre-commit-hooks
+    rev: v4.6.0
+    hooks:
+      - id: trailing-whitespace
+`
+	if err := os.WriteFile(configPath, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &preCommitIntegration{configPath: configPath}
+	changed, err := p.install("entire")
+	if err != nil || !changed {
+		t.Fatalf("install() = %v, %v; want true, nil", changed, err)
+	}
+	if !p.registered() {
+		t.Error("registered() = false after install")
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"- id: trailing-whitespace",
+		"- repo: local",
+		"id: entire-prepare-commit-msg",
+		`entire hooks git prepare-commit-msg "$1" "$PRE_COMMIT_COMMIT_MSG_SOURCE" 2>/dev/null || true' --`,
+		"- post-checkout # " + hookIntegrationMarker,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf(".pre-commit-config.yaml missing %q:\n%s", want, data)
+		}
+	}
+	if strings.Contains(string(data), "entire-post-rewrite") {
+		t.Errorf("post-rewrite should be left to Entire's own hook:\n%s", data)
+	}
+
+	if changed, err := p.install("entire"); err != nil || changed {
+		t.Errorf("second install() = %v, %v; want false, nil", changed, err)
+	}
+
+	if changed, err := p.remove(); err != nil || !changed {
+		t.Fatalf("remove() = %v, %v; want true, nil", changed, err)
+	}
+	data, err = os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "entire") || strings.Contains(string(data), "repo: local") {
+		t.Errorf("Entire's hooks should be removed:\n%s", data)
+	}
+	if !strings.Contains(string(data), "- pre-commit\n  - commit-msg\n") {
+		t.Errorf("the user's hook types should be kept:\n%s", data)
+	}
+}
+
+func TestPreCommitIntegration_KeepsFormatting(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), ".pre-commit-config.yaml")
+	config := `# Team hooks: keep this list sorted
+repos:
+-   repo: https://github.com/pre-commit/pre-commit-hooks
+    rev: 'v4.6.0'
+
+    hooks:
+    -   id: trailing-whitespace   # strips spaces
+    -   id: end-of-file-fixer
+`
+	if err := os.WriteFile(configPath, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &preCommitIntegration{configPath: configPath}
+	if changed, err := p.install("entire"); err != nil || !changed {
+		t.Fatalf("install() = %v, %v; want true, nil", changed, err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), config) {
+		t.Errorf("install() should only add lines after the user's entries:\n%s", data)
+	}
+	for _, want := range []string{
+		"\n-   repo: local\n    hooks:\n    -   id: entire-prepare-commit-msg\n        name: Entire (prepare-commit-msg)\n",
+		"\ndefault_install_hook_types:\n-   pre-commit # " + hookIntegrationMarker + "\n",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf(".pre-commit-config.yaml missing %q:\n%s", want, data)
+		}
+	}
+
+	if changed, err := p.remove(); err != nil || !changed {
+		t.Fatalf("remove() = %v, %v; want true, nil", changed, err)
+	}
+	data, err = os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != config {
+		t.Errorf(".pre-commit-config.yaml after remove = %q, want the original %q", data, config)
+	}
+}
+
+func TestOvercommitIntegration_InstallRemove(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), ".overcommit.yml")
+	config := `PreCommit:
+    RuboCop:
+        enabled: true
+
+PostCheckout:
+    IndexTags:
+        enabled: true
+`
+	if err := os.WriteFile(configPath, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	o := &overcommitIntegration{configPath: configPath}
+	changed, err := o.install("entire")
+	if err != nil || !changed {
+		t.Fatalf("install() = %v, %v; want true, nil", changed, err)
+	}
+	if !o.registered() {
+		t.Error("registered() = false after install")
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"PostCheckout:\n    IndexTags:\n        enabled: true\n    Entire:\n        enabled: true\n",
+		"\nCommitMsg:\n    Entire:\n",
+		`command: [sh, -c, command -v entire >/dev/null 2>&1 || exit 0; entire hooks git commit-msg "$1", --]`,
+		`entire hooks git post-checkout "$1" "$2" "$3" || true', --]`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf(".overcommit.yml missing %q:\n%s", want, data)
+		}
+	}
+
+	if changed, err := o.install("entire"); err != nil || changed {
+		t.Errorf("second install() = %v, %v; want false, nil", changed, err)
+	}
+
+	if changed, err := o.remove(); err != nil || !changed {
+		t.Fatalf("remove() = %v, %v; want true, nil", changed, err)
+	}
+	data, err = os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != config {
+		t.Errorf(".overcommit.yml after remove = %q, want the original %q", data, config)
+	}
+}
+
+func TestInstallGitHook_HandsOffToHookManager(t *testing.T) {
+	repoDir, hooksDir := initHooksTestRepo(t)
+	ctx := context.Background()
+
+	// Entire's hooks are installed first, then Lefthook is set up
+	if _, err := InstallGitHook(ctx, true, false); err != nil {
+		t.Fatalf("InstallGitHook() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "lefthook.yml"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	RegisterWithHookManagers(ctx, &out, false)
+	if !strings.Contains(out.String(), "Run 'lefthook install'") {
+		t.Errorf("RegisterWithHookManagers() should ask to install Lefthook's hooks, got:\n%s", out.String())
+	}
+	if !IsGitHookInstalled(ctx) {
+		t.Error("IsGitHookInstalled() = false; Entire's own hooks should still count before Lefthook installs its hooks")
+	}
+
+	// lefthook install moves Entire's hooks aside as .old
+	lefthookHook := "#!/bin/sh\ncall_lefthook run \"$0\" \"$@\"\n"
+	for _, hook := range gitHookNames {
+		hookPath := filepath.Join(hooksDir, hook)
+		if err := os.Rename(hookPath, hookPath+".old"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(hookPath, []byte(lefthookHook), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if IsGitHookInstalled(ctx) {
+		t.Error("IsGitHookInstalled() = true while Lefthook would run Entire's moved hooks too")
+	}
+
+	if _, err := InstallGitHook(ctx, true, false); err != nil {
+		t.Fatalf("InstallGitHook() error = %v", err)
+	}
+	for _, hook := range gitHookNames {
+		data, err := os.ReadFile(filepath.Join(hooksDir, hook))
+		if err != nil || string(data) != lefthookHook {
+			t.Errorf("%s = %q, %v; want Lefthook's hook", hook, data, err)
+		}
+		if fileExists(filepath.Join(hooksDir, hook+".old")) {
+			t.Errorf("%s.old should be removed", hook)
+		}
+	}
+	if !IsGitHookInstalled(ctx) {
+		t.Error("IsGitHookInstalled() = false with Lefthook running Entire's commands")
+	}
+	if integrations := RegisteredHookManagerIntegrations(ctx); len(integrations) != 1 || !integrations[0].Live {
+		t.Errorf("RegisteredHookManagerIntegrations() = %+v, want one live Lefthook integration", integrations)
+	}
+
+	// Without the integration, Entire needs its own hooks again
+	out.Reset()
+	if err := RemoveHookManagerIntegrations(ctx, &out); err != nil {
+		t.Fatalf("RemoveHookManagerIntegrations() error = %v", err)
+	}
+	if IsGitHookInstalled(ctx) {
+		t.Error("IsGitHookInstalled() = true after removing the integration")
+	}
+}
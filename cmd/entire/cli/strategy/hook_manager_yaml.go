@@ -0,0 +1,410 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlConfig is a YAML config being edited in place. Hook manager configs are
+// usually shared by a team, so edits replace only the lines of the entries
+// they change: the rest of the file keeps its indentation, quoting, comments
+// and blank lines. New entries follow the file's indentation.
+//
+// Every edit re-parses the file, so nodes must be looked up again afterwards.
+type yamlConfig struct {
+	name    string // file name, for errors
+	lines   []string
+	doc     *yaml.Node
+	ends    map[*yaml.Node]int        // node → line after the last line its entry can span
+	parents map[*yaml.Node]*yaml.Node // value or item → its mapping or sequence
+	style   yamlStyle
+}
+
+// yamlStyle is how a YAML file indents block collections.
+type yamlStyle struct {
+	indent    int // columns between a key and the keys of its mapping value
+	seqIndent int // columns between a key and the "-" of its sequence value
+	seqPad    int // columns between a "-" and its item
+}
+
+// editYAMLConfig loads a YAML config, applies edit to it, and writes it back
+// if it changed. A missing file is created. Returns true if the file was written.
+func editYAMLConfig(path string, edit func(c *yamlConfig) error) (bool, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // Path is a detected config file in the repository
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	c := &yamlConfig{name: filepath.Base(path)}
+	if len(data) > 0 {
+		c.lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+	if err := c.parse(); err != nil {
+		return false, err
+	}
+	c.style = c.detectStyle()
+	before := slices.Clone(c.lines)
+
+	if err := edit(c); err != nil {
+		return false, err
+	}
+	if slices.Equal(c.lines, before) {
+		return false, nil
+	}
+
+	var perm os.FileMode = 0o644
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	content := strings.Join(c.lines, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", c.name, err)
+	}
+	return true, nil
+}
+
+// loadYAMLConfig reads a YAML document whose root is a mapping. An empty or
+// missing file yields an empty mapping.
+func loadYAMLConfig(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // Path is a detected config file in the repository
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	return parseYAMLConfig(filepath.Base(path), data)
+}
+
+func parseYAMLConfig(name string, data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s is not a YAML mapping", name)
+	}
+	return &doc, nil
+}
+
+// root returns the config's root mapping.
+func (c *yamlConfig) root() *yaml.Node {
+	return c.doc.Content[0]
+}
+
+// set sets key in mapping m to value. An existing entry is replaced; a new one
+// is added after the mapping's last entry. A flow mapping stays in flow style.
+func (c *yamlConfig) set(m *yaml.Node, key string, value *yaml.Node) error {
+	if m.Style&yaml.FlowStyle != 0 {
+		edited := &yaml.Node{Kind: yaml.MappingNode, Style: m.Style, Content: slices.Clone(m.Content)}
+		setMappingValue(edited, key, value)
+		return c.replace(m, edited)
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if k := m.Content[i]; k.Value == key {
+			start, end := c.entryRange(m, i)
+			return c.splice(start, end, c.style.entryLines(k, value, k.Column-1))
+		}
+	}
+
+	// An empty block mapping is the root of a file without entries
+	at, col := len(c.lines), 0
+	if n := len(m.Content); n > 0 {
+		_, at = c.entryRange(m, n-2)
+		col = m.Content[0].Column - 1
+	}
+	return c.splice(at, at, c.style.entryLines(scalarNode(key), value, col))
+}
+
+// delete removes key from mapping m, if present.
+func (c *yamlConfig) delete(m *yaml.Node, key string) error {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value != key {
+			continue
+		}
+		if m.Style&yaml.FlowStyle != 0 {
+			edited := &yaml.Node{Kind: yaml.MappingNode, Style: m.Style, Content: slices.Delete(slices.Clone(m.Content), i, i+2)}
+			return c.replace(m, edited)
+		}
+		start, end := c.entryRange(m, i)
+		return c.splice(start, end, nil)
+	}
+	return nil
+}
+
+// insertItem inserts item into sequence seq before the item at index, or
+// after the last item if index is len(seq.Content). A flow sequence is
+// rewritten in block style, so items can carry comments.
+func (c *yamlConfig) insertItem(seq *yaml.Node, index int, item *yaml.Node) error {
+	if seq.Style&yaml.FlowStyle != 0 || len(seq.Content) == 0 {
+		block := &yaml.Node{Kind: yaml.SequenceNode, Content: slices.Insert(slices.Clone(seq.Content), index, item)}
+		return c.replace(seq, block)
+	}
+	if index < len(seq.Content) {
+		next := seq.Content[index]
+		return c.splice(next.Line-1, next.Line-1, c.style.itemLines(item, c.dashColumn(next)))
+	}
+	last := seq.Content[len(seq.Content)-1]
+	_, end := c.itemRange(last)
+	return c.splice(end, end, c.style.itemLines(item, c.dashColumn(last)))
+}
+
+// deleteItem removes the item at index from sequence seq. A sequence left
+// without items becomes [].
+func (c *yamlConfig) deleteItem(seq *yaml.Node, index int) error {
+	if seq.Style&yaml.FlowStyle != 0 || len(seq.Content) == 1 {
+		block := &yaml.Node{Kind: yaml.SequenceNode, Content: slices.Delete(slices.Clone(seq.Content), index, index+1)}
+		if len(block.Content) == 0 {
+			block.Style = yaml.FlowStyle
+		}
+		return c.replace(seq, block)
+	}
+	start, end := c.itemRange(seq.Content[index])
+	return c.splice(start, end, nil)
+}
+
+// replace rewrites the lines of the entry or item whose value is n with
+// replacement. Inside a flow collection, the outermost flow collection is
+// rewritten with n replaced.
+func (c *yamlConfig) replace(n, replacement *yaml.Node) error {
+	for parent := c.parents[n]; parent != nil && parent.Style&yaml.FlowStyle != 0; parent = c.parents[n] {
+		edited := &yaml.Node{Kind: parent.Kind, Style: parent.Style, Tag: parent.Tag, Content: slices.Clone(parent.Content)}
+		edited.Content[slices.Index(edited.Content, n)] = replacement
+		n, replacement = parent, edited
+	}
+
+	parent := c.parents[n]
+	switch {
+	case n == c.root():
+		// The root mapping
+		start := max(n.Line-1, 0)
+		end := c.trimEnd(start, c.ends[n], 0)
+		return c.splice(start, end, c.style.mappingLines(replacement, 0))
+	case parent == nil:
+		// Not in the config's tree: reported below
+	case parent.Kind == yaml.MappingNode:
+		for i := 1; i < len(parent.Content); i += 2 {
+			if parent.Content[i] == n {
+				k := parent.Content[i-1]
+				start, end := c.entryRange(parent, i-1)
+				return c.splice(start, end, c.style.entryLines(k, replacement, k.Column-1))
+			}
+		}
+	case parent.Kind == yaml.SequenceNode:
+		start, end := c.itemRange(n)
+		return c.splice(start, end, c.style.itemLines(replacement, c.dashColumn(n)))
+	}
+	return fmt.Errorf("failed to edit %s: node not found", c.name)
+}
+
+// splice replaces lines [start, end) and parses the result.
+func (c *yamlConfig) splice(start, end int, lines []string) error {
+	if slices.Equal(c.lines[start:end], lines) {
+		return nil
+	}
+	c.lines = slices.Concat(c.lines[:start], lines, c.lines[end:])
+	return c.parse()
+}
+
+func (c *yamlConfig) parse() error {
+	doc, err := parseYAMLConfig(c.name, []byte(strings.Join(c.lines, "\n")))
+	if err != nil {
+		return err
+	}
+	c.doc = doc
+	c.ends = make(map[*yaml.Node]int)
+	c.parents = make(map[*yaml.Node]*yaml.Node)
+	c.index(doc.Content[0], len(c.lines))
+	return nil
+}
+
+// index records where the entries under n can end: before the next sibling,
+// or where n's own entry can end. The entries of a flow collection share its
+// lines, as they are only edited by rewriting the whole collection.
+func (c *yamlConfig) index(n *yaml.Node, end int) {
+	c.ends[n] = end
+	var children []*yaml.Node
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 1; i < len(n.Content); i += 2 {
+			children = append(children, n.Content[i])
+		}
+	case yaml.SequenceNode:
+		children = n.Content
+	default:
+		return
+	}
+	for i, child := range children {
+		next := end
+		if i+1 < len(children) && n.Style&yaml.FlowStyle == 0 {
+			next = c.startLine(n, i+1)
+		}
+		c.parents[child] = n
+		c.index(child, next)
+	}
+}
+
+// startLine returns the line where the i-th entry or item of n starts.
+func (c *yamlConfig) startLine(n *yaml.Node, i int) int {
+	if n.Kind == yaml.MappingNode {
+		return n.Content[2*i].Line - 1
+	}
+	return n.Content[i].Line - 1
+}
+
+// entryRange returns the lines of the entry whose key is m.Content[i].
+func (c *yamlConfig) entryRange(m *yaml.Node, i int) (int, int) {
+	k := m.Content[i]
+	start := k.Line - 1
+	return start, c.trimEnd(start, c.ends[m.Content[i+1]], k.Column-1)
+}
+
+// itemRange returns the lines of a sequence item.
+func (c *yamlConfig) itemRange(item *yaml.Node) (int, int) {
+	start := item.Line - 1
+	return start, c.trimEnd(start, c.ends[item], c.dashColumn(item))
+}
+
+// trimEnd leaves out the blank lines and the comments of following entries
+// from the end of an entry at column col.
+func (c *yamlConfig) trimEnd(start, end, col int) int {
+	for end > start+1 {
+		line := c.lines[end-1]
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed != "" && (!strings.HasPrefix(trimmed, "#") || len(line)-len(trimmed) > col) {
+			break
+		}
+		end--
+	}
+	return end
+}
+
+// dashColumn returns the column of the "-" before a sequence item.
+func (c *yamlConfig) dashColumn(item *yaml.Node) int {
+	col := item.Column - 1
+	if line := c.lines[item.Line-1]; col <= len(line) {
+		if dash := strings.LastIndex(line[:col], "-"); dash >= 0 {
+			return dash
+		}
+	}
+	return col
+}
+
+// detectStyle returns the indentation of the first nested block mapping and
+// block sequence in the file, defaulting to two columns.
+func (c *yamlConfig) detectStyle() yamlStyle {
+	style := yamlStyle{seqIndent: -1}
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		for i := 0; i+1 < len(n.Content) && n.Kind == yaml.MappingNode; i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if !isBlockCollection(v) {
+				continue
+			}
+			switch first := v.Content[0]; {
+			case v.Kind == yaml.MappingNode && style.indent == 0 && first.Column > k.Column:
+				style.indent = first.Column - k.Column
+			case v.Kind == yaml.SequenceNode && style.seqIndent < 0 && first.Line > k.Line:
+				dash := c.dashColumn(first)
+				style.seqIndent = max(dash-(k.Column-1), 0)
+				if pad := first.Column - 1 - dash; pad >= 2 {
+					style.seqPad = pad
+				}
+			}
+		}
+		for _, child := range n.Content {
+			walk(child)
+		}
+	}
+	walk(c.root())
+
+	if style.indent == 0 {
+		style.indent = 2
+	}
+	if style.seqIndent < 0 {
+		style.seqIndent = style.indent
+	}
+	if style.seqPad == 0 {
+		style.seqPad = 2
+	}
+	return style
+}
+
+// entryLines renders a mapping entry with its key at column col.
+func (s yamlStyle) entryLines(key, value *yaml.Node, col int) []string {
+	head := strings.Repeat(" ", col) + inlineYAML(&yaml.Node{Kind: yaml.ScalarNode, Tag: key.Tag, Style: key.Style, Value: key.Value})[0] + ":"
+	switch {
+	case isBlockCollection(value) && value.Kind == yaml.MappingNode:
+		return append([]string{head}, s.mappingLines(value, col+s.indent)...)
+	case isBlockCollection(value):
+		return append([]string{head}, s.sequenceLines(value, col+s.seqIndent)...)
+	}
+	text := inlineYAML(value)
+	lines := []string{head + " " + text[0]}
+	for _, line := range text[1:] {
+		lines = append(lines, strings.Repeat(" ", col+s.indent)+line)
+	}
+	return lines
+}
+
+// mappingLines renders the entries of a mapping with its keys at column col.
+func (s yamlStyle) mappingLines(m *yaml.Node, col int) []string {
+	var lines []string
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		lines = append(lines, s.entryLines(m.Content[i], m.Content[i+1], col)...)
+	}
+	return lines
+}
+
+// sequenceLines renders the items of a sequence with their "-" at column col.
+func (s yamlStyle) sequenceLines(seq *yaml.Node, col int) []string {
+	var lines []string
+	for _, item := range seq.Content {
+		lines = append(lines, s.itemLines(item, col)...)
+	}
+	return lines
+}
+
+// itemLines renders a sequence item with its "-" at column col.
+func (s yamlStyle) itemLines(item *yaml.Node, col int) []string {
+	itemCol := col + s.seqPad
+	var lines []string
+	switch {
+	case isBlockCollection(item) && item.Kind == yaml.MappingNode:
+		lines = s.mappingLines(item, itemCol)
+	case isBlockCollection(item):
+		lines = s.sequenceLines(item, itemCol)
+	default:
+		text := inlineYAML(item)
+		lines = []string{strings.Repeat(" ", itemCol) + text[0]}
+		for _, line := range text[1:] {
+			lines = append(lines, strings.Repeat(" ", itemCol+s.indent)+line)
+		}
+	}
+	lines[0] = strings.Repeat(" ", col) + "-" + strings.Repeat(" ", s.seqPad-1) + lines[0][itemCol:]
+	return lines
+}
+
+// isBlockCollection reports whether n is a mapping or sequence written one
+// entry per line.
+func isBlockCollection(n *yaml.Node) bool {
+	return (n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode) &&
+		n.Style&yaml.FlowStyle == 0 && len(n.Content) > 0
+}
+
+// inlineYAML renders a scalar or flow collection, e.g. "[a, b]".
+func inlineYAML(n *yaml.Node) []string {
+	out, err := yaml.Marshal(n)
+	if err != nil {
+		return []string{n.Value}
+	}
+	return strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/entireio/cli/cmd/entire/cli/paths"
@@ -78,12 +79,14 @@ func hookManagerWarning(managers []hookManager, cmdPrefix string) string {
 			hookDir := m.ConfigPath
 
 			for _, spec := range specs {
-				cmdLine := extractCommandLine(spec.content)
-				if cmdLine == "" {
+				cmdLines := extractCommandLines(spec.content)
+				if len(cmdLines) == 0 {
 					continue
 				}
 				fmt.Fprintf(&b, "    %s%s:\n", hookDir, spec.name)
-				fmt.Fprintf(&b, "      %s\n", cmdLine)
+				for _, cmdLine := range cmdLines {
+					fmt.Fprintf(&b, "      %s\n", cmdLine)
+				}
 				fmt.Fprintf(&b, "\n")
 			}
 		} else {
@@ -100,18 +103,30 @@ func hookManagerWarning(managers []hookManager, cmdPrefix string) string {
 // extractCommandLine returns the first non-shebang, non-comment, non-empty line
 // from a hook script. This is the actual command invocation line.
 func extractCommandLine(hookContent string) string {
+	if lines := extractCommandLines(hookContent); len(lines) > 0 {
+		return lines[0]
+	}
+	return ""
+}
+
+// extractCommandLines returns the non-shebang, non-comment, non-empty lines
+// from a hook script. Most hooks are a single command; post-rewrite also
+// captures and restores stdin around it.
+func extractCommandLines(hookContent string) []string {
+	var lines []string
 	for _, line := range strings.Split(hookContent, "\n") {
 		trimmed := strings.TrimSpace(line)
 		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
 			continue
 		}
-		return trimmed
+		lines = append(lines, trimmed)
 	}
-	return ""
+	return lines
 }
 
 // CheckAndWarnHookManagers detects external hook managers and writes a warning
-// to w if any are found.
+// to w if any are found. Managers with Entire's hook commands registered in
+// their config are skipped (see RegisterWithHookManagers).
 // localDev controls whether the warning references "go run" or the "entire" binary.
 func CheckAndWarnHookManagers(ctx context.Context, w io.Writer, localDev bool) {
 	repoRoot, err := paths.WorktreeRoot(ctx)
@@ -119,7 +134,10 @@ func CheckAndWarnHookManagers(ctx context.Context, w io.Writer, localDev bool) {
 		return
 	}
 
-	managers := detectHookManagers(repoRoot)
+	managers := slices.DeleteFunc(detectHookManagers(repoRoot), func(m hookManager) bool {
+		integration := newHookManagerIntegration(repoRoot, m)
+		return integration != nil && integration.registered()
+	})
 	if len(managers) == 0 {
 		return
 	}
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/settings"
 )
 
@@ -20,7 +21,15 @@ const backupSuffix = ".pre-entire"
 const chainComment = "# Chain: run pre-existing hook"
 
 // gitHookNames are the git hooks managed by Entire CLI
-var gitHookNames = []string{"prepare-commit-msg", "commit-msg", "post-commit", "pre-push"}
+var gitHookNames = []string{"prepare-commit-msg", "commit-msg", "post-commit", "pre-push", "post-merge", "post-checkout", "post-rewrite"}
+
+// autoSyncCheck is a shell condition that is true when a settings file sets
+// strategy_options.auto_sync to true, as written by `entire enable` or by hand
+// on one line. It only spares the post-merge and post-checkout hooks from
+// starting the CLI when auto_sync is off: the CLI still reads the merged
+// settings (e.g., a local override to false) before syncing. Hooks run from
+// the top of the working tree, so the paths are relative to it.
+const autoSyncCheck = `grep -qs '"auto_sync"[[:space:]]*:[[:space:]]*true' .entire/settings.json .entire/settings.local.json`
 
 // ManagedGitHookNames returns the list of git hooks managed by Entire CLI.
 // This is useful for tests that need to manipulate hooks.
@@ -130,13 +139,19 @@ func getHooksDirInPath(ctx context.Context, dir string) (string, error) {
 	return filepath.Clean(hooksDir), nil
 }
 
-// IsGitHookInstalled checks if all generic Entire CLI hooks are installed.
+// IsGitHookInstalled checks if git runs Entire's commands from every managed
+// hook, exactly once: from Entire's own hook, or from a hook manager that has
+// Entire's commands registered and its hooks installed (see RegisterWithHookManagers).
 func IsGitHookInstalled(ctx context.Context) bool {
 	hooksDir, err := GetHooksDir(ctx)
 	if err != nil {
 		return false
 	}
-	return isGitHookInstalledInHooksDir(hooksDir)
+	repoRoot, err := paths.WorktreeRoot(ctx)
+	if err != nil {
+		return false
+	}
+	return isGitHookInstalledInHooksDir(hooksDir, hookManagerCoverage(repoRoot, hooksDir))
 }
 
 // IsGitHookInstalledInDir checks if all Entire CLI hooks are installed in the given repo directory.
@@ -146,24 +161,47 @@ func IsGitHookInstalledInDir(ctx context.Context, repoDir string) bool {
 	if err != nil {
 		return false
 	}
-	return isGitHookInstalledInHooksDir(hooksDir)
+	return isGitHookInstalledInHooksDir(hooksDir, hookManagerCoverage(repoDir, hooksDir))
 }
 
 // isGitHookInstalledInHooksDir checks if all hooks are installed in the given hooks directory.
-func isGitHookInstalledInHooksDir(hooksDir string) bool {
+// covered maps hooks a hook manager runs Entire's commands from to the manager's name;
+// Entire's own hook must not be there too, or the commands would run twice.
+func isGitHookInstalledInHooksDir(hooksDir string, covered map[string]string) bool {
 	for _, hook := range gitHookNames {
-		hookPath := filepath.Join(hooksDir, hook)
-		data, err := os.ReadFile(hookPath) //nolint:gosec // Path is constructed from constants
-		if err != nil {
-			return false
+		if _, ok := covered[hook]; ok {
+			if hasEntireHook(hooksDir, hook) || hasMovedEntireHook(hooksDir, hook) {
+				return false
+			}
+			continue
 		}
-		if !strings.Contains(string(data), entireHookMarker) {
+		if !hasEntireHook(hooksDir, hook) {
 			return false
 		}
 	}
 	return true
 }
 
+// hasEntireHook reports whether the hook is Entire's.
+func hasEntireHook(hooksDir, hook string) bool {
+	data, err := os.ReadFile(filepath.Join(hooksDir, hook)) //nolint:gosec // Path is constructed from constants
+	return err == nil && strings.Contains(string(data), entireHookMarker)
+}
+
+// movedHookSuffixes are where hook managers move hooks they replace on install:
+// Lefthook keeps them as .old, pre-commit as .legacy (and runs them).
+var movedHookSuffixes = []string{".old", ".legacy"}
+
+// hasMovedEntireHook reports whether a hook manager moved Entire's hook aside.
+func hasMovedEntireHook(hooksDir, hook string) bool {
+	for _, suffix := range movedHookSuffixes {
+		if hasEntireHook(hooksDir, hook+suffix) {
+			return true
+		}
+	}
+	return false
+}
+
 // buildHookSpecs returns the hook specifications for all managed hooks.
 func buildHookSpecs(cmdPrefix string) []hookSpec {
 	return []hookSpec{
@@ -197,6 +235,46 @@ func buildHookSpecs(cmdPrefix string) []hookSpec {
 # Pre-push hook: push session logs alongside user's push
 # $1 is the remote name (e.g., "origin")
 %s hooks git pre-push "$1" || true
+`, entireHookMarker, cmdPrefix),
+		},
+		{
+			name: "post-merge",
+			content: fmt.Sprintf(`#!/bin/sh
+# %s
+# Post-merge hook: sync teammates' checkpoints after a pull (if auto_sync is enabled)
+# $1 is 1 for a squash merge
+# Checking the settings first avoids starting the CLI on every pull when auto_sync is off
+if %s; then
+%s hooks git post-merge "$1" || true
+fi
+`, entireHookMarker, autoSyncCheck, cmdPrefix),
+		},
+		{
+			name: "post-checkout",
+			content: fmt.Sprintf(`#!/bin/sh
+# %s
+# Post-checkout hook: sync teammates' checkpoints on branch switch (if auto_sync is enabled)
+# $1 and $2 are the previous and new HEAD, $3 is 1 for a branch checkout
+# Checking the settings first avoids starting the CLI on every checkout when auto_sync is off
+if %s; then
+%s hooks git post-checkout "$1" "$2" "$3" || true
+fi
+`, entireHookMarker, autoSyncCheck, cmdPrefix),
+		},
+		{
+			name: "post-rewrite",
+			content: fmt.Sprintf(`#!/bin/sh
+# %s
+# Post-rewrite hook: follow checkpoints and sessions onto amended or rebased commits
+# $1 is "amend" or "rebase"; stdin lists "<old-sha> <new-sha>" per rewritten commit,
+# which is kept so a chained pre-existing hook can read it too
+_entire_rewritten="$(cat)"
+%s hooks git post-rewrite "$1" 2>/dev/null <<_ENTIRE_REWRITTEN || true
+$_entire_rewritten
+_ENTIRE_REWRITTEN
+exec <<_ENTIRE_REWRITTEN
+$_entire_rewritten
+_ENTIRE_REWRITTEN
 `, entireHookMarker, cmdPrefix),
 		},
 	}
@@ -217,10 +295,23 @@ func InstallGitHook(ctx context.Context, silent bool, localDev bool) (int, error
 		return 0, fmt.Errorf("failed to create hooks directory: %w", err)
 	}
 
+	// Hooks a hook manager runs Entire's commands from don't need Entire's own
+	var covered map[string]string
+	if repoRoot, err := paths.WorktreeRoot(ctx); err == nil {
+		covered = hookManagerCoverage(repoRoot, hooksDir)
+	}
+
 	specs := buildHookSpecs(hookCmdPrefix(localDev))
 	installedCount := 0
 
 	for _, spec := range specs {
+		if manager, ok := covered[spec.name]; ok {
+			if err := handOffHook(hooksDir, spec.name, manager); err != nil {
+				return installedCount, err
+			}
+			continue
+		}
+
 		hookPath := filepath.Join(hooksDir, spec.name)
 		backupPath := hookPath + backupSuffix
 		backupExists := fileExists(backupPath)
@@ -255,7 +346,7 @@ func InstallGitHook(ctx context.Context, silent bool, localDev bool) (int, error
 	}
 
 	if !silent {
-		fmt.Println("✓ Installed git hooks (prepare-commit-msg, commit-msg, post-commit, pre-push)")
+		fmt.Println("✓ Installed git hooks (prepare-commit-msg, commit-msg, post-commit, pre-push, post-merge, post-checkout, post-rewrite)")
 		fmt.Println("  Hooks delegate to the current strategy at runtime")
 	}
 
@@ -291,39 +382,73 @@ func RemoveGitHook(ctx context.Context) (int, error) {
 	var removeErrors []string
 
 	for _, hook := range gitHookNames {
-		hookPath := filepath.Join(hooksDir, hook)
-		backupPath := hookPath + backupSuffix
+		hookRemoved, err := removeEntireHook(hooksDir, hook)
+		if err != nil {
+			removeErrors = append(removeErrors, err.Error())
+		}
+		if hookRemoved {
+			removed++
+		}
+	}
 
-		// Remove the hook if it contains our marker
-		data, err := os.ReadFile(hookPath) //nolint:gosec // path is controlled
-		hookIsOurs := err == nil && strings.Contains(string(data), entireHookMarker)
-		hookExists := err == nil
+	if len(removeErrors) > 0 {
+		return removed, fmt.Errorf("failed to remove hooks: %s", strings.Join(removeErrors, "; "))
+	}
+	return removed, nil
+}
 
-		if hookIsOurs {
-			if err := os.Remove(hookPath); err != nil {
-				removeErrors = append(removeErrors, fmt.Sprintf("%s: %v", hook, err))
-				continue
-			}
-			removed++
+// removeEntireHook removes Entire's hook and restores the .pre-entire backup,
+// if there is one. Returns true if Entire's hook was removed.
+func removeEntireHook(hooksDir, hook string) (bool, error) {
+	hookPath := filepath.Join(hooksDir, hook)
+	backupPath := hookPath + backupSuffix
+
+	// Remove the hook if it contains our marker
+	data, err := os.ReadFile(hookPath) //nolint:gosec // path is controlled
+	hookIsOurs := err == nil && strings.Contains(string(data), entireHookMarker)
+	hookExists := err == nil
+
+	if hookIsOurs {
+		if err := os.Remove(hookPath); err != nil {
+			return false, fmt.Errorf("%s: %w", hook, err)
 		}
+	}
 
-		// Restore .pre-entire backup if it exists
-		if fileExists(backupPath) {
-			if hookExists && !hookIsOurs {
-				// A non-Entire hook is present — don't overwrite it with the backup
-				fmt.Fprintf(os.Stderr, "[entire] Warning: %s was modified since install; backup %s%s left in place\n", hook, hook, backupSuffix)
-			} else {
-				if err := os.Rename(backupPath, hookPath); err != nil {
-					removeErrors = append(removeErrors, fmt.Sprintf("restore %s%s: %v", hook, backupSuffix, err))
-				}
+	// Restore .pre-entire backup if it exists
+	if fileExists(backupPath) {
+		if hookExists && !hookIsOurs {
+			// A non-Entire hook is present — don't overwrite it with the backup
+			fmt.Fprintf(os.Stderr, "[entire] Warning: %s was modified since install; backup %s%s left in place\n", hook, hook, backupSuffix)
+		} else {
+			if err := os.Rename(backupPath, hookPath); err != nil {
+				return hookIsOurs, fmt.Errorf("restore %s%s: %w", hook, backupSuffix, err)
 			}
 		}
 	}
+	return hookIsOurs, nil
+}
 
-	if len(removeErrors) > 0 {
-		return removed, fmt.Errorf("failed to remove hooks: %s", strings.Join(removeErrors, "; "))
+// handOffHook removes Entire's own hook for a hook that a hook manager runs
+// Entire's commands from, restoring the manager's hook from the backup, and any
+// copy of Entire's hook the manager moved aside and would still run.
+func handOffHook(hooksDir, hook, manager string) error {
+	removed, err := removeEntireHook(hooksDir, hook)
+	if err != nil {
+		return fmt.Errorf("failed to remove %s hook: %w", hook, err)
 	}
-	return removed, nil
+	for _, suffix := range movedHookSuffixes {
+		if !hasEntireHook(hooksDir, hook+suffix) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(hooksDir, hook+suffix)); err != nil {
+			return fmt.Errorf("failed to remove %s%s: %w", hook, suffix, err)
+		}
+		removed = true
+	}
+	if removed {
+		fmt.Fprintf(os.Stderr, "[entire] %s runs Entire's %s commands; removed Entire's own %s hook\n", manager, hook, hook)
+	}
+	return nil
 }
 
 // generateChainedContent appends a chain call to the base hook content,
@@ -1260,3 +1260,58 @@ func TestRemoveGitHook_PermissionDenied(t *testing.T) {
 		t.Errorf("error should mention 'failed to remove hooks', got: %v", err)
 	}
 }
+
+func TestSyncHooks_SkipCLIWhenAutoSyncOff(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".entire"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// A stand-in for the CLI that records each call
+	logPath := filepath.Join(dir, "calls.log")
+	fakeCLI := filepath.Join(dir, "fake-entire")
+	if err := os.WriteFile(fakeCLI, []byte("#!/bin/sh\necho \"$@\" >> "+logPath+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	specs := make(map[string]string)
+	for _, spec := range buildHookSpecs(fakeCLI) {
+		specs[spec.name] = spec.content
+	}
+	runHook := func(hook string, args ...string) {
+		t.Helper()
+		cmd := exec.CommandContext(context.Background(), "sh", append([]string{"-c", specs[hook], hook}, args...)...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%s hook failed: %v: %s", hook, err, output)
+		}
+	}
+	calls := func() string {
+		t.Helper()
+		data, err := os.ReadFile(logPath)
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	settingsPath := filepath.Join(dir, ".entire", "settings.json")
+	if err := os.WriteFile(settingsPath, []byte("{\n  \"enabled\": true\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runHook("post-merge", "0")
+	runHook("post-checkout", "a", "b", "1")
+	if got := calls(); got != "" {
+		t.Errorf("the CLI should not run with auto_sync off, got calls:\n%s", got)
+	}
+
+	if err := os.WriteFile(settingsPath, []byte("{\n  \"strategy_options\": {\n    \"auto_sync\": true\n  }\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runHook("post-merge", "0")
+	runHook("post-checkout", "a", "b", "1")
+	if got, want := calls(), "hooks git post-merge 0\nhooks git post-checkout a b 1\n"; got != want {
+		t.Errorf("calls with auto_sync on = %q, want %q", got, want)
+	}
+}
@@ -328,6 +328,7 @@ func accumulateTokenUsage(existing, incoming *agent.TokenUsage) *agent.TokenUsag
 			OutputTokens:        incoming.OutputTokens,
 			APICallCount:        incoming.APICallCount,
 			SubagentTokens:      incoming.SubagentTokens,
+			Models:              agent.MergeModelUsage(nil, incoming.Models),
 		}
 	}
 
@@ -337,6 +338,7 @@ func accumulateTokenUsage(existing, incoming *agent.TokenUsage) *agent.TokenUsag
 	existing.CacheReadTokens += incoming.CacheReadTokens
 	existing.OutputTokens += incoming.OutputTokens
 	existing.APICallCount += incoming.APICallCount
+	existing.Models = agent.MergeModelUsage(existing.Models, incoming.Models)
 
 	// Accumulate subagent tokens if present
 	if incoming.SubagentTokens != nil {
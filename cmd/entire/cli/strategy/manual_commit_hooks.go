@@ -789,6 +789,9 @@ func (s *ManualCommitStrategy) PostCommit(ctx context.Context) error { //nolint:
 			shadowBranchesToDelete, uncondensedActiveOnBranch)
 	}
 
+	// Mirror the trailer into a git note once the checkpoint is on the metadata branch
+	writeCheckpointNoteIfEnabled(ctx, repo, head.Hash(), checkpointID)
+
 	// Clean up shadow branches — only delete when ALL sessions on the branch are non-active
 	// or were condensed during this PostCommit.
 	for shadowBranchName := range shadowBranchesToDelete {
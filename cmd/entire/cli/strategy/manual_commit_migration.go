@@ -37,29 +37,40 @@ func (s *ManualCommitStrategy) migrateShadowBranchIfNeeded(ctx context.Context,
 	// HEAD changed - check if old shadow branch exists and migrate it
 	oldShadowBranch := checkpoint.ShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)
 	newShadowBranch := checkpoint.ShadowBranchNameForCommit(currentHead, state.WorktreeID)
+	moved, err := moveShadowBranch(ctx, repo, oldShadowBranch, newShadowBranch)
+	if err != nil {
+		return false, err
+	}
+	if moved {
+		fmt.Fprintf(os.Stderr, "Moved shadow branch from %s to %s (HEAD changed during session)\n",
+			oldShadowBranch, newShadowBranch)
+	} else if oldShadowBranch != newShadowBranch {
+		// Old shadow branch doesn't exist - just update state.BaseCommit
+		// This can happen if this is the first checkpoint after HEAD changed
+		fmt.Fprintf(os.Stderr, "Updated session base commit to %s (HEAD changed during session)\n", currentHead[:7])
+	}
+
+	// Update state with new base commit
+	state.BaseCommit = currentHead
+	return true, nil
+}
 
-	// Guard against hash prefix collision: if both commits produce the same
-	// shadow branch name (same 7-char prefix), just update state - no ref rename needed
+// moveShadowBranch renames oldShadowBranch to newShadowBranch.
+// Returns false if there is nothing to move: the old branch doesn't exist, or
+// both base commits share a 7-char prefix and so the same shadow branch name.
+func moveShadowBranch(ctx context.Context, repo *git.Repository, oldShadowBranch, newShadowBranch string) (bool, error) {
 	if oldShadowBranch == newShadowBranch {
-		state.BaseCommit = currentHead
-		return true, nil
+		return false, nil
 	}
 
 	oldRefName := plumbing.NewBranchReferenceName(oldShadowBranch)
 	oldRef, err := repo.Reference(oldRefName, true)
 	if err != nil {
-		// Old shadow branch doesn't exist - just update state.BaseCommit
-		// This can happen if this is the first checkpoint after HEAD changed
-		state.BaseCommit = currentHead
-		fmt.Fprintf(os.Stderr, "Updated session base commit to %s (HEAD changed during session)\n", currentHead[:7])
-		return true, nil //nolint:nilerr // err is "reference not found" which is fine - just need to update state
+		return false, nil //nolint:nilerr // err is "reference not found" which is fine - nothing to move
 	}
 
-	// Old shadow branch exists - move it to new base commit
-	newRefName := plumbing.NewBranchReferenceName(newShadowBranch)
-
 	// Create new reference pointing to same commit as old shadow branch
-	newRef := plumbing.NewHashReference(newRefName, oldRef.Hash())
+	newRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(newShadowBranch), oldRef.Hash())
 	if err := repo.Storer.SetReference(newRef); err != nil {
 		return false, fmt.Errorf("failed to create new shadow branch %s: %w", newShadowBranch, err)
 	}
@@ -69,12 +80,6 @@ func (s *ManualCommitStrategy) migrateShadowBranchIfNeeded(ctx context.Context,
 		// Non-fatal: log but continue - the important thing is the new branch exists
 		fmt.Fprintf(os.Stderr, "Warning: failed to remove old shadow branch %s: %v\n", oldShadowBranch, err)
 	}
-
-	fmt.Fprintf(os.Stderr, "Moved shadow branch from %s to %s (HEAD changed during session)\n",
-		oldShadowBranch, newShadowBranch)
-
-	// Update state with new base commit
-	state.BaseCommit = currentHead
 	return true, nil
 }
 
@@ -7,11 +7,20 @@ import (
 )
 
 // PrePush is called by the git pre-push hook before pushing to a remote.
-// It pushes the entire/checkpoints/v1 branch alongside the user's push.
+// It pushes the entire/checkpoints/v1 branch alongside the user's push, to the
+// same remote unless strategy_options.metadata_remote names a dedicated one.
+// With strategy_options.git_notes, refs/notes/entire is pushed to the same
+// remotes, including strategy_options.metadata_mirrors.
 // Configuration options (stored in .entire/settings.json under strategy_options.push_sessions):
 //   - "auto": always push automatically
 //   - "prompt" (default): ask user with option to enable auto
 //   - "false"/"off"/"no": never push
 func (s *ManualCommitStrategy) PrePush(ctx context.Context, remote string) error {
-	return pushSessionsBranchCommon(ctx, remote, paths.MetadataBranchName)
+	if err := pushSessionsBranchCommon(ctx, remote, paths.MetadataBranchName); err != nil {
+		return err
+	}
+	if !isPushSessionsDisabled(ctx) {
+		pushCheckpointNotes(ctx, remote)
+	}
+	return nil
 }
@@ -60,6 +60,11 @@ func (s *ManualCommitStrategy) Reset(ctx context.Context) error {
 		return nil
 	}
 
+	// Save the shadow branch and session state so the reset can be undone
+	if _, err := s.SaveRecoveryPoint(ctx, "reset of "+shadowBranchName); err != nil {
+		return fmt.Errorf("failed to save recovery point: %w", err)
+	}
+
 	// Clear all sessions for this commit
 	clearedSessions := make([]string, 0)
 	for _, state := range sessions {
@@ -100,6 +105,11 @@ func (s *ManualCommitStrategy) ResetSession(ctx context.Context, sessionID strin
 		return fmt.Errorf("session not found: %s", sessionID)
 	}
 
+	// Save the shadow branch and session state so the reset can be undone
+	if _, err := s.SaveRecoveryPoint(ctx, "reset of session "+sessionID); err != nil {
+		return fmt.Errorf("failed to save recovery point: %w", err)
+	}
+
 	// Clear the session state file
 	if err := s.clearSessionState(ctx, sessionID); err != nil {
 		return fmt.Errorf("failed to clear session state: %w", err)
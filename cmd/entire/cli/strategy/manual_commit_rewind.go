@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -250,9 +252,15 @@ func (s *ManualCommitStrategy) GetLogsOnlyRewindPoints(ctx context.Context, limi
 }
 
 // Rewind restores the working directory to a checkpoint.
-//
-
 func (s *ManualCommitStrategy) Rewind(ctx context.Context, point RewindPoint) error {
+	return s.RewindWithOptions(ctx, point, RewindOptions{})
+}
+
+// RewindWithOptions restores the working directory to a checkpoint.
+// With opts.Paths, only matching files are restored or deleted. With
+// opts.Merge, changes made since the agent's latest checkpoint are merged
+// rather than overwritten; see planMergeRewind.
+func (s *ManualCommitStrategy) RewindWithOptions(ctx context.Context, point RewindPoint, opts RewindOptions) error {
 	repo, err := OpenRepository(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to open git repository: %w", err)
@@ -270,11 +278,46 @@ func (s *ManualCommitStrategy) Rewind(ctx context.Context, point RewindPoint) er
 		return fmt.Errorf("failed to get tree: %w", err)
 	}
 
+	// Save the working tree and shadow branches so the rewind can be undone
+	if _, err := s.SaveRecoveryPoint(ctx, "rewind to "+truncateHash(point.ID)); err != nil {
+		return fmt.Errorf("failed to save recovery point: %w", err)
+	}
+
+	// Plan a merge before the shadow branch moves: its tip is the merge base
+	var mergePlan *mergeRewindPlan
+	if opts.Merge {
+		if mergePlan, err = s.planMergeRewind(ctx, repo, commit, opts); err != nil {
+			return err
+		}
+	}
+
 	// Reset the shadow branch to the rewound checkpoint
-	// This ensures the next checkpoint will only include prompts from this point forward
-	if err := s.resetShadowBranchToCheckpoint(ctx, repo, commit); err != nil {
-		// Log warning but don't fail - file restoration is the primary operation
-		fmt.Fprintf(os.Stderr, "[entire] Warning: failed to reset shadow branch: %v\n", err)
+	// This ensures the next checkpoint will only include prompts from this point forward.
+	// A partial rewind keeps the session's transcript and the other files, so the
+	// shadow branch stays where it is.
+	if len(opts.Paths) == 0 {
+		if err := s.resetShadowBranchToCheckpoint(ctx, repo, commit); err != nil {
+			// Log warning but don't fail - file restoration is the primary operation
+			fmt.Fprintf(os.Stderr, "[entire] Warning: failed to reset shadow branch: %v\n", err)
+		}
+	}
+
+	if mergePlan != nil {
+		shortID := truncateHash(point.ID)
+		conflicts, err := mergePlan.apply(ctx, "checkpoint "+shortID)
+		if err != nil {
+			return err
+		}
+		fmt.Println()
+		fmt.Printf("Merged files from shadow commit %s\n", shortID)
+		for _, name := range conflicts {
+			fmt.Printf("CONFLICT: %s\n", name)
+		}
+		if len(conflicts) > 0 {
+			fmt.Printf("Resolve the conflicts in %d file(s) before continuing.\n", len(conflicts))
+		}
+		fmt.Println()
+		return nil
 	}
 
 	// Load session state to get untracked files that existed at session start
@@ -296,7 +339,7 @@ func (s *ManualCommitStrategy) Rewind(ctx context.Context, point RewindPoint) er
 		if err := ctx.Err(); err != nil {
 			return err //nolint:wrapcheck // Propagating context cancellation
 		}
-		if !strings.HasPrefix(f.Name, entireDir) {
+		if !strings.HasPrefix(f.Name, entireDir) && matchesPathspecs(f.Name, opts.Paths) {
 			checkpointFiles[f.Name] = true
 		}
 		return nil
@@ -345,6 +388,11 @@ func (s *ManualCommitStrategy) Rewind(ctx context.Context, point RewindPoint) er
 		fmt.Fprintf(os.Stderr, "Warning: error listing untracked files: %v\n", err)
 	}
 	for _, relPath := range untrackedNow {
+		// If file is outside the requested paths, leave it alone
+		if !matchesPathspecs(relPath, opts.Paths) {
+			continue
+		}
+
 		// If file is in checkpoint, it will be restored
 		if checkpointFiles[relPath] {
 			continue
@@ -372,8 +420,8 @@ func (s *ManualCommitStrategy) Rewind(ctx context.Context, point RewindPoint) er
 		if err := ctx.Err(); err != nil {
 			return err //nolint:wrapcheck // Propagating context cancellation
 		}
-		// Skip metadata directories - these are for checkpoint storage, not working dir
-		if strings.HasPrefix(f.Name, entireDir) {
+		// Skip metadata directories and files outside the requested paths
+		if !checkpointFiles[f.Name] {
 			return nil
 		}
 
@@ -383,12 +431,11 @@ func (s *ManualCommitStrategy) Rewind(ctx context.Context, point RewindPoint) er
 		}
 
 		// Ensure directory exists
-		dir := filepath.Dir(f.Name)
-		if dir != "." {
-			//nolint:gosec // G301: Need 0o755 for user directories during rewind
-			if err := os.MkdirAll(dir, 0o755); err != nil {
-				return fmt.Errorf("failed to create directory %s: %w", dir, err)
-			}
+		absPath := filepath.Join(repoRoot, f.Name)
+		dir := filepath.Dir(absPath)
+		//nolint:gosec // G301: Need 0o755 for user directories during rewind
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
 		}
 
 		// Write file with appropriate permissions
@@ -396,7 +443,7 @@ func (s *ManualCommitStrategy) Rewind(ctx context.Context, point RewindPoint) er
 		if f.Mode == filemode.Executable {
 			perm = 0o755
 		}
-		if err := os.WriteFile(f.Name, []byte(contents), perm); err != nil {
+		if err := os.WriteFile(absPath, []byte(contents), perm); err != nil {
 			return fmt.Errorf("failed to write file %s: %w", f.Name, err)
 		}
 
@@ -407,11 +454,15 @@ func (s *ManualCommitStrategy) Rewind(ctx context.Context, point RewindPoint) er
 		return fmt.Errorf("failed to iterate tree files: %w", err)
 	}
 
+	shortID := point.ID
+	if len(shortID) >= 7 {
+		shortID = shortID[:7]
+	}
 	fmt.Println()
-	if len(point.ID) >= 7 {
-		fmt.Printf("Restored files from shadow commit %s\n", point.ID[:7])
+	if len(opts.Paths) > 0 {
+		fmt.Printf("Restored %s from shadow commit %s\n", strings.Join(opts.Paths, " "), shortID)
 	} else {
-		fmt.Printf("Restored files from shadow commit %s\n", point.ID)
+		fmt.Printf("Restored files from shadow commit %s\n", shortID)
 	}
 	fmt.Println()
 
@@ -462,6 +513,13 @@ func (s *ManualCommitStrategy) CanRewind(ctx context.Context) (bool, string, err
 // PreviewRewind returns what will happen if rewinding to the given point.
 // This allows showing warnings about untracked files that will be deleted.
 func (s *ManualCommitStrategy) PreviewRewind(ctx context.Context, point RewindPoint) (*RewindPreview, error) {
+	return s.PreviewRewindWithOptions(ctx, point, RewindOptions{})
+}
+
+// PreviewRewindWithOptions is PreviewRewind for RewindWithOptions: with
+// opts.Paths, only matching files are listed, and with opts.Merge, files kept
+// from the working tree aren't.
+func (s *ManualCommitStrategy) PreviewRewindWithOptions(ctx context.Context, point RewindPoint, opts RewindOptions) (*RewindPreview, error) {
 	// Logs-only points don't modify the working directory
 	if point.IsLogsOnly {
 		return &RewindPreview{}, nil
@@ -479,6 +537,16 @@ func (s *ManualCommitStrategy) PreviewRewind(ctx context.Context, point RewindPo
 		return nil, fmt.Errorf("failed to get commit: %w", err)
 	}
 
+	if opts.Merge {
+		plan, err := s.planMergeRewind(ctx, repo, commit, opts)
+		if err != nil {
+			return nil, err
+		}
+		filesToRestore := append(slices.Clone(plan.take), plan.merge...)
+		sort.Strings(filesToRestore)
+		return &RewindPreview{FilesToRestore: filesToRestore, FilesToDelete: plan.remove}, nil
+	}
+
 	tree, err := commit.Tree()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tree: %w", err)
@@ -504,7 +572,7 @@ func (s *ManualCommitStrategy) PreviewRewind(ctx context.Context, point RewindPo
 		if err := ctx.Err(); err != nil {
 			return err //nolint:wrapcheck // Propagating context cancellation
 		}
-		if !strings.HasPrefix(f.Name, entireDir) {
+		if !strings.HasPrefix(f.Name, entireDir) && matchesPathspecs(f.Name, opts.Paths) {
 			checkpointFiles[f.Name] = true
 			filesToRestore = append(filesToRestore, f.Name)
 		}
@@ -551,6 +619,9 @@ func (s *ManualCommitStrategy) PreviewRewind(ctx context.Context, point RewindPo
 		}, nil
 	}
 	for _, relPath := range untrackedNow {
+		if !matchesPathspecs(relPath, opts.Paths) {
+			continue
+		}
 		if checkpointFiles[relPath] {
 			continue
 		}
@@ -645,6 +716,10 @@ func (s *ManualCommitStrategy) RestoreLogsOnly(ctx context.Context, point Rewind
 			fmt.Fprintf(os.Stderr, "  Warning: failed to read session %d: %v\n", i, readErr)
 			continue
 		}
+		if content != nil && content.Encrypted && len(content.Transcript) == 0 {
+			fmt.Fprintf(os.Stderr, "  Warning: session %d transcript is encrypted and no configured identity can decrypt it, skipping\n", i)
+			continue
+		}
 		if content == nil || len(content.Transcript) == 0 {
 			continue
 		}
@@ -927,3 +1002,26 @@ func PromptOverwriteNewerLogs(sessions []SessionRestoreInfo) (bool, error) {
 
 	return confirmed, nil
 }
+
+// matchesPathspecs reports whether a repo-relative, slash-separated file path
+// matches any of the pathspecs. A pathspec matches the file itself, everything
+// under it when it names a directory ("." is the whole tree), or, as a glob,
+// the file or one of its parent directories. No pathspecs matches everything.
+func matchesPathspecs(name string, specs []string) bool {
+	if len(specs) == 0 {
+		return true
+	}
+	name = filepath.ToSlash(name)
+	for _, spec := range specs {
+		spec = strings.TrimSuffix(filepath.ToSlash(spec), "/")
+		if spec == "." || spec == "" || name == spec || strings.HasPrefix(name, spec+"/") {
+			return true
+		}
+		for prefix := name; prefix != "."; prefix = path.Dir(prefix) {
+			if ok, err := path.Match(spec, prefix); err == nil && ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,265 @@
+package strategy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// mergeRewindPlan is what a merge rewind does to each file that differs between
+// the working tree ("ours") and the checkpoint ("theirs"), relative to the
+// merge base.
+type mergeRewindPlan struct {
+	// take are files unchanged in the working tree since the merge base: the
+	// checkpoint's version is written.
+	take []string
+
+	// remove are files unchanged in the working tree since the merge base that
+	// the checkpoint doesn't have.
+	remove []string
+
+	// merge are files changed on both sides, merged line by line.
+	merge []string
+
+	base, ours, theirs map[string]snapshotFile
+}
+
+// planMergeRewind compares the working tree and the checkpoint tree against the
+// merge base, file by file. Files only the agent changed since the merge base
+// are rolled back; files a human changed are kept, or merged when the rewind
+// changes them too. Protected directories and ignored files are left alone.
+//
+// The merge base is the agent's latest state: the tip of the session's shadow
+// branch. Against the session base commit instead, the working tree would still
+// carry the agent's later edits as "ours" and the merge would keep them. When
+// there is no shadow branch, the session base commit (or HEAD) is used.
+func (s *ManualCommitStrategy) planMergeRewind(ctx context.Context, repo *git.Repository, commit *object.Commit, opts RewindOptions) (*mergeRewindPlan, error) {
+	baseHash, err := s.mergeRewindBase(ctx, repo, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &mergeRewindPlan{}
+	if plan.base, err = commitSnapshot(ctx, repo, baseHash); err != nil {
+		return nil, err
+	}
+	if plan.theirs, err = commitSnapshot(ctx, repo, commit.Hash); err != nil {
+		return nil, err
+	}
+	if plan.ours, err = worktreeSnapshot(ctx); err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{}, len(plan.ours)+len(plan.theirs))
+	for name := range plan.ours {
+		names[name] = struct{}{}
+	}
+	for name := range plan.theirs {
+		names[name] = struct{}{}
+	}
+	for name := range names {
+		if !matchesPathspecs(name, opts.Paths) {
+			continue
+		}
+		base, inBase := plan.base[name]
+		ours, inOurs := plan.ours[name]
+		theirs, inTheirs := plan.theirs[name]
+		switch {
+		case sameSnapshotFile(ours, inOurs, theirs, inTheirs):
+			continue
+		case sameSnapshotFile(ours, inOurs, base, inBase) && inTheirs:
+			plan.take = append(plan.take, name)
+		case sameSnapshotFile(ours, inOurs, base, inBase):
+			plan.remove = append(plan.remove, name)
+		case sameSnapshotFile(theirs, inTheirs, base, inBase):
+			continue // Only the working tree changed: keep it
+		default:
+			plan.merge = append(plan.merge, name)
+		}
+	}
+	sort.Strings(plan.take)
+	sort.Strings(plan.remove)
+	sort.Strings(plan.merge)
+	return plan, nil
+}
+
+// mergeRewindBase returns the merge base for a merge rewind to commit.
+func (s *ManualCommitStrategy) mergeRewindBase(ctx context.Context, repo *git.Repository, commit *object.Commit) (plumbing.Hash, error) {
+	if sessionID, ok := trailers.ParseSession(commit.Message); ok {
+		state, err := s.loadSessionState(ctx, sessionID)
+		if err == nil && state != nil {
+			shadowBranchName := getShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)
+			if ref, err := repo.Reference(plumbing.NewBranchReferenceName(shadowBranchName), true); err == nil {
+				return ref.Hash(), nil
+			}
+			return plumbing.NewHash(state.BaseCommit), nil
+		}
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	return head.Hash(), nil
+}
+
+// apply carries out the plan and returns the files left with conflicts.
+// A file changed on both sides is merged with git merge-file, which writes
+// conflict markers where the changes overlap. A file deleted on one side and
+// changed on the other, or a binary file, keeps the working tree's version
+// (or the checkpoint's, if the working tree deleted it) and is reported as
+// conflicted.
+func (p *mergeRewindPlan) apply(ctx context.Context, checkpointLabel string) ([]string, error) {
+	repoRoot, err := paths.WorktreeRoot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree root: %w", err)
+	}
+
+	for _, name := range p.remove {
+		if err := os.Remove(filepath.Join(repoRoot, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to delete %s: %w", name, err)
+		}
+		fmt.Fprintf(os.Stderr, "  Deleted: %s\n", name)
+	}
+
+	for _, name := range p.take {
+		theirs := p.theirs[name]
+		content, err := theirs.read()
+		if err != nil {
+			return nil, err
+		}
+		if err := writeWorktreeFile(repoRoot, name, content, theirs.mode); err != nil {
+			return nil, err
+		}
+	}
+
+	var conflicts []string
+	for _, name := range p.merge {
+		if err := ctx.Err(); err != nil {
+			return nil, err //nolint:wrapcheck // Propagating context cancellation
+		}
+		ours, inOurs := p.ours[name]
+		theirs, inTheirs := p.theirs[name]
+		if !inOurs {
+			// Deleted in the working tree, changed by the rewind: bring it back
+			content, err := theirs.read()
+			if err != nil {
+				return nil, err
+			}
+			if err := writeWorktreeFile(repoRoot, name, content, theirs.mode); err != nil {
+				return nil, err
+			}
+			conflicts = append(conflicts, name)
+			continue
+		}
+		if !inTheirs {
+			conflicts = append(conflicts, name) // Changed in the working tree, deleted by the rewind
+			continue
+		}
+
+		var baseContent []byte
+		if base, inBase := p.base[name]; inBase {
+			if baseContent, err = base.read(); err != nil {
+				return nil, err
+			}
+		}
+		oursContent, err := ours.read()
+		if err != nil {
+			return nil, err
+		}
+		theirsContent, err := theirs.read()
+		if err != nil {
+			return nil, err
+		}
+		if isBinaryContent(baseContent) || isBinaryContent(oursContent) || isBinaryContent(theirsContent) {
+			conflicts = append(conflicts, name)
+			continue
+		}
+
+		merged, clean, err := mergeFileContents(ctx, oursContent, baseContent, theirsContent, checkpointLabel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge %s: %w", name, err)
+		}
+		if err := writeWorktreeFile(repoRoot, name, merged, ours.mode); err != nil {
+			return nil, err
+		}
+		if !clean {
+			conflicts = append(conflicts, name)
+		}
+	}
+	return conflicts, nil
+}
+
+// mergeFileContents runs a three-way merge with git merge-file. Returns the
+// merged content and whether it merged without conflicts.
+func mergeFileContents(ctx context.Context, ours, base, theirs []byte, theirsLabel string) ([]byte, bool, error) {
+	dir, err := os.MkdirTemp("", "entire-merge-")
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := make([]string, 0, 3)
+	for i, content := range [][]byte{ours, base, theirs} {
+		path := filepath.Join(dir, fmt.Sprintf("%d", i))
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			return nil, false, fmt.Errorf("failed to write temp file: %w", err)
+		}
+		files = append(files, path)
+	}
+
+	var stdout, stderr bytes.Buffer
+	//nolint:gosec // G204: Arguments are temp file paths and a fixed label
+	cmd := exec.CommandContext(ctx, "git", "merge-file", "-p",
+		"-L", "working tree", "-L", "merge base", "-L", theirsLabel,
+		files[0], files[1], files[2])
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err == nil {
+		return stdout.Bytes(), true, nil
+	}
+	// A positive exit code below 128 is the number of conflicts
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128 {
+		return stdout.Bytes(), false, nil
+	}
+	return nil, false, fmt.Errorf("git merge-file failed: %s: %w", bytes.TrimSpace(stderr.Bytes()), err)
+}
+
+// writeWorktreeFile writes a repo-relative file with the mode from a tree entry.
+func writeWorktreeFile(repoRoot, name string, content []byte, mode filemode.FileMode) error {
+	absPath := filepath.Join(repoRoot, name)
+	//nolint:gosec // G301: Need 0o755 for user directories during rewind
+	if err := os.MkdirAll(filepath.Dir(absPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", name, err)
+	}
+	var perm os.FileMode = 0o644
+	if mode == filemode.Executable {
+		perm = 0o755
+	}
+	if err := os.WriteFile(absPath, content, perm); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", name, err)
+	}
+	return nil
+}
+
+// sameSnapshotFile reports whether two sides have the same file, or both lack it.
+func sameSnapshotFile(a snapshotFile, inA bool, b snapshotFile, inB bool) bool {
+	if inA != inB {
+		return false
+	}
+	return !inA || (a.hash == b.hash && a.mode == b.mode)
+}
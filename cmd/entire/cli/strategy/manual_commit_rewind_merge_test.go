@@ -0,0 +1,115 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestShadowStrategy_RewindWithOptions_Merge(t *testing.T) {
+	// Uses t.Chdir, so cannot be parallel.
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+	t.Chdir(dir)
+	paths.ClearWorktreeRootCache()
+	t.Cleanup(paths.ClearWorktreeRootCache)
+
+	commitFile(t, repo, dir, "a.txt", "1\n2\n3\n4\n5\n")
+	commitFile(t, repo, dir, "b.txt", "x\n")
+	base := commitFile(t, repo, dir, "human.txt", "h\n")
+
+	// The agent's checkpoints: the rewind target, then its latest state
+	const sessionID = "merge-session"
+	trailer := "\n\nEntire-Session: " + sessionID + "\n"
+	target := commitWithMessage(t, repo, commitFile(t, repo, dir, "a.txt", "one\n2\n3\n4\n5\n"), "Checkpoint 1"+trailer)
+	commitFile(t, repo, dir, "a.txt", "one\n2\n3\n4\nfive\n")
+	commitFile(t, repo, dir, "b.txt", "agent\n")
+	latest := commitWithMessage(t, repo, commitFile(t, repo, dir, "new.txt", "agent new\n"), "Checkpoint 2"+trailer)
+
+	shadowRef := plumbing.NewBranchReferenceName(getShadowBranchNameForCommit(base.String(), ""))
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(shadowRef, latest)); err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := worktree.Reset(&git.ResetOptions{Commit: base, Mode: git.HardReset}); err != nil {
+		t.Fatalf("failed to reset to base: %v", err)
+	}
+
+	// The working tree: the agent's latest state plus human edits
+	for name, content := range map[string]string{
+		"a.txt":     "one\n2\nthree\n4\nfive\n", // Doesn't overlap the agent's edits
+		"b.txt":     "human\n",                  // Overlaps the agent's edit
+		"new.txt":   "agent new\n",              // Untouched since the agent wrote it
+		"human.txt": "h2\n",                     // Only the human changed it
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := &ManualCommitStrategy{}
+	if err := s.saveSessionState(ctx, &SessionState{
+		SessionID:    sessionID,
+		BaseCommit:   base.String(),
+		StartedAt:    time.Now(),
+		StepCount:    2,
+		WorktreePath: dir,
+	}); err != nil {
+		t.Fatalf("failed to save session state: %v", err)
+	}
+
+	point := RewindPoint{ID: target.String(), Message: "Checkpoint 1", Date: time.Now()}
+	opts := RewindOptions{Merge: true}
+
+	preview, err := s.PreviewRewindWithOptions(ctx, point, opts)
+	if err != nil {
+		t.Fatalf("PreviewRewindWithOptions() error = %v", err)
+	}
+	if want := []string{"a.txt", "b.txt"}; !slices.Equal(preview.FilesToRestore, want) {
+		t.Errorf("FilesToRestore = %v, want %v", preview.FilesToRestore, want)
+	}
+	if want := []string{"new.txt"}; !slices.Equal(preview.FilesToDelete, want) {
+		t.Errorf("FilesToDelete = %v, want %v", preview.FilesToDelete, want)
+	}
+
+	if err := s.RewindWithOptions(ctx, point, opts); err != nil {
+		t.Fatalf("RewindWithOptions() error = %v", err)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		return string(data)
+	}
+	if got, want := read("a.txt"), "one\n2\nthree\n4\n5\n"; got != want {
+		t.Errorf("a.txt = %q, want the human edit kept and the agent's later edit undone: %q", got, want)
+	}
+	if got := read("b.txt"); !strings.Contains(got, "<<<<<<< working tree\nhuman\n") || !strings.Contains(got, "x\n>>>>>>> checkpoint") {
+		t.Errorf("b.txt should have conflict markers, got %q", got)
+	}
+	if got := read("human.txt"); got != "h2\n" {
+		t.Errorf("human.txt = %q, want the human edit kept", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.txt")); !os.IsNotExist(err) {
+		t.Errorf("new.txt should be deleted, stat error = %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package strategy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// RewriteMapping is one rewritten commit from the list git passes to the
+// post-rewrite hook on stdin.
+type RewriteMapping struct {
+	Old string
+	New string
+}
+
+// ParseRewriteMappings parses the "<old-sha> <new-sha> [<extra>]" lines git
+// writes to the post-rewrite hook's stdin. Malformed lines are skipped.
+func ParseRewriteMappings(r io.Reader) ([]RewriteMapping, error) {
+	var mappings []RewriteMapping
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !plumbing.IsHash(fields[0]) || !plumbing.IsHash(fields[1]) {
+			continue
+		}
+		mappings = append(mappings, RewriteMapping{Old: fields[0], New: fields[1]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rewritten commits: %w", err)
+	}
+	return mappings, nil
+}
+
+// PostRewrite is called by the git post-rewrite hook after `git commit --amend`
+// (rewriteType "amend") or `git rebase` ("rebase"), with git's old→new commit
+// mapping. Squashed commits map several old commits to the same new one.
+//
+// It moves the shadow branches and session base commits off the rewritten
+// commits, and records the new commits on the checkpoints linked to the old
+// ones so `entire explain --commit` still finds them when the new commit has
+// lost or merged their Entire-Checkpoint trailers.
+func (s *ManualCommitStrategy) PostRewrite(ctx context.Context, rewriteType string, mappings []RewriteMapping) error { //nolint:unparam // error return is part of the hook contract; callers check it
+	logCtx := logging.WithComponent(ctx, "checkpoint")
+	if len(mappings) == 0 {
+		return nil
+	}
+
+	repo, err := OpenRepository(ctx)
+	if err != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+
+	rewritten := make(map[string]string, len(mappings))
+	for _, m := range mappings {
+		rewritten[m.Old] = m.New
+	}
+
+	s.migrateRewrittenSessions(ctx, repo, rewritten)
+
+	links := rewrittenCheckpointLinks(ctx, repo, mappings)
+	if len(links) == 0 {
+		return nil
+	}
+	updated, err := checkpoint.NewGitStore(repo).LinkRewrittenCommits(ctx, links)
+	if err != nil {
+		logging.Warn(logCtx, "post-rewrite: failed to link rewritten commits",
+			slog.String("rewrite_type", rewriteType),
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+	logging.Debug(logCtx, "post-rewrite: linked rewritten commits",
+		slog.String("rewrite_type", rewriteType),
+		slog.Int("checkpoints", len(updated)),
+	)
+	return nil
+}
+
+// migrateRewrittenSessions moves the shadow branch and base commits of this
+// worktree's sessions from rewritten commits to their replacements.
+func (s *ManualCommitStrategy) migrateRewrittenSessions(ctx context.Context, repo *git.Repository, rewritten map[string]string) {
+	logCtx := logging.WithComponent(ctx, "checkpoint")
+	worktreePath, err := paths.WorktreeRoot(ctx)
+	if err != nil {
+		return // Silent failure — hooks must be resilient
+	}
+	sessions, err := s.findSessionsForWorktree(ctx, worktreePath)
+	if err != nil || len(sessions) == 0 {
+		return
+	}
+
+	for _, state := range sessions {
+		changed := false
+		if newBase, ok := rewritten[state.BaseCommit]; ok {
+			oldShadowBranch := checkpoint.ShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)
+			newShadowBranch := checkpoint.ShadowBranchNameForCommit(newBase, state.WorktreeID)
+			if _, err := moveShadowBranch(ctx, repo, oldShadowBranch, newShadowBranch); err != nil {
+				fmt.Fprintf(os.Stderr, "[entire] Warning: failed to move shadow branch %s: %v\n", oldShadowBranch, err)
+				continue
+			}
+			logging.Debug(logCtx, "post-rewrite: updating BaseCommit",
+				slog.String("session_id", state.SessionID),
+				slog.String("old_base", truncateHash(state.BaseCommit)),
+				slog.String("new_base", truncateHash(newBase)),
+			)
+			state.BaseCommit = newBase
+			changed = true
+		}
+		if newBase, ok := rewritten[state.AttributionBaseCommit]; ok {
+			state.AttributionBaseCommit = newBase
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		if err := s.saveSessionState(ctx, state); err != nil {
+			fmt.Fprintf(os.Stderr, "[entire] Warning: failed to update session state: %v\n", err)
+		}
+	}
+}
+
+// rewrittenCheckpointLinks maps the checkpoints linked to each rewritten commit
+// to its replacement. Checkpoints come from the old commit's trailers and note,
+// and from earlier rewrites recorded on the metadata branch, so links survive
+// repeated amends. Replacements that still carry a checkpoint's trailer don't
+// need a link; when git notes are enabled, a replacement without any link
+// gets a note for its first checkpoint.
+func rewrittenCheckpointLinks(ctx context.Context, repo *git.Repository, mappings []RewriteMapping) map[id.CheckpointID][]string {
+	store := checkpoint.NewGitStore(repo)
+	links := make(map[id.CheckpointID][]string)
+	for _, m := range mappings {
+		oldCommit, err := repo.CommitObject(plumbing.NewHash(m.Old))
+		if err != nil {
+			continue
+		}
+		newCommit, err := repo.CommitObject(plumbing.NewHash(m.New))
+		if err != nil {
+			continue
+		}
+
+		cpIDs := trailers.ParseAllCheckpoints(oldCommit.Message)
+		if cpID, found := ReadCheckpointNote(repo, oldCommit.Hash); found && !slices.Contains(cpIDs, cpID) {
+			cpIDs = append(cpIDs, cpID)
+		}
+		if earlier, err := store.CheckpointsForCommit(ctx, m.Old); err == nil {
+			for _, cpID := range earlier {
+				if !slices.Contains(cpIDs, cpID) {
+					cpIDs = append(cpIDs, cpID)
+				}
+			}
+		}
+		if len(cpIDs) == 0 {
+			continue
+		}
+
+		kept := trailers.ParseAllCheckpoints(newCommit.Message)
+		for _, cpID := range cpIDs {
+			if !slices.Contains(kept, cpID) && !slices.Contains(links[cpID], m.New) {
+				links[cpID] = append(links[cpID], m.New)
+			}
+		}
+		if len(kept) == 0 {
+			if _, found := ReadCheckpointNote(repo, newCommit.Hash); !found {
+				writeCheckpointNoteIfEnabled(ctx, repo, newCommit.Hash, cpIDs[0])
+			}
+		}
+	}
+	return links
+}
@@ -0,0 +1,100 @@
+package strategy
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestParseRewriteMappings(t *testing.T) {
+	t.Parallel()
+	const (
+		a = "1111111111111111111111111111111111111111"
+		b = "2222222222222222222222222222222222222222"
+		c = "3333333333333333333333333333333333333333"
+	)
+	input := a + " " + c + "\n" + b + " " + c + " extra-info\n\nnot a mapping\n"
+
+	got, err := ParseRewriteMappings(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseRewriteMappings() error = %v", err)
+	}
+	want := []RewriteMapping{{Old: a, New: c}, {Old: b, New: c}}
+	if !slices.Equal(got, want) {
+		t.Errorf("ParseRewriteMappings() = %v, want %v", got, want)
+	}
+}
+
+func TestRewrittenCheckpointLinks(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+	base := commitFile(t, repo, dir, "a.txt", "base")
+
+	store := checkpoint.NewGitStore(repo)
+	cp1 := id.MustCheckpointID("111111111111")
+	cp2 := id.MustCheckpointID("222222222222")
+	for _, cpID := range []id.CheckpointID{cp1, cp2} {
+		if err := store.WriteCommitted(ctx, checkpoint.WriteCommittedOptions{
+			CheckpointID: cpID,
+			SessionID:    "session-" + cpID.String(),
+			Strategy:     StrategyNameManualCommit,
+			Transcript:   []byte(`{"type":"user","message":{"content":"hi"}}` + "\n"),
+			AuthorName:   "Test",
+			AuthorEmail:  "test@test.com",
+		}); err != nil {
+			t.Fatalf("WriteCommitted() error = %v", err)
+		}
+	}
+
+	first := commitWithMessage(t, repo, base, trailers.FormatCheckpoint("First change", cp1))
+	second := commitWithMessage(t, repo, first, trailers.FormatCheckpoint("Second change", cp2))
+	// A fixup squash keeps only the first commit's message and trailer.
+	squashed := commitWithMessage(t, repo, base, trailers.FormatCheckpoint("First change", cp1))
+
+	links := rewrittenCheckpointLinks(ctx, repo, []RewriteMapping{
+		{Old: first.String(), New: squashed.String()},
+		{Old: second.String(), New: squashed.String()},
+	})
+	if len(links) != 1 || !slices.Equal(links[cp2], []string{squashed.String()}) {
+		t.Fatalf("links after squash = %v, want only %s -> %s", links, cp2, squashed)
+	}
+	if _, err := store.LinkRewrittenCommits(ctx, links); err != nil {
+		t.Fatalf("LinkRewrittenCommits() error = %v", err)
+	}
+
+	// Amending away the trailer links both checkpoints to the new commit:
+	// cp1 from the old trailer, cp2 from the link recorded by the squash.
+	amended := commitWithMessage(t, repo, base, "Combined change\n")
+	links = rewrittenCheckpointLinks(ctx, repo, []RewriteMapping{{Old: squashed.String(), New: amended.String()}})
+	for _, cpID := range []id.CheckpointID{cp1, cp2} {
+		if !slices.Equal(links[cpID], []string{amended.String()}) {
+			t.Errorf("links after amend[%s] = %v, want [%s]", cpID, links[cpID], amended)
+		}
+	}
+}
+
+func commitWithMessage(t *testing.T, repo *git.Repository, parent plumbing.Hash, message string) plumbing.Hash {
+	t.Helper()
+	parentCommit, err := repo.CommitObject(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := createMergeCommitCommon(repo, parentCommit.TreeHash, []plumbing.Hash{parent}, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
@@ -0,0 +1,95 @@
+package strategy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// MetadataRemote returns the remote that checkpoint metadata is fetched from and
+// pushed to: strategy_options.metadata_remote when set, otherwise codeRemote.
+// The result is either a configured remote name or a URL.
+func MetadataRemote(ctx context.Context, codeRemote string) string {
+	s, err := settings.Load(ctx)
+	if err != nil {
+		return codeRemote
+	}
+	if remote := s.GetMetadataRemote(); remote != "" {
+		return remote
+	}
+	return codeRemote
+}
+
+// metadataPushTargets returns every remote a push of checkpoint metadata should
+// reach: the metadata remote followed by any strategy_options.metadata_mirrors,
+// without duplicates.
+func metadataPushTargets(ctx context.Context, codeRemote string) []string {
+	targets := []string{MetadataRemote(ctx, codeRemote)}
+	s, err := settings.Load(ctx)
+	if err != nil {
+		return targets
+	}
+	for _, mirror := range s.GetMetadataMirrors() {
+		if !slices.Contains(targets, mirror) {
+			targets = append(targets, mirror)
+		}
+	}
+	return targets
+}
+
+// isRemoteURL reports whether remote is a URL or path rather than a remote name.
+// Remote names can't contain ':' or start with '/' or '.', since they must be
+// valid in refspecs.
+func isRemoteURL(remote string) bool {
+	return strings.Contains(remote, ":") || strings.HasPrefix(remote, "/") || strings.HasPrefix(remote, ".")
+}
+
+// MetadataTrackingName returns the name under refs/remotes/ that tracks the
+// metadata branch of remote. Named remotes use their own name. URLs, which
+// git doesn't track, get a stable name derived from the URL.
+func MetadataTrackingName(remote string) string {
+	if !isRemoteURL(remote) {
+		return remote
+	}
+	sum := sha256.Sum256([]byte(remote))
+	return "entire-metadata-" + hex.EncodeToString(sum[:4])
+}
+
+// validateMetadataRemote returns an error when remote is neither a URL nor a
+// remote configured in repo.
+func validateMetadataRemote(repo *git.Repository, remote string) error {
+	if isRemoteURL(remote) {
+		return nil
+	}
+	if _, err := repo.Remote(remote); err != nil {
+		return fmt.Errorf("remote %q not found: %w", remote, err)
+	}
+	return nil
+}
+
+// recordPushedMetadata points the tracking ref for a URL remote at the branch
+// that was just pushed, so later pushes can tell there is nothing new to send.
+// git updates the tracking refs of named remotes itself.
+func recordPushedMetadata(ctx context.Context, remote, branchName string) {
+	if !isRemoteURL(remote) {
+		return
+	}
+	repo, err := OpenRepository(ctx)
+	if err != nil {
+		return
+	}
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(branchName), true)
+	if err != nil {
+		return
+	}
+	trackingRef := plumbing.NewHashReference(plumbing.NewRemoteReferenceName(MetadataTrackingName(remote), branchName), ref.Hash())
+	_ = repo.Storer.SetReference(trackingRef) //nolint:errcheck // Best-effort; only saves a redundant push
+}
@@ -0,0 +1,112 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestMetadataTrackingName(t *testing.T) {
+	t.Parallel()
+
+	for _, remote := range []string{"origin", "team/private"} {
+		if got := MetadataTrackingName(remote); got != remote {
+			t.Errorf("MetadataTrackingName(%q) = %q, want the remote name", remote, got)
+		}
+	}
+
+	url := "git@github.com:acme/private-checkpoints.git"
+	name := MetadataTrackingName(url)
+	if name == url || name != MetadataTrackingName(url) {
+		t.Errorf("MetadataTrackingName(%q) = %q, want a stable derived name", url, name)
+	}
+	if other := MetadataTrackingName("/srv/git/checkpoints.git"); other == name {
+		t.Error("different URLs should get different tracking names")
+	}
+}
+
+func TestPrePush_DedicatedMetadataRemote(t *testing.T) {
+	ctx := context.Background()
+	originDir := t.TempDir()
+	runGit(t, originDir, "init", "--quiet", "--bare")
+	privateDir := t.TempDir()
+	runGit(t, privateDir, "init", "--quiet", "--bare")
+	backupDir := t.TempDir()
+	runGit(t, backupDir, "init", "--quiet", "--bare")
+
+	dir := cloneForSync(t, originDir, "alice", "eeeeeeeeeeee")
+	runGit(t, dir, "remote", "add", "backup", backupDir)
+	t.Chdir(dir)
+
+	settingsJSON := `{"strategy_options": {"metadata_remote": "` + privateDir + `", "metadata_mirrors": ["backup"], "git_notes": true}}`
+	if err := os.MkdirAll(filepath.Join(dir, ".entire"), 0o755); err != nil {
+		t.Fatalf("failed to create .entire: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".entire", "settings.json"), []byte(settingsJSON), 0o644); err != nil {
+		t.Fatalf("failed to write settings: %v", err)
+	}
+
+	if got := MetadataRemote(ctx, "origin"); got != privateDir {
+		t.Fatalf("MetadataRemote() = %q, want %q", got, privateDir)
+	}
+
+	// Notes go wherever the checkpoints go.
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	local, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	if err != nil {
+		t.Fatalf("metadata branch missing: %v", err)
+	}
+	if err := WriteCheckpointNote(ctx, repo, local.Hash(), id.MustCheckpointID("eeeeeeeeeeee")); err != nil {
+		t.Fatalf("WriteCheckpointNote() error = %v", err)
+	}
+
+	s := &ManualCommitStrategy{}
+	if err := s.PrePush(ctx, "origin"); err != nil {
+		t.Fatalf("PrePush() error = %v", err)
+	}
+
+	hasRef := func(bareDir, refName string) bool {
+		cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", refName)
+		cmd.Dir = bareDir
+		return cmd.Run() == nil
+	}
+	for _, refName := range []string{"refs/heads/" + paths.MetadataBranchName, paths.CheckpointNotesRef} {
+		if hasRef(originDir, refName) {
+			t.Errorf("%s was pushed to the code remote", refName)
+		}
+		if !hasRef(privateDir, refName) {
+			t.Errorf("%s was not pushed to the metadata remote", refName)
+		}
+		if !hasRef(backupDir, refName) {
+			t.Errorf("%s was not pushed to the mirror", refName)
+		}
+	}
+
+	// The URL remote's tracking ref is recorded, so there is nothing left to push.
+	if hasUnpushedSessionsCommon(repo, privateDir, local.Hash(), paths.MetadataBranchName) {
+		t.Error("expected no unpushed sessions after pushing to the metadata remote")
+	}
+
+	// A second clone reads the metadata remote by URL.
+	bob := filepath.Join(t.TempDir(), "bob")
+	runGit(t, filepath.Dir(bob), "clone", "--quiet", originDir, "bob")
+	t.Chdir(bob)
+	result, err := SyncMetadataBranch(ctx, privateDir, false)
+	if err != nil {
+		t.Fatalf("SyncMetadataBranch() error = %v", err)
+	}
+	if result.NewCheckpoints != 1 {
+		t.Errorf("sync = %+v, want 1 new checkpoint from the metadata remote", result)
+	}
+}
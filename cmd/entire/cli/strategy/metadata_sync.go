@@ -0,0 +1,394 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// SyncResult describes what SyncMetadataBranch changed.
+type SyncResult struct {
+	Remote string
+	// RemoteMissing is true when the remote has no metadata branch yet.
+	RemoteMissing bool
+	// NewCheckpoints and UpdatedCheckpoints count checkpoints that appeared or
+	// changed locally as a result of the sync.
+	NewCheckpoints     int
+	UpdatedCheckpoints int
+	// Merged is true when local and remote had diverged and a merge commit was created.
+	Merged bool
+	// Pushed is true when the local branch was pushed to the remote.
+	Pushed bool
+	// NotesRebuilt is true when a resync rebuilt refs/notes/entire because
+	// the local notes predate a purge.
+	NotesRebuilt bool
+}
+
+// SyncMetadataBranch fetches the entire/checkpoints/v1 branch from remote and
+// brings the local branch up to date: it is created or fast-forwarded when
+// possible, and otherwise the local and remote trees are merged the same way
+// as when a pre-push is rejected. When push is true, the result is pushed back
+// if the remote is missing any of it.
+func SyncMetadataBranch(ctx context.Context, remote string, push bool) (*SyncResult, error) {
+	branchName := paths.MetadataBranchName
+	result := &SyncResult{Remote: remote}
+
+	repo, err := OpenRepository(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	if err := validateMetadataRemote(repo, remote); err != nil {
+		return nil, err
+	}
+
+	found, err := fetchMetadataRef(ctx, remote, branchName)
+	if err != nil {
+		return nil, err
+	}
+	result.RemoteMissing = !found
+
+	// Reopen so go-git picks up the packfiles the git CLI just fetched
+	repo, err = OpenRepository(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	branchRef := plumbing.NewBranchReferenceName(branchName)
+	var localHash plumbing.Hash
+	if ref, refErr := repo.Reference(branchRef, true); refErr == nil {
+		localHash = ref.Hash()
+	}
+
+	var remoteHash plumbing.Hash
+	if found {
+		remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName(MetadataTrackingName(remote), branchName), true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fetched %s: %w", branchName, err)
+		}
+		remoteHash = remoteRef.Hash()
+
+		newHash, merged, err := reconcileMetadataCommits(repo, localHash, remoteHash)
+		if err != nil {
+			return nil, err
+		}
+		result.Merged = merged
+
+		if newHash != localHash {
+			if err := moveMetadataBranch(repo, result, localHash, newHash); err != nil {
+				return nil, err
+			}
+			localHash = newHash
+		}
+	}
+
+	if push && !localHash.IsZero() && localHash != remoteHash {
+		if err := tryPushSessionsCommon(ctx, remote, branchName); err != nil {
+			return result, fmt.Errorf("failed to push %s to %s: %w", branchName, remote, err)
+		}
+		result.Pushed = true
+	}
+
+	return result, nil
+}
+
+// ResyncMetadataBranch replaces the local metadata branch with the one on
+// remote. It is the way out when the remote was rewritten by 'entire purge'
+// or 'entire gc' and merging would restore the removed content. Checkpoints that only exist
+// locally are kept in a commit on top of the remote branch, and checkpoint
+// notes that predate the purge are rebuilt from the new branch.
+func ResyncMetadataBranch(ctx context.Context, remote string) (*SyncResult, error) {
+	branchName := paths.MetadataBranchName
+	result := &SyncResult{Remote: remote}
+
+	repo, err := OpenRepository(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	if err := validateMetadataRemote(repo, remote); err != nil {
+		return nil, err
+	}
+	found, err := fetchMetadataRef(ctx, remote, branchName)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		result.RemoteMissing = true
+		return result, nil
+	}
+
+	// Reopen so go-git picks up the packfiles the git CLI just fetched
+	repo, err = OpenRepository(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName(MetadataTrackingName(remote), branchName), true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fetched %s: %w", branchName, err)
+	}
+	var localHash plumbing.Hash
+	if ref, refErr := repo.Reference(plumbing.NewBranchReferenceName(branchName), true); refErr == nil {
+		localHash = ref.Hash()
+	}
+
+	newHash, err := keepLocalOnlyCheckpoints(repo, localHash, remoteRef.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if newHash != localHash {
+		if err := moveMetadataBranch(repo, result, localHash, newHash); err != nil {
+			return nil, err
+		}
+	}
+	if result.NotesRebuilt, err = rebuildPurgedNotes(ctx, repo, newHash); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// keepLocalOnlyCheckpoints returns remoteHash, or a commit on top of it that
+// adds the checkpoints at localHash the remote doesn't have.
+func keepLocalOnlyCheckpoints(repo *git.Repository, localHash, remoteHash plumbing.Hash) (plumbing.Hash, error) {
+	local, err := checkpointTreeHashes(repo, localHash)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	remote, err := checkpointTreeHashes(repo, remoteHash)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	var localOnly []string
+	for cpID := range local {
+		if _, ok := remote[cpID]; !ok {
+			localOnly = append(localOnly, cpID)
+		}
+	}
+	if len(localOnly) == 0 {
+		return remoteHash, nil
+	}
+
+	remoteTree, err := metadataTree(repo, remoteHash)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	localTree, err := metadataTree(repo, localHash)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	entries := make(map[string]object.TreeEntry)
+	if err := checkpoint.FlattenTree(repo, remoteTree, "", entries); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to flatten remote tree: %w", err)
+	}
+	for _, cpID := range localOnly {
+		cpPath := cpID[:2] + "/" + cpID[2:]
+		cpTree, err := localTree.Tree(cpPath)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to read checkpoint %s: %w", cpID, err)
+		}
+		if err := checkpoint.FlattenTree(repo, cpTree, cpPath, entries); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to flatten checkpoint %s: %w", cpID, err)
+		}
+	}
+
+	treeHash, err := checkpoint.BuildTreeFromEntries(repo, entries)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to build tree: %w", err)
+	}
+	commitHash, err := createMergeCommitCommon(repo, treeHash, []plumbing.Hash{remoteHash},
+		fmt.Sprintf("Keep %d local checkpoint(s) after resync", len(localOnly)))
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to create commit: %w", err)
+	}
+	return commitHash, nil
+}
+
+func metadataTree(repo *git.Repository, commitHash plumbing.Hash) (*object.Tree, error) {
+	commit, err := repo.CommitObject(commitHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata tree: %w", err)
+	}
+	return tree, nil
+}
+
+// moveMetadataBranch points the local metadata branch at newHash and counts
+// the checkpoints that appeared or changed compared to localHash.
+func moveMetadataBranch(repo *git.Repository, result *SyncResult, localHash, newHash plumbing.Hash) error {
+	before, err := checkpointTreeHashes(repo, localHash)
+	if err != nil {
+		return err
+	}
+	after, err := checkpointTreeHashes(repo, newHash)
+	if err != nil {
+		return err
+	}
+	for cpID, hash := range after {
+		old, existed := before[cpID]
+		switch {
+		case !existed:
+			result.NewCheckpoints++
+		case old != hash:
+			result.UpdatedCheckpoints++
+		}
+	}
+
+	branchRef := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(branchRef, newHash)); err != nil {
+		return fmt.Errorf("failed to update %s: %w", paths.MetadataBranchName, err)
+	}
+	return nil
+}
+
+// fetchMetadataRef fetches the metadata branch into its tracking ref,
+// refs/remotes/<MetadataTrackingName(remote)>/<branch>.
+// Returns false when the remote doesn't have the branch.
+func fetchMetadataRef(ctx context.Context, remote, branchName string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	// Use git CLI for fetch (go-git's fetch can be tricky with auth)
+	refSpec := fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", branchName, MetadataTrackingName(remote), branchName)
+	cmd := exec.CommandContext(ctx, "git", "fetch", "--no-tags", remote, refSpec)
+	cmd.Stdin = nil // Disconnect stdin to prevent hanging in hook context
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return false, errors.New("fetch timed out after 2 minutes")
+		}
+		if strings.Contains(string(output), "couldn't find remote ref") {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to fetch %s from %s: %s: %w", branchName, remote, strings.TrimSpace(string(output)), err)
+	}
+	return true, nil
+}
+
+// reconcileMetadataCommits returns the commit the local metadata branch should
+// point to after taking in remoteHash, and whether a merge commit was created.
+// localHash is zero when the branch doesn't exist locally.
+func reconcileMetadataCommits(repo *git.Repository, localHash, remoteHash plumbing.Hash) (plumbing.Hash, bool, error) {
+	if localHash.IsZero() || localHash == remoteHash {
+		return remoteHash, false, nil
+	}
+
+	localCommit, err := repo.CommitObject(localHash)
+	if err != nil {
+		return plumbing.ZeroHash, false, fmt.Errorf("failed to get local commit: %w", err)
+	}
+	remoteCommit, err := repo.CommitObject(remoteHash)
+	if err != nil {
+		return plumbing.ZeroHash, false, fmt.Errorf("failed to get remote commit: %w", err)
+	}
+
+	// Local already contains everything from the remote
+	if isAncestor, err := remoteCommit.IsAncestor(localCommit); err == nil && isAncestor {
+		return localHash, false, nil
+	}
+	// Fast-forward
+	if isAncestor, err := localCommit.IsAncestor(remoteCommit); err == nil && isAncestor {
+		return remoteHash, false, nil
+	}
+
+	merged, err := mergeMetadataCommits(repo, localHash, remoteHash)
+	if err != nil {
+		return plumbing.ZeroHash, false, err
+	}
+	return merged, true, nil
+}
+
+// checkpointTreeHashes maps each checkpoint ID on the metadata branch at
+// commitHash to the hash of its directory tree (<id[:2]>/<id[2:]>/).
+// A zero commitHash yields an empty map.
+func checkpointTreeHashes(repo *git.Repository, commitHash plumbing.Hash) (map[string]plumbing.Hash, error) {
+	hashes := make(map[string]plumbing.Hash)
+	if commitHash.IsZero() {
+		return hashes, nil
+	}
+
+	commit, err := repo.CommitObject(commitHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata tree: %w", err)
+	}
+
+	for _, shard := range tree.Entries {
+		if shard.Mode != filemode.Dir || len(shard.Name) != 2 {
+			continue
+		}
+		shardTree, err := repo.TreeObject(shard.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", shard.Name, err)
+		}
+		for _, entry := range shardTree.Entries {
+			if entry.Mode == filemode.Dir {
+				hashes[shard.Name+entry.Name] = entry.Hash
+			}
+		}
+	}
+	return hashes, nil
+}
+
+// autoSyncMetadata runs SyncMetadataBranch against the metadata remote (origin
+// unless strategy_options.metadata_remote is set) from the post-merge and
+// post-checkout hooks when strategy_options.auto_sync is enabled.
+// It never pushes and never fails the hook.
+func autoSyncMetadata(ctx context.Context) {
+	s, err := settings.Load(ctx)
+	if err != nil || !s.IsAutoSyncEnabled() {
+		return
+	}
+
+	logCtx := logging.WithComponent(ctx, "sync")
+	result, err := SyncMetadataBranch(ctx, MetadataRemote(ctx, "origin"), false)
+	if err != nil {
+		logging.Warn(logCtx, "auto-sync of checkpoints failed", slog.String("error", err.Error()))
+		return
+	}
+	if result.NewCheckpoints > 0 || result.UpdatedCheckpoints > 0 {
+		fmt.Fprintf(os.Stderr, "[entire] Synced checkpoints from %s: %d new, %d updated\n",
+			result.Remote, result.NewCheckpoints, result.UpdatedCheckpoints)
+	}
+	logging.Debug(logCtx, "auto-sync completed",
+		slog.Int("new", result.NewCheckpoints),
+		slog.Int("updated", result.UpdatedCheckpoints),
+		slog.Bool("merged", result.Merged))
+}
+
+// PostMerge is called by the git post-merge hook (including after git pull).
+// It syncs checkpoints from the metadata remote when strategy_options.auto_sync is enabled.
+func (s *ManualCommitStrategy) PostMerge(ctx context.Context) error {
+	autoSyncMetadata(ctx)
+	return nil
+}
+
+// PostCheckout is called by the git post-checkout hook. File checkouts
+// (branchCheckout false) and the checkouts a rebase performs are ignored;
+// branch switches sync checkpoints from the metadata remote when
+// strategy_options.auto_sync is enabled.
+func (s *ManualCommitStrategy) PostCheckout(ctx context.Context, branchCheckout bool) error {
+	if !branchCheckout || isGitSequenceOperation(ctx) {
+		return nil
+	}
+	autoSyncMetadata(ctx)
+	return nil
+}
@@ -0,0 +1,237 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// runGit runs a git command in dir and fails the test on error.
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.CommandContext(context.Background(), "git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+}
+
+// cloneForSync clones bareDir and writes one committed checkpoint to the clone.
+func cloneForSync(t *testing.T, bareDir, name, checkpointID string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), name)
+	runGit(t, filepath.Dir(dir), "clone", "--quiet", bareDir, name)
+	runGit(t, dir, "config", "user.email", name+"@test.com")
+	runGit(t, dir, "config", "user.name", name)
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	err = checkpoint.NewGitStore(repo).WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID(checkpointID),
+		SessionID:    "session-" + name,
+		Strategy:     StrategyNameManualCommit,
+		Transcript:   []byte(`{"type":"user","message":{"content":"hi"}}` + "\n"),
+		AuthorName:   name,
+		AuthorEmail:  name + "@test.com",
+	})
+	if err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+	return dir
+}
+
+func metadataCheckpoints(t *testing.T, dir string) map[string]plumbing.Hash {
+	t.Helper()
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	if err != nil {
+		t.Fatalf("metadata branch missing: %v", err)
+	}
+	hashes, err := checkpointTreeHashes(repo, ref.Hash())
+	if err != nil {
+		t.Fatalf("checkpointTreeHashes() error = %v", err)
+	}
+	return hashes
+}
+
+func TestSyncMetadataBranch(t *testing.T) {
+	ctx := context.Background()
+	bareDir := t.TempDir()
+	runGit(t, bareDir, "init", "--quiet", "--bare")
+
+	alice := cloneForSync(t, bareDir, "alice", "aaaaaaaaaaaa")
+	runGit(t, alice, "push", "--quiet", "origin", paths.MetadataBranchName)
+	bob := cloneForSync(t, bareDir, "bob", "bbbbbbbbbbbb")
+
+	// Bob has diverged from the remote: merge, then push the result.
+	t.Chdir(bob)
+	result, err := SyncMetadataBranch(ctx, "origin", true)
+	if err != nil {
+		t.Fatalf("SyncMetadataBranch(bob) error = %v", err)
+	}
+	if result.NewCheckpoints != 1 || result.UpdatedCheckpoints != 0 || !result.Merged || !result.Pushed || result.RemoteMissing {
+		t.Errorf("bob sync = %+v, want 1 new, merged and pushed", result)
+	}
+	if got := metadataCheckpoints(t, bob); len(got) != 2 {
+		t.Errorf("bob checkpoints = %v, want both", got)
+	}
+
+	// Alice is behind: fast-forward to the merge, no push requested.
+	t.Chdir(alice)
+	result, err = SyncMetadataBranch(ctx, "origin", false)
+	if err != nil {
+		t.Fatalf("SyncMetadataBranch(alice) error = %v", err)
+	}
+	if result.NewCheckpoints != 1 || result.Merged || result.Pushed {
+		t.Errorf("alice sync = %+v, want 1 new by fast-forward", result)
+	}
+	if got := metadataCheckpoints(t, alice); len(got) != 2 {
+		t.Errorf("alice checkpoints = %v, want both", got)
+	}
+
+	// Syncing again changes nothing.
+	result, err = SyncMetadataBranch(ctx, "origin", true)
+	if err != nil {
+		t.Fatalf("SyncMetadataBranch(again) error = %v", err)
+	}
+	if result.NewCheckpoints != 0 || result.UpdatedCheckpoints != 0 || result.Merged || result.Pushed {
+		t.Errorf("repeat sync = %+v, want no changes", result)
+	}
+}
+
+func TestSyncMetadataBranch_UpdatedCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	bareDir := t.TempDir()
+	runGit(t, bareDir, "init", "--quiet", "--bare")
+
+	alice := cloneForSync(t, bareDir, "alice", "cccccccccccc")
+	runGit(t, alice, "push", "--quiet", "origin", paths.MetadataBranchName)
+	bob := filepath.Join(t.TempDir(), "bob")
+	runGit(t, filepath.Dir(bob), "clone", "--quiet", bareDir, "bob")
+
+	t.Chdir(bob)
+	if result, err := SyncMetadataBranch(ctx, "origin", false); err != nil || result.NewCheckpoints != 1 {
+		t.Fatalf("initial sync = %+v, %v; want 1 new", result, err)
+	}
+
+	// Alice adds a second session to the same checkpoint.
+	repo, err := git.PlainOpen(alice)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	err = checkpoint.NewGitStore(repo).WriteCommitted(ctx, checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("cccccccccccc"),
+		SessionID:    "session-alice-2",
+		Strategy:     StrategyNameManualCommit,
+		Transcript:   []byte(`{"type":"user","message":{"content":"more"}}` + "\n"),
+		AuthorName:   "alice",
+		AuthorEmail:  "alice@test.com",
+	})
+	if err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+	runGit(t, alice, "push", "--quiet", "origin", paths.MetadataBranchName)
+
+	result, err := SyncMetadataBranch(ctx, "origin", false)
+	if err != nil {
+		t.Fatalf("SyncMetadataBranch() error = %v", err)
+	}
+	if result.NewCheckpoints != 0 || result.UpdatedCheckpoints != 1 {
+		t.Errorf("sync = %+v, want 1 updated", result)
+	}
+}
+
+func TestSyncMetadataBranch_RemoteWithoutBranch(t *testing.T) {
+	ctx := context.Background()
+	bareDir := t.TempDir()
+	runGit(t, bareDir, "init", "--quiet", "--bare")
+	dir := cloneForSync(t, bareDir, "carol", "dddddddddddd")
+	t.Chdir(dir)
+
+	result, err := SyncMetadataBranch(ctx, "origin", false)
+	if err != nil {
+		t.Fatalf("SyncMetadataBranch() error = %v", err)
+	}
+	if !result.RemoteMissing || result.Pushed {
+		t.Errorf("sync = %+v, want remote missing and nothing pushed", result)
+	}
+
+	result, err = SyncMetadataBranch(ctx, "origin", true)
+	if err != nil {
+		t.Fatalf("SyncMetadataBranch(push) error = %v", err)
+	}
+	if !result.Pushed {
+		t.Errorf("sync = %+v, want the branch pushed to the empty remote", result)
+	}
+
+	if _, err := SyncMetadataBranch(ctx, "upstream", false); err == nil {
+		t.Error("expected error for unknown remote")
+	}
+}
+
+func TestResyncMetadataBranch(t *testing.T) {
+	ctx := context.Background()
+	bareDir := t.TempDir()
+	runGit(t, bareDir, "init", "--quiet", "--bare")
+
+	alice := cloneForSync(t, bareDir, "alice", "dddddddddddd")
+	runGit(t, alice, "push", "--quiet", "origin", paths.MetadataBranchName)
+	bob := cloneForSync(t, bareDir, "bob", "eeeeeeeeeeee")
+	t.Chdir(bob)
+	if _, err := SyncMetadataBranch(ctx, "origin", false); err != nil {
+		t.Fatalf("initial sync error = %v", err)
+	}
+
+	// Alice purges her checkpoint and replaces the remote branch.
+	repo, err := git.PlainOpen(alice)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	if _, err := checkpoint.NewGitStore(repo).Purge(ctx, checkpoint.PurgeOptions{
+		CheckpointIDs: []id.CheckpointID{id.MustCheckpointID("dddddddddddd")},
+	}); err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	runGit(t, alice, "push", "--quiet", "--force", "origin", paths.MetadataBranchName)
+	purged := metadataCheckpoints(t, alice)["dddddddddddd"]
+
+	// Bob's branch still has the purged content, so merging is refused.
+	var purgedErr *checkpoint.PurgedHistoryError
+	if _, err := SyncMetadataBranch(ctx, "origin", false); !errors.As(err, &purgedErr) || purgedErr.Local {
+		t.Fatalf("SyncMetadataBranch() error = %v, want a remote *checkpoint.PurgedHistoryError", err)
+	}
+
+	result, err := ResyncMetadataBranch(ctx, "origin")
+	if err != nil {
+		t.Fatalf("ResyncMetadataBranch() error = %v", err)
+	}
+	if result.UpdatedCheckpoints != 1 {
+		t.Errorf("resync = %+v, want 1 updated", result)
+	}
+	got := metadataCheckpoints(t, bob)
+	if got["dddddddddddd"] != purged {
+		t.Error("resync did not take the purged checkpoint from the remote")
+	}
+	if _, ok := got["eeeeeeeeeeee"]; !ok {
+		t.Error("resync dropped the local-only checkpoint")
+	}
+
+	// Bob can sync and push again.
+	if _, err := SyncMetadataBranch(ctx, "origin", true); err != nil {
+		t.Errorf("SyncMetadataBranch() after resync error = %v", err)
+	}
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
 	"strings"
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/settings"
 
 	"github.com/go-git/go-git/v5"
@@ -19,9 +21,11 @@ import (
 
 // pushSessionsBranchCommon is the shared implementation for pushing session branches.
 // By default, session logs are pushed automatically alongside user pushes.
-// Configuration (stored in .entire/settings.json under strategy_options.push_sessions):
-//   - false: disable automatic pushing
-//   - true or not set: push automatically (default)
+// Configuration (stored in .entire/settings.json under strategy_options):
+//   - push_sessions false: disable automatic pushing
+//   - push_sessions true or not set: push automatically (default)
+//   - metadata_remote: push to this remote name or URL instead of the code remote
+//   - metadata_mirrors: also push to each of these remote names or URLs
 func pushSessionsBranchCommon(ctx context.Context, remote, branchName string) error {
 	// Check if pushing is disabled
 	if isPushSessionsDisabled(ctx) {
@@ -41,20 +45,24 @@ func pushSessionsBranchCommon(ctx context.Context, remote, branchName string) er
 		return nil //nolint:nilerr // Expected when no sessions exist yet
 	}
 
-	// Check if there's actually something to push (local differs from remote)
-	if !hasUnpushedSessionsCommon(repo, remote, localRef.Hash(), branchName) {
-		// Nothing to push - skip silently
-		return nil
+	for _, target := range metadataPushTargets(ctx, remote) {
+		// Check if there's actually something to push (local differs from remote)
+		if !hasUnpushedSessionsCommon(repo, target, localRef.Hash(), branchName) {
+			// Nothing to push - skip silently
+			continue
+		}
+		if err := doPushSessionsBranch(ctx, target, branchName); err != nil {
+			return err
+		}
 	}
-
-	return doPushSessionsBranch(ctx, remote, branchName)
+	return nil
 }
 
 // hasUnpushedSessionsCommon checks if the local branch differs from the remote.
 // Returns true if there's any difference that needs syncing (local ahead, remote ahead, or diverged).
 func hasUnpushedSessionsCommon(repo *git.Repository, remote string, localHash plumbing.Hash, branchName string) bool {
 	// Check for remote tracking ref: refs/remotes/<remote>/<branch>
-	remoteRefName := plumbing.NewRemoteReferenceName(remote, branchName)
+	remoteRefName := plumbing.NewRemoteReferenceName(MetadataTrackingName(remote), branchName)
 	remoteRef, err := repo.Reference(remoteRefName, true)
 	if err != nil {
 		// Remote branch doesn't exist yet - we have content to push
@@ -90,6 +98,14 @@ func doPushSessionsBranch(ctx context.Context, remote, branchName string) error
 
 	if err := fetchAndMergeSessionsCommon(ctx, remote, branchName); err != nil {
 		fmt.Fprintf(os.Stderr, "[entire] Warning: couldn't sync sessions: %v\n", err)
+		var purgedErr *checkpoint.PurgedHistoryError
+		if errors.As(err, &purgedErr) {
+			if !purgedErr.Local {
+				fmt.Fprintf(os.Stderr, "[entire] Run 'entire sync --resync %s' to take the rewritten branch\n", remote)
+			} else if repo, repoErr := OpenRepository(ctx); repoErr == nil {
+				fmt.Fprintf(os.Stderr, "[entire] Replace the remote branch with: git push %s %s %s\n", metadataLeaseArg(repo, remote), remote, branchName)
+			}
+		}
 		return nil // Don't fail the main push
 	}
 
@@ -119,6 +135,7 @@ func tryPushSessionsCommon(ctx context.Context, remote, branchName string) error
 		}
 		return fmt.Errorf("push failed: %s", output)
 	}
+	recordPushedMetadata(ctx, remote, branchName)
 	return nil
 }
 
@@ -145,60 +162,90 @@ func fetchAndMergeSessionsCommon(ctx context.Context, remote, branchName string)
 	if err != nil {
 		return fmt.Errorf("failed to get local ref: %w", err)
 	}
-	localCommit, err := repo.CommitObject(localRef.Hash())
+
+	// Get remote (FETCH_HEAD)
+	fetchHeadRef, err := repo.Reference(plumbing.ReferenceName("FETCH_HEAD"), true)
 	if err != nil {
-		return fmt.Errorf("failed to get local commit: %w", err)
+		return fmt.Errorf("failed to get FETCH_HEAD: %w", err)
 	}
-	localTree, err := localCommit.Tree()
+
+	mergeCommitHash, err := mergeMetadataCommits(repo, localRef.Hash(), fetchHeadRef.Hash())
 	if err != nil {
-		return fmt.Errorf("failed to get local tree: %w", err)
+		return err
 	}
 
-	// Get remote (FETCH_HEAD)
-	fetchHeadRef, err := repo.Reference(plumbing.ReferenceName("FETCH_HEAD"), true)
+	// Update branch ref
+	newRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branchName), mergeCommitHash)
+	if err := repo.Storer.SetReference(newRef); err != nil {
+		return fmt.Errorf("failed to update branch ref: %w", err)
+	}
+
+	return nil
+}
+
+// mergeMetadataCommits creates a merge commit whose tree is the union of the
+// local and remote metadata trees, with both commits as parents.
+// Session logs have unique cond-* directories, so no conflicts are expected;
+// where both sides have the same path, the remote entry wins, except that
+// transcripts compacted by 'entire gc' on either side are not restored.
+// Returns a *checkpoint.PurgedHistoryError instead of merging when one side
+// was rewritten by 'entire purge' or 'entire gc' and the other still has the
+// old history, which the merge would make reachable again.
+func mergeMetadataCommits(repo *git.Repository, localHash, remoteHash plumbing.Hash) (plumbing.Hash, error) {
+	// A purge or gc on either side must not be undone by the merge
+	if err := checkpoint.CheckPurgedHistory(repo, localHash, remoteHash); err != nil {
+		return plumbing.ZeroHash, err //nolint:wrapcheck // Callers check for *checkpoint.PurgedHistoryError
+	}
+
+	localCommit, err := repo.CommitObject(localHash)
 	if err != nil {
-		return fmt.Errorf("failed to get FETCH_HEAD: %w", err)
+		return plumbing.ZeroHash, fmt.Errorf("failed to get local commit: %w", err)
+	}
+	localTree, err := localCommit.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get local tree: %w", err)
 	}
-	remoteCommit, err := repo.CommitObject(fetchHeadRef.Hash())
+	remoteCommit, err := repo.CommitObject(remoteHash)
 	if err != nil {
-		return fmt.Errorf("failed to get remote commit: %w", err)
+		return plumbing.ZeroHash, fmt.Errorf("failed to get remote commit: %w", err)
 	}
 	remoteTree, err := remoteCommit.Tree()
 	if err != nil {
-		return fmt.Errorf("failed to get remote tree: %w", err)
+		return plumbing.ZeroHash, fmt.Errorf("failed to get remote tree: %w", err)
 	}
 
 	// Flatten both trees and combine entries
-	// Session logs have unique cond-* directories, so no conflicts expected
-	entries := make(map[string]object.TreeEntry)
-	if err := checkpoint.FlattenTree(repo, localTree, "", entries); err != nil {
-		return fmt.Errorf("failed to flatten local tree: %w", err)
+	localEntries := make(map[string]object.TreeEntry)
+	if err := checkpoint.FlattenTree(repo, localTree, "", localEntries); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to flatten local tree: %w", err)
 	}
-	if err := checkpoint.FlattenTree(repo, remoteTree, "", entries); err != nil {
-		return fmt.Errorf("failed to flatten remote tree: %w", err)
+	remoteEntries := make(map[string]object.TreeEntry)
+	if err := checkpoint.FlattenTree(repo, remoteTree, "", remoteEntries); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to flatten remote tree: %w", err)
+	}
+	entries := make(map[string]object.TreeEntry, len(localEntries)+len(remoteEntries))
+	maps.Copy(entries, localEntries)
+	maps.Copy(entries, remoteEntries)
+
+	// Transcripts that 'entire gc' removed on one side stay removed
+	if err := checkpoint.DropCompactedTranscripts(repo, entries, localEntries, remoteEntries); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to apply compaction: %w", err)
 	}
 
 	// Build merged tree
 	mergedTreeHash, err := checkpoint.BuildTreeFromEntries(repo, entries)
 	if err != nil {
-		return fmt.Errorf("failed to build merged tree: %w", err)
+		return plumbing.ZeroHash, fmt.Errorf("failed to build merged tree: %w", err)
 	}
 
 	// Create merge commit with both parents
 	mergeCommitHash, err := createMergeCommitCommon(repo, mergedTreeHash,
-		[]plumbing.Hash{localRef.Hash(), fetchHeadRef.Hash()},
+		[]plumbing.Hash{localHash, remoteHash},
 		"Merge remote session logs")
 	if err != nil {
-		return fmt.Errorf("failed to create merge commit: %w", err)
-	}
-
-	// Update branch ref
-	newRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branchName), mergeCommitHash)
-	if err := repo.Storer.SetReference(newRef); err != nil {
-		return fmt.Errorf("failed to update branch ref: %w", err)
+		return plumbing.ZeroHash, fmt.Errorf("failed to create merge commit: %w", err)
 	}
-
-	return nil
+	return mergeCommitHash, nil
 }
 
 // createMergeCommitCommon creates a merge commit with multiple parents.
@@ -231,3 +278,63 @@ func createMergeCommitCommon(repo *git.Repository, treeHash plumbing.Hash, paren
 
 	return hash, nil
 }
+
+// ReplaceRemoteMetadataBranch force-pushes the local metadata branch to the
+// metadata remote and its mirrors after it was rewritten (e.g. by 'entire gc').
+// Each push is leased on the remote's last known tip, so checkpoints pushed by
+// someone else since the last fetch are never overwritten. Returns the remotes
+// that were updated.
+func ReplaceRemoteMetadataBranch(ctx context.Context, codeRemote string) ([]string, error) {
+	branchName := paths.MetadataBranchName
+	repo, err := OpenRepository(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	var pushed []string
+	for _, remote := range metadataPushTargets(ctx, codeRemote) {
+		if err := validateMetadataRemote(repo, remote); err != nil {
+			return pushed, err
+		}
+		pushCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		cmd := exec.CommandContext(pushCtx, "git", "push", "--no-verify", metadataLeaseArg(repo, remote), remote, branchName)
+		cmd.Stdin = nil
+		output, err := cmd.CombinedOutput()
+		cancel()
+		if err != nil {
+			return pushed, fmt.Errorf("push to %s failed (run 'entire sync %s' first if it has new checkpoints): %s", remote, remote, strings.TrimSpace(string(output)))
+		}
+		recordPushedMetadata(ctx, remote, branchName)
+		pushed = append(pushed, remote)
+	}
+	return pushed, nil
+}
+
+// ForcePushMetadataCommands returns the git commands that replace the metadata
+// branch on the metadata remote and its mirrors with the local one, leased the
+// same way as ReplaceRemoteMetadataBranch.
+func ForcePushMetadataCommands(ctx context.Context, codeRemote string) []string {
+	repo, err := OpenRepository(ctx)
+	if err != nil {
+		return nil
+	}
+	var commands []string
+	for _, remote := range metadataPushTargets(ctx, codeRemote) {
+		commands = append(commands, fmt.Sprintf("git push %s %s %s", metadataLeaseArg(repo, remote), remote, paths.MetadataBranchName))
+	}
+	return commands
+}
+
+// metadataLeaseArg returns the --force-with-lease argument that only lets a
+// force push replace the remote metadata branch if it is still at the tip
+// last fetched from or pushed to remote. Without a tracking ref, the lease
+// requires the branch not to exist remotely.
+func metadataLeaseArg(repo *git.Repository, remote string) string {
+	branchName := paths.MetadataBranchName
+	expected := ""
+	trackingRef := plumbing.NewRemoteReferenceName(MetadataTrackingName(remote), branchName)
+	if ref, err := repo.Reference(trackingRef, true); err == nil {
+		expected = ref.Hash().String()
+	}
+	return fmt.Sprintf("--force-with-lease=%s:%s", branchName, expected)
+}
@@ -0,0 +1,328 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+	"github.com/entireio/cli/cmd/entire/cli/validation"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+)
+
+// RecoveryPoint is a snapshot of the working tree, including untracked files
+// that aren't ignored, and of this worktree's session state, saved before a
+// rewind or reset so it can be undone.
+type RecoveryPoint struct {
+	// ID is the recovery point's commit hash on paths.RecoveryRef
+	ID string
+
+	// Message describes the operation the recovery point was saved before
+	Message string
+
+	// Date is when the recovery point was saved
+	Date time.Time
+
+	// Head is the HEAD commit at the time
+	Head string
+
+	// ShadowBranches maps this worktree's shadow branches to their tips at the time
+	ShadowBranches map[string]string
+}
+
+// SaveRecoveryPoint snapshots the working tree, this worktree's session state
+// files and its shadow branch tips before a destructive operation. reason completes the sentence
+// "Before ...", e.g. "rewind to abc1234". Returns the recovery point's ID.
+func (s *ManualCommitStrategy) SaveRecoveryPoint(ctx context.Context, reason string) (string, error) {
+	repo, err := OpenRepository(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	shadowTips, err := worktreeShadowBranchTips(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+
+	sessionStates, err := worktreeSessionStateFiles(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authorName, authorEmail := GetGitAuthorFromRepo(repo)
+	store := checkpoint.NewGitStore(repo)
+	hash, err := store.WriteRecoveryPoint(ctx, checkpoint.WriteRecoveryOptions{
+		CommitMessage: trailers.FormatRecoveryPoint("Before "+reason, shadowTips),
+		AuthorName:    authorName,
+		AuthorEmail:   authorEmail,
+		SessionStates: sessionStates,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to write recovery point: %w", err)
+	}
+	return hash.String(), nil
+}
+
+// ListRecoveryPoints returns recovery points, most recent first.
+// limit <= 0 returns all of them.
+func (s *ManualCommitStrategy) ListRecoveryPoints(ctx context.Context, limit int) ([]RecoveryPoint, error) {
+	repo, err := OpenRepository(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	infos, err := checkpoint.NewGitStore(repo).ListRecoveryPoints(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recovery points: %w", err)
+	}
+
+	points := make([]RecoveryPoint, 0, len(infos))
+	for _, info := range infos {
+		subject, _, _ := strings.Cut(info.Message, "\n")
+		points = append(points, RecoveryPoint{
+			ID:             info.CommitHash.String(),
+			Message:        subject,
+			Date:           info.Timestamp,
+			Head:           info.HeadCommit.String(),
+			ShadowBranches: trailers.ParseRecoveryShadows(info.Message),
+		})
+	}
+	return points, nil
+}
+
+// RestoreRecoveryPoint puts the working tree, HEAD, session state files and
+// shadow branch tips back the way they were when point was saved. The current
+// state is saved as a new recovery point first, so the undo can itself be
+// undone. Protected directories and ignored files are left alone, as in Rewind;
+// the index is reset to the restored HEAD. Sessions started after point was
+// saved keep their state.
+func (s *ManualCommitStrategy) RestoreRecoveryPoint(ctx context.Context, point RecoveryPoint) error {
+	repo, err := OpenRepository(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	if _, err := s.SaveRecoveryPoint(ctx, "undo to "+truncateHash(point.ID)); err != nil {
+		return fmt.Errorf("failed to save recovery point: %w", err)
+	}
+
+	// Move HEAD back first: git reset --mixed leaves the working tree alone
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if point.Head != "" {
+		cmd := exec.CommandContext(ctx, "git", "reset", "--mixed", "-q", point.Head)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to reset HEAD to %s: %s: %w", truncateHash(point.Head), strings.TrimSpace(string(output)), err)
+		}
+		if head.Hash().String() != point.Head {
+			fmt.Fprintf(os.Stderr, "Moved HEAD from %s back to %s\n", truncateHash(head.Hash().String()), truncateHash(point.Head))
+		}
+	}
+
+	for branch, tip := range point.ShadowBranches {
+		ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), plumbing.NewHash(tip))
+		if err := repo.Storer.SetReference(ref); err != nil {
+			fmt.Fprintf(os.Stderr, "[entire] Warning: failed to restore shadow branch %s: %v\n", branch, err)
+		}
+	}
+
+	sessionStates, err := checkpoint.NewGitStore(repo).ReadRecoverySessionStates(plumbing.NewHash(point.ID))
+	if err != nil {
+		return fmt.Errorf("failed to read session states: %w", err)
+	}
+	if err := restoreSessionStateFiles(ctx, sessionStates); err != nil {
+		return err
+	}
+
+	return restoreWorktreeFromCommit(ctx, repo, plumbing.NewHash(point.ID))
+}
+
+// worktreeSessionStateFiles returns the raw session state files of this
+// worktree's sessions, keyed by file name.
+func worktreeSessionStateFiles(ctx context.Context) (map[string][]byte, error) {
+	worktreePath, err := paths.WorktreeRoot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree path: %w", err)
+	}
+	worktreeID, err := paths.GetWorktreeID(worktreePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree ID: %w", err)
+	}
+	stateDir, err := getSessionStateDir(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session state directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(stateDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session state directory: %w", err)
+	}
+	files := make(map[string][]byte)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(stateDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session state %s: %w", entry.Name(), err)
+		}
+		var state struct {
+			WorktreeID string `json:"worktree_id"`
+		}
+		if err := json.Unmarshal(data, &state); err != nil || state.WorktreeID != worktreeID {
+			continue // Corrupted or another worktree's session
+		}
+		files[entry.Name()] = data
+	}
+	return files, nil
+}
+
+// restoreSessionStateFiles writes session state files saved by
+// worktreeSessionStateFiles back to the session state directory.
+func restoreSessionStateFiles(ctx context.Context, files map[string][]byte) error {
+	if len(files) == 0 {
+		return nil
+	}
+	stateDir, err := getSessionStateDir(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get session state directory: %w", err)
+	}
+	if err := os.MkdirAll(stateDir, 0o750); err != nil {
+		return fmt.Errorf("failed to create session state directory: %w", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		sessionID := strings.TrimSuffix(name, ".json")
+		if err := validation.ValidateSessionID(sessionID); err != nil || sessionID == name {
+			continue // Not a session state file
+		}
+		stateFile := filepath.Join(stateDir, name)
+		tmpFile := stateFile + ".tmp"
+		if err := os.WriteFile(tmpFile, files[name], 0o600); err != nil {
+			return fmt.Errorf("failed to write session state %s: %w", sessionID, err)
+		}
+		if err := os.Rename(tmpFile, stateFile); err != nil {
+			return fmt.Errorf("failed to rename session state file: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "  Restored session state: %s\n", sessionID)
+	}
+	return nil
+}
+
+// worktreeShadowBranchTips returns the tips of the current worktree's shadow branches.
+func worktreeShadowBranchTips(ctx context.Context, repo *git.Repository) (map[string]string, error) {
+	worktreePath, err := paths.WorktreeRoot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree path: %w", err)
+	}
+	worktreeID, err := paths.GetWorktreeID(worktreePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree ID: %w", err)
+	}
+	worktreeHash := checkpoint.HashWorktreeID(worktreeID)
+
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get references: %w", err)
+	}
+	tips := make(map[string]string)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if !ref.Name().IsBranch() || ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		branchName := ref.Name().Short()
+		if !IsShadowBranch(branchName) {
+			return nil
+		}
+		if _, hash, ok := checkpoint.ParseShadowBranchName(branchName); ok && hash == worktreeHash {
+			tips[branchName] = ref.Hash().String()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate references: %w", err)
+	}
+	return tips, nil
+}
+
+// restoreWorktreeFromCommit makes the working tree match a commit's tree:
+// changed and missing files are written, and files the commit doesn't have
+// are deleted. Only tracked and non-ignored untracked files are considered.
+func restoreWorktreeFromCommit(ctx context.Context, repo *git.Repository, hash plumbing.Hash) error {
+	repoRoot, err := paths.WorktreeRoot(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get worktree root: %w", err)
+	}
+	target, err := commitSnapshot(ctx, repo, hash)
+	if err != nil {
+		return err
+	}
+	current, err := worktreeSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(current)) {
+		if _, keep := target[name]; keep {
+			continue
+		}
+		if err := os.Remove(filepath.Join(repoRoot, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to delete %s: %w", name, err)
+		}
+		fmt.Fprintf(os.Stderr, "  Deleted: %s\n", name)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(target)) {
+		if err := ctx.Err(); err != nil {
+			return err //nolint:wrapcheck // Propagating context cancellation
+		}
+		file := target[name]
+		if cur, ok := current[name]; ok && cur.hash == file.hash && cur.mode == file.mode {
+			continue
+		}
+		content, err := file.read()
+		if err != nil {
+			return err
+		}
+		absPath := filepath.Join(repoRoot, name)
+		//nolint:gosec // G301: Need 0o755 for user directories during restore
+		if err := os.MkdirAll(filepath.Dir(absPath), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", name, err)
+		}
+		if err := os.Remove(absPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to replace %s: %w", name, err)
+		}
+		if file.mode == filemode.Symlink {
+			if err := os.Symlink(string(content), absPath); err != nil {
+				return fmt.Errorf("failed to create symlink %s: %w", name, err)
+			}
+			continue
+		}
+		var perm os.FileMode = 0o644
+		if file.mode == filemode.Executable {
+			perm = 0o755
+		}
+		if err := os.WriteFile(absPath, content, perm); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", name, err)
+		}
+		fmt.Fprintf(os.Stderr, "  Restored: %s\n", name)
+	}
+	return nil
+}
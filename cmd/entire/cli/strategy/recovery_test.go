@@ -0,0 +1,180 @@
+package strategy
+
+import (
+	"context"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestRecoveryPoint_SaveAndRestore(t *testing.T) {
+	// Uses t.Chdir, so cannot be parallel.
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+	t.Chdir(dir)
+	paths.ClearWorktreeRootCache()
+	t.Cleanup(paths.ClearWorktreeRootCache)
+
+	base := commitFile(t, repo, dir, "tracked.txt", "committed\n")
+	shadowBranch := getShadowBranchNameForCommit(base.String(), "")
+	shadowRef := plumbing.NewBranchReferenceName(shadowBranch)
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(shadowRef, base)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Uncommitted human edits: a modified tracked file and an untracked file
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("tracked.txt", "edited by hand\n")
+	writeFile("notes.txt", "untracked notes\n")
+
+	s := &ManualCommitStrategy{}
+	saved, err := s.SaveRecoveryPoint(ctx, "rewind to abc1234")
+	if err != nil {
+		t.Fatalf("SaveRecoveryPoint() error = %v", err)
+	}
+
+	// A destructive operation: HEAD moves, edits are lost, the shadow branch is reset
+	moved := commitFile(t, repo, dir, "tracked.txt", "rewound\n")
+	writeFile("later.txt", "created after\n")
+	if err := os.Remove(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(shadowRef, moved)); err != nil {
+		t.Fatal(err)
+	}
+
+	points, err := s.ListRecoveryPoints(ctx, 0)
+	if err != nil {
+		t.Fatalf("ListRecoveryPoints() error = %v", err)
+	}
+	if len(points) != 1 || points[0].ID != saved {
+		t.Fatalf("ListRecoveryPoints() = %+v, want one point %s", points, saved)
+	}
+	if points[0].Message != "Before rewind to abc1234" || points[0].Head != base.String() {
+		t.Errorf("recovery point = %+v, want message %q and head %s", points[0], "Before rewind to abc1234", base)
+	}
+	if got := points[0].ShadowBranches[shadowBranch]; got != base.String() {
+		t.Errorf("ShadowBranches[%s] = %q, want %s", shadowBranch, got, base)
+	}
+
+	if err := s.RestoreRecoveryPoint(ctx, points[0]); err != nil {
+		t.Fatalf("RestoreRecoveryPoint() error = %v", err)
+	}
+
+	for name, want := range map[string]string{"tracked.txt": "edited by hand\n", "notes.txt": "untracked notes\n"} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "later.txt")); !os.IsNotExist(err) {
+		t.Errorf("later.txt should be deleted, stat error = %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Hash() != base {
+		t.Errorf("HEAD = %s, want %s", head.Hash(), base)
+	}
+	ref, err := repo.Reference(shadowRef, true)
+	if err != nil || ref.Hash() != base {
+		t.Errorf("shadow branch = %v, %v; want %s", ref, err, base)
+	}
+
+	// The undo saved the state it replaced, so it can be undone too
+	points, err = s.ListRecoveryPoints(ctx, 0)
+	if err != nil {
+		t.Fatalf("ListRecoveryPoints() error = %v", err)
+	}
+	if len(points) != 2 || points[0].Head != moved.String() || points[1].ID != saved {
+		t.Errorf("ListRecoveryPoints() after undo = %+v, want the undo's point then %s", points, saved)
+	}
+}
+
+func TestRecoveryPoint_RestoresSessionState(t *testing.T) {
+	// Uses t.Chdir, so cannot be parallel.
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+	t.Chdir(dir)
+	paths.ClearWorktreeRootCache()
+	t.Cleanup(paths.ClearWorktreeRootCache)
+
+	base := commitFile(t, repo, dir, "tracked.txt", "committed\n")
+	state := &SessionState{SessionID: "reset-me-123", BaseCommit: base.String(), StartedAt: time.Now(), StepCount: 2}
+	if err := SaveSessionState(ctx, state); err != nil {
+		t.Fatalf("SaveSessionState() error = %v", err)
+	}
+	other := &SessionState{SessionID: "other-worktree-456", BaseCommit: base.String(), WorktreeID: "feature", StartedAt: time.Now()}
+	if err := SaveSessionState(ctx, other); err != nil {
+		t.Fatalf("SaveSessionState() error = %v", err)
+	}
+
+	s := &ManualCommitStrategy{}
+	if err := s.ResetSession(ctx, state.SessionID); err != nil {
+		t.Fatalf("ResetSession() error = %v", err)
+	}
+	if got, err := LoadSessionState(ctx, state.SessionID); err != nil || got != nil {
+		t.Fatalf("session state after reset = %+v, %v; want cleared", got, err)
+	}
+
+	// Staged changes are dropped: the index is reset even though HEAD didn't move
+	if err := os.WriteFile(filepath.Join(dir, "tracked.txt"), []byte("staged\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", "tracked.txt")
+
+	points, err := s.ListRecoveryPoints(ctx, 1)
+	if err != nil || len(points) != 1 {
+		t.Fatalf("ListRecoveryPoints() = %+v, %v; want one point", points, err)
+	}
+	if err := s.RestoreRecoveryPoint(ctx, points[0]); err != nil {
+		t.Fatalf("RestoreRecoveryPoint() error = %v", err)
+	}
+
+	restored, err := LoadSessionState(ctx, state.SessionID)
+	if err != nil || restored == nil {
+		t.Fatalf("session state after undo = %+v, %v; want restored", restored, err)
+	}
+	if restored.BaseCommit != base.String() || restored.StepCount != 2 {
+		t.Errorf("restored session state = %+v, want base %s and 2 steps", restored, base)
+	}
+
+	// Another worktree's session isn't part of this worktree's recovery points
+	states, err := checkpoint.NewGitStore(repo).ReadRecoverySessionStates(plumbing.NewHash(points[0].ID))
+	if err != nil {
+		t.Fatalf("ReadRecoverySessionStates() error = %v", err)
+	}
+	if _, ok := states[other.SessionID+".json"]; ok || len(states) != 1 {
+		t.Errorf("ReadRecoverySessionStates() = %v files, want only %s", slices.Collect(maps.Keys(states)), state.SessionID)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "diff", "--cached", "--quiet")
+	cmd.Dir = dir
+	if err := cmd.Run(); err != nil {
+		t.Errorf("index differs from HEAD after undo: %v", err)
+	}
+}
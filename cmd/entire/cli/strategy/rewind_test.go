@@ -4,14 +4,18 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	_ "github.com/entireio/cli/cmd/entire/cli/agent/claudecode" // Register agent for ResolveAgentForRewind tests
 	_ "github.com/entireio/cli/cmd/entire/cli/agent/geminicli"  // Register agent for ResolveAgentForRewind tests
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
@@ -203,6 +207,122 @@ func TestShadowStrategy_PreviewRewind_LogsOnly(t *testing.T) {
 	}
 }
 
+func TestShadowStrategy_RewindWithOptions_Paths(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+
+	t.Chdir(dir)
+	paths.ClearWorktreeRootCache()
+	t.Cleanup(paths.ClearWorktreeRootCache)
+
+	for _, sub := range []string{"src", "docs"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	base := commitFile(t, repo, dir, "README.md", "# Test\n")
+	guide := commitFile(t, repo, dir, "docs/guide.md", "guide\n")
+
+	// The checkpoint belongs to a session whose shadow branch has moved on
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "src", "app.go"), []byte("package src\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add("src/app.go"); err != nil {
+		t.Fatal(err)
+	}
+	checkpoint, err := worktree.Commit("Checkpoint\n\n"+trailers.SessionTrailerKey+": partial-rewind-session\n", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@test.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := &SessionState{SessionID: "partial-rewind-session", BaseCommit: base.String(), StartedAt: time.Now()}
+	if err := SaveSessionState(context.Background(), state); err != nil {
+		t.Fatalf("SaveSessionState() error = %v", err)
+	}
+	shadowRef := plumbing.NewBranchReferenceName(getShadowBranchNameForCommit(base.String(), ""))
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(shadowRef, guide)); err != nil {
+		t.Fatal(err)
+	}
+	if err := worktree.Reset(&git.ResetOptions{Commit: base, Mode: git.HardReset}); err != nil {
+		t.Fatalf("failed to reset to base: %v", err)
+	}
+	for name, content := range map[string]string{"src/extra.go": "package src\n", "docs/extra.md": "extra\n"} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := &ManualCommitStrategy{}
+	point := RewindPoint{ID: checkpoint.String(), Message: "Checkpoint", Date: time.Now()}
+	opts := RewindOptions{Paths: []string{"src/*.go"}}
+
+	preview, err := s.PreviewRewindWithOptions(context.Background(), point, opts)
+	if err != nil {
+		t.Fatalf("PreviewRewindWithOptions() error = %v", err)
+	}
+	if !slices.Equal(preview.FilesToRestore, []string{"src/app.go"}) {
+		t.Errorf("FilesToRestore = %v, want [src/app.go]", preview.FilesToRestore)
+	}
+	if !slices.Equal(preview.FilesToDelete, []string{"src/extra.go"}) {
+		t.Errorf("FilesToDelete = %v, want [src/extra.go]", preview.FilesToDelete)
+	}
+
+	if err := s.RewindWithOptions(context.Background(), point, opts); err != nil {
+		t.Fatalf("RewindWithOptions() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "src", "app.go")); err != nil {
+		t.Errorf("src/app.go should be restored: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "src", "extra.go")); !os.IsNotExist(err) {
+		t.Errorf("src/extra.go should be deleted, stat error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "docs", "guide.md")); !os.IsNotExist(err) {
+		t.Errorf("docs/guide.md is outside the paths and should not be restored, stat error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "docs", "extra.md")); err != nil {
+		t.Errorf("docs/extra.md is outside the paths and should be kept: %v", err)
+	}
+	if ref, err := repo.Reference(shadowRef, true); err != nil || ref.Hash() != guide {
+		t.Errorf("shadow branch = %v, %v; a partial rewind should leave it at %s", ref, err, guide)
+	}
+}
+
+func TestMatchesPathspecs(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		specs []string
+		want  bool
+	}{
+		{"src/app.go", nil, true},
+		{"src/app.go", []string{"."}, true},
+		{"src/app.go", []string{"src/app.go"}, true},
+		{"src/app.go", []string{"src"}, true},
+		{"src/app.go", []string{"src/"}, true},
+		{"src/app.go", []string{"sr"}, false},
+		{"src/app.go", []string{"docs", "src/*.go"}, true},
+		{"src/app.go", []string{"*.go"}, false},
+		{"src/sub/app.go", []string{"src/s*"}, true},
+		{"srcfile.go", []string{"src"}, false},
+	}
+	for _, tt := range tests {
+		if got := matchesPathspecs(tt.name, tt.specs); got != tt.want {
+			t.Errorf("matchesPathspecs(%q, %v) = %v, want %v", tt.name, tt.specs, got, tt.want)
+		}
+	}
+}
+
 func TestResolveAgentForRewind(t *testing.T) {
 	t.Parallel()
 
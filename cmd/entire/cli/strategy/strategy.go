@@ -114,6 +114,19 @@ type RewindPreview struct {
 	TrackedChanges []string
 }
 
+// RewindOptions narrows what a rewind touches.
+type RewindOptions struct {
+	// Paths restricts the rewind to files matching these pathspecs, relative
+	// to the repository root: a file, a directory, or a glob pattern.
+	// Empty means the whole working tree.
+	Paths []string
+
+	// Merge keeps changes made in the working tree since the agent's latest
+	// checkpoint: each file is three-way merged instead of overwritten, with
+	// conflict markers where the changes overlap.
+	Merge bool
+}
+
 // StepContext contains all information needed for saving a step checkpoint.
 // All file paths should be pre-filtered and normalized by the CLI layer.
 type StepContext struct {
@@ -0,0 +1,99 @@
+package summarize
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+)
+
+// DefaultGeminiModel is the default model used for summarization via the Gemini CLI.
+const DefaultGeminiModel = "gemini-2.5-flash"
+
+// GeminiGenerator generates summaries using the Gemini CLI in non-interactive mode.
+type GeminiGenerator struct {
+	// GeminiPath is the path to the gemini CLI executable.
+	// If empty, defaults to "gemini" (expects it to be in PATH).
+	GeminiPath string
+
+	// Model is the Gemini model to use for summarization.
+	// If empty, defaults to DefaultGeminiModel.
+	Model string
+
+	// CommandRunner allows injection of the command execution for testing.
+	// If nil, uses exec.CommandContext directly.
+	CommandRunner CommandRunner
+}
+
+// geminiCLIResponse represents the JSON response from `gemini --output-format json`.
+type geminiCLIResponse struct {
+	Response string `json:"response"`
+}
+
+// Generate creates a summary from checkpoint data by calling the Gemini CLI.
+func (g *GeminiGenerator) Generate(ctx context.Context, input Input) (*checkpoint.Summary, error) {
+	prompt := buildSummarizationPrompt(FormatCondensedTranscript(input))
+
+	geminiPath := g.GeminiPath
+	if geminiPath == "" {
+		geminiPath = "gemini"
+	}
+	model := g.Model
+	if model == "" {
+		model = DefaultGeminiModel
+	}
+
+	// Piped stdin puts the Gemini CLI in non-interactive mode.
+	stdout, err := runIsolatedCLI(ctx, g.CommandRunner, "gemini", geminiPath,
+		[]string{"--model", model, "--output-format", "json"}, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Older Gemini CLI versions ignore --output-format and print plain text.
+	var cliResponse geminiCLIResponse
+	if err := json.Unmarshal(stdout, &cliResponse); err == nil && cliResponse.Response != "" {
+		return parseSummaryResponse(cliResponse.Response)
+	}
+	return parseSummaryResponse(string(stdout))
+}
+
+// OpenCodeGenerator generates summaries using `opencode run`.
+type OpenCodeGenerator struct {
+	// OpenCodePath is the path to the opencode CLI executable.
+	// If empty, defaults to "opencode" (expects it to be in PATH).
+	OpenCodePath string
+
+	// Model is the model in OpenCode's "provider/model" form.
+	// If empty, OpenCode's configured default model is used.
+	Model string
+
+	// CommandRunner allows injection of the command execution for testing.
+	// If nil, uses exec.CommandContext directly.
+	CommandRunner CommandRunner
+}
+
+// Generate creates a summary from checkpoint data by calling the OpenCode CLI.
+func (g *OpenCodeGenerator) Generate(ctx context.Context, input Input) (*checkpoint.Summary, error) {
+	prompt := buildSummarizationPrompt(FormatCondensedTranscript(input))
+
+	opencodePath := g.OpenCodePath
+	if opencodePath == "" {
+		opencodePath = "opencode"
+	}
+
+	// `opencode run` appends piped stdin to the message, so the prompt is passed
+	// on stdin to stay clear of argument length limits.
+	args := []string{"run"}
+	if g.Model != "" {
+		args = append(args, "--model", g.Model)
+	}
+
+	stdout, err := runIsolatedCLI(ctx, g.CommandRunner, "opencode", opencodePath, args, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSummaryResponse(strings.TrimSpace(string(stdout)))
+}
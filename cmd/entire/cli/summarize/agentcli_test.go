@@ -0,0 +1,111 @@
+package summarize
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestGeminiGenerator_ValidResponse(t *testing.T) {
+	var capturedArgs []string
+	response := `{"response":"` + strings.ReplaceAll(testSummaryJSON, `"`, `\"`) + `","stats":{}}`
+
+	gen := &GeminiGenerator{
+		Model: "gemini-2.5-pro",
+		CommandRunner: func(ctx context.Context, _ string, args ...string) *exec.Cmd {
+			capturedArgs = args
+			return exec.CommandContext(ctx, "sh", "-c", "printf '%s' '"+response+"'")
+		},
+	}
+
+	summary, err := gen.Generate(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Intent != "Fix login" {
+		t.Errorf("Intent = %q, want %q", summary.Intent, "Fix login")
+	}
+
+	want := "--model gemini-2.5-pro --output-format json"
+	if got := strings.Join(capturedArgs, " "); got != want {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+}
+
+func TestGeminiGenerator_PlainTextResponse(t *testing.T) {
+	gen := &GeminiGenerator{
+		CommandRunner: func(ctx context.Context, _ string, _ ...string) *exec.Cmd {
+			return exec.CommandContext(ctx, "sh", "-c", "printf '%s' '```json\n"+testSummaryJSON+"\n```'")
+		},
+	}
+
+	summary, err := gen.Generate(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Outcome != "Fixed" {
+		t.Errorf("Outcome = %q, want %q", summary.Outcome, "Fixed")
+	}
+}
+
+func TestGeminiGenerator_NonZeroExit(t *testing.T) {
+	gen := &GeminiGenerator{
+		CommandRunner: func(ctx context.Context, _ string, _ ...string) *exec.Cmd {
+			return exec.CommandContext(ctx, "sh", "-c", "echo 'quota exceeded' >&2; exit 1")
+		},
+	}
+
+	_, err := gen.Generate(context.Background(), Input{})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "gemini CLI failed (exit 1)") || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestOpenCodeGenerator_ValidResponse(t *testing.T) {
+	var capturedArgs []string
+
+	gen := &OpenCodeGenerator{
+		Model: "anthropic/claude-sonnet-4",
+		CommandRunner: func(ctx context.Context, _ string, args ...string) *exec.Cmd {
+			capturedArgs = args
+			// Only answer when the prompt arrives on stdin rather than as an argument
+			return exec.CommandContext(ctx, "sh", "-c", "grep -q 'Fix the login bug' && printf '%s' 'Summary follows.\n"+testSummaryJSON+"\n'")
+		},
+	}
+
+	summary, err := gen.Generate(context.Background(), Input{
+		Transcript: []Entry{{Type: EntryTypeUser, Content: "Fix the login bug"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Intent != "Fix login" {
+		t.Errorf("Intent = %q, want %q", summary.Intent, "Fix login")
+	}
+
+	want := "run --model anthropic/claude-sonnet-4"
+	if got := strings.Join(capturedArgs, " "); got != want {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+}
+
+func TestOpenCodeGenerator_DefaultModel(t *testing.T) {
+	var capturedArgs []string
+	gen := &OpenCodeGenerator{
+		CommandRunner: func(ctx context.Context, _ string, args ...string) *exec.Cmd {
+			capturedArgs = args
+			return exec.CommandContext(ctx, "sh", "-c", "printf '%s' '"+testSummaryJSON+"'")
+		},
+	}
+
+	if _, err := gen.Generate(context.Background(), Input{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(capturedArgs) != 1 || capturedArgs[0] != "run" {
+		t.Errorf("args = %v, want [run]", capturedArgs)
+	}
+}
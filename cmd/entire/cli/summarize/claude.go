@@ -1,19 +1,15 @@
 package summarize
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"os"
-	"os/exec"
 	"strings"
 
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 )
 
-// summarizationPromptTemplate is the prompt used to generate summaries via the Claude CLI.
+// summarizationPromptTemplate is the prompt sent to every summary generator.
 //
 // Security note: The transcript is wrapped in <transcript> tags to provide clear boundary
 // markers. This helps contain any potentially malicious content within the transcript
@@ -63,7 +59,7 @@ type ClaudeGenerator struct {
 
 	// CommandRunner allows injection of the command execution for testing.
 	// If nil, uses exec.CommandContext directly.
-	CommandRunner func(ctx context.Context, name string, args ...string) *exec.Cmd
+	CommandRunner CommandRunner
 }
 
 // claudeCLIResponse represents the JSON response from the Claude CLI.
@@ -79,12 +75,6 @@ func (g *ClaudeGenerator) Generate(ctx context.Context, input Input) (*checkpoin
 	// Build the prompt
 	prompt := buildSummarizationPrompt(transcriptText)
 
-	// Execute the Claude CLI
-	runner := g.CommandRunner
-	if runner == nil {
-		runner = exec.CommandContext
-	}
-
 	claudePath := g.ClaudePath
 	if claudePath == "" {
 		claudePath = "claude"
@@ -98,63 +88,23 @@ func (g *ClaudeGenerator) Generate(ctx context.Context, input Input) (*checkpoin
 	// Use empty --setting-sources to skip all settings (user, project, local).
 	// This avoids loading MCP servers, hooks, or other config that could interfere
 	// with a simple --print summarization call.
-	cmd := runner(ctx, claudePath, "--print", "--output-format", "json", "--model", model, "--setting-sources", "")
-
-	// Fully isolate the subprocess from the user's git repo (ENT-242).
-	// Claude Code performs internal git operations (plugin cache, context gathering)
-	// that pollute the worktree index with phantom entries from its plugin cache.
-	// We must both change the working directory AND strip GIT_* env vars, because
-	// git hooks set GIT_DIR which lets Claude Code find the repo regardless of cwd.
-	// This also prevents recursive triggering of Entire's own git hooks.
-	cmd.Dir = os.TempDir()
-	cmd.Env = stripGitEnv(os.Environ())
-
-	// Pass prompt via stdin
-	cmd.Stdin = strings.NewReader(prompt)
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
+	stdout, err := runIsolatedCLI(ctx, g.CommandRunner, "claude", claudePath,
+		[]string{"--print", "--output-format", "json", "--model", model, "--setting-sources", ""}, prompt)
 	if err != nil {
-		// Check if the command was not found
-		var execErr *exec.Error
-		if errors.As(err, &execErr) {
-			return nil, fmt.Errorf("claude CLI not found: %w", err)
-		}
-
-		// Check for exit error
-		var exitErr *exec.ExitError
-		if errors.As(err, &exitErr) {
-			return nil, fmt.Errorf("claude CLI failed (exit %d): %s", exitErr.ExitCode(), stderr.String())
-		}
-
-		return nil, fmt.Errorf("failed to run claude CLI: %w", err)
+		return nil, err
 	}
 
 	// Parse the CLI response
 	var cliResponse claudeCLIResponse
-	if err := json.Unmarshal(stdout.Bytes(), &cliResponse); err != nil {
+	if err := json.Unmarshal(stdout, &cliResponse); err != nil {
 		return nil, fmt.Errorf("failed to parse claude CLI response: %w", err)
 	}
 
 	// The result field contains the actual JSON summary
-	resultJSON := cliResponse.Result
-
-	// Try to extract JSON if it's wrapped in markdown code blocks
-	resultJSON = extractJSONFromMarkdown(resultJSON)
-
-	// Parse the summary from the result
-	var summary checkpoint.Summary
-	if err := json.Unmarshal([]byte(resultJSON), &summary); err != nil {
-		return nil, fmt.Errorf("failed to parse summary JSON: %w (response: %s)", err, resultJSON)
-	}
-
-	return &summary, nil
+	return parseSummaryResponse(cliResponse.Result)
 }
 
-// buildSummarizationPrompt creates the prompt for the Claude CLI.
+// buildSummarizationPrompt creates the summarization prompt shared by all generators.
 func buildSummarizationPrompt(transcriptText string) string {
 	return fmt.Sprintf(summarizationPromptTemplate, transcriptText)
 }
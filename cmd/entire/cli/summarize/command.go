@@ -0,0 +1,83 @@
+package summarize
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+)
+
+// CommandRunner creates the command used to invoke an agent CLI.
+// Generators accept one so tests can substitute a stub process.
+type CommandRunner func(ctx context.Context, name string, args ...string) *exec.Cmd
+
+// runIsolatedCLI runs an agent CLI with the prompt on stdin and returns its stdout.
+// toolName is used in error messages (e.g., "claude" yields "claude CLI not found").
+//
+// The subprocess is fully isolated from the user's git repo (ENT-242).
+// Agent CLIs perform internal git operations (plugin cache, context gathering)
+// that can pollute the worktree index. We must both change the working directory
+// AND strip GIT_* env vars, because git hooks set GIT_DIR which lets the CLI find
+// the repo regardless of cwd. This also prevents recursive triggering of Entire's
+// own git hooks.
+func runIsolatedCLI(ctx context.Context, runner CommandRunner, toolName, path string, args []string, prompt string) ([]byte, error) {
+	if runner == nil {
+		runner = exec.CommandContext
+	}
+
+	cmd := runner(ctx, path, args...)
+	cmd.Dir = os.TempDir()
+	cmd.Env = stripGitEnv(os.Environ())
+
+	// Pass prompt via stdin
+	cmd.Stdin = strings.NewReader(prompt)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		// Check if the command was not found
+		var execErr *exec.Error
+		if errors.As(err, &execErr) {
+			return nil, fmt.Errorf("%s CLI not found: %w", toolName, err)
+		}
+
+		// Check for exit error
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("%s CLI failed (exit %d): %s", toolName, exitErr.ExitCode(), stderr.String())
+		}
+
+		return nil, fmt.Errorf("failed to run %s CLI: %w", toolName, err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// parseSummaryResponse parses the model's text response into a summary.
+// Models sometimes wrap the JSON in markdown code blocks or surround it with
+// prose, so the outermost JSON object is extracted before parsing.
+func parseSummaryResponse(text string) (*checkpoint.Summary, error) {
+	resultJSON := extractJSONFromMarkdown(text)
+	if !strings.HasPrefix(resultJSON, "{") {
+		start := strings.Index(resultJSON, "{")
+		end := strings.LastIndex(resultJSON, "}")
+		if start != -1 && end > start {
+			resultJSON = resultJSON[start : end+1]
+		}
+	}
+
+	var summary checkpoint.Summary
+	if err := json.Unmarshal([]byte(resultJSON), &summary); err != nil {
+		return nil, fmt.Errorf("failed to parse summary JSON: %w (response: %s)", err, resultJSON)
+	}
+
+	return &summary, nil
+}
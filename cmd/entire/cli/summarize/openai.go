@@ -0,0 +1,152 @@
+package summarize
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+)
+
+const (
+	// DefaultOpenAIEndpoint is the base URL of the OpenAI API.
+	DefaultOpenAIEndpoint = "https://api.openai.com/v1"
+
+	// DefaultOpenAIModel is the default model for the OpenAI API.
+	DefaultOpenAIModel = "gpt-4o-mini"
+
+	// DefaultOpenAIAPIKeyEnv is the environment variable read for the OpenAI API key.
+	DefaultOpenAIAPIKeyEnv = "OPENAI_API_KEY"
+
+	// DefaultOllamaEndpoint is the OpenAI-compatible base URL of a local Ollama server.
+	DefaultOllamaEndpoint = "http://localhost:11434/v1"
+
+	// maxErrorBodyBytes limits how much of an error response is included in errors.
+	maxErrorBodyBytes = 512
+)
+
+// OpenAIGenerator generates summaries using an OpenAI-compatible chat completions
+// endpoint. This covers the OpenAI API as well as local servers that implement the
+// same API, such as Ollama and llama.cpp.
+type OpenAIGenerator struct {
+	// Endpoint is the API base URL (e.g., "http://localhost:11434/v1").
+	// "/chat/completions" is appended unless already present.
+	// If empty, defaults to DefaultOpenAIEndpoint.
+	Endpoint string
+
+	// Model is the model name sent in the request. Required unless the endpoint
+	// is the OpenAI API, where it defaults to DefaultOpenAIModel.
+	Model string
+
+	// APIKey is sent as a bearer token. Local servers usually don't need one.
+	APIKey string
+
+	// HTTPClient allows injection of the HTTP client for testing.
+	// If nil, uses http.DefaultClient. Cancellation is driven by ctx.
+	HTTPClient *http.Client
+}
+
+type chatCompletionRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatCompletionResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// Generate creates a summary by calling the chat completions endpoint.
+func (g *OpenAIGenerator) Generate(ctx context.Context, input Input) (*checkpoint.Summary, error) {
+	endpoint := g.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultOpenAIEndpoint
+	}
+	model := g.Model
+	if model == "" {
+		if endpoint != DefaultOpenAIEndpoint {
+			return nil, fmt.Errorf("a model is required for summary endpoint %s", endpoint)
+		}
+		model = DefaultOpenAIModel
+	}
+
+	prompt := buildSummarizationPrompt(FormatCondensedTranscript(input))
+	body, err := json.Marshal(chatCompletionRequest{
+		Model:    model,
+		Messages: []chatMessage{{Role: "user", Content: prompt}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode summary request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, chatCompletionsURL(endpoint), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create summary request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+g.APIKey)
+	}
+
+	client := g.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("summary request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read summary response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail := strings.TrimSpace(string(respBody))
+		if len(detail) > maxErrorBodyBytes {
+			detail = detail[:maxErrorBodyBytes] + "..."
+		}
+		return nil, fmt.Errorf("summary endpoint returned %s: %s", resp.Status, detail)
+	}
+
+	var completion chatCompletionResponse
+	if err := json.Unmarshal(respBody, &completion); err != nil {
+		return nil, fmt.Errorf("failed to parse summary response: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return nil, errors.New("summary response contained no choices")
+	}
+
+	return parseSummaryResponse(completion.Choices[0].Message.Content)
+}
+
+// chatCompletionsURL appends the chat completions path to a base URL.
+func chatCompletionsURL(endpoint string) string {
+	endpoint = strings.TrimRight(endpoint, "/")
+	if strings.HasSuffix(endpoint, "/chat/completions") {
+		return endpoint
+	}
+	return endpoint + "/chat/completions"
+}
+
+// apiKeyFromEnv returns the value of the named environment variable, or "" if unset.
+func apiKeyFromEnv(name string) string {
+	if name == "" {
+		return ""
+	}
+	return os.Getenv(name)
+}
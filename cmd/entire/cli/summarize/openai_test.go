@@ -0,0 +1,173 @@
+package summarize
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testSummaryJSON = `{"intent":"Fix login","outcome":"Fixed","learnings":{"repo":[],"code":[],"workflow":[]},"friction":[],"open_items":[]}`
+
+func newChatCompletionServer(t *testing.T, content string, captured *chatCompletionRequest, authHeader *string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		if authHeader != nil {
+			*authHeader = r.Header.Get("Authorization")
+		}
+		if captured != nil {
+			if err := json.NewDecoder(r.Body).Decode(captured); err != nil {
+				t.Errorf("failed to decode request: %v", err)
+			}
+		}
+		resp := map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]string{"role": "assistant", "content": content}},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp) //nolint:errcheck // test server
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOpenAIGenerator_ValidResponse(t *testing.T) {
+	var captured chatCompletionRequest
+	var auth string
+	srv := newChatCompletionServer(t, testSummaryJSON, &captured, &auth)
+
+	gen := &OpenAIGenerator{Endpoint: srv.URL + "/v1", Model: "llama3.2", APIKey: "secret"}
+	summary, err := gen.Generate(context.Background(), Input{
+		Transcript: []Entry{{Type: EntryTypeUser, Content: "Fix the login bug"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Intent != "Fix login" {
+		t.Errorf("Intent = %q, want %q", summary.Intent, "Fix login")
+	}
+
+	if captured.Model != "llama3.2" {
+		t.Errorf("request model = %q, want %q", captured.Model, "llama3.2")
+	}
+	if captured.Stream {
+		t.Error("request should not ask for streaming")
+	}
+	if len(captured.Messages) != 1 || captured.Messages[0].Role != "user" {
+		t.Fatalf("unexpected request messages: %+v", captured.Messages)
+	}
+	if !strings.Contains(captured.Messages[0].Content, "Fix the login bug") {
+		t.Error("prompt should contain the transcript")
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want %q", auth, "Bearer secret")
+	}
+}
+
+func TestOpenAIGenerator_NoAPIKey(t *testing.T) {
+	auth := "unset"
+	srv := newChatCompletionServer(t, testSummaryJSON, nil, &auth)
+
+	gen := &OpenAIGenerator{Endpoint: srv.URL + "/v1/chat/completions/", Model: "local"}
+	if _, err := gen.Generate(context.Background(), Input{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if auth != "" {
+		t.Errorf("Authorization header should be omitted without an API key, got %q", auth)
+	}
+}
+
+func TestOpenAIGenerator_MarkdownContent(t *testing.T) {
+	srv := newChatCompletionServer(t, "Here is the summary:\n```json\n"+testSummaryJSON+"\n```", nil, nil)
+
+	gen := &OpenAIGenerator{Endpoint: srv.URL + "/v1", Model: "local"}
+	summary, err := gen.Generate(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Outcome != "Fixed" {
+		t.Errorf("Outcome = %q, want %q", summary.Outcome, "Fixed")
+	}
+}
+
+func TestOpenAIGenerator_ErrorCases(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		wantErr string
+	}{
+		{
+			name: "error status",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				http.Error(w, `{"error":"model not found"}`, http.StatusNotFound)
+			},
+			wantErr: "model not found",
+		},
+		{
+			name: "invalid response body",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("not json")) //nolint:errcheck // test server
+			},
+			wantErr: "failed to parse summary response",
+		},
+		{
+			name: "no choices",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(`{"choices":[]}`)) //nolint:errcheck // test server
+			},
+			wantErr: "no choices",
+		},
+		{
+			name: "content is not a summary",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"sorry"}}]}`)) //nolint:errcheck // test server
+			},
+			wantErr: "failed to parse summary JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			gen := &OpenAIGenerator{Endpoint: srv.URL, Model: "local"}
+			_, err := gen.Generate(context.Background(), Input{})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOpenAIGenerator_ModelRequiredForCustomEndpoint(t *testing.T) {
+	gen := &OpenAIGenerator{Endpoint: "http://localhost:1/v1"}
+	_, err := gen.Generate(context.Background(), Input{})
+	if err == nil || !strings.Contains(err.Error(), "a model is required") {
+		t.Errorf("expected model required error, got %v", err)
+	}
+}
+
+func TestChatCompletionsURL(t *testing.T) {
+	tests := map[string]string{
+		"https://api.openai.com/v1":                    "https://api.openai.com/v1/chat/completions",
+		"http://localhost:11434/v1/":                   "http://localhost:11434/v1/chat/completions",
+		"http://localhost:8080/v1/chat/completions":    "http://localhost:8080/v1/chat/completions",
+		"http://localhost:8080/v1/chat/completions///": "http://localhost:8080/v1/chat/completions",
+	}
+	for in, want := range tests {
+		if got := chatCompletionsURL(in); got != want {
+			t.Errorf("chatCompletionsURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
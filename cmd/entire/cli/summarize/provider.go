@@ -0,0 +1,60 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+)
+
+// Summary provider names accepted in strategy_options.summarize.provider.
+const (
+	ProviderClaude   = "claude"
+	ProviderOpenAI   = "openai"
+	ProviderOllama   = "ollama"
+	ProviderGemini   = "gemini"
+	ProviderOpenCode = "opencode"
+)
+
+// Providers returns the supported summary provider names.
+func Providers() []string {
+	return []string{ProviderClaude, ProviderGemini, ProviderOllama, ProviderOpenAI, ProviderOpenCode}
+}
+
+// NewGenerator creates the summary generator configured by opts.
+// An empty provider selects the Claude CLI, preserving the original behavior.
+//
+// The "openai" provider works with any OpenAI-compatible endpoint; "ollama" is
+// the same generator with a local default endpoint and no API key.
+func NewGenerator(opts settings.SummarizeOptions) (Generator, error) {
+	provider := strings.ToLower(strings.TrimSpace(opts.Provider))
+	switch provider {
+	case "", ProviderClaude:
+		return &ClaudeGenerator{Model: opts.Model}, nil
+	case ProviderOpenAI:
+		keyEnv := opts.APIKeyEnv
+		if keyEnv == "" {
+			keyEnv = DefaultOpenAIAPIKeyEnv
+		}
+		return &OpenAIGenerator{Endpoint: opts.Endpoint, Model: opts.Model, APIKey: apiKeyFromEnv(keyEnv)}, nil
+	case ProviderOllama:
+		endpoint := opts.Endpoint
+		if endpoint == "" {
+			endpoint = DefaultOllamaEndpoint
+		}
+		return &OpenAIGenerator{Endpoint: endpoint, Model: opts.Model, APIKey: apiKeyFromEnv(opts.APIKeyEnv)}, nil
+	case ProviderGemini:
+		return &GeminiGenerator{Model: opts.Model}, nil
+	case ProviderOpenCode:
+		return &OpenCodeGenerator{Model: opts.Model}, nil
+	default:
+		return nil, fmt.Errorf("unknown summarize provider %q (available: %s)", opts.Provider, strings.Join(Providers(), ", "))
+	}
+}
+
+// GeneratorFromSettings creates the summary generator configured in the
+// repository's settings.
+func GeneratorFromSettings(ctx context.Context) (Generator, error) {
+	return NewGenerator(settings.GetSummarizeOptions(ctx))
+}
@@ -0,0 +1,107 @@
+package summarize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+)
+
+func TestNewGenerator(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-default")
+	t.Setenv("CUSTOM_KEY", "sk-custom")
+
+	t.Run("default is claude", func(t *testing.T) {
+		gen, err := NewGenerator(settings.SummarizeOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := gen.(*ClaudeGenerator); !ok {
+			t.Errorf("expected *ClaudeGenerator, got %T", gen)
+		}
+	})
+
+	t.Run("claude with model", func(t *testing.T) {
+		gen, err := NewGenerator(settings.SummarizeOptions{Provider: "Claude", Model: "opus"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c, ok := gen.(*ClaudeGenerator); !ok || c.Model != "opus" {
+			t.Errorf("unexpected generator: %#v", gen)
+		}
+	})
+
+	t.Run("openai reads default key env", func(t *testing.T) {
+		gen, err := NewGenerator(settings.SummarizeOptions{Provider: ProviderOpenAI})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		o, ok := gen.(*OpenAIGenerator)
+		if !ok {
+			t.Fatalf("expected *OpenAIGenerator, got %T", gen)
+		}
+		if o.APIKey != "sk-default" || o.Endpoint != "" {
+			t.Errorf("unexpected generator: %+v", o)
+		}
+	})
+
+	t.Run("openai with custom endpoint and key env", func(t *testing.T) {
+		gen, err := NewGenerator(settings.SummarizeOptions{
+			Provider:  ProviderOpenAI,
+			Endpoint:  "http://localhost:8080/v1",
+			Model:     "qwen",
+			APIKeyEnv: "CUSTOM_KEY",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		o, ok := gen.(*OpenAIGenerator)
+		if !ok {
+			t.Fatalf("expected *OpenAIGenerator, got %T", gen)
+		}
+		if o.APIKey != "sk-custom" || o.Endpoint != "http://localhost:8080/v1" || o.Model != "qwen" {
+			t.Errorf("unexpected generator: %+v", o)
+		}
+	})
+
+	t.Run("ollama defaults to local endpoint without key", func(t *testing.T) {
+		gen, err := NewGenerator(settings.SummarizeOptions{Provider: ProviderOllama, Model: "llama3.2"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		o, ok := gen.(*OpenAIGenerator)
+		if !ok {
+			t.Fatalf("expected *OpenAIGenerator, got %T", gen)
+		}
+		if o.Endpoint != DefaultOllamaEndpoint || o.APIKey != "" {
+			t.Errorf("unexpected generator: %+v", o)
+		}
+	})
+
+	t.Run("gemini", func(t *testing.T) {
+		gen, err := NewGenerator(settings.SummarizeOptions{Provider: ProviderGemini})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := gen.(*GeminiGenerator); !ok {
+			t.Errorf("expected *GeminiGenerator, got %T", gen)
+		}
+	})
+
+	t.Run("opencode", func(t *testing.T) {
+		gen, err := NewGenerator(settings.SummarizeOptions{Provider: ProviderOpenCode, Model: "openai/gpt-4o"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if o, ok := gen.(*OpenCodeGenerator); !ok || o.Model != "openai/gpt-4o" {
+			t.Errorf("unexpected generator: %#v", gen)
+		}
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		_, err := NewGenerator(settings.SummarizeOptions{Provider: "bard"})
+		if err == nil || !strings.Contains(err.Error(), `unknown summarize provider "bard"`) {
+			t.Errorf("expected unknown provider error, got %v", err)
+		}
+	})
+}
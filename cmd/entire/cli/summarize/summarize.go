@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/aider"
 	"github.com/entireio/cli/cmd/entire/cli/agent/geminicli"
 	"github.com/entireio/cli/cmd/entire/cli/agent/opencode"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
@@ -99,6 +100,13 @@ type Entry struct {
 
 	// ToolDetail is a description or file path (for tool entries)
 	ToolDetail string
+
+	// ToolUseID is the agent's ID for the tool call (for tool entries), if recorded
+	ToolUseID string
+
+	// ToolInput is the tool's input as a JSON object (for tool entries), if recorded.
+	// Not included in FormatCondensedTranscript.
+	ToolInput string
 }
 
 // minimalDetailTools lists tools that should show only essential details in summaries.
@@ -119,6 +127,8 @@ func BuildCondensedTranscriptFromBytes(content []byte, agentType agent.AgentType
 		return buildCondensedTranscriptFromGemini(content)
 	case agent.AgentTypeOpenCode:
 		return buildCondensedTranscriptFromOpenCode(content)
+	case agent.AgentTypeAider:
+		return buildCondensedTranscriptFromAider(content), nil
 	case agent.AgentTypeClaudeCode, agent.AgentTypeCursor, agent.AgentTypeUnknown:
 		// Claude/cursor format - fall through to shared logic below
 	}
@@ -161,6 +171,8 @@ func buildCondensedTranscriptFromGemini(content []byte) ([]Entry, error) {
 					Type:       EntryTypeTool,
 					ToolName:   tc.Name,
 					ToolDetail: extractGenericToolDetail(tc.Args),
+					ToolUseID:  tc.ID,
+					ToolInput:  marshalToolInput(tc.Args),
 				})
 			}
 		}
@@ -169,6 +181,27 @@ func buildCondensedTranscriptFromGemini(content []byte) ([]Entry, error) {
 	return entries, nil
 }
 
+// buildCondensedTranscriptFromAider parses an Aider Markdown chat history and extracts a condensed view.
+// Aider's own output is reduced to the edits it applied; token reports and notices are dropped.
+func buildCondensedTranscriptFromAider(content []byte) []Entry {
+	var entries []Entry
+	for _, session := range aider.ParseChatHistory(content) {
+		for _, msg := range session.Messages {
+			switch msg.Role {
+			case aider.RoleUser:
+				entries = append(entries, Entry{Type: EntryTypeUser, Content: msg.Content})
+			case aider.RoleAssistant:
+				entries = append(entries, Entry{Type: EntryTypeAssistant, Content: msg.Content})
+			case aider.RoleTool:
+				for _, file := range msg.EditedFiles() {
+					entries = append(entries, Entry{Type: EntryTypeTool, ToolName: "Edit", ToolDetail: file})
+				}
+			}
+		}
+	}
+	return entries
+}
+
 // buildCondensedTranscriptFromOpenCode parses OpenCode export JSON transcript and extracts a condensed view.
 func buildCondensedTranscriptFromOpenCode(content []byte) ([]Entry, error) {
 	session, err := opencode.ParseExportSession(content)
@@ -204,6 +237,8 @@ func buildCondensedTranscriptFromOpenCode(content []byte) ([]Entry, error) {
 						Type:       EntryTypeTool,
 						ToolName:   part.Tool,
 						ToolDetail: extractOpenCodeToolDetail(part.State.Input),
+						ToolUseID:  part.CallID,
+						ToolInput:  marshalToolInput(part.State.Input),
 					})
 				}
 			}
@@ -235,6 +270,18 @@ func extractGenericToolDetail(input map[string]interface{}) string {
 	return ""
 }
 
+// marshalToolInput encodes a decoded tool input map as JSON, or "" if there is none.
+func marshalToolInput(input map[string]interface{}) string {
+	if len(input) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
 // BuildCondensedTranscript extracts a condensed view of the transcript.
 // It processes user prompts, assistant responses, and tool calls into
 // a simplified format suitable for LLM summarization.
@@ -311,6 +358,8 @@ func extractAssistantEntries(line transcript.Line) []Entry {
 				Type:       EntryTypeTool,
 				ToolName:   block.Name,
 				ToolDetail: detail,
+				ToolUseID:  block.ID,
+				ToolInput:  string(block.Input),
 			})
 		}
 	}
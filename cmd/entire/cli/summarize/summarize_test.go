@@ -74,6 +74,7 @@ func TestBuildCondensedTranscript_ToolCalls(t *testing.T) {
 				Content: []transcript.ContentBlock{
 					{
 						Type: "tool_use",
+						ID:   "toolu_read1",
 						Name: "Read",
 						Input: mustMarshal(t, transcript.ToolInput{
 							FilePath: "/path/to/file.go",
@@ -101,6 +102,14 @@ func TestBuildCondensedTranscript_ToolCalls(t *testing.T) {
 	if entries[0].ToolDetail != "/path/to/file.go" {
 		t.Errorf("expected tool detail /path/to/file.go, got %s", entries[0].ToolDetail)
 	}
+
+	if entries[0].ToolUseID != "toolu_read1" {
+		t.Errorf("expected tool use ID toolu_read1, got %s", entries[0].ToolUseID)
+	}
+
+	if entries[0].ToolInput != `{"file_path":"/path/to/file.go"}` {
+		t.Errorf("expected raw tool input, got %s", entries[0].ToolInput)
+	}
 }
 
 func TestBuildCondensedTranscript_ToolCallWithCommand(t *testing.T) {
@@ -493,6 +502,9 @@ func TestBuildCondensedTranscriptFromBytes_GeminiToolCalls(t *testing.T) {
 	if entries[3].ToolDetail != "go build ./..." {
 		t.Errorf("entry 3: expected tool detail 'go build ./...', got %s", entries[3].ToolDetail)
 	}
+	if entries[3].ToolUseID != "tc-2" || entries[3].ToolInput != `{"command":"go build ./..."}` {
+		t.Errorf("entry 3: expected tool call ID and input, got %q %q", entries[3].ToolUseID, entries[3].ToolInput)
+	}
 }
 
 func TestBuildCondensedTranscriptFromBytes_GeminiToolCallArgShapes(t *testing.T) {
@@ -769,6 +781,9 @@ func TestBuildCondensedTranscriptFromBytes_OpenCodeToolCalls(t *testing.T) {
 	if entries[3].ToolDetail != "go test ./..." {
 		t.Errorf("entry 3: expected tool detail 'go test ./...', got %s", entries[3].ToolDetail)
 	}
+	if entries[3].ToolUseID != "call-2" || entries[3].ToolInput != `{"command":"go test ./..."}` {
+		t.Errorf("entry 3: expected call ID and input, got %q %q", entries[3].ToolUseID, entries[3].ToolInput)
+	}
 }
 
 func TestBuildCondensedTranscriptFromBytes_OpenCodeSkipsEmptyContent(t *testing.T) {
@@ -882,3 +897,35 @@ func mustMarshal(t *testing.T, v interface{}) json.RawMessage {
 	}
 	return data
 }
+
+func TestBuildCondensedTranscriptFromBytes_Aider(t *testing.T) {
+	chatHistory := `# aider chat started at 2026-01-01 12:00:00
+
+> Aider v0.86.1  
+
+#### Fix the bug in main.go  
+
+I'll fix the bug.
+
+> Tokens: 1.2k sent, 80 received.  
+> Applied edit to main.go  
+`
+
+	entries, err := BuildCondensedTranscriptFromBytes([]byte(chatHistory), agent.AgentTypeAider)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d: %+v", len(entries), entries)
+	}
+	if entries[0].Type != EntryTypeUser || entries[0].Content != "Fix the bug in main.go" {
+		t.Errorf("entry 0: unexpected entry: %+v", entries[0])
+	}
+	if entries[1].Type != EntryTypeAssistant || entries[1].Content != "I'll fix the bug." {
+		t.Errorf("entry 1: unexpected entry: %+v", entries[1])
+	}
+	if entries[2].Type != EntryTypeTool || entries[2].ToolName != "Edit" || entries[2].ToolDetail != "main.go" {
+		t.Errorf("entry 2: unexpected entry: %+v", entries[2])
+	}
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/spf13/cobra"
+)
+
+func newSyncCmd() *cobra.Command {
+	var (
+		pushFlag   bool
+		resyncFlag bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "sync [remote]",
+		Short: "Fetch and merge checkpoints from a remote",
+		Long: `Fetch the ` + paths.MetadataBranchName + ` branch from a remote and merge it
+into the local branch, so teammates' checkpoints show up in explain,
+resume and report without waiting for your next push.
+
+The local branch is fast-forwarded when possible. When both sides have new
+checkpoints, their trees are combined in a merge commit, the same way a
+rejected push is reconciled. With --push, the merged branch is pushed back.
+
+The remote defaults to "strategy_options": {"metadata_remote": ...} when it is
+set in .entire/settings.json, and to origin otherwise.
+
+To sync automatically after git pull and on branch switches, set
+"strategy_options": {"auto_sync": true} in .entire/settings.json.
+
+When the remote branch was rewritten by 'entire purge' or 'entire gc',
+merging would bring the removed content back, so sync refuses. Use --resync
+to replace the local branch with the remote one; checkpoints that only exist
+locally are kept.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkDisabledGuard(cmd.Context(), cmd.OutOrStdout()) {
+				return nil
+			}
+			remote := strategy.MetadataRemote(cmd.Context(), "origin")
+			if len(args) > 0 {
+				remote = args[0]
+			}
+			if resyncFlag {
+				return runResync(cmd.Context(), cmd.OutOrStdout(), remote)
+			}
+			return runSync(cmd.Context(), cmd.OutOrStdout(), remote, pushFlag)
+		},
+	}
+
+	cmd.Flags().BoolVar(&pushFlag, "push", false, "Push the merged branch back to the remote")
+	cmd.Flags().BoolVar(&resyncFlag, "resync", false, "Replace the local branch with the remote one after a purge or gc")
+	cmd.MarkFlagsMutuallyExclusive("push", "resync")
+
+	return cmd
+}
+
+func runSync(ctx context.Context, w io.Writer, remote string, push bool) error {
+	result, err := strategy.SyncMetadataBranch(ctx, remote, push)
+	if purgedErr := (*checkpoint.PurgedHistoryError)(nil); errors.As(err, &purgedErr) {
+		if purgedErr.Local {
+			return fmt.Errorf("failed to sync checkpoints: %w; replace the remote branch with:\n  %s",
+				err, strings.Join(strategy.ForcePushMetadataCommands(ctx, remote), "\n  "))
+		}
+		return fmt.Errorf("failed to sync checkpoints: %w; run 'entire sync --resync %s' to replace the local branch", err, remote)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to sync checkpoints: %w", err)
+	}
+
+	switch {
+	case result.RemoteMissing:
+		fmt.Fprintf(w, "%s has no %s branch yet\n", remote, paths.MetadataBranchName)
+	case result.NewCheckpoints == 0 && result.UpdatedCheckpoints == 0:
+		fmt.Fprintf(w, "Checkpoints are up to date with %s\n", remote)
+	default:
+		fmt.Fprintf(w, "Synced checkpoints from %s: %d new, %d updated\n", remote, result.NewCheckpoints, result.UpdatedCheckpoints)
+		if result.Merged {
+			fmt.Fprintln(w, "Local and remote checkpoints had diverged and were merged")
+		}
+	}
+	if result.Pushed {
+		fmt.Fprintf(w, "Pushed %s to %s\n", paths.MetadataBranchName, remote)
+	}
+	return nil
+}
+
+func runResync(ctx context.Context, w io.Writer, remote string) error {
+	result, err := strategy.ResyncMetadataBranch(ctx, remote)
+	if err != nil {
+		return fmt.Errorf("failed to resync checkpoints: %w", err)
+	}
+	if result.RemoteMissing {
+		fmt.Fprintf(w, "%s has no %s branch yet\n", remote, paths.MetadataBranchName)
+		return nil
+	}
+	fmt.Fprintf(w, "Replaced %s with the branch on %s (%d new, %d updated checkpoints)\n",
+		paths.MetadataBranchName, remote, result.NewCheckpoints, result.UpdatedCheckpoints)
+	if result.NotesRebuilt {
+		fmt.Fprintf(w, "Rebuilt %s from the purged checkpoints\n", paths.CheckpointNotesRef)
+	}
+	return nil
+}
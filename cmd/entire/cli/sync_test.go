@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestRunSync(t *testing.T) {
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.CommandContext(context.Background(), "git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	bareDir := t.TempDir()
+	run(bareDir, "init", "--quiet", "--bare")
+
+	// A teammate pushes a checkpoint.
+	teammate := filepath.Join(t.TempDir(), "teammate")
+	run(filepath.Dir(teammate), "clone", "--quiet", bareDir, "teammate")
+	repo, err := git.PlainOpen(teammate)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	err = checkpoint.NewGitStore(repo).WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("abc123def456"),
+		SessionID:    "session-teammate",
+		Strategy:     "manual-commit",
+		Transcript:   []byte(`{"type":"user","message":{"content":"hi"}}` + "\n"),
+		AuthorName:   "Teammate",
+		AuthorEmail:  "teammate@example.com",
+	})
+	if err != nil {
+		t.Fatalf("failed to write committed checkpoint: %v", err)
+	}
+
+	tmpDir := setupTestDir(t)
+	run(tmpDir, "clone", "--quiet", bareDir, ".")
+	testutil.WriteFile(t, tmpDir, "README.md", "hello")
+	testutil.GitAdd(t, tmpDir, "README.md")
+	testutil.GitCommit(t, tmpDir, "initial commit")
+
+	var buf bytes.Buffer
+	if err := runSync(context.Background(), &buf, "origin", false); err != nil {
+		t.Fatalf("runSync() error = %v", err)
+	}
+	if want := "origin has no " + paths.MetadataBranchName + " branch yet"; !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q, got: %s", want, buf.String())
+	}
+
+	run(teammate, "push", "--quiet", "origin", paths.MetadataBranchName)
+
+	buf.Reset()
+	if err := runSync(context.Background(), &buf, "origin", false); err != nil {
+		t.Fatalf("runSync() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "Synced checkpoints from origin: 1 new, 0 updated") {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+
+	// The teammate's checkpoint is now readable locally.
+	var explainBuf bytes.Buffer
+	if err := runExplainRender(context.Background(), &explainBuf, "abc123", "md", ""); err != nil {
+		t.Errorf("synced checkpoint not readable: %v", err)
+	}
+
+	buf.Reset()
+	if err := runSync(context.Background(), &buf, "origin", false); err != nil {
+		t.Fatalf("runSync() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "Checkpoints are up to date with origin") {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+
+	if err := runSync(context.Background(), &buf, "nope", false); err == nil {
+		t.Error("expected error for unknown remote")
+	}
+}
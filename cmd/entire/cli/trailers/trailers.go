@@ -6,6 +6,7 @@ package trailers
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	checkpointID "github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
@@ -48,6 +49,11 @@ const (
 	// AgentTrailerKey identifies the agent that created a checkpoint.
 	// Format: human-readable agent name e.g. "Claude Code", "Cursor"
 	AgentTrailerKey = "Entire-Agent"
+
+	// RecoveryShadowTrailerKey records a shadow branch tip in a recovery point,
+	// so undoing a rewind or reset can put the branch back.
+	// Format: "<branch>@<full-commit-hash>" e.g. "entire/2b4c177-e3b0c4@<40 hex chars>"
+	RecoveryShadowTrailerKey = "Entire-Recovery-Shadow"
 )
 
 // Pre-compiled regexes for trailer parsing.
@@ -60,6 +66,7 @@ var (
 	condensationTrailerRegex = regexp.MustCompile(CondensationTrailerKey + `:\s*(.+)`)
 	sessionTrailerRegex      = regexp.MustCompile(SessionTrailerKey + `:\s*(.+)`)
 	checkpointTrailerRegex   = regexp.MustCompile(CheckpointTrailerKey + `:\s*(` + checkpointID.Pattern + `)(?:\s|$)`)
+	recoveryShadowRegex      = regexp.MustCompile(RecoveryShadowTrailerKey + `:\s*(\S+)@([a-f0-9]{40})`)
 )
 
 // ParseStrategy extracts strategy from commit message.
@@ -138,6 +145,25 @@ func ParseCheckpoint(commitMessage string) (checkpointID.CheckpointID, bool) {
 	return checkpointID.EmptyCheckpointID, false
 }
 
+// ParseAllCheckpoints extracts all valid checkpoint IDs from a commit message,
+// deduplicated in order. Squashing commits concatenates their messages, so a
+// squashed commit can have several Entire-Checkpoint trailers.
+func ParseAllCheckpoints(commitMessage string) []checkpointID.CheckpointID {
+	matches := checkpointTrailerRegex.FindAllStringSubmatch(commitMessage, -1)
+	var cpIDs []checkpointID.CheckpointID
+	for _, match := range matches {
+		if len(match) < 2 {
+			continue
+		}
+		cpID, err := checkpointID.NewCheckpointID(strings.TrimSpace(match[1]))
+		if err != nil || slices.Contains(cpIDs, cpID) {
+			continue
+		}
+		cpIDs = append(cpIDs, cpID)
+	}
+	return cpIDs
+}
+
 // ParseAllSessions extracts all session IDs from a commit message.
 // Returns a slice of session IDs (may be empty if none found).
 // Duplicate session IDs are deduplicated while preserving order.
@@ -226,3 +252,38 @@ func FormatShadowTaskCommit(message, taskMetadataDir, sessionID string) string {
 func FormatCheckpoint(message string, cpID checkpointID.CheckpointID) string {
 	return fmt.Sprintf("%s\n\n%s: %s\n", message, CheckpointTrailerKey, cpID.String())
 }
+
+// FormatRecoveryPoint creates a commit message for a recovery point with one
+// Entire-Recovery-Shadow trailer per shadow branch tip, sorted by branch.
+func FormatRecoveryPoint(message string, shadowTips map[string]string) string {
+	if len(shadowTips) == 0 {
+		return message + "\n"
+	}
+	branches := make([]string, 0, len(shadowTips))
+	for branch := range shadowTips {
+		branches = append(branches, branch)
+	}
+	slices.Sort(branches)
+
+	var sb strings.Builder
+	sb.WriteString(message)
+	sb.WriteString("\n\n")
+	for _, branch := range branches {
+		fmt.Fprintf(&sb, "%s: %s@%s\n", RecoveryShadowTrailerKey, branch, shadowTips[branch])
+	}
+	return sb.String()
+}
+
+// ParseRecoveryShadows extracts the shadow branch tips from a recovery point's
+// commit message, keyed by branch name.
+func ParseRecoveryShadows(commitMessage string) map[string]string {
+	matches := recoveryShadowRegex.FindAllStringSubmatch(commitMessage, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	tips := make(map[string]string, len(matches))
+	for _, match := range matches {
+		tips[match[1]] = match[2]
+	}
+	return tips
+}
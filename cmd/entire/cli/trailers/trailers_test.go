@@ -1,6 +1,7 @@
 package trailers
 
 import (
+	"maps"
 	"testing"
 )
 
@@ -318,3 +319,67 @@ func TestParseCheckpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestParseAllCheckpoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []string
+	}{
+		{
+			name:    "no trailer",
+			message: "Simple commit message",
+			want:    nil,
+		},
+		{
+			name:    "single trailer",
+			message: "Add feature\n\nEntire-Checkpoint: a1b2c3d4e5f6\n",
+			want:    []string{"a1b2c3d4e5f6"},
+		},
+		{
+			name:    "squashed messages",
+			message: "Add feature\n\nEntire-Checkpoint: a1b2c3d4e5f6\n\nFix tests\n\nEntire-Checkpoint: 0123456789ab\n",
+			want:    []string{"a1b2c3d4e5f6", "0123456789ab"},
+		},
+		{
+			name:    "duplicates and invalid IDs skipped",
+			message: "A\n\nEntire-Checkpoint: a1b2c3d4e5f6\n\nB\n\nEntire-Checkpoint: abc123\n\nC\n\nEntire-Checkpoint: a1b2c3d4e5f6\n",
+			want:    []string{"a1b2c3d4e5f6"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseAllCheckpoints(tt.message)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseAllCheckpoints() = %v, want %v", got, tt.want)
+			}
+			for i, wantID := range tt.want {
+				if got[i].String() != wantID {
+					t.Errorf("ParseAllCheckpoints()[%d] = %v, want %v", i, got[i], wantID)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatRecoveryPoint_RoundTrip(t *testing.T) {
+	tips := map[string]string{
+		"entire/bbbbbbb-e3b0c4": "2222222222222222222222222222222222222222",
+		"entire/aaaaaaa-e3b0c4": "1111111111111111111111111111111111111111",
+	}
+	msg := FormatRecoveryPoint("Before rewind to abc1234", tips)
+
+	want := "Before rewind to abc1234\n\n" +
+		"Entire-Recovery-Shadow: entire/aaaaaaa-e3b0c4@1111111111111111111111111111111111111111\n" +
+		"Entire-Recovery-Shadow: entire/bbbbbbb-e3b0c4@2222222222222222222222222222222222222222\n"
+	if msg != want {
+		t.Errorf("FormatRecoveryPoint() = %q, want %q", msg, want)
+	}
+	if got := ParseRecoveryShadows(msg); !maps.Equal(got, tips) {
+		t.Errorf("ParseRecoveryShadows() = %v, want %v", got, tips)
+	}
+	if got := ParseRecoveryShadows(FormatRecoveryPoint("Before reset", nil)); got != nil {
+		t.Errorf("ParseRecoveryShadows(no trailers) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,316 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultWatchInterval = time.Second
+	defaultWatchIdle     = 5 * time.Second
+)
+
+func newWatchCmd() *cobra.Command {
+	var agentFlag string
+	var interval, idle time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Capture sessions from agents that don't support hooks",
+		Long: `Watch the session files of agents that don't support lifecycle hooks
+(such as Aider) and record their sessions like any hook-based agent.
+
+File changes are translated into session start, turn start and turn end
+events. A turn ends once the agent's files have been quiet for --idle.
+
+Run this in a separate terminal while you use the agent. Stop it with Ctrl+C;
+any turn still in progress is saved before exiting.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if checkDisabledGuard(cmd.Context(), cmd.OutOrStdout()) {
+				return nil
+			}
+			if interval <= 0 || idle <= 0 {
+				return errors.New("--interval and --idle must be positive durations")
+			}
+			return runWatch(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr(), agentFlag, interval, idle)
+		},
+	}
+
+	cmd.Flags().StringVar(&agentFlag, "agent", "", "Only watch this agent (default: all agents that support file watching)")
+	cmd.Flags().DurationVar(&interval, "interval", defaultWatchInterval, "How often to check the agent's files for changes")
+	cmd.Flags().DurationVar(&idle, "idle", defaultWatchIdle, "How long the agent's files must be quiet before a turn is considered finished")
+
+	return cmd
+}
+
+// runWatch polls the watch paths of every selected FileWatcher agent until ctx is
+// cancelled, dispatching the resulting lifecycle events.
+func runWatch(ctx context.Context, w, errW io.Writer, agentName string, interval, idle time.Duration) error {
+	if _, err := paths.WorktreeRoot(ctx); err != nil {
+		return errors.New("not a git repository")
+	}
+
+	watchers, err := resolveFileWatchers(agentName)
+	if err != nil {
+		return err
+	}
+
+	if cleanup := initHookLogging(ctx); cleanup != nil {
+		defer cleanup()
+	}
+	logCtx := logging.WithComponent(ctx, "watch")
+
+	sw := newSessionWatcher(DispatchLifecycleEvent, errW, idle)
+	pollers := make([]*watchPoller, 0, len(watchers))
+	for _, fw := range watchers {
+		watchPaths, err := fw.GetWatchPaths()
+		if err != nil {
+			return fmt.Errorf("failed to get watch paths for %s: %w", fw.Name(), err)
+		}
+		pollers = append(pollers, newWatchPoller(fw, watchPaths))
+		fmt.Fprintf(w, "Watching %s session files:\n", fw.Type())
+		for _, p := range watchPaths {
+			fmt.Fprintf(w, "  %s\n", p)
+		}
+	}
+	fmt.Fprintln(w, "Press Ctrl+C to stop.")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Save turns in progress. The parent context is already cancelled,
+			// so dispatch with a context that is not.
+			sw.Close(context.WithoutCancel(logCtx))
+			fmt.Fprintln(w, "Stopped watching.")
+			return nil
+		case now := <-ticker.C:
+			for _, p := range pollers {
+				for _, path := range p.Changed() {
+					change, err := p.watcher.OnFileChange(path)
+					if err != nil {
+						logging.Warn(logCtx, "failed to process file change",
+							slog.String("agent", string(p.watcher.Name())),
+							slog.String("path", path),
+							slog.String("error", err.Error()))
+						continue
+					}
+					if change != nil {
+						sw.Handle(logCtx, p.watcher, change, now)
+					}
+				}
+			}
+			sw.Tick(logCtx, now)
+		}
+	}
+}
+
+// resolveFileWatchers returns the named agent, or all registered agents that
+// implement agent.FileWatcher when name is empty.
+func resolveFileWatchers(name string) ([]agent.FileWatcher, error) {
+	if name != "" {
+		ag, err := agent.Get(agent.AgentName(name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get agent: %w", err)
+		}
+		fw, ok := ag.(agent.FileWatcher)
+		if !ok {
+			return nil, fmt.Errorf("agent %q does not support file watching; it reports sessions through hooks", name)
+		}
+		return []agent.FileWatcher{fw}, nil
+	}
+
+	var watchers []agent.FileWatcher
+	for _, agentName := range agent.List() {
+		ag, err := agent.Get(agentName)
+		if err != nil {
+			continue
+		}
+		if fw, ok := ag.(agent.FileWatcher); ok {
+			watchers = append(watchers, fw)
+		}
+	}
+	if len(watchers) == 0 {
+		return nil, errors.New("no registered agent supports file watching")
+	}
+	return watchers, nil
+}
+
+// fileSignature identifies a version of a watched file.
+type fileSignature struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func statSignature(path string) fileSignature {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileSignature{}
+	}
+	return fileSignature{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
+
+// watchPoller detects changes to an agent's watch paths by polling file metadata.
+// Polling keeps the runtime dependency-free and works for files that are created
+// after the watcher starts.
+type watchPoller struct {
+	watcher agent.FileWatcher
+	paths   []string
+	seen    map[string]fileSignature
+}
+
+// newWatchPoller creates a poller whose baseline is the current state of the files,
+// so activity that happened before the watcher started is not replayed.
+func newWatchPoller(fw agent.FileWatcher, watchPaths []string) *watchPoller {
+	p := &watchPoller{watcher: fw, paths: watchPaths, seen: make(map[string]fileSignature, len(watchPaths))}
+	for _, path := range watchPaths {
+		p.seen[path] = statSignature(path)
+	}
+	return p
+}
+
+// Changed returns the paths that were created or modified since the last call,
+// in watch path order.
+func (p *watchPoller) Changed() []string {
+	var changed []string
+	for _, path := range p.paths {
+		sig := statSignature(path)
+		if sig != p.seen[path] {
+			p.seen[path] = sig
+			if sig.exists {
+				changed = append(changed, path)
+			}
+		}
+	}
+	return changed
+}
+
+// watchedSession tracks the lifecycle state of one agent's current session.
+type watchedSession struct {
+	agent        agent.Agent
+	sessionID    string
+	sessionRef   string
+	turnOpen     bool
+	lastActivity time.Time
+}
+
+// sessionWatcher turns agent.SessionChange notifications into lifecycle events.
+// File-based agents never report a turn end explicitly, so a turn is closed once
+// its files have been quiet for the idle duration.
+type sessionWatcher struct {
+	dispatch func(context.Context, agent.Agent, *agent.Event) error
+	errW     io.Writer
+	idle     time.Duration
+	sessions map[agent.AgentName]*watchedSession
+}
+
+func newSessionWatcher(dispatch func(context.Context, agent.Agent, *agent.Event) error, errW io.Writer, idle time.Duration) *sessionWatcher {
+	return &sessionWatcher{
+		dispatch: dispatch,
+		errW:     errW,
+		idle:     idle,
+		sessions: make(map[agent.AgentName]*watchedSession),
+	}
+}
+
+// Handle processes one session change reported by ag.
+func (sw *sessionWatcher) Handle(ctx context.Context, ag agent.Agent, change *agent.SessionChange, now time.Time) {
+	s := sw.sessions[ag.Name()]
+	if s == nil || s.sessionID != change.SessionID {
+		if s != nil {
+			sw.endSession(ctx, s)
+		}
+		s = &watchedSession{agent: ag, sessionID: change.SessionID, sessionRef: change.SessionRef}
+		sw.sessions[ag.Name()] = s
+		sw.emit(ctx, s, agent.SessionStart, "")
+	}
+	if change.SessionRef != "" {
+		s.sessionRef = change.SessionRef
+	}
+
+	switch change.EventType {
+	case agent.HookUserPromptSubmit:
+		// A new prompt before the idle timeout still closes the previous turn
+		sw.endTurn(ctx, s)
+		sw.emit(ctx, s, agent.TurnStart, change.Prompt)
+		s.turnOpen = true
+		s.lastActivity = now
+	case agent.HookStop:
+		if s.turnOpen {
+			s.lastActivity = now
+		}
+	case agent.HookSessionEnd:
+		sw.endSession(ctx, s)
+		delete(sw.sessions, ag.Name())
+	case agent.HookSessionStart, agent.HookPreToolUse, agent.HookPostToolUse:
+		// Session start is handled above; tool activity has no lifecycle action
+	}
+}
+
+// Tick ends turns whose files have been quiet for the idle duration.
+func (sw *sessionWatcher) Tick(ctx context.Context, now time.Time) {
+	for _, s := range sw.sessions {
+		if s.turnOpen && now.Sub(s.lastActivity) >= sw.idle {
+			sw.endTurn(ctx, s)
+		}
+	}
+}
+
+// Close ends any open turns. Sessions are left open because the agent may still
+// be running; the next watcher picks them up again on their next change.
+func (sw *sessionWatcher) Close(ctx context.Context) {
+	for _, s := range sw.sessions {
+		sw.endTurn(ctx, s)
+	}
+}
+
+func (sw *sessionWatcher) endTurn(ctx context.Context, s *watchedSession) {
+	if !s.turnOpen {
+		return
+	}
+	s.turnOpen = false
+	sw.emit(ctx, s, agent.TurnEnd, "")
+}
+
+func (sw *sessionWatcher) endSession(ctx context.Context, s *watchedSession) {
+	sw.endTurn(ctx, s)
+	sw.emit(ctx, s, agent.SessionEnd, "")
+}
+
+// emit dispatches a lifecycle event. Failures are reported but never stop the
+// watcher, matching how hook failures never block the agent.
+func (sw *sessionWatcher) emit(ctx context.Context, s *watchedSession, eventType agent.EventType, prompt string) {
+	event := &agent.Event{
+		Type:       eventType,
+		SessionID:  s.sessionID,
+		SessionRef: s.sessionRef,
+		Prompt:     prompt,
+		Timestamp:  time.Now(),
+	}
+	logging.Debug(ctx, "watch dispatching event",
+		slog.String("agent", string(s.agent.Name())),
+		slog.String("event", eventType.String()),
+		slog.String("session_id", s.sessionID),
+	)
+	if err := sw.dispatch(ctx, s.agent, event); err != nil {
+		var silent *SilentError
+		if !errors.As(err, &silent) {
+			fmt.Fprintf(sw.errW, "Warning: %s for session %s failed: %v\n", eventType, s.sessionID, err)
+		}
+	}
+}
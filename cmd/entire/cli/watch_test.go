@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/aider"
+)
+
+// recordedEvent captures a dispatched lifecycle event for assertions.
+type recordedEvent struct {
+	Type      agent.EventType
+	SessionID string
+	Prompt    string
+}
+
+func newRecordingWatcher(idle time.Duration) (*sessionWatcher, *[]recordedEvent, *bytes.Buffer) {
+	var events []recordedEvent
+	var errBuf bytes.Buffer
+	sw := newSessionWatcher(func(_ context.Context, _ agent.Agent, e *agent.Event) error {
+		events = append(events, recordedEvent{Type: e.Type, SessionID: e.SessionID, Prompt: e.Prompt})
+		return nil
+	}, &errBuf, idle)
+	return sw, &events, &errBuf
+}
+
+func eventTypes(events []recordedEvent) string {
+	names := make([]string, 0, len(events))
+	for _, e := range events {
+		names = append(names, e.Type.String())
+	}
+	return strings.Join(names, ",")
+}
+
+func TestNewWatchCmd(t *testing.T) {
+	cmd := newWatchCmd()
+	if cmd.Use != "watch" {
+		t.Errorf("expected Use to be 'watch', got %s", cmd.Use)
+	}
+	for _, name := range []string{"agent", "interval", "idle"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag to exist", name)
+		}
+	}
+}
+
+func TestSessionWatcher_TurnLifecycle(t *testing.T) {
+	ag := &aider.AiderAgent{}
+	sw, events, _ := newRecordingWatcher(5 * time.Second)
+	ctx := context.Background()
+	start := time.Now()
+
+	sw.Handle(ctx, ag, &agent.SessionChange{SessionID: "s1", EventType: agent.HookSessionStart}, start)
+	sw.Handle(ctx, ag, &agent.SessionChange{SessionID: "s1", EventType: agent.HookUserPromptSubmit, Prompt: "fix it"}, start)
+	sw.Handle(ctx, ag, &agent.SessionChange{SessionID: "s1", EventType: agent.HookStop}, start.Add(3*time.Second))
+
+	// Still within the idle window after the last activity
+	sw.Tick(ctx, start.Add(6*time.Second))
+	if got := eventTypes(*events); got != "SessionStart,TurnStart" {
+		t.Fatalf("events before idle = %s, want SessionStart,TurnStart", got)
+	}
+	if (*events)[1].Prompt != "fix it" {
+		t.Errorf("TurnStart prompt = %q, want %q", (*events)[1].Prompt, "fix it")
+	}
+
+	sw.Tick(ctx, start.Add(8*time.Second))
+	if got := eventTypes(*events); got != "SessionStart,TurnStart,TurnEnd" {
+		t.Fatalf("events after idle = %s, want SessionStart,TurnStart,TurnEnd", got)
+	}
+
+	// Quiet activity with no open turn must not produce another TurnEnd
+	sw.Handle(ctx, ag, &agent.SessionChange{SessionID: "s1", EventType: agent.HookStop}, start.Add(9*time.Second))
+	sw.Tick(ctx, start.Add(20*time.Second))
+	if len(*events) != 3 {
+		t.Errorf("unexpected extra events: %s", eventTypes(*events))
+	}
+}
+
+func TestSessionWatcher_NewSessionEndsPrevious(t *testing.T) {
+	ag := &aider.AiderAgent{}
+	sw, events, _ := newRecordingWatcher(time.Minute)
+	ctx := context.Background()
+	now := time.Now()
+
+	sw.Handle(ctx, ag, &agent.SessionChange{SessionID: "s1", EventType: agent.HookUserPromptSubmit, Prompt: "one"}, now)
+	sw.Handle(ctx, ag, &agent.SessionChange{SessionID: "s2", EventType: agent.HookSessionStart}, now)
+
+	want := "SessionStart,TurnStart,TurnEnd,SessionEnd,SessionStart"
+	if got := eventTypes(*events); got != want {
+		t.Fatalf("events = %s, want %s", got, want)
+	}
+	if (*events)[3].SessionID != "s1" || (*events)[4].SessionID != "s2" {
+		t.Errorf("unexpected session IDs: %+v", *events)
+	}
+}
+
+func TestSessionWatcher_PromptClosesOpenTurn(t *testing.T) {
+	ag := &aider.AiderAgent{}
+	sw, events, _ := newRecordingWatcher(time.Minute)
+	ctx := context.Background()
+	now := time.Now()
+
+	sw.Handle(ctx, ag, &agent.SessionChange{SessionID: "s1", EventType: agent.HookUserPromptSubmit, Prompt: "one"}, now)
+	sw.Handle(ctx, ag, &agent.SessionChange{SessionID: "s1", EventType: agent.HookUserPromptSubmit, Prompt: "two"}, now)
+	sw.Close(ctx)
+
+	want := "SessionStart,TurnStart,TurnEnd,TurnStart,TurnEnd"
+	if got := eventTypes(*events); got != want {
+		t.Errorf("events = %s, want %s", got, want)
+	}
+}
+
+func TestSessionWatcher_DispatchErrorsAreReported(t *testing.T) {
+	var errBuf bytes.Buffer
+	sw := newSessionWatcher(func(_ context.Context, _ agent.Agent, e *agent.Event) error {
+		if e.Type == agent.TurnStart {
+			return NewSilentError(errors.New("already reported"))
+		}
+		return errors.New("boom")
+	}, &errBuf, time.Minute)
+
+	sw.Handle(context.Background(), &aider.AiderAgent{}, &agent.SessionChange{SessionID: "s1", EventType: agent.HookUserPromptSubmit}, time.Now())
+
+	out := errBuf.String()
+	if !strings.Contains(out, "SessionStart for session s1 failed: boom") {
+		t.Errorf("expected SessionStart failure warning, got %q", out)
+	}
+	if strings.Contains(out, "already reported") {
+		t.Errorf("silent errors should not be printed, got %q", out)
+	}
+}
+
+func TestWatchPoller_Changed(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.md")
+	created := filepath.Join(dir, "created.md")
+	if err := os.WriteFile(existing, []byte("a"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	p := newWatchPoller(&aider.AiderAgent{}, []string{existing, created})
+	if changed := p.Changed(); len(changed) != 0 {
+		t.Fatalf("expected no changes against baseline, got %v", changed)
+	}
+
+	if err := os.WriteFile(existing, []byte("ab"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.WriteFile(created, []byte("new"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	changed := p.Changed()
+	if len(changed) != 2 || changed[0] != existing || changed[1] != created {
+		t.Errorf("Changed() = %v, want [%s %s]", changed, existing, created)
+	}
+	if changed := p.Changed(); len(changed) != 0 {
+		t.Errorf("expected changes to be reported once, got %v", changed)
+	}
+}
+
+func TestResolveFileWatchers(t *testing.T) {
+	watchers, err := resolveFileWatchers("")
+	if err != nil {
+		t.Fatalf("resolveFileWatchers() error = %v", err)
+	}
+	found := false
+	for _, w := range watchers {
+		if w.Name() == agent.AgentNameAider {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected Aider among file watchers")
+	}
+
+	if _, err := resolveFileWatchers(string(agent.AgentNameClaudeCode)); err == nil || !strings.Contains(err.Error(), "does not support file watching") {
+		t.Errorf("expected hook-based agent to be rejected, got %v", err)
+	}
+}
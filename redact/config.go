@@ -0,0 +1,299 @@
+package redact
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/viper"
+	"github.com/zricethezav/gitleaks/v8/config"
+	"github.com/zricethezav/gitleaks/v8/detect"
+)
+
+// ConfigFileName is the repository-relative path of the redaction config file.
+const ConfigFileName = ".entire/redaction.toml"
+
+// EntropyRuleID identifies findings from the entropy layer.
+const EntropyRuleID = "entropy"
+
+// Config controls how secrets are detected. The zero value reproduces the
+// built-in behavior: entropy detection at threshold 4.5 plus the default
+// gitleaks ruleset, with nothing allowlisted.
+type Config struct {
+	Entropy   EntropyConfig   `json:"entropy,omitzero" toml:"entropy"`
+	Gitleaks  GitleaksConfig  `json:"gitleaks,omitzero" toml:"gitleaks"`
+	Rules     []Rule          `json:"rules,omitempty" toml:"rules"`
+	Allowlist AllowlistConfig `json:"allowlist,omitzero" toml:"allowlist"`
+}
+
+// EntropyConfig tunes the entropy layer.
+type EntropyConfig struct {
+	// Disabled turns off entropy-based detection entirely.
+	Disabled bool `json:"disabled,omitempty" toml:"disabled"`
+	// Threshold is the minimum Shannon entropy for a token to be redacted.
+	// Zero means the default (4.5).
+	Threshold float64 `json:"threshold,omitempty" toml:"threshold"`
+}
+
+// GitleaksConfig extends or trims the gitleaks layer.
+type GitleaksConfig struct {
+	// Disabled turns off gitleaks pattern detection entirely.
+	Disabled bool `json:"disabled,omitempty" toml:"disabled"`
+	// Config is the path to a gitleaks TOML config. Its rules are added to the
+	// default ruleset unless the file sets its own [extend] section.
+	Config string `json:"config,omitempty" toml:"config"`
+	// DisabledRules lists gitleaks rule IDs to skip (e.g., "generic-api-key").
+	DisabledRules []string `json:"disabled_rules,omitempty" toml:"disabled_rules"`
+}
+
+// Rule is a custom regex detection rule.
+type Rule struct {
+	// ID names the rule in reports.
+	ID string `json:"id" toml:"id"`
+	// Regex is the Go regular expression to match.
+	Regex string `json:"regex" toml:"regex"`
+	// SecretGroup selects the capture group to redact. Zero redacts the whole match.
+	SecretGroup int `json:"secret_group,omitempty" toml:"secret_group"`
+}
+
+// AllowlistConfig lists content that is never redacted.
+type AllowlistConfig struct {
+	// Literals are exact strings that are never redacted, along with any
+	// finding that lies inside one of them.
+	Literals []string `json:"literals,omitempty" toml:"literals"`
+	// Regexes drop findings whose text matches any of them.
+	Regexes []string `json:"regexes,omitempty" toml:"regexes"`
+	// Paths are glob patterns (e.g., "testdata/**", "*.golden") for files whose
+	// content is never redacted.
+	Paths []string `json:"paths,omitempty" toml:"paths"`
+}
+
+// IsZero reports whether c has no settings, i.e. it selects the default behavior.
+func (c *Config) IsZero() bool {
+	return c == nil || (c.Entropy == EntropyConfig{} &&
+		!c.Gitleaks.Disabled && c.Gitleaks.Config == "" && len(c.Gitleaks.DisabledRules) == 0 &&
+		len(c.Rules) == 0 &&
+		len(c.Allowlist.Literals) == 0 && len(c.Allowlist.Regexes) == 0 && len(c.Allowlist.Paths) == 0)
+}
+
+// LoadConfigFile reads a redaction config from a TOML file.
+// Returns an error wrapping fs.ErrNotExist if the file does not exist.
+func LoadConfigFile(filePath string) (*Config, error) {
+	data, err := os.ReadFile(filePath) //nolint:gosec // path is the repo's redaction config
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redaction config: %w", err)
+	}
+
+	var cfg Config
+	dec := toml.NewDecoder(strings.NewReader(string(data)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	return &cfg, nil
+}
+
+// compiledRule is a custom rule with its regex compiled.
+type compiledRule struct {
+	id          string
+	re          *regexp.Regexp
+	secretGroup int
+}
+
+// Redactor is a compiled redaction pipeline.
+type Redactor struct {
+	entropyEnabled   bool
+	entropyThreshold float64
+	// detector is nil when the gitleaks layer is disabled or failed to load.
+	detector *detect.Detector
+	rules    []compiledRule
+
+	allowLiterals []string
+	allowRegexes  []*regexp.Regexp
+	allowPaths    []string
+}
+
+// active is the pipeline used by the package-level functions.
+// nil means the default pipeline.
+var active atomic.Pointer[Redactor]
+
+// NewRedactor compiles cfg into a redaction pipeline.
+// A nil cfg yields the default pipeline.
+func NewRedactor(cfg *Config) (*Redactor, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	r := &Redactor{
+		entropyEnabled:   !cfg.Entropy.Disabled,
+		entropyThreshold: entropyThreshold,
+		allowLiterals:    cfg.Allowlist.Literals,
+		allowPaths:       cfg.Allowlist.Paths,
+	}
+	if cfg.Entropy.Threshold < 0 {
+		return nil, fmt.Errorf("entropy threshold must not be negative, got %v", cfg.Entropy.Threshold)
+	}
+	if cfg.Entropy.Threshold > 0 {
+		r.entropyThreshold = cfg.Entropy.Threshold
+	}
+
+	if !cfg.Gitleaks.Disabled {
+		if cfg.Gitleaks.Config == "" && len(cfg.Gitleaks.DisabledRules) == 0 {
+			r.detector = getDetector()
+		} else {
+			d, err := newGitleaksDetector(cfg.Gitleaks)
+			if err != nil {
+				return nil, err
+			}
+			r.detector = d
+		}
+	}
+
+	for i, rule := range cfg.Rules {
+		if rule.Regex == "" {
+			return nil, fmt.Errorf("redaction rule %d (%q) has no regex", i+1, rule.ID)
+		}
+		re, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex for redaction rule %q: %w", rule.ID, err)
+		}
+		if rule.SecretGroup < 0 || rule.SecretGroup > re.NumSubexp() {
+			return nil, fmt.Errorf("redaction rule %q: secret_group %d out of range", rule.ID, rule.SecretGroup)
+		}
+		id := rule.ID
+		if id == "" {
+			id = fmt.Sprintf("custom-%d", i+1)
+		}
+		r.rules = append(r.rules, compiledRule{id: id, re: re, secretGroup: rule.SecretGroup})
+	}
+
+	for _, expr := range cfg.Allowlist.Regexes {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist regex %q: %w", expr, err)
+		}
+		r.allowRegexes = append(r.allowRegexes, re)
+	}
+
+	for _, pattern := range cfg.Allowlist.Paths {
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil {
+			return nil, fmt.Errorf("invalid allowlist path %q: %w", pattern, err)
+		}
+	}
+
+	return r, nil
+}
+
+// Configure compiles cfg and makes it the pipeline used by String, Bytes and
+// JSONLContent. A nil or zero cfg restores the default pipeline. On error the
+// active pipeline is left unchanged.
+func Configure(cfg *Config) error {
+	if cfg.IsZero() {
+		active.Store(nil)
+		return nil
+	}
+	r, err := NewRedactor(cfg)
+	if err != nil {
+		return err
+	}
+	active.Store(r)
+	return nil
+}
+
+// current returns the active pipeline.
+func current() *Redactor {
+	if r := active.Load(); r != nil {
+		return r
+	}
+	return defaultRedactor()
+}
+
+// defaultRedactor is the built-in pipeline, shared by every caller that has no config.
+var defaultRedactor = sync.OnceValue(func() *Redactor {
+	return &Redactor{
+		entropyEnabled:   true,
+		entropyThreshold: entropyThreshold,
+		detector:         getDetector(),
+	}
+})
+
+// newGitleaksDetector builds a detector from the default ruleset extended by
+// the user's gitleaks config and with the requested rules disabled.
+func newGitleaksDetector(gc GitleaksConfig) (*detect.Detector, error) {
+	var vc config.ViperConfig
+	if gc.Config != "" {
+		v := viper.New()
+		v.SetConfigFile(gc.Config)
+		v.SetConfigType("toml")
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("failed to read gitleaks config %s: %w", gc.Config, err)
+		}
+		if err := v.Unmarshal(&vc); err != nil {
+			return nil, fmt.Errorf("failed to parse gitleaks config %s: %w", gc.Config, err)
+		}
+	}
+	// A config without its own [extend] section adds to the defaults rather than
+	// replacing them, which is what users expect from an extension file.
+	if vc.Extend.Path == "" && vc.Extend.URL == "" {
+		vc.Extend.UseDefault = true
+	}
+	vc.Extend.DisabledRules = append(vc.Extend.DisabledRules, gc.DisabledRules...)
+
+	cfg, err := vc.Translate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load gitleaks config: %w", err)
+	}
+	return detect.NewDetector(cfg), nil
+}
+
+// PathAllowed reports whether content from the given file path is allowlisted
+// by the active pipeline and must not be redacted.
+func PathAllowed(p string) bool {
+	return current().PathAllowed(p)
+}
+
+// PathAllowed reports whether content from the given file path is allowlisted.
+// Patterns match the slash-separated path, its base name, or (with a trailing
+// "/**") any path below a directory.
+func (r *Redactor) PathAllowed(p string) bool {
+	if p == "" || len(r.allowPaths) == 0 {
+		return false
+	}
+	p = strings.TrimPrefix(path.Clean(strings.ReplaceAll(p, "\\", "/")), "./")
+	base := path.Base(p)
+	for _, pattern := range r.allowPaths {
+		if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+			if p == dir || strings.HasPrefix(p, dir+"/") || strings.Contains(p, "/"+dir+"/") {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(pattern, p); ok { //nolint:errcheck // patterns are validated in NewRedactor
+			return true
+		}
+		if ok, _ := path.Match(pattern, base); ok { //nolint:errcheck // patterns are validated in NewRedactor
+			return true
+		}
+	}
+	return false
+}
+
+// allowed reports whether a finding's text is allowlisted.
+func (r *Redactor) allowed(text string) bool {
+	for _, lit := range r.allowLiterals {
+		if lit != "" && strings.Contains(lit, text) {
+			return true
+		}
+	}
+	for _, re := range r.allowRegexes {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
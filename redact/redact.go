@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -43,89 +44,172 @@ func getDetector() *detect.Detector {
 	return gitleaksDetector
 }
 
-// region represents a byte range to redact.
-type region struct{ start, end int }
+// region represents a byte range to redact and the rule that flagged it.
+type region struct {
+	start, end int
+	rule       string
+}
 
-// String replaces secrets in s with "REDACTED" using layered detection:
+// String replaces secrets in s with "REDACTED" using the active pipeline
+// (see Configure). By default detection is layered:
 // 1. Entropy-based: high-entropy alphanumeric sequences (threshold 4.5)
 // 2. Pattern-based: gitleaks regex rules (180+ known secret formats)
-// A string is redacted if EITHER method flags it.
+// A string is redacted if ANY layer flags it.
 func String(s string) string {
+	return current().String(s)
+}
+
+// String replaces secrets in s with "REDACTED".
+func (r *Redactor) String(s string) string {
+	return r.redactString(s, nil)
+}
+
+// redactString replaces secrets in s with "REDACTED", tallying each
+// replacement in counts (which may be nil).
+func (r *Redactor) redactString(s string, counts Counts) string {
+	regions := r.findRegions(s)
+	if len(regions) == 0 {
+		return s
+	}
+
+	merged := mergeRegions(regions)
+
+	var b strings.Builder
+	prev := 0
+	for _, reg := range merged {
+		counts.add(reg.rule)
+		b.WriteString(s[prev:reg.start])
+		b.WriteString(RedactedPlaceholder)
+		prev = reg.end
+	}
+	b.WriteString(s[prev:])
+	return b.String()
+}
+
+// findRegions returns every region of s flagged by any layer, unmerged.
+func (r *Redactor) findRegions(s string) []region {
+	// Allowlisted literals are blanked out before scanning so they can neither
+	// be flagged themselves nor contribute to a neighbouring token's entropy.
+	// Blanking keeps byte offsets identical to s.
+	scan := r.maskLiterals(s)
+
 	var regions []region
+	add := func(start, end int, rule string) {
+		if end > start && !r.allowed(s[start:end]) {
+			regions = append(regions, region{start, end, rule})
+		}
+	}
 
 	// 1. Entropy-based detection.
-	for _, loc := range secretPattern.FindAllStringIndex(s, -1) {
-		start, end := loc[0], loc[1]
-
-		// Don't consume characters that are part of JSON/string escape sequences.
-		// Example: in "controller.go\nmodel.go", the regex could match "nmodel"
-		// (consuming the 'n' from '\n'), and after replacement the '\' would be
-		// followed by 'R' from "REDACTED", creating invalid escape '\R'.
-		// Only skip for known JSON escape letters to avoid trimming real secrets
-		// that happen to follow a literal backslash in decoded content.
-		if start > 0 && s[start-1] == '\\' {
-			switch s[start] {
-			case 'n', 't', 'r', 'b', 'f', 'u', '"', '\\', '/':
-				start++
-				if end-start < 10 {
-					continue
+	if r.entropyEnabled {
+		for _, loc := range secretPattern.FindAllStringIndex(scan, -1) {
+			start, end := loc[0], loc[1]
+
+			// Don't consume characters that are part of JSON/string escape sequences.
+			// Example: in "controller.go\nmodel.go", the regex could match "nmodel"
+			// (consuming the 'n' from '\n'), and after replacement the '\' would be
+			// followed by 'R' from "REDACTED", creating invalid escape '\R'.
+			// Only skip for known JSON escape letters to avoid trimming real secrets
+			// that happen to follow a literal backslash in decoded content.
+			if start > 0 && scan[start-1] == '\\' {
+				switch scan[start] {
+				case 'n', 't', 'r', 'b', 'f', 'u', '"', '\\', '/':
+					start++
+					if end-start < 10 {
+						continue
+					}
 				}
 			}
-		}
 
-		if shannonEntropy(s[start:end]) > entropyThreshold {
-			regions = append(regions, region{start, end})
+			if shannonEntropy(scan[start:end]) > r.entropyThreshold {
+				add(start, end, EntropyRuleID)
+			}
 		}
 	}
 
 	// 2. Pattern-based detection via gitleaks.
-	if d := getDetector(); d != nil {
-		for _, f := range d.DetectString(s) {
+	if r.detector != nil {
+		for _, f := range r.detector.DetectString(scan) {
 			if f.Secret == "" {
 				continue
 			}
 			searchFrom := 0
 			for {
-				idx := strings.Index(s[searchFrom:], f.Secret)
+				idx := strings.Index(scan[searchFrom:], f.Secret)
 				if idx < 0 {
 					break
 				}
 				absIdx := searchFrom + idx
-				regions = append(regions, region{absIdx, absIdx + len(f.Secret)})
+				add(absIdx, absIdx+len(f.Secret), f.RuleID)
 				searchFrom = absIdx + len(f.Secret)
 			}
 		}
 	}
 
-	if len(regions) == 0 {
+	// 3. Custom regex rules.
+	for _, rule := range r.rules {
+		for _, m := range rule.re.FindAllStringSubmatchIndex(scan, -1) {
+			start, end := m[2*rule.secretGroup], m[2*rule.secretGroup+1]
+			if start >= 0 {
+				add(start, end, rule.id)
+			}
+		}
+	}
+
+	return regions
+}
+
+// maskLiterals returns s with every allowlisted literal replaced by spaces.
+func (r *Redactor) maskLiterals(s string) string {
+	if len(r.allowLiterals) == 0 {
 		return s
 	}
+	var masked []byte
+	for _, lit := range r.allowLiterals {
+		if lit == "" {
+			continue
+		}
+		searchFrom := 0
+		for {
+			idx := strings.Index(s[searchFrom:], lit)
+			if idx < 0 {
+				break
+			}
+			if masked == nil {
+				masked = []byte(s)
+			}
+			absIdx := searchFrom + idx
+			for i := absIdx; i < absIdx+len(lit); i++ {
+				masked[i] = ' '
+			}
+			searchFrom = absIdx + len(lit)
+		}
+	}
+	if masked == nil {
+		return s
+	}
+	return string(masked)
+}
 
-	// Merge overlapping regions and build result.
-	sort.Slice(regions, func(i, j int) bool {
-		return regions[i].start < regions[j].start
+// mergeRegions sorts regions and merges overlapping ones.
+// A merged region keeps the rule of its first constituent.
+func mergeRegions(regions []region) []region {
+	sorted := slices.Clone(regions)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].start < sorted[j].start
 	})
-	merged := []region{regions[0]}
-	for _, r := range regions[1:] {
+	merged := []region{sorted[0]}
+	for _, reg := range sorted[1:] {
 		last := &merged[len(merged)-1]
-		if r.start <= last.end {
-			if r.end > last.end {
-				last.end = r.end
+		if reg.start <= last.end {
+			if reg.end > last.end {
+				last.end = reg.end
 			}
 		} else {
-			merged = append(merged, r)
+			merged = append(merged, reg)
 		}
 	}
-
-	var b strings.Builder
-	prev := 0
-	for _, r := range merged {
-		b.WriteString(s[prev:r.start])
-		b.WriteString(RedactedPlaceholder)
-		prev = r.end
-	}
-	b.WriteString(s[prev:])
-	return b.String()
+	return merged
 }
 
 // Bytes is a convenience wrapper around String for []byte content.
@@ -155,6 +239,17 @@ func JSONLBytes(b []byte) ([]byte, error) {
 // need redaction, then performs targeted replacements on the raw JSON bytes.
 // Lines with no secrets are returned unchanged, preserving original formatting.
 func JSONLContent(content string) (string, error) {
+	return current().JSONLContent(content)
+}
+
+// JSONLContent redacts JSONL content line by line. See the package-level JSONLContent.
+func (r *Redactor) JSONLContent(content string) (string, error) {
+	return r.jsonlContent(content, nil)
+}
+
+// jsonlContent redacts JSONL content, tallying each replacement in counts
+// (which may be nil).
+func (r *Redactor) jsonlContent(content string, counts Counts) (string, error) {
 	lines := strings.Split(content, "\n")
 	var b strings.Builder
 	for i, line := range lines {
@@ -168,63 +263,118 @@ func JSONLContent(content string) (string, error) {
 		}
 		var parsed any
 		if err := json.Unmarshal([]byte(trimmed), &parsed); err != nil {
-			b.WriteString(String(line))
+			b.WriteString(r.redactString(line, counts))
 			continue
 		}
-		repls := collectJSONLReplacements(parsed)
-		if len(repls) == 0 {
-			b.WriteString(line)
-			continue
-		}
-		result := line
-		for _, r := range repls {
-			origJSON, err := jsonEncodeString(r[0])
-			if err != nil {
-				return "", err
-			}
-			replJSON, err := jsonEncodeString(r[1])
-			if err != nil {
-				return "", err
-			}
-			result = strings.ReplaceAll(result, origJSON, replJSON)
+		result, err := replaceJSONStrings(line, r.collectJSONLReplacements(parsed, counts))
+		if err != nil {
+			return "", err
 		}
 		b.WriteString(result)
 	}
 	return b.String(), nil
 }
 
+// JSONBytes redacts a single JSON document, which may span several lines
+// (e.g., indented metadata), with the same field rules as JSONLContent.
+// Formatting is preserved. Returns an error if b isn't valid JSON.
+func JSONBytes(b []byte) ([]byte, error) {
+	var parsed any
+	if err := json.Unmarshal(b, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	s := string(b)
+	redacted, err := replaceJSONStrings(s, collectJSONLReplacements(parsed))
+	if err != nil {
+		return nil, err
+	}
+	if redacted == s {
+		return b, nil
+	}
+	return []byte(redacted), nil
+}
+
+// replaceJSONStrings replaces each (original, redacted) pair in raw JSON,
+// matching the JSON-encoded form of the strings.
+func replaceJSONStrings(raw string, repls [][2]string) (string, error) {
+	for _, repl := range repls {
+		origJSON, err := jsonEncodeString(repl[0])
+		if err != nil {
+			return "", err
+		}
+		replJSON, err := jsonEncodeString(repl[1])
+		if err != nil {
+			return "", err
+		}
+		raw = strings.ReplaceAll(raw, origJSON, replJSON)
+	}
+	return raw, nil
+}
+
 // collectJSONLReplacements walks a parsed JSON value and collects unique
-// (original, redacted) string pairs for values that need redaction.
+// (original, redacted) string pairs for values that need redaction,
+// using the active pipeline.
 func collectJSONLReplacements(v any) [][2]string {
+	return current().collectJSONLReplacements(v, nil)
+}
+
+// collectJSONLReplacements is the Redactor counterpart of the package-level
+// function. Every occurrence of a redacted value is tallied in counts (which may be nil).
+func (r *Redactor) collectJSONLReplacements(v any, counts Counts) [][2]string {
 	seen := make(map[string]bool)
 	var repls [][2]string
-	var walk func(v any)
-	walk = func(v any) {
-		switch val := v.(type) {
-		case map[string]any:
-			if shouldSkipJSONLObject(val) {
-				return
-			}
-			for k, child := range val {
-				if shouldSkipJSONLField(k) {
-					continue
-				}
-				walk(child)
-			}
-		case []any:
-			for _, child := range val {
-				walk(child)
+	r.walkJSONLStrings(v, func(val string) {
+		redacted := r.redactString(val, counts)
+		if redacted != val && !seen[val] {
+			seen[val] = true
+			repls = append(repls, [2]string{val, redacted})
+		}
+	})
+	return repls
+}
+
+// walkJSONLStrings calls fn for every string value in v that is subject to
+// redaction, skipping excluded fields and objects.
+func (r *Redactor) walkJSONLStrings(v any, fn func(string)) {
+	switch val := v.(type) {
+	case map[string]any:
+		if shouldSkipJSONLObject(val) || r.objectPathAllowed(val) {
+			return
+		}
+		for k, child := range val {
+			if shouldSkipJSONLField(k) {
+				continue
 			}
-		case string:
-			redacted := String(val)
-			if redacted != val && !seen[val] {
-				seen[val] = true
-				repls = append(repls, [2]string{val, redacted})
+			r.walkJSONLStrings(child, fn)
+		}
+	case []any:
+		for _, child := range val {
+			r.walkJSONLStrings(child, fn)
+		}
+	case string:
+		fn(val)
+	}
+}
+
+// objectPathAllowed reports whether a JSON object (typically a tool call input)
+// targets an allowlisted file, e.g. {"file_path": "testdata/x.golden", "content": ...}.
+func (r *Redactor) objectPathAllowed(obj map[string]any) bool {
+	if len(r.allowPaths) == 0 {
+		return false
+	}
+	for k, v := range obj {
+		p, ok := v.(string)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(k) {
+		case "filepath", "file_path", "path", "notebook_path", "target_file":
+			if r.PathAllowed(p) {
+				return true
 			}
 		}
 	}
-	walk(v)
-	return repls
+	return false
 }
 
 // shouldSkipJSONLField returns true if a JSON key should be excluded from scanning/redaction.
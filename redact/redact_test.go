@@ -57,6 +57,22 @@ func TestJSONLBytes_WithSecret(t *testing.T) {
 	}
 }
 
+func TestJSONBytes_Indented(t *testing.T) {
+	input := []byte("{\n  \"session_id\": \"" + highEntropySecret + "\",\n  \"note\": \"key=" + highEntropySecret + "\"\n}\n")
+	result, err := JSONBytes(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []byte("{\n  \"session_id\": \"" + highEntropySecret + "\",\n  \"note\": \"REDACTED\"\n}\n")
+	if !bytes.Equal(result, expected) {
+		t.Errorf("got %q, want %q", result, expected)
+	}
+
+	if _, err := JSONBytes([]byte("{not json")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
 func TestJSONLContent_TopLevelArray(t *testing.T) {
 	// Top-level JSON arrays are valid JSONL and should be redacted.
 	input := `["` + highEntropySecret + `","normal text"]`
@@ -0,0 +1,243 @@
+package redact
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// previewContext is the number of bytes of surrounding text shown on each
+// side of a finding in its preview.
+const previewContext = 20
+
+// Finding is a span of content that the redaction pipeline would replace.
+type Finding struct {
+	// RuleID is the rule that flagged the span: EntropyRuleID, a gitleaks
+	// rule ID (e.g., "aws-access-token"), or a custom rule ID.
+	RuleID string `json:"rule_id"`
+	// Offset is the byte offset of the span in the scanned content.
+	Offset int `json:"offset"`
+	// Length is the byte length of the span.
+	Length int `json:"length"`
+	// Line and Column locate the span (both 1-based; Column counts bytes).
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	// Preview shows the span in context with the secret itself masked.
+	Preview string `json:"preview"`
+}
+
+// Counts tallies redactions by rule ID.
+type Counts map[string]int
+
+// Total returns the number of redactions across all rules.
+func (c Counts) Total() int {
+	total := 0
+	for _, n := range c {
+		total += n
+	}
+	return total
+}
+
+// add records one redaction for rule. It is a no-op on a nil Counts.
+func (c Counts) add(rule string) {
+	if c != nil {
+		c[rule]++
+	}
+}
+
+// StringCounted is String, additionally tallying each replacement in counts.
+func StringCounted(s string, counts Counts) string {
+	return current().redactString(s, counts)
+}
+
+// BytesCounted is Bytes, additionally tallying each replacement in counts.
+func BytesCounted(b []byte, counts Counts) []byte {
+	s := string(b)
+	redacted := StringCounted(s, counts)
+	if redacted == s {
+		return b
+	}
+	return []byte(redacted)
+}
+
+// JSONLBytesCounted is JSONLBytes, additionally tallying each replacement in counts.
+func JSONLBytesCounted(b []byte, counts Counts) ([]byte, error) {
+	s := string(b)
+	redacted, err := current().jsonlContent(s, counts)
+	if err != nil {
+		return nil, err
+	}
+	if redacted == s {
+		return b, nil
+	}
+	return []byte(redacted), nil
+}
+
+// Scan reports every span String would redact in content, without redacting
+// anything. Overlapping spans flagged by different rules are reported separately.
+func Scan(content string) []Finding {
+	return current().Scan(content)
+}
+
+// ScanJSONL reports every span JSONLContent would redact in content, with
+// offsets into the raw JSONL bytes.
+func ScanJSONL(content string) []Finding {
+	return current().ScanJSONL(content)
+}
+
+// Scan reports every span String would redact in content.
+func (r *Redactor) Scan(content string) []Finding {
+	return buildFindings(content, r.findRegions(content))
+}
+
+// ScanJSONL reports every span JSONLContent would redact in content.
+// Like JSONLContent it scans decoded string values, then maps each finding
+// back to its position in the encoded line.
+func (r *Redactor) ScanJSONL(content string) []Finding {
+	var regions []region
+	lineStart := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		var parsed any
+		switch {
+		case trimmed == "":
+		case json.Unmarshal([]byte(trimmed), &parsed) != nil:
+			for _, reg := range r.findRegions(line) {
+				regions = append(regions, region{lineStart + reg.start, lineStart + reg.end, reg.rule})
+			}
+		default:
+			regions = append(regions, r.jsonlLineRegions(line, lineStart, parsed)...)
+		}
+		lineStart += len(line)
+	}
+	return buildFindings(content, regions)
+}
+
+// jsonlLineRegions returns the regions of a parsed JSONL line, offset by lineStart.
+// JSONLContent replaces every occurrence of a redacted value's encoding in the
+// line, so every occurrence is reported.
+func (r *Redactor) jsonlLineRegions(line string, lineStart int, parsed any) []region {
+	var regions []region
+	seen := make(map[string]bool)
+	r.walkJSONLStrings(parsed, func(val string) {
+		if seen[val] {
+			return
+		}
+		seen[val] = true
+
+		valRegions := r.findRegions(val)
+		if len(valRegions) == 0 {
+			return
+		}
+		encoded, err := jsonEncodeString(val)
+		if err != nil {
+			return
+		}
+		for searchFrom := 0; ; {
+			idx := strings.Index(line[searchFrom:], encoded)
+			if idx < 0 {
+				break
+			}
+			absIdx := lineStart + searchFrom + idx
+			for _, reg := range valRegions {
+				regions = append(regions, region{
+					start: absIdx + encodedOffset(val, reg.start),
+					end:   absIdx + encodedOffset(val, reg.end),
+					rule:  reg.rule,
+				})
+			}
+			searchFrom += idx + len(encoded)
+		}
+	})
+	return regions
+}
+
+// encodedOffset maps a byte offset in s to the matching offset in its JSON
+// encoding (which starts with the opening quote).
+func encodedOffset(s string, offset int) int {
+	prefix, err := jsonEncodeString(s[:offset])
+	if err != nil {
+		return offset + 1
+	}
+	return len(prefix) - 1 // Drop the closing quote
+}
+
+// buildFindings converts regions of content into sorted, de-duplicated findings.
+func buildFindings(content string, regions []region) []Finding {
+	if len(regions) == 0 {
+		return nil
+	}
+
+	sort.Slice(regions, func(i, j int) bool {
+		if regions[i].start != regions[j].start {
+			return regions[i].start < regions[j].start
+		}
+		if regions[i].end != regions[j].end {
+			return regions[i].end < regions[j].end
+		}
+		return regions[i].rule < regions[j].rule
+	})
+
+	lineStarts := []int{0}
+	for i := range len(content) {
+		if content[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+
+	findings := make([]Finding, 0, len(regions))
+	for i, reg := range regions {
+		if i > 0 && reg == regions[i-1] {
+			continue
+		}
+		line := sort.Search(len(lineStarts), func(n int) bool { return lineStarts[n] > reg.start }) - 1
+		findings = append(findings, Finding{
+			RuleID:  reg.rule,
+			Offset:  reg.start,
+			Length:  reg.end - reg.start,
+			Line:    line + 1,
+			Column:  reg.start - lineStarts[line] + 1,
+			Preview: preview(content, reg.start, reg.end),
+		})
+	}
+	return findings
+}
+
+// preview returns the line around content[start:end] with the span masked.
+func preview(content string, start, end int) string {
+	lineStart := strings.LastIndexByte(content[:start], '\n') + 1
+	lineEnd := len(content)
+	if i := strings.IndexByte(content[end:], '\n'); i >= 0 {
+		lineEnd = end + i
+	}
+
+	from := max(lineStart, start-previewContext)
+	for from < start && !utf8.RuneStart(content[from]) {
+		from++
+	}
+	to := min(lineEnd, end+previewContext)
+	for to > end && to < len(content) && !utf8.RuneStart(content[to]) {
+		to--
+	}
+
+	var b strings.Builder
+	if from > lineStart {
+		b.WriteString("...")
+	}
+	b.WriteString(content[from:start])
+	b.WriteString(MaskSecret(content[start:end]))
+	b.WriteString(strings.TrimRight(content[end:to], "\r"))
+	if to < lineEnd {
+		b.WriteString("...")
+	}
+	return b.String()
+}
+
+// MaskSecret hides a secret for display, keeping a short prefix so the kind
+// of token is still recognizable (e.g., "AKIA************").
+func MaskSecret(secret string) string {
+	runes := []rune(secret)
+	keep := min(4, len(runes)/4)
+	return string(runes[:keep]) + strings.Repeat("*", min(len(runes)-keep, 12))
+}